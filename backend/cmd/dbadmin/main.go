@@ -0,0 +1,95 @@
+// cmd/dbadmin/main.go
+
+// dbadmin performs database maintenance tasks outside the server:
+//
+//	dbadmin backup [-dir DIR] [-compress=false]   take an online backup (safe while the server runs)
+//	dbadmin list [-dir DIR]                        list backups, newest first
+//	dbadmin restore [-skip-checksum] FILE          restore a backup (stop the server first)
+//
+// Database and backup settings come from the same environment variables as the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/db"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cfg := config.Load()
+
+	switch os.Args[1] {
+	case "backup":
+		runBackup(cfg, os.Args[2:])
+	case "list":
+		runList(cfg, os.Args[2:])
+	case "restore":
+		runRestore(cfg, os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dbadmin <backup|list|restore> [flags]")
+	os.Exit(2)
+}
+
+func runBackup(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	fs.StringVar(&cfg.Backup.Dir, "dir", cfg.Backup.Dir, "backup directory")
+	fs.BoolVar(&cfg.Backup.Compress, "compress", cfg.Backup.Compress, "gzip the backup")
+	fs.IntVar(&cfg.Backup.Retention, "retention", cfg.Backup.Retention, "number of backups to keep (0 keeps all)")
+	fs.Parse(args)
+
+	database, err := db.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	info, err := database.Backup(context.Background(), cfg.Backup)
+	if err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	fmt.Printf("%s  %s (%d bytes)\n", info.SHA256, info.Path, info.Size)
+}
+
+func runList(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	fs.StringVar(&cfg.Backup.Dir, "dir", cfg.Backup.Dir, "backup directory")
+	fs.Parse(args)
+
+	backups, err := db.ListBackups(cfg.Backup.Dir)
+	if err != nil {
+		log.Fatalf("Failed to list backups: %v", err)
+	}
+	for _, b := range backups {
+		fmt.Printf("%s  %10d  %s\n", b.CreatedAt.Format("2006-01-02 15:04:05Z"), b.Size, b.Path)
+	}
+}
+
+func runRestore(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	skipChecksum := fs.Bool("skip-checksum", false, "restore a backup that has no checksum file")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: dbadmin restore [-skip-checksum] FILE")
+		os.Exit(2)
+	}
+	if cfg.Database.Driver != config.DriverSQLite {
+		log.Fatalf("Restore is only supported for SQLite; use pg_restore for PostgreSQL")
+	}
+
+	if err := db.RestoreSQLite(cfg.Database.Path, fs.Arg(0), db.RestoreOptions{SkipChecksum: *skipChecksum}); err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+}
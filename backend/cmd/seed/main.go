@@ -0,0 +1,418 @@
+// cmd/seed/main.go
+
+// seed fills a database with a reproducible development dataset: users with
+// mixed profile privacy, a follow graph with pending and accepted requests,
+// posts at every privacy level, groups with members, invitations and join
+// requests, events with RSVPs, and direct and group chat history.
+//
+//	seed [-seed N] [-users N] [-posts N] [-groups N] [-messages N]
+//
+// Everything except direct chat is created through the services, so the data
+// obeys the same rules as data created through the API. The same -seed value
+// always produces the same users, relationships and content. Every seeded user
+// signs in with the password printed at the end of the run.
+//
+// Database settings come from the same environment variables as the server.
+// Run it against a fresh database, e.g. DB_PATH=./data/dev.db go run ./cmd/seed
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/db"
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/services"
+	"github.com/HASANALI117/social-network/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// seedPassword is shared by every seeded account
+const seedPassword = "password123"
+
+// seedEmailDomain marks seeded accounts and is used to detect a previous run
+const seedEmailDomain = "seed.local"
+
+var (
+	firstNames = []string{"Amira", "Ben", "Chloe", "Dario", "Elif", "Farid", "Grace", "Hassan", "Ines", "Jonas",
+		"Kira", "Leo", "Maya", "Nadia", "Omar", "Priya", "Quentin", "Rania", "Sami", "Tara", "Umar", "Vera", "Wen", "Yara", "Zain"}
+	lastNames = []string{"Ali", "Brown", "Costa", "Dubois", "Evans", "Fischer", "Garcia", "Haddad", "Ivanova", "Jensen",
+		"Khan", "Lopez", "Moreau", "Novak", "Okafor", "Park", "Rossi", "Saleh", "Tanaka", "Weber"}
+	topics = []string{"coffee", "running", "Go", "photography", "hiking", "jazz", "chess", "gardening", "cooking",
+		"sci-fi novels", "board games", "cycling", "football", "open source", "travel", "astronomy"}
+	postOpeners = []string{"Thoughts on %s", "Weekend plans: %s", "Hot take about %s", "Just discovered %s",
+		"Anyone else into %s?", "A short guide to %s", "%s, one year in"}
+	postBodies = []string{
+		"I've been spending a lot of time on %s lately and it keeps surprising me.",
+		"Quick question for everyone who does %s: where did you start?",
+		"Finally made some real progress with %s this week. Small steps!",
+		"Unpopular opinion: %s is better when you don't take it too seriously.",
+		"Sharing a few notes on %s in case they help someone else.",
+	}
+	groupKinds   = []string{"Club", "Circle", "Collective", "Society", "Crew"}
+	eventTitles  = []string{"Monthly meetup", "Beginners' workshop", "Show and tell", "Weekend outing", "Online hangout"}
+	chatMessages = []string{"Hey! How's it going?", "Did you see the latest post?", "Are you coming on Saturday?",
+		"Haha, that's great", "Let me check and get back to you", "Sounds good to me", "Thanks for the invite!",
+		"See you there", "Who's bringing snacks?", "Running a bit late, sorry"}
+)
+
+// noopNotifier discards real-time notifications; nobody is connected while seeding
+type noopNotifier struct{}
+
+func (noopNotifier) NotifyUser(userID string, payload interface{}) error { return nil }
+
+// seeder creates the dataset; all randomness comes from rng so runs are reproducible
+type seeder struct {
+	rng   *rand.Rand
+	svc   *services.Services
+	repos *repositories.Repositories
+	ctx   context.Context
+
+	users     []*services.UserResponse
+	isPrivate map[string]bool
+	followers map[string][]string // user ID -> accepted follower IDs
+
+	counts map[string]int
+}
+
+func main() {
+	seed := flag.Int64("seed", 1, "random seed; the same seed produces the same dataset")
+	numUsers := flag.Int("users", 25, "number of users")
+	numPosts := flag.Int("posts", 4, "posts per user")
+	numGroups := flag.Int("groups", 5, "number of groups")
+	numMessages := flag.Int("messages", 15, "chat messages per conversation")
+	flag.Parse()
+
+	if *numUsers < 2 {
+		log.Fatal("-users must be at least 2")
+	}
+
+	cfg := config.Load()
+	database, err := db.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	conn := repositories.NewReadWriteDB(database.DB, database.Writer)
+	repos := repositories.InitRepositories(conn, database.Dialect)
+	uow := repositories.NewUnitOfWork(database.Writer, database.Dialect)
+	store, err := storage.New(context.Background(), cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+	svc := services.InitServices(repos, uow, noopNotifier{}, database, store, cfg)
+
+	if _, err := svc.User.GetByEmail(seedEmail(1)); err == nil {
+		log.Fatalf("Database already contains seed data (%s exists); use a fresh database", seedEmail(1))
+	}
+
+	s := &seeder{
+		rng:       rand.New(rand.NewSource(*seed)),
+		svc:       svc,
+		repos:     repos,
+		ctx:       context.Background(),
+		isPrivate: make(map[string]bool),
+		followers: make(map[string][]string),
+		counts:    make(map[string]int),
+	}
+
+	s.seedUsers(*numUsers)
+	s.seedFollows()
+	s.seedPosts(*numPosts)
+	groups := s.seedGroups(*numGroups)
+	s.seedEvents(groups)
+	s.seedChat(groups, *numMessages)
+
+	log.Printf("Seeded database with seed %d:", *seed)
+	for _, key := range []string{"users", "private users", "follows", "pending follow requests", "posts",
+		"groups", "group members", "pending invitations", "pending join requests", "events", "rsvps",
+		"direct messages", "group messages"} {
+		log.Printf("  %-24s %d", key, s.counts[key])
+	}
+	log.Printf("Sign in as %s ... %s with password %q", seedEmail(1), seedEmail(*numUsers), seedPassword)
+}
+
+func seedEmail(n int) string {
+	return fmt.Sprintf("user%03d@%s", n, seedEmailDomain)
+}
+
+// seedUsers registers n users, roughly a third of them with private profiles
+func (s *seeder) seedUsers(n int) {
+	for i := 1; i <= n; i++ {
+		first := firstNames[s.rng.Intn(len(firstNames))]
+		last := lastNames[s.rng.Intn(len(lastNames))]
+		topic := topics[s.rng.Intn(len(topics))]
+		user := &models.User{
+			Username:  fmt.Sprintf("%s%s%d", strings.ToLower(first), strings.ToLower(last), i),
+			Email:     seedEmail(i),
+			Password:  seedPassword,
+			FirstName: first,
+			LastName:  last,
+			AboutMe:   fmt.Sprintf("Into %s. Seeded account #%d.", topic, i),
+			BirthDate: fmt.Sprintf("%d-%02d-%02d", 1970+s.rng.Intn(35), 1+s.rng.Intn(12), 1+s.rng.Intn(28)),
+		}
+		resp, err := s.svc.User.Register(user)
+		if err != nil {
+			log.Fatalf("Failed to register %s: %v", user.Email, err)
+		}
+		s.users = append(s.users, resp)
+		s.counts["users"]++
+
+		if s.rng.Intn(3) == 0 {
+			if err := s.svc.User.UpdatePrivacy(resp.ID, true); err != nil {
+				log.Fatalf("Failed to make %s private: %v", resp.Username, err)
+			}
+			s.isPrivate[resp.ID] = true
+			s.counts["private users"]++
+		}
+	}
+}
+
+// seedFollows has every user follow a handful of others. Requests to private
+// profiles are mostly accepted; the rest stay pending.
+func (s *seeder) seedFollows() {
+	for _, requester := range s.users {
+		for _, target := range s.pick(s.users, 2+s.rng.Intn(5)) {
+			if target.ID == requester.ID {
+				continue
+			}
+			if err := s.svc.Follower.RequestFollow(s.ctx, requester.ID, target.ID); err != nil {
+				continue // already following or pending
+			}
+			if s.isPrivate[target.ID] {
+				if s.rng.Intn(4) == 0 {
+					s.counts["pending follow requests"]++
+					continue
+				}
+				if err := s.svc.Follower.AcceptFollow(s.ctx, target.ID, requester.ID); err != nil {
+					log.Fatalf("Failed to accept follow %s -> %s: %v", requester.Username, target.Username, err)
+				}
+			}
+			s.followers[target.ID] = append(s.followers[target.ID], requester.ID)
+			s.counts["follows"]++
+		}
+	}
+}
+
+// seedPosts creates n posts per user across all three privacy levels. Private
+// posts are shared with a subset of the author's followers.
+func (s *seeder) seedPosts(n int) {
+	for _, author := range s.users {
+		for i := 0; i < n; i++ {
+			request := &services.PostCreateRequest{
+				UserID:  author.ID,
+				Title:   s.title(),
+				Content: s.content(),
+				Privacy: models.PrivacyPublic,
+			}
+			switch roll := s.rng.Intn(10); {
+			case roll >= 8 && len(s.followers[author.ID]) > 0:
+				request.Privacy = models.PrivacyPrivate
+				request.AllowedUserIDs = s.pickIDs(s.followers[author.ID], 1+s.rng.Intn(len(s.followers[author.ID])))
+			case roll >= 5:
+				request.Privacy = models.PrivacyAlmostPrivate
+			}
+			if _, err := s.svc.Post.Create(request); err != nil {
+				log.Fatalf("Failed to create post for %s: %v", author.Username, err)
+			}
+			s.counts["posts"]++
+		}
+	}
+}
+
+// seededGroup tracks the members of a seeded group for events and chat
+type seededGroup struct {
+	id      string
+	members []string
+}
+
+// seedGroups creates n groups. Each gets members through accepted invitations
+// and join requests, plus some of each left pending, and a few posts.
+func (s *seeder) seedGroups(n int) []*seededGroup {
+	var groups []*seededGroup
+	for i := 0; i < n; i++ {
+		creator := s.users[s.rng.Intn(len(s.users))]
+		topic := topics[s.rng.Intn(len(topics))]
+		resp, err := s.svc.Group.Create(&services.GroupCreateRequest{
+			CreatorID:   creator.ID,
+			Name:        fmt.Sprintf("%s%s %s", strings.ToUpper(topic[:1]), topic[1:], groupKinds[s.rng.Intn(len(groupKinds))]),
+			Description: fmt.Sprintf("A place for people who enjoy %s.", topic),
+		})
+		if err != nil {
+			log.Fatalf("Failed to create group: %v", err)
+		}
+		group := &seededGroup{id: resp.ID, members: []string{creator.ID}}
+		s.counts["groups"]++
+
+		for _, user := range s.pick(s.users, 3+s.rng.Intn(6)) {
+			if user.ID == creator.ID {
+				continue
+			}
+			if s.rng.Intn(2) == 0 {
+				invitation, err := s.svc.Group.InviteUser(group.id, user.ID, creator.ID)
+				if err != nil {
+					log.Fatalf("Failed to invite %s: %v", user.Username, err)
+				}
+				if s.rng.Intn(4) == 0 {
+					s.counts["pending invitations"]++
+					continue
+				}
+				if err := s.svc.Group.AcceptInvitation(invitation.ID, user.ID); err != nil {
+					log.Fatalf("Failed to accept invitation: %v", err)
+				}
+			} else {
+				request, err := s.svc.Group.RequestToJoin(group.id, user.ID)
+				if err != nil {
+					log.Fatalf("Failed to request to join for %s: %v", user.Username, err)
+				}
+				if s.rng.Intn(4) == 0 {
+					s.counts["pending join requests"]++
+					continue
+				}
+				if err := s.svc.Group.AcceptJoinRequest(request.ID, creator.ID); err != nil {
+					log.Fatalf("Failed to accept join request: %v", err)
+				}
+			}
+			group.members = append(group.members, user.ID)
+		}
+		s.counts["group members"] += len(group.members)
+
+		for j := 0; j < 1+s.rng.Intn(4); j++ {
+			groupID := group.id
+			_, err := s.svc.Post.Create(&services.PostCreateRequest{
+				UserID:  group.members[s.rng.Intn(len(group.members))],
+				GroupID: &groupID,
+				Title:   s.title(),
+				Content: s.content(),
+			})
+			if err != nil {
+				log.Fatalf("Failed to create group post: %v", err)
+			}
+			s.counts["posts"]++
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// seedEvents creates past and upcoming events in every group and has members RSVP
+func (s *seeder) seedEvents(groups []*seededGroup) {
+	now := time.Now().Truncate(time.Hour)
+	for _, group := range groups {
+		for i := 0; i < 1+s.rng.Intn(3); i++ {
+			event, err := s.svc.GroupEvent.Create(&services.GroupEventCreateRequest{
+				GroupID:     group.id,
+				CreatorID:   group.members[s.rng.Intn(len(group.members))],
+				Title:       eventTitles[s.rng.Intn(len(eventTitles))],
+				Description: "Everyone is welcome, bring a friend!",
+				EventTime:   now.Add(time.Duration(s.rng.Intn(60)-20) * 24 * time.Hour),
+			})
+			if err != nil {
+				log.Fatalf("Failed to create event: %v", err)
+			}
+			s.counts["events"]++
+
+			for _, memberID := range group.members {
+				if s.rng.Intn(4) == 0 {
+					continue // no answer
+				}
+				response := "going"
+				if s.rng.Intn(3) == 0 {
+					response = "not_going"
+				}
+				if err := s.svc.GroupEvent.RespondToEvent(event.ID, memberID, &services.GroupEventResponseRequest{Response: response}); err != nil {
+					log.Fatalf("Failed to respond to event: %v", err)
+				}
+				s.counts["rsvps"]++
+			}
+		}
+	}
+}
+
+// seedChat writes backdated conversations between followers and in every
+// group. There is no chat service; messages are stored the way the hub does.
+func (s *seeder) seedChat(groups []*seededGroup, perConversation int) {
+	start := time.Now().Add(-7 * 24 * time.Hour)
+
+	for _, user := range s.users {
+		for _, followerID := range s.pickIDs(s.followers[user.ID], 2) {
+			at := start.Add(time.Duration(s.rng.Intn(72)) * time.Hour)
+			for i := 0; i < perConversation; i++ {
+				sender, receiver := user.ID, followerID
+				if s.rng.Intn(2) == 0 {
+					sender, receiver = receiver, sender
+				}
+				at = at.Add(time.Duration(1+s.rng.Intn(90)) * time.Minute)
+				err := s.repos.ChatMessage.SaveDirectMessage(&models.Message{
+					ID:         uuid.New().String(),
+					SenderID:   sender,
+					ReceiverID: receiver,
+					Content:    chatMessages[s.rng.Intn(len(chatMessages))],
+					CreatedAt:  at.UTC().Format(time.RFC3339),
+				})
+				if err != nil {
+					log.Fatalf("Failed to save direct message: %v", err)
+				}
+				s.counts["direct messages"]++
+			}
+		}
+	}
+
+	for _, group := range groups {
+		at := start.Add(time.Duration(s.rng.Intn(72)) * time.Hour)
+		for i := 0; i < perConversation; i++ {
+			at = at.Add(time.Duration(1+s.rng.Intn(90)) * time.Minute)
+			err := s.repos.ChatMessage.SaveGroupMessage(&models.GroupMessage{
+				ID:        uuid.New().String(),
+				GroupID:   group.id,
+				SenderID:  group.members[s.rng.Intn(len(group.members))],
+				Content:   chatMessages[s.rng.Intn(len(chatMessages))],
+				CreatedAt: at.UTC(),
+			})
+			if err != nil {
+				log.Fatalf("Failed to save group message: %v", err)
+			}
+			s.counts["group messages"]++
+		}
+	}
+}
+
+func (s *seeder) title() string {
+	return fmt.Sprintf(postOpeners[s.rng.Intn(len(postOpeners))], topics[s.rng.Intn(len(topics))])
+}
+
+func (s *seeder) content() string {
+	return fmt.Sprintf(postBodies[s.rng.Intn(len(postBodies))], topics[s.rng.Intn(len(topics))])
+}
+
+// pick returns up to n distinct users chosen at random
+func (s *seeder) pick(users []*services.UserResponse, n int) []*services.UserResponse {
+	if n > len(users) {
+		n = len(users)
+	}
+	picked := make([]*services.UserResponse, 0, n)
+	for _, i := range s.rng.Perm(len(users))[:n] {
+		picked = append(picked, users[i])
+	}
+	return picked
+}
+
+// pickIDs returns up to n distinct IDs chosen at random
+func (s *seeder) pickIDs(ids []string, n int) []string {
+	if n > len(ids) {
+		n = len(ids)
+	}
+	picked := make([]string, 0, n)
+	for _, i := range s.rng.Perm(len(ids))[:n] {
+		picked = append(picked, ids[i])
+	}
+	return picked
+}
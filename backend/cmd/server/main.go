@@ -9,16 +9,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
+	"github.com/HASANALI117/social-network/pkg/config"
 	"github.com/HASANALI117/social-network/pkg/db"
 	"github.com/HASANALI117/social-network/pkg/routes"
+	"github.com/HASANALI117/social-network/pkg/storage"
 )
 
 func main() {
+	cfg := config.Load()
+
 	// Initialize database
-	database, err := db.InitDB()
+	database, err := db.InitDB(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -29,8 +34,17 @@ func main() {
 	}
 	log.Printf("MAIN: DB instance initialized and pinged successfully: %p", database)
 
+	// Start scheduled backups (no-op unless BACKUP_INTERVAL is set)
+	database.StartBackupScheduler(context.Background(), cfg.Backup)
+
+	// Initialize media storage
+	store, err := storage.New(context.Background(), cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+
 	// Setup HTTP routes
-	handler := routes.Setup(database)
+	handler := routes.Setup(database, store, cfg)
 
 	// Start HTTP server
 	addr := ":8080"
@@ -0,0 +1,442 @@
+package db
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+)
+
+const (
+	backupPrefix     = "social_network-"
+	backupTimeFormat = "20060102T150405Z"
+	checksumSuffix   = ".sha256"
+)
+
+var (
+	// ErrBackupUnsupported is returned for drivers without online file backups (use pg_dump for PostgreSQL)
+	ErrBackupUnsupported = errors.New("online backups are only supported for SQLite")
+	// ErrBackupInProgress is returned when a backup is requested while another one is running
+	ErrBackupInProgress = errors.New("a backup is already in progress")
+	// ErrChecksumMismatch is returned when a backup file doesn't match its recorded checksum
+	ErrChecksumMismatch = errors.New("backup checksum mismatch")
+)
+
+// backupMu ensures only one backup runs at a time
+var backupMu sync.Mutex
+
+// BackupInfo describes a backup file
+type BackupInfo struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"-"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	Compressed bool      `json:"compressed"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Backup writes a consistent snapshot of the live database into cfg.Dir using
+// VACUUM INTO, which runs alongside normal reads and writes. The file is
+// optionally gzipped, gets a sha256sum-compatible checksum file next to it,
+// and backups beyond cfg.Retention are removed.
+func (d *DB) Backup(ctx context.Context, cfg config.BackupConfig) (*BackupInfo, error) {
+	if d.Dialect != SQLite {
+		return nil, ErrBackupUnsupported
+	}
+	if !backupMu.TryLock() {
+		return nil, ErrBackupInProgress
+	}
+	defer backupMu.Unlock()
+
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	createdAt := time.Now().UTC()
+	name := backupPrefix + createdAt.Format(backupTimeFormat) + ".db"
+	if cfg.Compress {
+		name += ".gz"
+	}
+	path := filepath.Join(cfg.Dir, name)
+
+	// VACUUM INTO refuses to overwrite, and the snapshot must not be picked up
+	// by ListBackups until it is complete
+	snapshot := filepath.Join(cfg.Dir, ".tmp-"+createdAt.Format(backupTimeFormat)+".db")
+	os.Remove(snapshot)
+	defer os.Remove(snapshot)
+
+	if err := d.snapshot(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	sum, size, err := copyBackupFile(snapshot, path+".tmp", cfg.Compress)
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return nil, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return nil, fmt.Errorf("failed to finalize backup: %w", err)
+	}
+	if err := os.WriteFile(path+checksumSuffix, []byte(sum+"  "+name+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write backup checksum: %w", err)
+	}
+
+	if cfg.Retention > 0 {
+		if err := pruneBackups(cfg.Dir, cfg.Retention); err != nil {
+			log.Printf("Warning: failed to prune old backups: %v", err)
+		}
+	}
+
+	log.Printf("Database backup written to %s (%d bytes, sha256 %s)", path, size, sum)
+	return &BackupInfo{
+		Name:       name,
+		Path:       path,
+		Size:       size,
+		SHA256:     sum,
+		Compressed: cfg.Compress,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+// snapshot runs VACUUM INTO on a read connection so the writer isn't held up.
+// Read connections are query-only, which also forbids VACUUM INTO, so the
+// pragma is lifted on this one connection for the duration of the snapshot.
+func (d *DB) snapshot(ctx context.Context, path string) error {
+	conn, err := d.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = false"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "PRAGMA query_only = true")
+
+	_, err = conn.ExecContext(ctx, "VACUUM INTO ?", path)
+	return err
+}
+
+// copyBackupFile copies src to dst, gzipping it if compress is set, and
+// returns the hex sha256 and size of the written file
+func copyBackupFile(src, dst string, compress bool) (string, int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(out, hash)}
+
+	if compress {
+		gz := gzip.NewWriter(counter)
+		if _, err := io.Copy(gz, in); err != nil {
+			return "", 0, fmt.Errorf("failed to compress backup: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return "", 0, fmt.Errorf("failed to compress backup: %w", err)
+		}
+	} else if _, err := io.Copy(counter, in); err != nil {
+		return "", 0, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err := out.Sync(); err != nil {
+		return "", 0, fmt.Errorf("failed to flush backup: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), counter.n, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ListBackups returns the backups in dir, newest first
+func ListBackups(dir string) ([]BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []BackupInfo{}, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	backups := make([]BackupInfo, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) {
+			continue
+		}
+		compressed := strings.HasSuffix(name, ".db.gz")
+		if !compressed && !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), ".gz"), ".db")
+		createdAt, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		sum, _ := readChecksum(path)
+		backups = append(backups, BackupInfo{
+			Name:       name,
+			Path:       path,
+			Size:       info.Size(),
+			SHA256:     sum,
+			Compressed: compressed,
+			CreatedAt:  createdAt,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// pruneBackups deletes all but the newest keep backups in dir
+func pruneBackups(dir string, keep int) error {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(backup.Path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", backup.Name, err)
+		}
+		os.Remove(backup.Path + checksumSuffix)
+		log.Printf("Removed old backup %s", backup.Name)
+	}
+	return nil
+}
+
+// readChecksum returns the checksum recorded next to a backup file
+func readChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path + checksumSuffix)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file for %s", filepath.Base(path))
+	}
+	return fields[0], nil
+}
+
+// verifyChecksum compares a backup file against its recorded checksum
+func verifyChecksum(path string) error {
+	want, err := readChecksum(path)
+	if err != nil {
+		return fmt.Errorf("failed to read checksum: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return fmt.Errorf("failed to hash backup: %w", err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, want, got)
+	}
+	return nil
+}
+
+// StartBackupScheduler takes a backup every cfg.Interval until ctx is done.
+// It does nothing if the interval is zero or the driver has no online backups.
+func (d *DB) StartBackupScheduler(ctx context.Context, cfg config.BackupConfig) {
+	if cfg.Interval <= 0 || d.Dialect != SQLite {
+		return
+	}
+
+	log.Printf("Scheduled database backups every %s into %s (keeping %d)", cfg.Interval, cfg.Dir, cfg.Retention)
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := d.Backup(ctx, cfg); err != nil {
+					log.Printf("Scheduled database backup failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// RestoreOptions controls RestoreSQLite
+type RestoreOptions struct {
+	SkipChecksum bool // Restore even if the backup has no checksum file
+}
+
+// RestoreSQLite replaces the database at dbPath with the backup at backupPath.
+// The server must be stopped. The backup's checksum, integrity and schema
+// version are validated before anything is touched, and the current database
+// is kept next to it with a ".pre-restore-<time>" suffix.
+func RestoreSQLite(dbPath, backupPath string, opts RestoreOptions) error {
+	if err := verifyChecksum(backupPath); err != nil {
+		if !opts.SkipChecksum || errors.Is(err, ErrChecksumMismatch) {
+			return err
+		}
+		log.Printf("Warning: restoring without checksum verification: %v", err)
+	}
+
+	// Unpack next to the live database so the final swap is a rename
+	staged := dbPath + ".restore"
+	os.Remove(staged)
+	if err := unpackBackup(backupPath, staged); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	defer os.Remove(staged)
+
+	if err := validateBackup(staged); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		// Fold any WAL content into the current file so the kept copy is complete
+		if err := checkpointSQLite(dbPath); err != nil {
+			return err
+		}
+		previous := dbPath + ".pre-restore-" + time.Now().UTC().Format(backupTimeFormat)
+		if err := os.Rename(dbPath, previous); err != nil {
+			return fmt.Errorf("failed to move current database aside: %w", err)
+		}
+		log.Printf("Current database moved to %s", previous)
+	}
+	os.Remove(dbPath + "-wal")
+	os.Remove(dbPath + "-shm")
+
+	if err := os.Rename(staged, dbPath); err != nil {
+		return fmt.Errorf("failed to swap in restored database: %w", err)
+	}
+	log.Printf("Database restored from %s", backupPath)
+	return nil
+}
+
+// unpackBackup copies (and gunzips if needed) a backup file to dst
+func unpackBackup(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+
+	var r io.Reader = in
+	if strings.HasSuffix(src, ".gz") {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return fmt.Errorf("failed to decompress backup: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+	return out.Sync()
+}
+
+// validateBackup checks a staged backup's integrity and that its schema
+// version is one the embedded migrations know about
+func validateBackup(path string) error {
+	conn, err := sql.Open("sqlite3", path+"?_query_only=true")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer conn.Close()
+
+	if err := checkSQLiteIntegrity(conn, "full"); err != nil {
+		return err
+	}
+
+	var version uint
+	var dirty bool
+	if err := conn.QueryRow("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty); err != nil {
+		return fmt.Errorf("failed to read backup schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("backup schema version %d is dirty (a migration failed before the backup was taken)", version)
+	}
+
+	latest, err := LatestMigrationVersion(SQLite)
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("backup schema version %d is newer than this build's migrations (%d)", version, latest)
+	}
+	known, err := hasMigrationVersion(SQLite, version)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return fmt.Errorf("backup schema version %d does not match any embedded migration", version)
+	}
+
+	if version < latest {
+		log.Printf("Backup schema version %d is older than %d; remaining migrations run on next start", version, latest)
+	}
+	return nil
+}
+
+// checkpointSQLite folds the WAL of the database at path into the main file
+func checkpointSQLite(path string) error {
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to open current database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint current database (is the server still running?): %w", err)
+	}
+	return nil
+}
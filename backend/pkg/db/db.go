@@ -0,0 +1,135 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+)
+
+// DB represents a connection to the database.
+// The embedded *sql.DB serves reads; Writer serves writes and transactions.
+// Both are the same pool unless the driver needs writes serialized (SQLite).
+type DB struct {
+	*sql.DB
+	Writer  *sql.DB
+	Dialect Dialect
+}
+
+// Ping checks both the read and write connections
+func (d *DB) Ping() error {
+	if err := d.DB.Ping(); err != nil {
+		return err
+	}
+	if d.Writer != d.DB {
+		return d.Writer.Ping()
+	}
+	return nil
+}
+
+// Close closes the read and write connections
+func (d *DB) Close() error {
+	err := d.DB.Close()
+	if d.Writer != d.DB {
+		if werr := d.Writer.Close(); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+var GlobalDB *sql.DB
+
+// InitDB opens the database selected by cfg.Driver and applies its migrations
+func InitDB(cfg config.DatabaseConfig) (*DB, error) {
+	var (
+		conn *DB
+		err  error
+	)
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		conn, err = openSQLite(cfg)
+	case config.DriverPostgres:
+		conn, err = openPostgres(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Test connection
+	if err := conn.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	if conn.Dialect == SQLite {
+		if err := checkSQLiteIntegrity(conn.Writer, cfg.SQLite.IntegrityCheck); err != nil {
+			return nil, err
+		}
+	}
+
+	// Run migrations
+	if err := runMigrations(conn); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	GlobalDB = conn.DB
+
+	log.Printf("Database initialized successfully (%s)", conn.Dialect)
+	return conn, nil
+}
+
+// migrationDriver wraps an open connection in the matching golang-migrate driver
+func migrationDriver(conn *DB) (database.Driver, error) {
+	switch conn.Dialect {
+	case Postgres:
+		return postgres.WithInstance(conn.Writer, &postgres.Config{})
+	default:
+		return sqlite3.WithInstance(conn.Writer, &sqlite3.Config{})
+	}
+}
+
+// runMigrations runs the embedded migrations for the connection's dialect
+func runMigrations(conn *DB) error {
+	driver, err := migrationDriver(conn)
+	if err != nil {
+		return fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	src, err := migrationSource(conn.Dialect)
+	if err != nil {
+		return fmt.Errorf("failed to load embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, string(conn.Dialect), driver)
+	if err != nil {
+		return fmt.Errorf("failed to create migration instance: %w", err)
+	}
+
+	// Add this to check current version
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		log.Printf("Migration version error: %v", err)
+	} else {
+		log.Printf("Current migration version: %d, Dirty: %v", version, dirty)
+	}
+
+	if err := m.Up(); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			log.Println("No new migrations to apply")
+		} else {
+			log.Printf("Migration error: %v", err)
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	log.Println("Database migrations completed successfully")
+	return nil
+}
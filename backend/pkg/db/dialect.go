@@ -0,0 +1,84 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the SQL flavour spoken by the open database.
+// Repository queries are written with SQLite syntax ("?" placeholders) and
+// rewritten for the dialect in use.
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite3"
+	Postgres Dialect = "postgres"
+)
+
+// Rebind rewrites "?" and "?N" placeholders into the dialect's bind syntax.
+// "$N" placeholders are understood by both dialects and are left untouched.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	inString := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inString = !inString
+			b.WriteByte(c)
+		case c == '?' && !inString:
+			// Numbered placeholder (?1, ?2, ...) keeps its position
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			b.WriteByte('$')
+			if j > i+1 {
+				b.WriteString(query[i+1 : j])
+				i = j - 1
+			} else {
+				n++
+				b.WriteString(strconv.Itoa(n))
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Like returns the case-insensitive pattern matching operator.
+// SQLite's LIKE is already case-insensitive for ASCII; PostgreSQL needs ILIKE.
+func (d Dialect) Like() string {
+	if d == Postgres {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+// ForUpdate returns the clause that locks the rows selected in a transaction
+// until it ends. SQLite runs one write transaction at a time and has no such
+// clause.
+func (d Dialect) ForUpdate() string {
+	if d == Postgres {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+// Timestamp wraps a timestamp column or placeholder so that comparing two
+// wrapped values follows time order. SQLite keeps timestamps as text in
+// whichever layout they were written in, so they are compared as Julian days;
+// PostgreSQL compares its timestamps natively.
+func (d Dialect) Timestamp(expr string) string {
+	if d == Postgres {
+		return expr
+	}
+	return "julianday(" + expr + ")"
+}
@@ -0,0 +1,55 @@
+package db
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{"sqlite untouched", SQLite, "SELECT * FROM users WHERE id = ? AND email = ?", "SELECT * FROM users WHERE id = ? AND email = ?"},
+		{"sequential", Postgres, "SELECT * FROM users WHERE id = ? AND email = ?", "SELECT * FROM users WHERE id = $1 AND email = $2"},
+		{"numbered", Postgres, "UPDATE posts SET title = ?2 WHERE id = ?1", "UPDATE posts SET title = $2 WHERE id = $1"},
+		{"numbered and sequential", Postgres, "SELECT ?1, ?, ?1", "SELECT $1, $1, $1"},
+		{"question mark in string", Postgres, "SELECT 'why?' FROM t WHERE a = ?", "SELECT 'why?' FROM t WHERE a = $1"},
+		{"escaped quote in string", Postgres, "SELECT 'it''s?' WHERE a = ?", "SELECT 'it''s?' WHERE a = $1"},
+		{"dollar placeholders left alone", Postgres, "SELECT * FROM t WHERE a = $1", "SELECT * FROM t WHERE a = $1"},
+		{"no placeholders", Postgres, "SELECT 1", "SELECT 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Rebind(tt.query); got != tt.want {
+				t.Errorf("Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLike(t *testing.T) {
+	if got := SQLite.Like(); got != "LIKE" {
+		t.Errorf("SQLite.Like() = %q, want LIKE", got)
+	}
+	if got := Postgres.Like(); got != "ILIKE" {
+		t.Errorf("Postgres.Like() = %q, want ILIKE", got)
+	}
+}
+
+func TestForUpdate(t *testing.T) {
+	if got := SQLite.ForUpdate(); got != "" {
+		t.Errorf("SQLite.ForUpdate() = %q, want nothing", got)
+	}
+	if got := Postgres.ForUpdate(); got != " FOR UPDATE" {
+		t.Errorf("Postgres.ForUpdate() = %q, want FOR UPDATE", got)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	if got := SQLite.Timestamp("c.created_at"); got != "julianday(c.created_at)" {
+		t.Errorf("SQLite.Timestamp() = %q, want julianday(c.created_at)", got)
+	}
+	if got := Postgres.Timestamp("?"); got != "?" {
+		t.Errorf("Postgres.Timestamp() = %q, want ?", got)
+	}
+}
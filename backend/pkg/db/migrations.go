@@ -0,0 +1,68 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// migrationFiles embeds every migration set so the binary (and the restore
+// command) always knows the schema it expects.
+//
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
+var migrationFiles embed.FS
+
+// migrationSource returns the embedded migration set for dialect
+func migrationSource(dialect Dialect) (source.Driver, error) {
+	dir := "migrations/sqlite"
+	if dialect == Postgres {
+		dir = "migrations/postgres"
+	}
+	return iofs.New(migrationFiles, dir)
+}
+
+// LatestMigrationVersion returns the highest migration version embedded for dialect
+func LatestMigrationVersion(dialect Dialect) (uint, error) {
+	src, err := migrationSource(dialect)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load embedded migrations: %w", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("no embedded migrations: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+		}
+		version = next
+	}
+}
+
+// hasMigrationVersion reports whether version is one of the embedded migrations
+func hasMigrationVersion(dialect Dialect, version uint) (bool, error) {
+	src, err := migrationSource(dialect)
+	if err != nil {
+		return false, fmt.Errorf("failed to load embedded migrations: %w", err)
+	}
+	defer src.Close()
+
+	if _, _, err := src.ReadUp(version); err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
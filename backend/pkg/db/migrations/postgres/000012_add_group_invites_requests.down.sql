@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS update_group_join_requests_updated_at ON group_join_requests;
+DROP TRIGGER IF EXISTS update_group_invitations_updated_at ON group_invitations;
+
+DROP TABLE IF EXISTS group_join_requests;
+DROP TABLE IF EXISTS group_invitations;
+
+DROP FUNCTION IF EXISTS set_updated_at();
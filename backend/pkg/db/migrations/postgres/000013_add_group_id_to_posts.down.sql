@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_group_id;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS group_id;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_group_events_updated_at ON group_events;
+DROP TABLE IF EXISTS group_events;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS group_events (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    creator_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    event_time TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Trigger to update updated_at timestamp on update
+CREATE TRIGGER update_group_events_updated_at
+BEFORE UPDATE ON group_events
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_group_event_responses_updated_at ON group_event_responses;
+DROP TABLE IF EXISTS group_event_responses;
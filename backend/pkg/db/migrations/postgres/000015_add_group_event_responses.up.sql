@@ -0,0 +1,20 @@
+-- Create the table to store user responses to group events
+CREATE TABLE IF NOT EXISTS group_event_responses (
+    id TEXT PRIMARY KEY,
+    event_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    response TEXT NOT NULL CHECK(response IN ('going', 'not_going')), -- Ensure valid response values
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (event_id) REFERENCES group_events(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    -- Ensure only one response per user per event
+    UNIQUE (event_id, user_id)
+);
+
+-- Trigger to automatically update 'updated_at' timestamp
+CREATE TRIGGER update_group_event_responses_updated_at
+BEFORE UPDATE ON group_event_responses
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1 @@
+ALTER TABLE comments ADD COLUMN image_url TEXT DEFAULT '';
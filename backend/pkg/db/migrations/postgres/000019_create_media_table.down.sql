@@ -0,0 +1,6 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS avatar_id;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_id;
+ALTER TABLE comments DROP COLUMN IF EXISTS image_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS image_id;
+DROP INDEX IF EXISTS idx_media_owner_id;
+DROP TABLE IF EXISTS media;
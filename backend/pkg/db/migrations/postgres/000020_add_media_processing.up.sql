@@ -0,0 +1,25 @@
+-- Uploaded images are normalized by a background worker. Until status is
+-- 'ready' the media is not served; afterwards storage_key, content_type and
+-- size describe the re-encoded full-size image and the original is deleted.
+ALTER TABLE media ADD COLUMN status TEXT NOT NULL DEFAULT 'pending';
+ALTER TABLE media ADD COLUMN width INTEGER;
+ALTER TABLE media ADD COLUMN height INTEGER;
+ALTER TABLE media ADD COLUMN blurhash TEXT;
+ALTER TABLE media ADD COLUMN processing_error TEXT;
+ALTER TABLE media ADD COLUMN processed_at TIMESTAMPTZ;
+
+CREATE INDEX idx_media_status ON media(status);
+
+-- Smaller renditions of a processed image (e.g. thumb, avatar)
+CREATE TABLE media_variants (
+    media_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+
+    PRIMARY KEY (media_id, name),
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
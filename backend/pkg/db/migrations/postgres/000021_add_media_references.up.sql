@@ -0,0 +1,11 @@
+-- Chat messages can carry an uploaded image, visible to the conversation's participants
+ALTER TABLE messages ADD COLUMN image_id TEXT REFERENCES media(id) ON DELETE SET NULL;
+ALTER TABLE group_messages ADD COLUMN image_id TEXT REFERENCES media(id) ON DELETE SET NULL;
+
+-- Access checks look up the entities that reference a media item
+CREATE INDEX idx_posts_image_id ON posts(image_id);
+CREATE INDEX idx_comments_image_id ON comments(image_id);
+CREATE INDEX idx_users_avatar_id ON users(avatar_id);
+CREATE INDEX idx_groups_avatar_id ON groups(avatar_id);
+CREATE INDEX idx_messages_image_id ON messages(image_id);
+CREATE INDEX idx_group_messages_image_id ON group_messages(image_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_post_attachments_media_id;
+DROP TABLE IF EXISTS post_attachments;
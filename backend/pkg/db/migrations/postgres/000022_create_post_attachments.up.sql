@@ -0,0 +1,15 @@
+-- Ordered gallery of uploaded media (images, GIFs, short videos) attached to a post.
+-- posts.image_id/image_url keep the first image as the post's cover.
+CREATE TABLE post_attachments (
+    post_id TEXT NOT NULL,
+    media_id TEXT NOT NULL,
+    position INTEGER NOT NULL,                 -- 0-based order in the gallery
+    alt_text TEXT NOT NULL DEFAULT '',         -- Description for screen readers
+
+    PRIMARY KEY (post_id, position),
+    UNIQUE (post_id, media_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_attachments_media_id ON post_attachments(media_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_media_orphaned_at;
+ALTER TABLE media DROP COLUMN IF EXISTS orphaned_at;
@@ -0,0 +1,27 @@
+-- Full-text search: generated tsvector columns stay in sync with the indexed
+-- text without triggers. The 'simple' configuration doesn't stem, matching the
+-- language-agnostic tokenizer used by the SQLite FTS5 indexes.
+ALTER TABLE posts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(content, '')), 'B')
+) STORED;
+
+ALTER TABLE comments ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', COALESCE(content, ''))
+) STORED;
+
+ALTER TABLE users ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(username, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(about_me, '')), 'C')
+) STORED;
+
+ALTER TABLE groups ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(description, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_posts_search ON posts USING GIN (search_vector);
+CREATE INDEX idx_comments_search ON comments USING GIN (search_vector);
+CREATE INDEX idx_users_search ON users USING GIN (search_vector);
+CREATE INDEX idx_groups_search ON groups USING GIN (search_vector);
@@ -0,0 +1,30 @@
+-- Hashtags parsed from post content. Tag names are stored lowercase without the '#'.
+CREATE TABLE tags (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE post_tags (
+    post_id TEXT NOT NULL,
+    tag_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL, -- Creation time of the post, for tag pages and trending
+    PRIMARY KEY (post_id, tag_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_tags_tag_id ON post_tags(tag_id, created_at);
+CREATE INDEX idx_post_tags_created_at ON post_tags(created_at);
+
+-- Public posts with a followed tag show up in the follower's feed
+CREATE TABLE tag_follows (
+    user_id TEXT NOT NULL,
+    tag_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, tag_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tag_follows_tag_id ON tag_follows(tag_id);
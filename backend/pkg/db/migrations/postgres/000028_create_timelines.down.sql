@@ -0,0 +1,2 @@
+ALTER TABLE posts DROP COLUMN IF EXISTS fanned_out;
+DROP TABLE IF EXISTS timelines;
@@ -0,0 +1,19 @@
+-- Home timelines built at write time: each non-group post is copied to its
+-- author and to the followers (and allowed users) who may see it. Posts of
+-- accounts with too many followers aren't fanned out; fanned_out stays
+-- false and readers find them through followers instead.
+CREATE TABLE timelines (
+    user_id TEXT NOT NULL,   -- Owner of the timeline
+    post_id TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_timelines_user_author ON timelines(user_id, author_id);
+CREATE INDEX idx_timelines_post_id ON timelines(post_id);
+
+-- Existing posts keep being read through followers
+ALTER TABLE posts ADD COLUMN fanned_out BOOLEAN NOT NULL DEFAULT FALSE;
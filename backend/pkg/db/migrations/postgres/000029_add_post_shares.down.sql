@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_posts_one_repost;
+DROP INDEX IF EXISTS idx_posts_shared_post_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS share_type;
+ALTER TABLE posts DROP COLUMN IF EXISTS shared_post_id;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_status_scheduled_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS scheduled_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS status;
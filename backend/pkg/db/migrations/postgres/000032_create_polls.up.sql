@@ -0,0 +1,31 @@
+-- A post carries at most one poll. Voters pick one option, or several if
+-- multiple_choice is set; votes of anonymous polls are only counted.
+CREATE TABLE polls (
+    post_id TEXT PRIMARY KEY,
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    closes_at TIMESTAMPTZ, -- NULL if the poll never closes
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE TABLE poll_options (
+    id TEXT PRIMARY KEY,
+    post_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    UNIQUE (post_id, position),
+    FOREIGN KEY (post_id) REFERENCES polls(post_id) ON DELETE CASCADE
+);
+
+CREATE TABLE poll_votes (
+    option_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    post_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (option_id, user_id),
+    FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES polls(post_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_poll_votes_post_user ON poll_votes(post_id, user_id);
@@ -0,0 +1,3 @@
+-- Pinned posts are listed first on their author's profile, or in their group
+-- for group posts.
+ALTER TABLE posts ADD COLUMN pinned_at TIMESTAMPTZ;
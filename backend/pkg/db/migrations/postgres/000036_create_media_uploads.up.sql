@@ -0,0 +1,15 @@
+-- Append-only log of uploads for the hourly upload limit. Rows outlive the
+-- media they record, so deleting an upload doesn't free up the limit; the
+-- media garbage collector prunes rows older than the limit's window.
+CREATE TABLE media_uploads (
+    media_id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_media_uploads_owner_id_created_at ON media_uploads(owner_id, created_at);
+
+INSERT INTO media_uploads (media_id, owner_id, created_at)
+SELECT id, owner_id, created_at FROM media;
@@ -0,0 +1,20 @@
+-- Files uploaded through /api/media. The bytes live in the blob store under storage_key.
+CREATE TABLE media (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL,                    -- Uploader; only they may attach the media
+    storage_key TEXT NOT NULL UNIQUE,          -- Key in the blob store
+    content_type TEXT NOT NULL,                -- Sniffed from the file contents
+    size BIGINT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_media_owner_id ON media(owner_id);
+
+-- Entities reference uploaded media by ID. The existing URL columns keep the
+-- URL the media is served from, so readers that only display images are unchanged.
+ALTER TABLE posts ADD COLUMN image_id TEXT REFERENCES media(id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN image_id TEXT REFERENCES media(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN avatar_id TEXT REFERENCES media(id) ON DELETE SET NULL;
+ALTER TABLE groups ADD COLUMN avatar_id TEXT REFERENCES media(id) ON DELETE SET NULL;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS media_variants;
+DROP INDEX IF EXISTS idx_media_status;
+ALTER TABLE media DROP COLUMN processed_at;
+ALTER TABLE media DROP COLUMN processing_error;
+ALTER TABLE media DROP COLUMN blurhash;
+ALTER TABLE media DROP COLUMN height;
+ALTER TABLE media DROP COLUMN width;
+ALTER TABLE media DROP COLUMN status;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_group_messages_image_id;
+DROP INDEX IF EXISTS idx_messages_image_id;
+DROP INDEX IF EXISTS idx_groups_avatar_id;
+DROP INDEX IF EXISTS idx_users_avatar_id;
+DROP INDEX IF EXISTS idx_comments_image_id;
+DROP INDEX IF EXISTS idx_posts_image_id;
+ALTER TABLE group_messages DROP COLUMN image_id;
+ALTER TABLE messages DROP COLUMN image_id;
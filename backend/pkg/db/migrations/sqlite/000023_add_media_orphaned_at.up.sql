@@ -0,0 +1,5 @@
+-- Set by the media garbage collector when it first finds the media unused;
+-- the media is deleted once it has stayed unused for the grace period.
+ALTER TABLE media ADD COLUMN orphaned_at DATETIME;
+
+CREATE INDEX idx_media_orphaned_at ON media(orphaned_at);
@@ -0,0 +1,88 @@
+-- Full-text search indexes (FTS5). Each index is a standalone table keyed by
+-- the entity ID rather than an external-content table: the indexed tables
+-- have TEXT primary keys, and VACUUM (including VACUUM INTO backups) may
+-- renumber their implicit rowids. Triggers keep the indexes in sync.
+CREATE VIRTUAL TABLE posts_fts USING fts5(
+    post_id UNINDEXED,
+    title,
+    content,
+    tokenize = 'unicode61 remove_diacritics 2',
+    prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE comments_fts USING fts5(
+    comment_id UNINDEXED,
+    content,
+    tokenize = 'unicode61 remove_diacritics 2',
+    prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE users_fts USING fts5(
+    user_id UNINDEXED,
+    username,
+    first_name,
+    last_name,
+    about_me,
+    tokenize = 'unicode61 remove_diacritics 2',
+    prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE groups_fts USING fts5(
+    group_id UNINDEXED,
+    name,
+    description,
+    tokenize = 'unicode61 remove_diacritics 2',
+    prefix = '2 3'
+);
+
+INSERT INTO posts_fts (post_id, title, content) SELECT id, COALESCE(title, ''), COALESCE(content, '') FROM posts;
+INSERT INTO comments_fts (comment_id, content) SELECT id, content FROM comments;
+INSERT INTO users_fts (user_id, username, first_name, last_name, about_me)
+    SELECT id, COALESCE(username, ''), first_name, last_name, COALESCE(about_me, '') FROM users;
+INSERT INTO groups_fts (group_id, name, description) SELECT id, name, COALESCE(description, '') FROM groups;
+
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (post_id, title, content) VALUES (new.id, COALESCE(new.title, ''), COALESCE(new.content, ''));
+END;
+CREATE TRIGGER posts_fts_update AFTER UPDATE OF title, content ON posts BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.id;
+    INSERT INTO posts_fts (post_id, title, content) VALUES (new.id, COALESCE(new.title, ''), COALESCE(new.content, ''));
+END;
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.id;
+END;
+
+CREATE TRIGGER comments_fts_insert AFTER INSERT ON comments BEGIN
+    INSERT INTO comments_fts (comment_id, content) VALUES (new.id, new.content);
+END;
+CREATE TRIGGER comments_fts_update AFTER UPDATE OF content ON comments BEGIN
+    DELETE FROM comments_fts WHERE comment_id = old.id;
+    INSERT INTO comments_fts (comment_id, content) VALUES (new.id, new.content);
+END;
+CREATE TRIGGER comments_fts_delete AFTER DELETE ON comments BEGIN
+    DELETE FROM comments_fts WHERE comment_id = old.id;
+END;
+
+CREATE TRIGGER users_fts_insert AFTER INSERT ON users BEGIN
+    INSERT INTO users_fts (user_id, username, first_name, last_name, about_me)
+    VALUES (new.id, COALESCE(new.username, ''), new.first_name, new.last_name, COALESCE(new.about_me, ''));
+END;
+CREATE TRIGGER users_fts_update AFTER UPDATE OF username, first_name, last_name, about_me ON users BEGIN
+    DELETE FROM users_fts WHERE user_id = old.id;
+    INSERT INTO users_fts (user_id, username, first_name, last_name, about_me)
+    VALUES (new.id, COALESCE(new.username, ''), new.first_name, new.last_name, COALESCE(new.about_me, ''));
+END;
+CREATE TRIGGER users_fts_delete AFTER DELETE ON users BEGIN
+    DELETE FROM users_fts WHERE user_id = old.id;
+END;
+
+CREATE TRIGGER groups_fts_insert AFTER INSERT ON groups BEGIN
+    INSERT INTO groups_fts (group_id, name, description) VALUES (new.id, new.name, COALESCE(new.description, ''));
+END;
+CREATE TRIGGER groups_fts_update AFTER UPDATE OF name, description ON groups BEGIN
+    DELETE FROM groups_fts WHERE group_id = old.id;
+    INSERT INTO groups_fts (group_id, name, description) VALUES (new.id, new.name, COALESCE(new.description, ''));
+END;
+CREATE TRIGGER groups_fts_delete AFTER DELETE ON groups BEGIN
+    DELETE FROM groups_fts WHERE group_id = old.id;
+END;
@@ -0,0 +1,18 @@
+-- @username mentions in posts and comments. comment_id is NULL for mentions
+-- in the post itself. Only users who can view the post are recorded.
+CREATE TABLE mentions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,   -- Mentioned user
+    author_id TEXT NOT NULL, -- Author of the post or comment
+    post_id TEXT NOT NULL,
+    comment_id TEXT,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_mentions_user_id ON mentions(user_id, created_at);
+CREATE INDEX idx_mentions_post_id ON mentions(post_id);
+CREATE INDEX idx_mentions_comment_id ON mentions(comment_id);
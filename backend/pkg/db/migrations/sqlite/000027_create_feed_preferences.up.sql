@@ -0,0 +1,8 @@
+-- Home feed ordering chosen by each user. Users without a row get the
+-- ranked feed.
+CREATE TABLE feed_preferences (
+    user_id TEXT PRIMARY KEY,
+    mode TEXT NOT NULL CHECK (mode IN ('ranked', 'chronological')),
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
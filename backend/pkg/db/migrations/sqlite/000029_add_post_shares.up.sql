@@ -0,0 +1,8 @@
+-- Reposts and quote posts reference the shared post. Deleting the shared
+-- post leaves the shares in place with shared_post_id NULL (a tombstone).
+ALTER TABLE posts ADD COLUMN shared_post_id TEXT REFERENCES posts(id) ON DELETE SET NULL;
+ALTER TABLE posts ADD COLUMN share_type TEXT CHECK (share_type IN ('repost', 'quote'));
+
+CREATE INDEX idx_posts_shared_post_id ON posts(shared_post_id);
+-- A user reposts a post at most once; quotes are not limited
+CREATE UNIQUE INDEX idx_posts_one_repost ON posts(user_id, shared_post_id) WHERE share_type = 'repost';
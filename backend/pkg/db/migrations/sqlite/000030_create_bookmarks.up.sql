@@ -0,0 +1,25 @@
+-- Named collections a user files bookmarks into. Bookmarks are private.
+CREATE TABLE bookmark_collections (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- A post is bookmarked at most once per user, in at most one collection.
+-- Deleting a collection keeps its bookmarks, unfiled.
+CREATE TABLE bookmarks (
+    user_id TEXT NOT NULL,
+    post_id TEXT NOT NULL,
+    collection_id TEXT,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (collection_id) REFERENCES bookmark_collections(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_bookmarks_user_created_at ON bookmarks(user_id, created_at);
+CREATE INDEX idx_bookmarks_collection_id ON bookmarks(collection_id);
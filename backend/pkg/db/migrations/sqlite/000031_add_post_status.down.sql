@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_status_scheduled_at;
+ALTER TABLE posts DROP COLUMN scheduled_at;
+ALTER TABLE posts DROP COLUMN status;
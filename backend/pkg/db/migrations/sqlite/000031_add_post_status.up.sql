@@ -0,0 +1,6 @@
+-- Drafts and scheduled posts are only visible to their author until they are
+-- published. created_at of a draft or scheduled post is reset when it is published.
+ALTER TABLE posts ADD COLUMN status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('draft', 'scheduled', 'published'));
+ALTER TABLE posts ADD COLUMN scheduled_at DATETIME; -- Set while status is 'scheduled'
+
+CREATE INDEX idx_posts_status_scheduled_at ON posts(status, scheduled_at);
@@ -0,0 +1,4 @@
+DROP VIEW IF EXISTS post_audience;
+DROP TABLE IF EXISTS post_audience_lists;
+DROP TABLE IF EXISTS audience_list_members;
+DROP TABLE IF EXISTS audience_lists;
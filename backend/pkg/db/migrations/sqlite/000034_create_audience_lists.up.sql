@@ -0,0 +1,42 @@
+-- Named lists of a user's followers ("close friends") that private posts can
+-- be shared with. Members are resolved when the post is read, so adding
+-- someone to a list grants access to earlier posts shared with it.
+CREATE TABLE audience_lists (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE audience_list_members (
+    list_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (list_id, user_id),
+    FOREIGN KEY (list_id) REFERENCES audience_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_audience_list_members_user_id ON audience_list_members(user_id);
+
+-- A private post is shared with at most one list. Deleting the list leaves
+-- the post visible to its author and explicitly allowed users only.
+CREATE TABLE post_audience_lists (
+    post_id TEXT PRIMARY KEY,
+    list_id TEXT NOT NULL,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (list_id) REFERENCES audience_lists(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_audience_lists_list_id ON post_audience_lists(list_id);
+
+-- Everyone a private post is shared with: its allowed users plus the current
+-- members of its list.
+CREATE VIEW post_audience AS
+    SELECT post_id, user_id FROM post_allowed_users
+    UNION
+    SELECT pal.post_id, alm.user_id
+    FROM post_audience_lists pal
+    JOIN audience_list_members alm ON alm.list_id = pal.list_id;
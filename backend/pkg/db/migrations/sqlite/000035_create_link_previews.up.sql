@@ -0,0 +1,12 @@
+-- Cache of link previews, keyed by the URL as written in posts and messages.
+-- Failed fetches are cached too, so a dead link isn't fetched on every read.
+CREATE TABLE link_previews (
+    url TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL DEFAULT '',
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    fetched_at DATETIME NOT NULL
+);
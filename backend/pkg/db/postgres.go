@@ -0,0 +1,28 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	_ "github.com/lib/pq"
+)
+
+// openPostgres opens a connection pool to the PostgreSQL server described by cfg.DSN.
+// PostgreSQL handles concurrent writers itself, so reads and writes share the pool.
+func openPostgres(cfg config.DatabaseConfig) (*DB, error) {
+	if cfg.DSN == "" {
+		return nil, errors.New("DATABASE_URL must be set when using the postgres driver")
+	}
+
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if cfg.MaxReadConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxReadConns)
+	}
+
+	return &DB{DB: db, Writer: db, Dialect: Postgres}, nil
+}
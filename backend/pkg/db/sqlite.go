@@ -5,86 +5,126 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite3"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/HASANALI117/social-network/pkg/config"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-const (
-	dbPath     = "/app/data/social_network.db"
-	migrations = "file://pkg/db/migrations/sqlite"
-)
-
-// DB represents a connection to the database
-type DB struct {
-	*sql.DB
-}
-
-var GlobalDB *sql.DB
-
-// NewDB creates a new database connection
-func InitDB() (*sql.DB, error) {
+// openSQLite opens (creating if needed) the SQLite database file at cfg.Path.
+// SQLite allows a single writer at a time, so writes go through a dedicated
+// one-connection pool (callers queue in database/sql instead of failing with
+// "database is locked"), while reads use a separate query-only pool.
+func openSQLite(cfg config.DatabaseConfig) (*DB, error) {
 	// Ensure directory exists
-	dbDir := filepath.Dir(dbPath)
+	dbDir := filepath.Dir(cfg.Path)
 	if err := os.MkdirAll(dbDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create DB directory: %w", err)
 	}
 
-	// Connect to SQLite database
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	writer, err := sql.Open("sqlite3", sqliteDSN(cfg, false))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-
-	// Test connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	if err := checkSQLiteFTS5(writer); err != nil {
+		writer.Close()
+		return nil, err
 	}
+	writer.SetMaxOpenConns(1)
+	writer.SetMaxIdleConns(1)
+	writer.SetConnMaxLifetime(0) // Keep the writer connection (and its pragmas) open
 
-	// Run migrations
-	if err := runMigrations(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	reader, err := sql.Open("sqlite3", sqliteDSN(cfg, true))
+	if err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open read pool: %w", err)
+	}
+	readConns := cfg.MaxReadConns
+	if readConns <= 0 {
+		readConns = 1
 	}
+	reader.SetMaxOpenConns(readConns)
+	reader.SetMaxIdleConns(readConns)
 
-	GlobalDB = db
+	return &DB{DB: reader, Writer: writer, Dialect: SQLite}, nil
+}
 
-	log.Println("Database initialized successfully")
-	return GlobalDB, nil
+// sqliteDSN builds the go-sqlite3 connection string carrying the configured pragmas
+func sqliteDSN(cfg config.DatabaseConfig, readOnly bool) string {
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", strconv.Itoa(cfg.SQLite.BusyTimeoutMS))
+	params.Set("_synchronous", cfg.SQLite.Synchronous)
+	if cfg.SQLite.CacheSize != 0 {
+		params.Set("_cache_size", strconv.Itoa(cfg.SQLite.CacheSize))
+	}
+	if readOnly {
+		params.Set("_query_only", "true")
+	} else {
+		// The journal mode is persisted in the file, so only the writer sets it
+		params.Set("_journal_mode", cfg.SQLite.JournalMode)
+		// Take the write lock when the transaction begins rather than on its
+		// first write, so busy_timeout applies instead of an immediate SQLITE_BUSY
+		params.Set("_txlock", "immediate")
+	}
+	return cfg.Path + "?" + params.Encode()
 }
 
-// runMigrations runs database migrations
-func runMigrations(db *sql.DB) error {
-	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to create migration driver: %w", err)
+// checkSQLiteFTS5 fails if the SQLite library lacks the FTS5 extension that
+// the search indexes use. go-sqlite3 only includes it when built with
+// -tags sqlite_fts5.
+func checkSQLiteFTS5(db *sql.DB) error {
+	var enabled bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return fmt.Errorf("failed to check for FTS5 support: %w", err)
+	}
+	if !enabled {
+		return errors.New("SQLite was built without FTS5, which search requires; build with -tags sqlite_fts5")
 	}
+	return nil
+}
 
-	m, err := migrate.NewWithDatabaseInstance(migrations, "sqlite3", driver)
-	if err != nil {
-		return fmt.Errorf("failed to create migration instance: %w", err)
+// checkSQLiteIntegrity runs PRAGMA quick_check or integrity_check depending on mode
+func checkSQLiteIntegrity(db *sql.DB, mode string) error {
+	var pragma string
+	switch strings.ToLower(mode) {
+	case "", "off":
+		return nil
+	case "quick":
+		pragma = "PRAGMA quick_check"
+	case "full":
+		pragma = "PRAGMA integrity_check"
+	default:
+		return fmt.Errorf("unknown integrity check mode %q", mode)
 	}
 
-	// Add this to check current version
-	version, dirty, err := m.Version()
-	if err != nil && err != migrate.ErrNilVersion {
-		log.Printf("Migration version error: %v", err)
-	} else {
-		log.Printf("Current migration version: %d, Dirty: %v", version, dirty)
+	rows, err := db.Query(pragma)
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", pragma, err)
 	}
+	defer rows.Close()
 
-	if err := m.Up(); err != nil {
-		if errors.Is(err, migrate.ErrNoChange) {
-			log.Println("No new migrations to apply")
-		} else {
-			log.Printf("Migration error: %v", err)
-			return fmt.Errorf("failed to run migrations: %w", err)
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return fmt.Errorf("failed to read %s result: %w", pragma, err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s result: %w", pragma, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("database failed %s: %s", pragma, strings.Join(problems, "; "))
+	}
 
-	log.Println("Database migrations completed successfully")
+	log.Printf("Database %s passed", pragma)
 	return nil
 }
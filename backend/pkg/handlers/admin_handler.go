@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HASANALI117/social-network/pkg/db"
+	"github.com/HASANALI117/social-network/pkg/helpers"
+	"github.com/HASANALI117/social-network/pkg/httperr"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
+
+// AdminHandler serves the /api/admin endpoints, restricted to the users listed in ADMIN_USER_IDS
+type AdminHandler struct {
+	backupService  services.BackupService
+	mediaGCService services.MediaGCService
+	authService    services.AuthService
+	adminIDs       map[string]bool
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(backupService services.BackupService, mediaGCService services.MediaGCService, authService services.AuthService, adminUserIDs []string) *AdminHandler {
+	adminIDs := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		adminIDs[id] = true
+	}
+	return &AdminHandler{
+		backupService:  backupService,
+		mediaGCService: mediaGCService,
+		authService:    authService,
+		adminIDs:       adminIDs,
+	}
+}
+
+// ServeHTTP routes admin requests
+// GET  /api/admin/backups - List backups
+// POST /api/admin/backups - Take a backup now
+// POST /api/admin/media/gc - Delete unused media now (?dry_run=true only reports)
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	user, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required.")
+	}
+	if !h.adminIDs[user.ID] {
+		return httperr.NewForbidden(nil, "Admin access required.")
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin"), "/")
+
+	switch path {
+	case "backups":
+		switch r.Method {
+		case http.MethodGet:
+			return h.listBackups(w, r)
+		case http.MethodPost:
+			return h.createBackup(w, r, user.ID)
+		default:
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+	case "media/gc":
+		if r.Method != http.MethodPost {
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+		return h.collectMedia(w, r, user.ID)
+	default:
+		return httperr.NewNotFound(nil, "Admin endpoint not found.")
+	}
+}
+
+func (h *AdminHandler) createBackup(w http.ResponseWriter, r *http.Request, userID string) error {
+	info, err := h.backupService.CreateBackup(r.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrBackupInProgress):
+			return httperr.NewConflict(err, "A backup is already in progress.")
+		case errors.Is(err, db.ErrBackupUnsupported):
+			return httperr.NewBadRequest(err, err.Error())
+		default:
+			return httperr.NewInternalServerError(err, "Failed to create backup.")
+		}
+	}
+	log.Printf("Admin %s created backup %s", userID, info.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(info)
+}
+
+func (h *AdminHandler) listBackups(w http.ResponseWriter, r *http.Request) error {
+	backups, err := h.backupService.ListBackups()
+	if err != nil {
+		return httperr.NewInternalServerError(err, "Failed to list backups.")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"backups": backups,
+	})
+}
+
+func (h *AdminHandler) collectMedia(w http.ResponseWriter, r *http.Request, userID string) error {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return httperr.NewBadRequest(err, "dry_run must be true or false.")
+		}
+	}
+
+	report, err := h.mediaGCService.Collect(r.Context(), dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrMediaGCInProgress) {
+			return httperr.NewConflict(err, "Media garbage collection is already running.")
+		}
+		return httperr.NewInternalServerError(err, "Failed to collect unused media.")
+	}
+	log.Printf("Admin %s ran media garbage collection: %d media and %d files deleted, %d bytes reclaimed (dry run %t)",
+		userID, report.MediaDeleted, report.BlobsDeleted, report.BytesReclaimed, report.DryRun)
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(report)
+}
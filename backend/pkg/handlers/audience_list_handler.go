@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HASANALI117/social-network/pkg/helpers"
+	"github.com/HASANALI117/social-network/pkg/httperr"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
+
+// AudienceListHandler handles the audience lists private posts can be shared
+// with. UserHandler delegates /api/users/me/audience-lists to it.
+type AudienceListHandler struct {
+	audienceListService services.AudienceListService
+	authService         services.AuthService
+}
+
+// NewAudienceListHandler creates a new AudienceListHandler
+func NewAudienceListHandler(audienceListService services.AudienceListService, authService services.AuthService) *AudienceListHandler {
+	return &AudienceListHandler{
+		audienceListService: audienceListService,
+		authService:         authService,
+	}
+}
+
+// ServeHTTP routes audience list requests:
+// GET/POST /api/users/me/audience-lists, GET/DELETE /api/users/me/audience-lists/{id}
+// and PUT/DELETE /api/users/me/audience-lists/{id}/members/{userId}
+func (h *AudienceListHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	currentUser, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil || currentUser == nil {
+		return httperr.NewUnauthorized(err, "Authentication required.")
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 4:
+		switch r.Method {
+		case http.MethodGet:
+			return h.list(w, r, currentUser.ID)
+		case http.MethodPost:
+			return h.create(w, r, currentUser.ID)
+		}
+		return httperr.NewMethodNotAllowed(nil, "Method GET or POST required for /users/me/audience-lists")
+	case len(parts) == 5 && parts[4] != "":
+		switch r.Method {
+		case http.MethodGet:
+			return h.get(w, r, parts[4], currentUser.ID)
+		case http.MethodDelete:
+			return h.delete(w, r, parts[4], currentUser.ID)
+		}
+		return httperr.NewMethodNotAllowed(nil, "Method GET or DELETE required for /users/me/audience-lists/{id}")
+	case len(parts) == 7 && parts[4] != "" && parts[5] == "members" && parts[6] != "":
+		switch r.Method {
+		case http.MethodPut:
+			return h.addMember(w, r, parts[4], parts[6], currentUser.ID)
+		case http.MethodDelete:
+			return h.removeMember(w, r, parts[4], parts[6], currentUser.ID)
+		}
+		return httperr.NewMethodNotAllowed(nil, "Method PUT or DELETE required for /users/me/audience-lists/{id}/members/{userId}")
+	default:
+		return httperr.NewNotFound(nil, "Audience list endpoint not found.")
+	}
+}
+
+// list handles GET /api/users/me/audience-lists
+// @Summary My audience lists
+// @Description Your audience lists by name, with the number of members in each
+// @Tags audience-lists
+// @Produce json
+// @Success 200 {object} map[string]interface{} "audience_lists"
+// @Router /users/me/audience-lists [get]
+func (h *AudienceListHandler) list(w http.ResponseWriter, r *http.Request, userID string) error {
+	lists, err := h.audienceListService.List(userID)
+	if err != nil {
+		return httperr.NewInternalServerError(err, "Failed to list audience lists")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{"audience_lists": lists})
+}
+
+// create handles POST /api/users/me/audience-lists
+// @Summary Create an audience list
+// @Description Create a named list of your followers, such as "Close friends", to share private posts with
+// @Tags audience-lists
+// @Accept json
+// @Produce json
+// @Param body body map[string]string true "name"
+// @Success 201 {object} models.AudienceList
+// @Failure 400 {object} httperr.ErrorResponse "Invalid name"
+// @Failure 409 {object} httperr.ErrorResponse "Audience list already exists"
+// @Router /users/me/audience-lists [post]
+func (h *AudienceListHandler) create(w http.ResponseWriter, r *http.Request, userID string) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+
+	list, err := h.audienceListService.Create(userID, req.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAudienceListName) {
+			return httperr.NewBadRequest(err, err.Error())
+		}
+		if errors.Is(err, repositories.ErrAudienceListExists) {
+			return httperr.NewConflict(err, "Audience list already exists")
+		}
+		return httperr.NewInternalServerError(err, "Failed to create audience list")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(list)
+}
+
+// get handles GET /api/users/me/audience-lists/{id}
+// @Summary Get an audience list
+// @Description One of your audience lists with its members
+// @Tags audience-lists
+// @Produce json
+// @Param id path string true "Audience list ID"
+// @Success 200 {object} services.AudienceListResponse
+// @Failure 404 {object} httperr.ErrorResponse "Audience list not found"
+// @Router /users/me/audience-lists/{id} [get]
+func (h *AudienceListHandler) get(w http.ResponseWriter, r *http.Request, listID, userID string) error {
+	list, err := h.audienceListService.Get(userID, listID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrAudienceListNotFound) {
+			return httperr.NewNotFound(err, "Audience list not found")
+		}
+		return httperr.NewInternalServerError(err, "Failed to get audience list")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(list)
+}
+
+// delete handles DELETE /api/users/me/audience-lists/{id}
+// @Summary Delete an audience list
+// @Description Posts shared with the list stay private, visible to their allowed users only
+// @Tags audience-lists
+// @Produce json
+// @Param id path string true "Audience list ID"
+// @Success 200 {object} map[string]string "Audience list deleted"
+// @Failure 404 {object} httperr.ErrorResponse "Audience list not found"
+// @Router /users/me/audience-lists/{id} [delete]
+func (h *AudienceListHandler) delete(w http.ResponseWriter, r *http.Request, listID, userID string) error {
+	if err := h.audienceListService.Delete(userID, listID); err != nil {
+		if errors.Is(err, repositories.ErrAudienceListNotFound) {
+			return httperr.NewNotFound(err, "Audience list not found")
+		}
+		return httperr.NewInternalServerError(err, "Failed to delete audience list")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]string{"message": "Audience list deleted"})
+}
+
+// addMember handles PUT /api/users/me/audience-lists/{id}/members/{userId}
+// @Summary Add a member to an audience list
+// @Description Add one of your followers to a list. They can see the posts already shared with it.
+// @Tags audience-lists
+// @Produce json
+// @Param id path string true "Audience list ID"
+// @Param userId path string true "ID of the follower to add"
+// @Success 200 {object} map[string]string "Member added"
+// @Failure 400 {object} httperr.ErrorResponse "User doesn't follow you"
+// @Failure 404 {object} httperr.ErrorResponse "Audience list not found"
+// @Router /users/me/audience-lists/{id}/members/{userId} [put]
+func (h *AudienceListHandler) addMember(w http.ResponseWriter, r *http.Request, listID, memberID, userID string) error {
+	if err := h.audienceListService.AddMember(userID, listID, memberID); err != nil {
+		return audienceListMemberError(err, "Failed to add audience list member")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]string{"message": "Member added"})
+}
+
+// removeMember handles DELETE /api/users/me/audience-lists/{id}/members/{userId}
+// @Summary Remove a member from an audience list
+// @Description They lose access to the posts shared with the list, unless allowed otherwise
+// @Tags audience-lists
+// @Produce json
+// @Param id path string true "Audience list ID"
+// @Param userId path string true "ID of the member to remove"
+// @Success 200 {object} map[string]string "Member removed"
+// @Failure 404 {object} httperr.ErrorResponse "Audience list or member not found"
+// @Router /users/me/audience-lists/{id}/members/{userId} [delete]
+func (h *AudienceListHandler) removeMember(w http.ResponseWriter, r *http.Request, listID, memberID, userID string) error {
+	if err := h.audienceListService.RemoveMember(userID, listID, memberID); err != nil {
+		return audienceListMemberError(err, "Failed to remove audience list member")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]string{"message": "Member removed"})
+}
+
+// audienceListMemberError maps errors of member changes to HTTP errors
+func audienceListMemberError(err error, message string) error {
+	switch {
+	case errors.Is(err, repositories.ErrAudienceListNotFound):
+		return httperr.NewNotFound(err, "Audience list not found")
+	case errors.Is(err, repositories.ErrAudienceListMemberNotFound):
+		return httperr.NewNotFound(err, "User is not a member of the audience list")
+	case errors.Is(err, services.ErrNotFollower):
+		return httperr.NewBadRequest(err, "Only your followers can be added to audience lists")
+	default:
+		return httperr.NewInternalServerError(err, message)
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/HASANALI117/social-network/pkg/helpers"
+	"github.com/HASANALI117/social-network/pkg/httperr"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
+
+// BookmarkHandler handles bookmarks and bookmark collections. PostHandler and
+// UserHandler delegate their bookmark routes to it.
+type BookmarkHandler struct {
+	bookmarkService services.BookmarkService
+	authService     services.AuthService
+}
+
+// NewBookmarkHandler creates a new BookmarkHandler
+func NewBookmarkHandler(bookmarkService services.BookmarkService, authService services.AuthService) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkService: bookmarkService,
+		authService:     authService,
+	}
+}
+
+// ServeHTTP routes bookmark requests:
+// POST/DELETE /api/posts/{id}/bookmark, GET /api/users/me/bookmarks,
+// GET/POST /api/users/me/bookmarks/collections and
+// DELETE /api/users/me/bookmarks/collections/{id}
+func (h *BookmarkHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	currentUser, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil || currentUser == nil {
+		return httperr.NewUnauthorized(err, "Authentication required.")
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 4 && parts[1] == "posts" && parts[2] != "" && parts[3] == "bookmark":
+		switch r.Method {
+		case http.MethodPost:
+			return h.bookmark(w, r, parts[2], currentUser.ID)
+		case http.MethodDelete:
+			return h.unbookmark(w, r, parts[2], currentUser.ID)
+		}
+		return httperr.NewMethodNotAllowed(nil, "Method POST or DELETE required for /posts/{id}/bookmark")
+	case len(parts) == 4 && parts[1] == "users" && parts[2] == "me" && parts[3] == "bookmarks":
+		if r.Method != http.MethodGet {
+			return httperr.NewMethodNotAllowed(nil, "Method GET required for /users/me/bookmarks")
+		}
+		return h.list(w, r, currentUser.ID)
+	case len(parts) == 5 && parts[1] == "users" && parts[2] == "me" && parts[4] == "collections":
+		switch r.Method {
+		case http.MethodGet:
+			return h.listCollections(w, r, currentUser.ID)
+		case http.MethodPost:
+			return h.createCollection(w, r, currentUser.ID)
+		}
+		return httperr.NewMethodNotAllowed(nil, "Method GET or POST required for /users/me/bookmarks/collections")
+	case len(parts) == 6 && parts[1] == "users" && parts[2] == "me" && parts[4] == "collections" && parts[5] != "":
+		if r.Method != http.MethodDelete {
+			return httperr.NewMethodNotAllowed(nil, "Method DELETE required for /users/me/bookmarks/collections/{id}")
+		}
+		return h.deleteCollection(w, r, parts[5], currentUser.ID)
+	default:
+		return httperr.NewNotFound(nil, "Bookmark endpoint not found.")
+	}
+}
+
+// bookmark handles POST /api/posts/{id}/bookmark
+// @Summary Bookmark a post
+// @Description Save a post you can see, optionally in one of your collections. Bookmarking a post again moves it to the given collection, or out of collections.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param body body map[string]string false "collection_id"
+// @Success 200 {object} services.BookmarkResponse
+// @Failure 404 {object} httperr.ErrorResponse "Post or collection not found"
+// @Router /posts/{id}/bookmark [post]
+func (h *BookmarkHandler) bookmark(w http.ResponseWriter, r *http.Request, postID, userID string) error {
+	var req struct {
+		CollectionID string `json:"collection_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+
+	bookmark, err := h.bookmarkService.Bookmark(userID, postID, req.CollectionID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return httperr.NewNotFound(err, "Post not found")
+		}
+		if errors.Is(err, repositories.ErrCollectionNotFound) {
+			return httperr.NewNotFound(err, "Collection not found")
+		}
+		return httperr.NewInternalServerError(err, "Failed to bookmark post")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(bookmark)
+}
+
+// unbookmark handles DELETE /api/posts/{id}/bookmark
+// @Summary Remove a bookmark
+// @Tags bookmarks
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} map[string]string "Bookmark removed"
+// @Failure 404 {object} httperr.ErrorResponse "Bookmark not found"
+// @Router /posts/{id}/bookmark [delete]
+func (h *BookmarkHandler) unbookmark(w http.ResponseWriter, r *http.Request, postID, userID string) error {
+	if err := h.bookmarkService.Unbookmark(userID, postID); err != nil {
+		if errors.Is(err, repositories.ErrBookmarkNotFound) {
+			return httperr.NewNotFound(err, "Bookmark not found")
+		}
+		return httperr.NewInternalServerError(err, "Failed to remove bookmark")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]string{"message": "Bookmark removed"})
+}
+
+// list handles GET /api/users/me/bookmarks
+// @Summary My bookmarks
+// @Description Your bookmarks, newest first. Posts you can no longer see are marked unavailable; bookmarks of deleted posts are removed.
+// @Tags bookmarks
+// @Produce json
+// @Param collection_id query string false "Only bookmarks in this collection"
+// @Param limit query int false "Number of bookmarks to return"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of bookmarks to skip"
+// @Success 200 {object} map[string]interface{} "bookmarks"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid cursor"
+// @Failure 404 {object} httperr.ErrorResponse "Collection not found"
+// @Router /users/me/bookmarks [get]
+func (h *BookmarkHandler) list(w http.ResponseWriter, r *http.Request, userID string) error {
+	page := helpers.GetPage(w, r, helpers.DefaultLimit)
+	bookmarks, info, err := h.bookmarkService.List(userID, r.URL.Query().Get("collection_id"), page)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
+		if errors.Is(err, repositories.ErrCollectionNotFound) {
+			return httperr.NewNotFound(err, "Collection not found")
+		}
+		return httperr.NewInternalServerError(err, "Failed to list bookmarks")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(helpers.PageEnvelope("bookmarks", bookmarks, len(bookmarks), page, info))
+}
+
+// listCollections handles GET /api/users/me/bookmarks/collections
+// @Summary My bookmark collections
+// @Description Your bookmark collections by name, with the number of bookmarks in each
+// @Tags bookmarks
+// @Produce json
+// @Success 200 {object} map[string]interface{} "collections"
+// @Router /users/me/bookmarks/collections [get]
+func (h *BookmarkHandler) listCollections(w http.ResponseWriter, r *http.Request, userID string) error {
+	collections, err := h.bookmarkService.ListCollections(userID)
+	if err != nil {
+		return httperr.NewInternalServerError(err, "Failed to list bookmark collections")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{"collections": collections})
+}
+
+// createCollection handles POST /api/users/me/bookmarks/collections
+// @Summary Create a bookmark collection
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param body body map[string]string true "name"
+// @Success 201 {object} models.BookmarkCollection
+// @Failure 400 {object} httperr.ErrorResponse "Invalid name"
+// @Failure 409 {object} httperr.ErrorResponse "Collection already exists"
+// @Router /users/me/bookmarks/collections [post]
+func (h *BookmarkHandler) createCollection(w http.ResponseWriter, r *http.Request, userID string) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+
+	collection, err := h.bookmarkService.CreateCollection(userID, req.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCollectionName) {
+			return httperr.NewBadRequest(err, err.Error())
+		}
+		if errors.Is(err, repositories.ErrCollectionExists) {
+			return httperr.NewConflict(err, "Collection already exists")
+		}
+		return httperr.NewInternalServerError(err, "Failed to create bookmark collection")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(collection)
+}
+
+// deleteCollection handles DELETE /api/users/me/bookmarks/collections/{id}
+// @Summary Delete a bookmark collection
+// @Description Its bookmarks are kept outside any collection
+// @Tags bookmarks
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} map[string]string "Collection deleted"
+// @Failure 404 {object} httperr.ErrorResponse "Collection not found"
+// @Router /users/me/bookmarks/collections/{id} [delete]
+func (h *BookmarkHandler) deleteCollection(w http.ResponseWriter, r *http.Request, collectionID, userID string) error {
+	if err := h.bookmarkService.DeleteCollection(userID, collectionID); err != nil {
+		if errors.Is(err, repositories.ErrCollectionNotFound) {
+			return httperr.NewNotFound(err, "Collection not found")
+		}
+		return httperr.NewInternalServerError(err, "Failed to delete bookmark collection")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]string{"message": "Collection deleted"})
+}
@@ -5,11 +5,11 @@ import (
 "errors"
 "log"
 "net/http"
-"strconv"
 "strings"
 
 "github.com/HASANALI117/social-network/pkg/helpers"
 "github.com/HASANALI117/social-network/pkg/httperr"
+"github.com/HASANALI117/social-network/pkg/repositories"
 "github.com/HASANALI117/social-network/pkg/services"
 // "github.com/gorilla/mux" // If using mux for path variables
 )
@@ -102,6 +102,9 @@ if err != nil {
 if errors.Is(err, services.ErrPostNotFound) {
 return httperr.NewNotFound(err, "Post not found or not accessible")
 }
+if mediaErr := mediaReferenceError(err); mediaErr != nil {
+return mediaErr
+}
 // TODO: Handle specific validation errors if service provides them
 log.Printf("Error creating comment via handler: %v", err)
 return httperr.NewInternalServerError(err, "Failed to create comment")
@@ -115,26 +118,14 @@ return nil
 
 // handleGetCommentsByPost handles GET /api/posts/{postId}/comments
 func (h *CommentHandler) handleGetCommentsByPost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
-limitStr := r.URL.Query().Get("limit")
-offsetStr := r.URL.Query().Get("offset")
-
-limit := 20 // Default limit
-if limitStr != "" {
-if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-limit = parsedLimit
-}
-}
-
-offset := 0 // Default offset
-if offsetStr != "" {
-if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-offset = parsedOffset
-}
-}
+page := helpers.GetPage(w, r, 20)
 
 // Call service to get comments
-commentsResponse, err := h.commentService.GetCommentsByPost(postID, requestingUserID, limit, offset)
+commentsResponse, info, err := h.commentService.GetCommentsByPost(postID, requestingUserID, page)
 if err != nil {
+if errors.Is(err, repositories.ErrInvalidCursor) {
+return httperr.NewBadRequest(err, "Invalid cursor")
+}
 if errors.Is(err, services.ErrPostNotFound) {
 return httperr.NewNotFound(err, "Post not found or not accessible")
 }
@@ -143,12 +134,7 @@ return httperr.NewInternalServerError(err, "Failed to get comments")
 }
 
 w.Header().Set("Content-Type", "application/json")
-json.NewEncoder(w).Encode(map[string]interface{}{
-"comments": commentsResponse,
-"limit":    limit,
-"offset":   offset,
-"count":    len(commentsResponse),
-})
+json.NewEncoder(w).Encode(helpers.PageEnvelope("comments", commentsResponse, len(commentsResponse), page, info))
 return nil
 }
 
@@ -332,6 +332,9 @@ func (h *GroupHandler) createGroup(w http.ResponseWriter, r *http.Request, curre
 
 	groupResponse, err := h.groupService.Create(&req)
 	if err != nil {
+		if mediaErr := mediaReferenceError(err); mediaErr != nil {
+			return mediaErr
+		}
 		// TODO: Handle specific validation errors
 		return httperr.NewInternalServerError(err, "Failed to create group")
 	}
@@ -450,6 +453,9 @@ func (h *GroupHandler) updateGroup(w http.ResponseWriter, r *http.Request, group
 		if errors.Is(err, services.ErrGroupAdminRequired) {
 			return httperr.NewForbidden(err, "Only group admins can update the group")
 		}
+		if mediaErr := mediaReferenceError(err); mediaErr != nil {
+			return mediaErr
+		}
 		// TODO: Handle validation errors
 		return httperr.NewInternalServerError(err, "Failed to update group")
 	}
@@ -572,8 +578,9 @@ func (h *GroupHandler) listMembers(w http.ResponseWriter, r *http.Request, group
 // @Accept json
 // @Produce json
 // @Param id path string true "Group ID"
-// @Param limit query int false "Number of messages to return (default 50)"
-// @Param offset query int false "Number of messages to skip (default 0)"
+// @Param limit query int false "Number of messages to return (default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of messages to skip (default 0)"
 // @Success 200 {object} map[string]interface{} "Group messages"
 // @Failure 403 {object} httperr.ErrorResponse "Forbidden (not a member)"
 // @Failure 404 {object} httperr.ErrorResponse "Group not found"
@@ -584,30 +591,31 @@ func (h *GroupHandler) getGroupMessages(w http.ResponseWriter, r *http.Request,
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
 
-	limit := 20 // Default limit
 	if limitStr != "" {
 		parsedLimit, err := strconv.Atoi(limitStr)
 		if err != nil || parsedLimit <= 0 {
 			// Consider allowing 0 limit? For now, require positive.
 			return httperr.NewBadRequest(err, "Invalid 'limit' parameter: must be a positive integer")
 		}
-		limit = parsedLimit
 	}
 
-	offset := 0 // Default offset
 	if offsetStr != "" {
 		parsedOffset, err := strconv.Atoi(offsetStr)
 		if err != nil || parsedOffset < 0 {
 			return httperr.NewBadRequest(err, "Invalid 'offset' parameter: must be a non-negative integer")
 		}
-		offset = parsedOffset
 	}
 
+	page := helpers.GetPage(w, r, 20)
+
 	// 2. Call MessageService to fetch messages
 	// Note: The exact method signature might differ. Adjust if needed.
 	// Passing currentUser.ID for potential authorization checks within the service.
-	messages, err := h.messageService.GetGroupMessages(groupID, limit, offset, currentUser.ID) // Use messageService
+	messages, info, err := h.messageService.GetGroupMessages(groupID, page, currentUser.ID) // Use messageService
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
 		// 3. Handle potential errors
 		if errors.Is(err, repositories.ErrGroupNotFound) { // Assuming service might return this
 			return httperr.NewNotFound(err, "Group not found")
@@ -621,12 +629,7 @@ func (h *GroupHandler) getGroupMessages(w http.ResponseWriter, r *http.Request,
 	}
 
 	// 4. Return successful response (manual JSON encoding)
-	response := map[string]interface{}{
-		"messages": messages,
-		"limit":    limit,
-		"offset":   offset,
-		"count":    len(messages), // Assuming messages is a slice
-	}
+	response := helpers.PageEnvelope("messages", messages, len(messages), page, info)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -961,7 +964,8 @@ func (h *GroupHandler) rejectGroupJoinRequest(w http.ResponseWriter, r *http.Req
 // @Produce json
 // @Param groupID path string true "Group ID"
 // @Param limit query int false "Number of posts to return (default 20)"
-// @Param offset query int false "Number of posts to skip (default 0)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of posts to skip (default 0)"
 // @Success 200 {object} map[string]interface{} "List of group posts"
 // @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
 // @Failure 403 {object} httperr.ErrorResponse "Forbidden (not a member)" // Although service returns empty list currently
@@ -969,26 +973,14 @@ func (h *GroupHandler) rejectGroupJoinRequest(w http.ResponseWriter, r *http.Req
 // @Failure 500 {object} httperr.ErrorResponse "Failed to list group posts"
 // @Router /groups/{groupID}/posts [get]
 func (h *GroupHandler) listGroupPosts(w http.ResponseWriter, r *http.Request, groupID string, currentUser *services.UserResponse) error {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 20 // Default limit
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	offset := 0 // Default offset
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
+	page := helpers.GetPage(w, r, 20)
 
 	// Call post service to list group posts (service handles auth check - is member?)
-	postsResponse, err := h.postService.ListGroupPosts(groupID, currentUser.ID, limit, offset)
+	postsResponse, info, err := h.postService.ListGroupPosts(groupID, currentUser.ID, page)
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
 		// The service method ListGroupPosts checks membership and returns empty list if not member,
 		// or error if group doesn't exist or other DB issue.
 		// Let's check for specific errors if the service provides them, otherwise assume internal error.
@@ -1012,12 +1004,7 @@ func (h *GroupHandler) listGroupPosts(w http.ResponseWriter, r *http.Request, gr
 	// This avoids revealing the existence of the group or its posts if the user isn't a member.
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"posts":  postsResponse,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(postsResponse), // Count of returned posts
-	})
+	json.NewEncoder(w).Encode(helpers.PageEnvelope("posts", postsResponse, len(postsResponse), page, info))
 	return nil
 }
 
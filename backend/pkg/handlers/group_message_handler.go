@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors" // Import errors
 	"net/http"
-	"strconv"
 
 	"github.com/HASANALI117/social-network/pkg/helpers"
 	"github.com/HASANALI117/social-network/pkg/httperr"
@@ -37,7 +36,8 @@ func NewGroupMessageHandler(messageService services.MessageService, groupService
 // @Param id query string true "Group ID"
 // @Param limit query int false "Number of messages to return (default 50)"
 // @Param offset query int false "Number of messages to skip (default 0)"
-// @Success 200 {object} map[string]interface{} "Group messages"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of messages to skip (default 0)"
 // @Failure 400 {object} httperr.ErrorResponse "Group ID is required"
 // @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
 // @Failure 405 {object} httperr.ErrorResponse "Method not allowed"
@@ -75,27 +75,15 @@ func (h *GroupMessageHandler) GetGroupMessages(w http.ResponseWriter, r *http.Re
 	}
 
 	// Parse pagination parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 50 // Default limit for messages
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	offset := 0 // Default offset
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
+	page := helpers.GetPage(w, r, 50) // Default limit for messages
 
 	// Get messages using MessageService
 	// Pass currentUser.ID as requestingUserID for authorization check within the service
-	messages, err := h.messageService.GetGroupMessages(groupID, limit, offset, currentUser.ID)
+	messages, info, err := h.messageService.GetGroupMessages(groupID, page, currentUser.ID)
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
 		// Handle specific service errors
 		if errors.Is(err, services.ErrGroupMemberRequired) {
 			// This shouldn't happen if the IsMember check above passed, but handle defensively
@@ -111,11 +99,6 @@ func (h *GroupMessageHandler) GetGroupMessages(w http.ResponseWriter, r *http.Re
 	// The service returns []*models.GroupMessage, which should be suitable for JSON encoding
 	// TODO: Consider mapping to a specific response DTO if needed
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"messages": messages, // Use the response directly from the service
-		"limit":    limit,
-		"offset":   offset,
-		"count":    len(messages),
-	})
+	json.NewEncoder(w).Encode(helpers.PageEnvelope("messages", messages, len(messages), page, info))
 	return nil
 }
@@ -1,6 +1,9 @@
 package handlers
 
-import "github.com/HASANALI117/social-network/pkg/services"
+import (
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
 
 // Handlers holds all handler instances.
 type Handlers struct {
@@ -14,22 +17,33 @@ type Handlers struct {
 	GroupMessage *GroupMessageHandler // Added GroupMessageHandler
 	GroupMember  *GroupMemberHandler  // Added GroupMemberHandler
 	Notification *NotificationHandler // Added NotificationHandler
+	Admin        *AdminHandler
+	Media        *MediaHandler
+	Search       *SearchHandler
+	Tag          *TagHandler
 	// TODO: Add handlers for GroupInvite, GroupJoinReq, GroupEvent, GroupEventRes later
 }
 
 // InitHandlers initializes all handlers.
-func InitHandlers(svc *services.Services) *Handlers {
+func InitHandlers(svc *services.Services, cfg *config.Config) *Handlers {
 	authHandler := NewAuthHandler(svc.Auth) // Initialize AuthHandler using AuthService from services struct
 	// Pass PostService to GroupHandler constructor
 	groupHandler := NewGroupHandler(svc.Group, svc.Post, svc.Auth, svc.GroupEvent, svc.Message) // Pass MessageService
 	followerHandler := NewFollowerHandler(svc.Follower, svc.Auth)                               // Initialize FollowerHandler with AuthService
 	commentHandler := NewCommentHandler(svc.Comment, svc.Auth)                                  // Initialize CommentHandler
-	postHandler := NewPostHandler(svc.Post, svc.Auth, commentHandler)                           // Initialize PostHandler, passing CommentHandler
-	userHandler := NewUserHandler(svc.User, svc.Auth, followerHandler)                          // Pass FollowerHandler
-	messageHandler := NewMessageHandler(svc.Message, svc.Auth)                                  // Initialize MessageHandler
-	groupMessageHandler := NewGroupMessageHandler(svc.Message, svc.Group, svc.Auth)             // Initialize GroupMessageHandler
-	groupMemberHandler := NewGroupMemberHandler(svc.Group, svc.Auth)                            // Initialize GroupMemberHandler
-	notificationHandler := NewNotificationHandler(svc.Notification, svc.Auth)                   // Initialize NotificationHandler
+	bookmarkHandler := NewBookmarkHandler(svc.Bookmark, svc.Auth)
+	pollHandler := NewPollHandler(svc.Poll, svc.Auth)
+	audienceListHandler := NewAudienceListHandler(svc.AudienceList, svc.Auth)
+	postHandler := NewPostHandler(svc.Post, svc.Auth, commentHandler, bookmarkHandler, pollHandler)                               // Initialize PostHandler, passing CommentHandler
+	userHandler := NewUserHandler(svc.User, svc.Auth, svc.Media, svc.Feed, followerHandler, bookmarkHandler, audienceListHandler) // Pass FollowerHandler
+	messageHandler := NewMessageHandler(svc.Message, svc.Auth)                                                                    // Initialize MessageHandler
+	groupMessageHandler := NewGroupMessageHandler(svc.Message, svc.Group, svc.Auth)                                               // Initialize GroupMessageHandler
+	groupMemberHandler := NewGroupMemberHandler(svc.Group, svc.Auth)                                                              // Initialize GroupMemberHandler
+	notificationHandler := NewNotificationHandler(svc.Notification, svc.Auth)                                                     // Initialize NotificationHandler
+	adminHandler := NewAdminHandler(svc.Backup, svc.MediaGC, svc.Auth, cfg.AdminUserIDs)
+	mediaHandler := NewMediaHandler(svc.Media, svc.Auth)
+	searchHandler := NewSearchHandler(svc.Search, svc.Auth)
+	tagHandler := NewTagHandler(svc.Tag, svc.Post, svc.Auth)
 
 	return &Handlers{
 		User:         userHandler,
@@ -42,5 +56,9 @@ func InitHandlers(svc *services.Services) *Handlers {
 		GroupMessage: groupMessageHandler, // Assign initialized GroupMessageHandler
 		GroupMember:  groupMemberHandler,  // Assign initialized GroupMemberHandler
 		Notification: notificationHandler, // Assign initialized NotificationHandler
+		Admin:        adminHandler,
+		Media:        mediaHandler,
+		Search:       searchHandler,
+		Tag:          tagHandler,
 	}
 }
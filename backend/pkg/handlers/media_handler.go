@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/helpers"
+	"github.com/HASANALI117/social-network/pkg/httperr"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
+
+// multipartOverhead allows for the multipart headers and boundaries around the uploaded file
+const multipartOverhead = 1 << 20
+
+// MediaHandler handles uploading and serving media
+type MediaHandler struct {
+	mediaService services.MediaService
+	authService  services.AuthService
+}
+
+// NewMediaHandler creates a new MediaHandler
+func NewMediaHandler(mediaService services.MediaService, authService services.AuthService) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+		authService:  authService,
+	}
+}
+
+// ServeHTTP routes media requests
+// POST /api/media                - Upload a file (multipart form field "file")
+// GET  /api/media/{id}           - Download a processed file
+// GET  /api/media/{id}/{variant} - Download a variant (e.g. "thumb", "avatar")
+//
+// Downloads are authorized with the rules of the entities using the media and
+// then redirected to a short-lived signed URL, which serves the file.
+func (h *MediaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/media"), "/")
+	mediaID, variant, _ := strings.Cut(path, "/")
+
+	switch {
+	case mediaID == "":
+		if r.Method != http.MethodPost {
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+		return h.upload(w, r)
+	case strings.Contains(variant, "/"):
+		return httperr.NewNotFound(nil, "Media endpoint not found.")
+	default:
+		if r.Method != http.MethodGet {
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+		return h.download(w, r, mediaID, variant)
+	}
+}
+
+// upload handles POST /api/media
+// @Summary Upload media
+// @Description Upload an image as multipart/form-data (field "file"). The returned ID is used as image_id or avatar_id.
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Success 201 {object} services.MediaResponse
+// @Failure 400 {object} httperr.ErrorResponse "Missing file"
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Failure 413 {object} httperr.ErrorResponse "File too large"
+// @Failure 413 {object} httperr.ErrorResponse "Storage or file quota exceeded"
+// @Failure 415 {object} httperr.ErrorResponse "File type not allowed"
+// @Failure 429 {object} httperr.ErrorResponse "Hourly upload limit reached"
+// @Router /media [post]
+func (h *MediaHandler) upload(w http.ResponseWriter, r *http.Request) error {
+	user, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required to upload media.")
+	}
+
+	maxBytes := h.mediaService.MaxUploadBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return httperr.NewRequestEntityTooLarge(err, fmt.Sprintf("File exceeds the maximum size of %d bytes.", maxBytes))
+		}
+		return httperr.NewBadRequest(err, "Expected a multipart form with a \"file\" field.")
+	}
+	defer file.Close()
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+
+	media, err := h.mediaService.Upload(r.Context(), user.ID, file, header.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaTooLarge):
+			return httperr.NewRequestEntityTooLarge(err, fmt.Sprintf("File exceeds the maximum size of %d bytes.", maxBytes))
+		case errors.Is(err, services.ErrUnsupportedMediaType):
+			return httperr.NewUnsupportedMediaType(err, "File type is not allowed.")
+		case errors.Is(err, services.ErrStorageQuotaExceeded):
+			return httperr.NewRequestEntityTooLarge(err, "Upload would exceed your storage quota.")
+		case errors.Is(err, services.ErrFileQuotaExceeded):
+			return httperr.NewRequestEntityTooLarge(err, "You have reached the maximum number of uploaded files.")
+		case errors.Is(err, services.ErrUploadRateLimited):
+			var rateErr *services.UploadRateLimitError
+			if errors.As(err, &rateErr) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+			}
+			return httperr.NewTooManyRequests(err, "Too many uploads in the last hour. Try again later.")
+		default:
+			return httperr.NewInternalServerError(err, "Failed to upload media.")
+		}
+	}
+	log.Printf("User %s uploaded media %s (%s, %d bytes)", user.ID, media.ID, media.ContentType, media.Size)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(media)
+}
+
+// download handles GET /api/media/{id} and GET /api/media/{id}/{variant}.
+// Signed requests are served directly; others are checked against the
+// viewer's session and redirected to a signed URL.
+func (h *MediaHandler) download(w http.ResponseWriter, r *http.Request, mediaID, variant string) error {
+	if expires, ok := h.mediaService.VerifySignedURL(mediaID, variant, r.URL.Query()); ok {
+		return h.serve(w, r, mediaID, variant, expires)
+	}
+
+	userID := ""
+	if user, err := helpers.GetUserFromSession(r, h.authService); err == nil {
+		userID = user.ID
+	}
+	allowed, err := h.mediaService.CanView(mediaID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrMediaNotFound) {
+			return httperr.NewNotFound(err, "Media not found.")
+		}
+		return httperr.NewInternalServerError(err, "Failed to load media.")
+	}
+	if !allowed {
+		if userID == "" {
+			return httperr.NewUnauthorized(nil, "Authentication required to view this media.")
+		}
+		// Same response as a missing file, so private media can't be probed
+		return httperr.NewNotFound(nil, "Media not found.")
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, h.mediaService.SignedURL(mediaID, variant), http.StatusFound)
+	return nil
+}
+
+// serve streams a file whose URL signature has been verified
+func (h *MediaHandler) serve(w http.ResponseWriter, r *http.Request, mediaID, variant string, expires time.Time) error {
+	body, media, err := h.mediaService.Open(r.Context(), mediaID, variant)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaNotFound), errors.Is(err, services.ErrMediaProcessingFailed):
+			return httperr.NewNotFound(err, "Media not found.")
+		case errors.Is(err, services.ErrMediaVariantNotFound):
+			return httperr.NewNotFound(err, "Media variant not found.")
+		case errors.Is(err, services.ErrMediaProcessing):
+			w.Header().Set("Retry-After", "2")
+			return httperr.NewServiceUnavailable(err, "Media is still being processed.")
+		default:
+			return httperr.NewInternalServerError(err, "Failed to load media.")
+		}
+	}
+	defer body.Close()
+
+	// The signed URL is only cached by the viewer's browser, and only until it expires
+	maxAge := int(time.Until(expires).Seconds())
+	w.Header().Set("Content-Type", media.ContentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// Seekable blobs support range requests, which video players need for seeking
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", time.Time{}, seeker)
+		return nil
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(media.Size, 10))
+	if _, err := io.Copy(w, body); err != nil {
+		// Headers are already sent; all we can do is log
+		log.Printf("Error streaming media %s: %v", mediaID, err)
+	}
+	return nil
+}
+
+// mediaReferenceError maps errors from resolving an image_id or avatar_id to
+// a client error, or returns nil if err is not one of them.
+func mediaReferenceError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrMediaNotFound):
+		return httperr.NewBadRequest(err, "Referenced media does not exist.")
+	case errors.Is(err, services.ErrMediaForbidden):
+		return httperr.NewForbidden(err, "Referenced media was uploaded by another user.")
+	case errors.Is(err, services.ErrMediaNotImage):
+		return httperr.NewBadRequest(err, "Referenced media is not an image.")
+	case errors.Is(err, services.ErrMediaNotAttachable):
+		return httperr.NewBadRequest(err, "Referenced media is not an image or video.")
+	case errors.Is(err, services.ErrMediaProcessingFailed):
+		return httperr.NewBadRequest(err, "Referenced media could not be processed.")
+	}
+	return nil
+}
@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/HASANALI117/social-network/pkg/helpers"
 	"github.com/HASANALI117/social-network/pkg/httperr"
 	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
 	"github.com/HASANALI117/social-network/pkg/services"
 )
 
@@ -63,7 +63,8 @@ func (h *MessageHandler) GetChatConversations(w http.ResponseWriter, r *http.Req
 // @Produce json
 // @Param targetUserId query string true "Target user ID"
 // @Param limit query int false "Number of messages to return (default 20)"
-// @Param offset query int false "Number of messages to skip (default 0)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of messages to skip (default 0)"
 // @Success 200 {object} map[string]interface{} "Messages response with pagination"
 // @Failure 400 {object} httperr.ErrorResponse "Missing targetUserId"
 // @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
@@ -90,24 +91,14 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) err
     }
 
     // Get pagination parameters
-    limit := 20 // Default limit
-    offset := 0 // Default offset
-    
-    if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-        if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-            limit = parsedLimit
-        }
-    }
-    
-    if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-        if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-            offset = parsedOffset
-        }
-    }
+    page := helpers.GetPage(w, r, 20)
 
     // Get messages from service
-    messages, totalCount, err := h.messageService.GetDirectMessagesBetweenUsers(currentUser.ID, targetUserID, limit, offset)
+    messages, info, totalCount, err := h.messageService.GetDirectMessagesBetweenUsers(currentUser.ID, targetUserID, page)
     if err != nil {
+        if errors.Is(err, repositories.ErrInvalidCursor) {
+            return httperr.NewBadRequest(err, "Invalid cursor")
+        }
         return httperr.NewInternalServerError(err, "Failed to fetch messages")
     }
 
@@ -117,11 +108,15 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) err
         TotalCount int64 `json:"total_count"`
         Limit int `json:"limit"`
         Offset int `json:"offset"`
+        NextCursor string `json:"next_cursor"`
+        HasMore bool `json:"has_more"`
     }{
         Messages: messages,
         TotalCount: totalCount,
-        Limit: limit,
-        Offset: offset,
+        Limit: page.Limit,
+        Offset: page.Offset,
+        NextCursor: info.NextCursor,
+        HasMore: info.HasMore,
     }
 
     // Write response
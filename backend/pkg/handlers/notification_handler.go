@@ -3,14 +3,15 @@ package handlers
 import (
 	// "context" // No longer directly needed as r.Context() is used
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/HASANALI117/social-network/pkg/helpers"
 	"github.com/HASANALI117/social-network/pkg/httperr"
 	"github.com/HASANALI117/social-network/pkg/models" // Added import for models.Notification
+	"github.com/HASANALI117/social-network/pkg/repositories"
 	"github.com/HASANALI117/social-network/pkg/services"
 )
 
@@ -68,21 +69,13 @@ func (h *NotificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *NotificationHandler) listNotifications(w http.ResponseWriter, r *http.Request, userID string) error {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	page := helpers.GetPage(w, r, 20)
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 20 // Default limit
-	}
-
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0 // Default offset
-	}
-
-	notifications, err := h.service.GetUserNotifications(r.Context(), userID, limit, offset)
+	notifications, info, err := h.service.GetUserNotifications(r.Context(), userID, page)
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
 		log.Printf("Error getting notifications for user %s: %v", userID, err)
 		return httperr.NewInternalServerError(err, "Failed to retrieve notifications.")
 	}
@@ -100,13 +93,15 @@ func (h *NotificationHandler) listNotifications(w http.ResponseWriter, r *http.R
 		UnreadCount   int                    `json:"unread_count"`
 		Limit         int                    `json:"limit"`
 		Offset        int                    `json:"offset"`
+		NextCursor    string                 `json:"next_cursor"`
 		HasMore       bool                   `json:"has_more"`
 	}{
 		Notifications: notifications,
 		UnreadCount:   unreadCount,
-		Limit:         limit,
-		Offset:        offset,
-		HasMore:       len(notifications) == limit,
+		Limit:         page.Limit,
+		Offset:        page.Offset,
+		NextCursor:    info.NextCursor,
+		HasMore:       info.HasMore,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HASANALI117/social-network/pkg/helpers"
+	"github.com/HASANALI117/social-network/pkg/httperr"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
+
+// PollHandler handles votes in polls. PostHandler delegates its vote routes to it.
+type PollHandler struct {
+	pollService services.PollService
+	authService services.AuthService
+}
+
+// NewPollHandler creates a new PollHandler
+func NewPollHandler(pollService services.PollService, authService services.AuthService) *PollHandler {
+	return &PollHandler{
+		pollService: pollService,
+		authService: authService,
+	}
+}
+
+// ServeHTTP routes POST/DELETE /api/posts/{id}/vote and
+// GET /api/posts/{id}/poll/options/{optionId}/voters
+func (h *PollHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	currentUser, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil || currentUser == nil {
+		return httperr.NewUnauthorized(err, "Authentication required.")
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 7 && parts[1] == "posts" && parts[2] != "" && parts[3] == "poll" && parts[4] == "options" && parts[5] != "" && parts[6] == "voters" {
+		if r.Method != http.MethodGet {
+			return httperr.NewMethodNotAllowed(nil, "Method GET required for /posts/{id}/poll/options/{optionId}/voters")
+		}
+		return h.listVoters(w, r, parts[2], parts[5], currentUser.ID)
+	}
+	if len(parts) != 4 || parts[1] != "posts" || parts[2] == "" || parts[3] != "vote" {
+		return httperr.NewNotFound(nil, "Poll endpoint not found.")
+	}
+	switch r.Method {
+	case http.MethodPost:
+		return h.vote(w, r, parts[2], currentUser.ID)
+	case http.MethodDelete:
+		return h.unvote(w, r, parts[2], currentUser.ID)
+	}
+	return httperr.NewMethodNotAllowed(nil, "Method POST or DELETE required for /posts/{id}/vote")
+}
+
+// vote handles POST /api/posts/{id}/vote
+// @Summary Vote in a poll
+// @Description Vote in the poll of a post you can see. Voting again replaces your previous votes.
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param body body map[string][]string true "option_ids: one option, or several in multiple choice polls"
+// @Success 200 {object} services.PollResponse
+// @Failure 400 {object} httperr.ErrorResponse "Invalid vote"
+// @Failure 404 {object} httperr.ErrorResponse "Post or poll not found"
+// @Failure 409 {object} httperr.ErrorResponse "Poll is closed"
+// @Router /posts/{id}/vote [post]
+func (h *PollHandler) vote(w http.ResponseWriter, r *http.Request, postID, userID string) error {
+	var req struct {
+		OptionIDs []string `json:"option_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+
+	poll, err := h.pollService.Vote(postID, userID, req.OptionIDs)
+	if err != nil {
+		return pollError(err, "Failed to vote")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(poll)
+}
+
+// unvote handles DELETE /api/posts/{id}/vote
+// @Summary Withdraw a vote
+// @Description Remove your votes from an open poll
+// @Tags polls
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} services.PollResponse
+// @Failure 404 {object} httperr.ErrorResponse "Post, poll or vote not found"
+// @Failure 409 {object} httperr.ErrorResponse "Poll is closed"
+// @Router /posts/{id}/vote [delete]
+func (h *PollHandler) unvote(w http.ResponseWriter, r *http.Request, postID, userID string) error {
+	poll, err := h.pollService.Unvote(postID, userID)
+	if err != nil {
+		return pollError(err, "Failed to withdraw vote")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(poll)
+}
+
+// listVoters handles GET /api/posts/{id}/poll/options/{optionId}/voters
+// @Summary Voters of a poll option
+// @Description Page through who voted for an option, in voting order. Anonymous polls don't show their voters.
+// @Tags polls
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param optionId path string true "Option ID"
+// @Param limit query int false "Number of voters to return" default(10)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} map[string]interface{} "voters"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid cursor"
+// @Failure 403 {object} httperr.ErrorResponse "Poll is anonymous"
+// @Failure 404 {object} httperr.ErrorResponse "Post, poll or option not found"
+// @Router /posts/{id}/poll/options/{optionId}/voters [get]
+func (h *PollHandler) listVoters(w http.ResponseWriter, r *http.Request, postID, optionID, userID string) error {
+	page := helpers.GetPage(w, r, helpers.DefaultLimit)
+	voters, info, err := h.pollService.ListVoters(postID, optionID, userID, page)
+	if err != nil {
+		return pollError(err, "Failed to list voters")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(helpers.PageEnvelope("voters", voters, len(voters), page, info))
+}
+
+// pollError maps errors from the poll endpoints to HTTP errors
+func pollError(err error, message string) error {
+	switch {
+	case errors.Is(err, repositories.ErrPostNotFound):
+		return httperr.NewNotFound(err, "Post not found")
+	case errors.Is(err, repositories.ErrPollNotFound):
+		return httperr.NewNotFound(err, "Post has no poll")
+	case errors.Is(err, services.ErrPollOptionNotFound):
+		return httperr.NewNotFound(err, "Poll option not found")
+	case errors.Is(err, services.ErrPollAnonymous):
+		return httperr.NewForbidden(err, "Poll is anonymous")
+	case errors.Is(err, repositories.ErrInvalidCursor):
+		return httperr.NewBadRequest(err, "Invalid cursor")
+	case errors.Is(err, repositories.ErrVoteNotFound):
+		return httperr.NewNotFound(err, "You haven't voted in this poll")
+	case errors.Is(err, services.ErrPollClosed):
+		return httperr.NewConflict(err, "Poll is closed")
+	case errors.Is(err, services.ErrInvalidVote):
+		return httperr.NewBadRequest(err, err.Error())
+	}
+	return httperr.NewInternalServerError(err, message)
+}
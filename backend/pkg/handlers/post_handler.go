@@ -3,10 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log" // Import log
 	"net/http"
-	"strconv"
 	"strings" // Import strings
+	"time"
 
 	"github.com/HASANALI117/social-network/pkg/helpers"
 	"github.com/HASANALI117/social-network/pkg/httperr"
@@ -20,17 +21,21 @@ import (
 
 // PostHandler handles HTTP requests for posts and delegates comment routes
 type PostHandler struct {
-	postService    services.PostService
-	authService    services.AuthService
-	commentHandler *CommentHandler // Added CommentHandler
+	postService     services.PostService
+	authService     services.AuthService
+	commentHandler  *CommentHandler // Added CommentHandler
+	bookmarkHandler *BookmarkHandler
+	pollHandler     *PollHandler
 }
 
 // NewPostHandler creates a new PostHandler
-func NewPostHandler(postService services.PostService, authService services.AuthService, commentHandler *CommentHandler) *PostHandler {
+func NewPostHandler(postService services.PostService, authService services.AuthService, commentHandler *CommentHandler, bookmarkHandler *BookmarkHandler, pollHandler *PollHandler) *PostHandler {
 	return &PostHandler{
-		postService:    postService,
-		authService:    authService,
-		commentHandler: commentHandler, // Store CommentHandler
+		postService:     postService,
+		authService:     authService,
+		commentHandler:  commentHandler, // Store CommentHandler
+		bookmarkHandler: bookmarkHandler,
+		pollHandler:     pollHandler,
 	}
 }
 
@@ -85,6 +90,14 @@ func (h *PostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 		// or require adjustments in CommentHandler.
 		return h.commentHandler.ServeHTTP(w, r)
 	}
+	// /api/posts/{postId}/bookmark
+	if len(parts) == 2 && parts[1] == "bookmark" {
+		return h.bookmarkHandler.ServeHTTP(w, r)
+	}
+	// /api/posts/{postId}/vote and /api/posts/{postId}/poll/options/{optionId}/voters
+	if (len(parts) == 2 && parts[1] == "vote") || (len(parts) == 5 && parts[1] == "poll") {
+		return h.pollHandler.ServeHTTP(w, r)
+	}
 
 	// --- Original Post Routing Logic ---
 	switch r.Method {
@@ -96,6 +109,18 @@ func (h *PostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 			}
 			return h.createPost(w, r, currentUser)
 		}
+		// POST /api/posts/{id}/repost -> Repost or quote a post
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "repost" {
+			return h.sharePost(w, r, parts[0], currentUser.ID)
+		}
+		// POST /api/posts/{id}/publish -> Publish a draft or scheduled post now
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "publish" {
+			return h.publishPost(w, r, parts[0], currentUser.ID)
+		}
+		// POST /api/posts/{id}/pin -> Pin a post on your profile or in your group
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "pin" {
+			return h.pinPost(w, r, parts[0], currentUser.ID)
+		}
 		return httperr.NewNotFound(nil, "Invalid path for POST")
 
 	case http.MethodGet:
@@ -112,6 +137,20 @@ func (h *PostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 			}
 			return h.listFollowingPosts(w, r, currentUser) // Pass currentUser
 		}
+		// GET /api/posts/drafts -> List the current user's drafts and scheduled posts
+		if len(parts) == 1 && parts[0] == "drafts" {
+			if currentUser == nil {
+				return httperr.NewUnauthorized(errors.New("authentication required"), "Authentication required to view drafts.")
+			}
+			return h.listDrafts(w, r, currentUser.ID)
+		}
+		// GET /api/posts/{id}/audience -> Privacy and allowed users of your post
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "audience" {
+			if currentUser == nil {
+				return httperr.NewUnauthorized(errors.New("authentication required"), "Authentication required to view a post's audience.")
+			}
+			return h.getAudience(w, r, parts[0], currentUser.ID)
+		}
 		// GET /api/posts/{id} -> Get Post by ID
 		if len(parts) == 1 && parts[0] != "" { // Ensure this doesn't catch "explore" or "following"
 			postID := parts[0]
@@ -141,8 +180,22 @@ func (h *PostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 		return httperr.NewNotFound(nil, "Invalid path for GET")
 
 	case http.MethodPut:
-		// PUT /api/posts/{id} -> Update Post (Not Implemented Yet)
-		return httperr.NewMethodNotAllowed(nil, "PUT method not implemented for posts")
+		// PUT /api/posts/{id} -> Update Post
+		if len(parts) == 1 && parts[0] != "" {
+			if currentUser == nil { // Must be logged in to edit
+				return httperr.NewUnauthorized(nil, "Authentication required to edit post")
+			}
+			return h.updatePost(w, r, parts[0], currentUser.ID)
+		}
+		// PUT /api/posts/{id}/schedule -> Schedule or reschedule a post
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "schedule" {
+			return h.schedulePost(w, r, parts[0], currentUser.ID)
+		}
+		// PUT /api/posts/{id}/audience -> Change the privacy and allowed users of your post
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "audience" {
+			return h.updateAudience(w, r, parts[0], currentUser.ID)
+		}
+		return httperr.NewNotFound(nil, "Invalid path for PUT")
 
 	case http.MethodDelete:
 		// DELETE /api/posts/{id} -> Delete Post
@@ -153,6 +206,18 @@ func (h *PostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 			postID := parts[0]
 			return h.deletePost(w, r, postID, currentUser.ID)
 		}
+		// DELETE /api/posts/{id}/repost -> Undo a repost
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "repost" {
+			return h.unrepost(w, r, parts[0], currentUser.ID)
+		}
+		// DELETE /api/posts/{id}/schedule -> Cancel a schedule, keeping the post as a draft
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "schedule" {
+			return h.unschedulePost(w, r, parts[0], currentUser.ID)
+		}
+		// DELETE /api/posts/{id}/pin -> Unpin a post
+		if len(parts) == 2 && parts[0] != "" && parts[1] == "pin" {
+			return h.unpinPost(w, r, parts[0], currentUser.ID)
+		}
 		return httperr.NewNotFound(nil, "Invalid path for DELETE")
 
 	default:
@@ -184,6 +249,14 @@ func (h *PostHandler) createPost(w http.ResponseWriter, r *http.Request, current
 	// Call service to create post
 	postResponse, err := h.postService.Create(&req)
 	if err != nil {
+		if mediaErr := mediaReferenceError(err); mediaErr != nil {
+			return mediaErr
+		}
+		if errors.Is(err, services.ErrTooManyAttachments) || errors.Is(err, services.ErrInvalidAttachments) ||
+			errors.Is(err, services.ErrInvalidSchedule) || errors.Is(err, services.ErrInvalidPoll) ||
+			errors.Is(err, services.ErrInvalidAudience) {
+			return httperr.NewBadRequest(err, err.Error())
+		}
 		// TODO: Handle specific validation errors from service if implemented
 		return httperr.NewInternalServerError(err, "Failed to create post")
 	}
@@ -235,42 +308,26 @@ func (h *PostHandler) getPostByID(w http.ResponseWriter, r *http.Request, postID
 // @Accept json
 // @Produce json
 // @Param limit query int false "Number of posts to return (default 10)"
-// @Param offset query int false "Number of posts to skip (default 0)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of posts to skip (default 0)"
 // @Success 200 {object} map[string]interface{} "List of posts"
 // @Failure 405 {object} httperr.ErrorResponse "Method not allowed"
 // @Failure 500 {object} httperr.ErrorResponse "Failed to list posts"
 // @Router /posts [get]
 func (h *PostHandler) listPosts(w http.ResponseWriter, r *http.Request, requestingUserID string) error {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 20 // Default limit
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	offset := 0 // Default offset
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
+	page := helpers.GetPage(w, r, 20)
 
 	// Call service to list posts (service handles filtering logic)
-	postsResponse, err := h.postService.List(requestingUserID, limit, offset) // Pass requestingUserID first
+	postsResponse, info, err := h.postService.List(requestingUserID, page) // Pass requestingUserID first
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
 		return httperr.NewInternalServerError(err, "Failed to list posts")
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"posts":  postsResponse,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(postsResponse), // Count of returned posts
-	})
+	json.NewEncoder(w).Encode(helpers.PageEnvelope("posts", postsResponse, len(postsResponse), page, info))
 	return nil
 }
 
@@ -282,75 +339,88 @@ func (h *PostHandler) listPosts(w http.ResponseWriter, r *http.Request, requesti
 // @Produce json
 // @Param user_id query string true "User ID"
 // @Param limit query int false "Number of posts to return (default 10)"
-// @Param offset query int false "Number of posts to skip (default 0)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of posts to skip (default 0)"
 // @Success 200 {object} map[string]interface{} "List of user's posts"
 // @Failure 400 {object} httperr.ErrorResponse "User ID is required"
 // @Failure 405 {object} httperr.ErrorResponse "Method not allowed"
 // @Failure 500 {object} httperr.ErrorResponse "Failed to list user posts"
 // @Router /posts/user/{userID} [get]
 func (h *PostHandler) listUserPosts(w http.ResponseWriter, r *http.Request, targetUserID string, requestingUserID string) error {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 20 // Default limit
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	offset := 0 // Default offset
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
+	page := helpers.GetPage(w, r, 20)
 
 	// Call service to list posts by user (service handles filtering logic)
-	postsResponse, err := h.postService.ListPostsByUser(targetUserID, requestingUserID, limit, offset) // Pass requestingUserID before limit/offset
+	postsResponse, info, err := h.postService.ListPostsByUser(targetUserID, requestingUserID, page)
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
 		return httperr.NewInternalServerError(err, "Failed to list user posts")
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"posts":  postsResponse,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(postsResponse), // Count of returned posts
-	})
+	json.NewEncoder(w).Encode(helpers.PageEnvelope("posts", postsResponse, len(postsResponse), page, info))
 	return nil
 }
 
 // listExplorePosts handles GET /api/posts/explore
 func (h *PostHandler) listExplorePosts(w http.ResponseWriter, r *http.Request) error {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	page := helpers.GetPage(w, r, 20)
 
-	limit := 20 // Default limit
-	if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-		limit = parsedLimit
+	postsResponse, info, err := h.postService.ListExplore(page)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
+		// Log the full error for server-side debugging
+		log.Printf("Error in listExplorePosts service call: %v", err)
+		return httperr.NewInternalServerError(err, "Failed to list explore posts")
 	}
 
-	offset := 0 // Default offset
-	if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-		offset = parsedOffset
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(helpers.PageEnvelope("posts", postsResponse, len(postsResponse), page, info))
+	return nil
+}
+
+// updatePost handles PUT /api/posts/{id}
+// @Summary Edit post
+// @Description Edit the title and content of a post. Hashtags are parsed again from the new content.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param post body services.PostUpdateRequest true "New title and content"
+// @Success 200 {object} services.PostResponse "Updated post"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid request body"
+// @Failure 403 {object} httperr.ErrorResponse "Forbidden (not the author)"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to update post"
+// @Router /posts/{id} [put]
+func (h *PostHandler) updatePost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	var req services.PostUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Content) == "" {
+		return httperr.NewBadRequest(nil, "Title and content are required")
+	}
+	if len([]rune(req.Title)) > 100 {
+		return httperr.NewBadRequest(nil, "Title must be at most 100 characters")
 	}
 
-	postsResponse, err := h.postService.ListExplore(limit, offset)
+	postResponse, err := h.postService.Update(postID, requestingUserID, &req)
 	if err != nil {
-		// Log the full error for server-side debugging
-		log.Printf("Error in listExplorePosts service call: %v", err)
-		return httperr.NewInternalServerError(err, "Failed to list explore posts")
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return httperr.NewNotFound(err, "Post not found")
+		}
+		if errors.Is(err, services.ErrPostForbidden) {
+			return httperr.NewForbidden(err, "You are not authorized to edit this post")
+		}
+		return httperr.NewInternalServerError(err, "Failed to update post")
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"posts":  postsResponse,
-		"limit":  limit,
-		"offset": offset,             // Return the offset used for the query
-		"count":  len(postsResponse), // Count of items in the current response
-	})
+	json.NewEncoder(w).Encode(postResponse)
 	return nil
 }
 
@@ -388,6 +458,334 @@ func (h *PostHandler) deletePost(w http.ResponseWriter, r *http.Request, postID
 	return nil
 }
 
+// sharePost handles POST /api/posts/{id}/repost
+// @Summary Repost or quote a post
+// @Description Share a post with your followers. An empty body reposts it as is; content quotes it. Followers-only and private posts can only be shared with users who can see them.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param share body services.PostShareRequest false "Quote text and audience"
+// @Success 201 {object} services.PostResponse "The repost or quote"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid request body"
+// @Failure 403 {object} httperr.ErrorResponse "Share would reach beyond the post's audience"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 409 {object} httperr.ErrorResponse "Post already reposted"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to share post"
+// @Router /posts/{id}/repost [post]
+func (h *PostHandler) sharePost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	var req services.PostShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+	if len([]rune(req.Title)) > 100 {
+		return httperr.NewBadRequest(nil, "Title must be at most 100 characters")
+	}
+	req.UserID = requestingUserID
+
+	postResponse, err := h.postService.Share(postID, &req)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return httperr.NewNotFound(err, "Post not found")
+		}
+		if errors.Is(err, services.ErrShareAudience) {
+			return httperr.NewForbidden(err, err.Error())
+		}
+		if errors.Is(err, repositories.ErrAlreadyReposted) {
+			return httperr.NewConflict(err, "Post already reposted")
+		}
+		if errors.Is(err, services.ErrInvalidShare) {
+			return httperr.NewBadRequest(err, err.Error())
+		}
+		return httperr.NewInternalServerError(err, "Failed to share post")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(postResponse)
+	return nil
+}
+
+// unrepost handles DELETE /api/posts/{id}/repost
+// @Summary Undo a repost
+// @Description Delete your repost of a post. Quotes are deleted like other posts.
+// @Tags posts
+// @Produce json
+// @Param id path string true "ID of the reposted post"
+// @Success 200 {object} map[string]string "Repost deleted successfully"
+// @Failure 404 {object} httperr.ErrorResponse "Repost not found"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to delete repost"
+// @Router /posts/{id}/repost [delete]
+func (h *PostHandler) unrepost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	if err := h.postService.Unrepost(postID, requestingUserID); err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return httperr.NewNotFound(err, "Repost not found")
+		}
+		return httperr.NewInternalServerError(err, "Failed to delete repost")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Repost deleted successfully",
+	})
+	return nil
+}
+
+// listDrafts handles GET /api/posts/drafts
+// @Summary List drafts and scheduled posts
+// @Description List the current user's unpublished posts, newest first
+// @Tags posts
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} map[string]interface{} "Page of unpublished posts"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid cursor"
+// @Failure 401 {object} httperr.ErrorResponse "Authentication required"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to list drafts"
+// @Router /posts/drafts [get]
+func (h *PostHandler) listDrafts(w http.ResponseWriter, r *http.Request, requestingUserID string) error {
+	page := helpers.GetPage(w, r, 20)
+
+	postsResponse, info, err := h.postService.ListUnpublished(requestingUserID, page)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
+		return httperr.NewInternalServerError(err, "Failed to list drafts")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(helpers.PageEnvelope("posts", postsResponse, len(postsResponse), page, info))
+	return nil
+}
+
+// schedulePost handles PUT /api/posts/{id}/schedule
+// @Summary Schedule a post
+// @Description Set or change when a draft or scheduled post is published
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param schedule body object true "Publication time as scheduled_at (RFC 3339)"
+// @Success 200 {object} services.PostResponse "The scheduled post"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid request body or time not in the future"
+// @Failure 403 {object} httperr.ErrorResponse "Not the author"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 409 {object} httperr.ErrorResponse "Post already published"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to schedule post"
+// @Router /posts/{id}/schedule [put]
+func (h *PostHandler) schedulePost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	var req struct {
+		ScheduledAt *time.Time `json:"scheduled_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+	if req.ScheduledAt == nil {
+		return httperr.NewBadRequest(nil, "scheduled_at is required")
+	}
+
+	postResponse, err := h.postService.Schedule(postID, requestingUserID, *req.ScheduledAt)
+	if err != nil {
+		return scheduleError(err, "Failed to schedule post")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(postResponse)
+	return nil
+}
+
+// unschedulePost handles DELETE /api/posts/{id}/schedule
+// @Summary Cancel a scheduled post
+// @Description Cancel publication of a scheduled post. The post is kept as a draft.
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} services.PostResponse "The draft"
+// @Failure 403 {object} httperr.ErrorResponse "Not the author"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 409 {object} httperr.ErrorResponse "Post already published"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to cancel schedule"
+// @Router /posts/{id}/schedule [delete]
+func (h *PostHandler) unschedulePost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	postResponse, err := h.postService.Unschedule(postID, requestingUserID)
+	if err != nil {
+		return scheduleError(err, "Failed to cancel schedule")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(postResponse)
+	return nil
+}
+
+// publishPost handles POST /api/posts/{id}/publish
+// @Summary Publish a post now
+// @Description Publish a draft or scheduled post immediately
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} services.PostResponse "The published post"
+// @Failure 403 {object} httperr.ErrorResponse "Not the author, or no longer a member of the post's group"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 409 {object} httperr.ErrorResponse "Post already published"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to publish post"
+// @Router /posts/{id}/publish [post]
+func (h *PostHandler) publishPost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	postResponse, err := h.postService.Publish(postID, requestingUserID)
+	if err != nil {
+		return scheduleError(err, "Failed to publish post")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(postResponse)
+	return nil
+}
+
+// scheduleError maps errors from the draft and schedule endpoints to HTTP errors
+func scheduleError(err error, message string) error {
+	switch {
+	case errors.Is(err, repositories.ErrPostNotFound):
+		return httperr.NewNotFound(err, "Post not found")
+	case errors.Is(err, services.ErrPostForbidden):
+		return httperr.NewForbidden(err, "You can only manage your own drafts")
+	case errors.Is(err, services.ErrGroupAccessDenied):
+		return httperr.NewForbidden(err, "You are no longer a member of this group")
+	case errors.Is(err, services.ErrPostPublished):
+		return httperr.NewConflict(err, "Post is already published")
+	case errors.Is(err, services.ErrInvalidSchedule):
+		return httperr.NewBadRequest(err, err.Error())
+	}
+	return httperr.NewInternalServerError(err, message)
+}
+
+// getAudience handles GET /api/posts/{id}/audience
+// @Summary Get a post's audience
+// @Description Privacy and allowed users of one of your posts
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} services.PostAudienceResponse
+// @Failure 400 {object} httperr.ErrorResponse "Group post"
+// @Failure 403 {object} httperr.ErrorResponse "Not the author"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to get audience"
+// @Router /posts/{id}/audience [get]
+func (h *PostHandler) getAudience(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	audience, err := h.postService.GetAudience(postID, requestingUserID)
+	if err != nil {
+		return audienceError(err, "Failed to get audience")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(audience)
+	return nil
+}
+
+// updateAudience handles PUT /api/posts/{id}/audience
+// @Summary Change a post's audience
+// @Description Replace the privacy and, for private posts, the allowed users of one of your posts. Timelines, mentions and notifications follow the new audience.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param audience body services.PostAudienceRequest true "New audience"
+// @Success 200 {object} services.PostAudienceResponse
+// @Failure 400 {object} httperr.ErrorResponse "Invalid audience or group post"
+// @Failure 403 {object} httperr.ErrorResponse "Not the author, or a share would reach beyond the shared post's audience"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to update audience"
+// @Router /posts/{id}/audience [put]
+func (h *PostHandler) updateAudience(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	var req services.PostAudienceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+
+	audience, err := h.postService.UpdateAudience(postID, requestingUserID, &req)
+	if err != nil {
+		return audienceError(err, "Failed to update audience")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(audience)
+	return nil
+}
+
+// audienceError maps errors from the audience endpoints to HTTP errors
+func audienceError(err error, message string) error {
+	switch {
+	case errors.Is(err, repositories.ErrPostNotFound):
+		return httperr.NewNotFound(err, "Post not found")
+	case errors.Is(err, services.ErrPostForbidden):
+		return httperr.NewForbidden(err, "You can only manage the audience of your own posts")
+	case errors.Is(err, services.ErrShareAudience):
+		return httperr.NewForbidden(err, err.Error())
+	case errors.Is(err, services.ErrInvalidAudience):
+		return httperr.NewBadRequest(err, err.Error())
+	}
+	return httperr.NewInternalServerError(err, message)
+}
+
+// pinPost handles POST /api/posts/{id}/pin
+// @Summary Pin a post
+// @Description Pin your post on your profile, or a post in a group you are an admin of. Pinned posts are listed first.
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} services.PostResponse "The pinned post"
+// @Failure 400 {object} httperr.ErrorResponse "Post is not published"
+// @Failure 403 {object} httperr.ErrorResponse "Not the author or a group admin"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 409 {object} httperr.ErrorResponse "Too many pinned posts"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to pin post"
+// @Router /posts/{id}/pin [post]
+func (h *PostHandler) pinPost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	postResponse, err := h.postService.Pin(postID, requestingUserID)
+	if err != nil {
+		return pinError(err, "Failed to pin post")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(postResponse)
+	return nil
+}
+
+// unpinPost handles DELETE /api/posts/{id}/pin
+// @Summary Unpin a post
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} services.PostResponse "The unpinned post"
+// @Failure 403 {object} httperr.ErrorResponse "Not the author or a group admin"
+// @Failure 404 {object} httperr.ErrorResponse "Post not found"
+// @Failure 500 {object} httperr.ErrorResponse "Failed to unpin post"
+// @Router /posts/{id}/pin [delete]
+func (h *PostHandler) unpinPost(w http.ResponseWriter, r *http.Request, postID string, requestingUserID string) error {
+	postResponse, err := h.postService.Unpin(postID, requestingUserID)
+	if err != nil {
+		return pinError(err, "Failed to unpin post")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(postResponse)
+	return nil
+}
+
+// pinError maps errors from the pin endpoints to HTTP errors
+func pinError(err error, message string) error {
+	switch {
+	case errors.Is(err, repositories.ErrPostNotFound):
+		return httperr.NewNotFound(err, "Post not found")
+	case errors.Is(err, services.ErrPostForbidden):
+		return httperr.NewForbidden(err, "Only the author can pin a post, or a group admin in groups")
+	case errors.Is(err, services.ErrInvalidPin):
+		return httperr.NewBadRequest(err, err.Error())
+	case errors.Is(err, services.ErrPinLimit):
+		return httperr.NewConflict(err, err.Error())
+	}
+	return httperr.NewInternalServerError(err, message)
+}
+
 // listFollowingPosts handles GET /api/posts/following
 func (h *PostHandler) listFollowingPosts(w http.ResponseWriter, r *http.Request, currentUser *services.UserResponse) error {
 	// currentUser is already validated by the caller (ServeHTTP) for this route
@@ -398,21 +796,13 @@ func (h *PostHandler) listFollowingPosts(w http.ResponseWriter, r *http.Request,
 	}
 	requestingUserID := currentUser.ID
 
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	page := helpers.GetPage(w, r, 20)
 
-	limit := 20 // Default limit
-	if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-		limit = parsedLimit
-	}
-
-	offset := 0 // Default offset
-	if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-		offset = parsedOffset
-	}
-
-	postsResponse, err := h.postService.ListFollowingFeed(requestingUserID, limit, offset)
+	postsResponse, info, err := h.postService.ListFollowingFeed(requestingUserID, page)
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
 		log.Printf("Error in listFollowingPosts service call for user %s: %v", requestingUserID, err)
 		// Avoid exposing internal error details directly to client unless wrapped.
 		// The service layer should return specific error types if needed for different HTTP responses.
@@ -420,11 +810,6 @@ func (h *PostHandler) listFollowingPosts(w http.ResponseWriter, r *http.Request,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"posts":  postsResponse,
-		"limit":  limit,
-		"offset": offset,
-		"count":  len(postsResponse),
-	})
+	json.NewEncoder(w).Encode(helpers.PageEnvelope("posts", postsResponse, len(postsResponse), page, info))
 	return nil
 }
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/HASANALI117/social-network/pkg/helpers"
+	"github.com/HASANALI117/social-network/pkg/httperr"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
+
+// SearchHandler serves full-text search
+type SearchHandler struct {
+	searchService services.SearchService
+	authService   services.AuthService
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(searchService services.SearchService, authService services.AuthService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		authService:   authService,
+	}
+}
+
+// Search handles GET /api/search
+// @Summary Search posts, comments, users and groups
+// @Description Full-text search ranked by relevance. Only posts and comments the current user may view are returned. Titles and snippets are HTML-escaped with matched words wrapped in <mark>.
+// @Tags search
+// @Produce json
+// @Param q query string true "Search words; the end of each word may be missing"
+// @Param type query string false "all (default), post, comment, user or group"
+// @Param limit query int false "Page size (max 50)"
+// @Param offset query int false "Results to skip"
+// @Success 200 {object} services.SearchResponse
+// @Failure 400 {object} httperr.ErrorResponse "Missing query or invalid type"
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Router /search [get]
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return httperr.NewMethodNotAllowed(nil, "")
+	}
+	user, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required to search.")
+	}
+
+	limit, offset := helpers.GetPaginationParams(r)
+	query := r.URL.Query()
+	results, err := h.searchService.Search(query.Get("q"), query.Get("type"), user.ID, limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmptySearchQuery):
+			return httperr.NewBadRequest(err, "Query parameter 'q' must contain at least one word.")
+		case errors.Is(err, services.ErrInvalidSearchType):
+			return httperr.NewBadRequest(err, "Query parameter 'type' must be one of all, post, comment, user or group.")
+		default:
+			return httperr.NewInternalServerError(err, "Failed to search.")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(results)
+}
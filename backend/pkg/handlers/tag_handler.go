@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/helpers"
+	"github.com/HASANALI117/social-network/pkg/httperr"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/services"
+)
+
+// TagHandler serves hashtag pages, trending tags and followed tags
+type TagHandler struct {
+	tagService  services.TagService
+	postService services.PostService
+	authService services.AuthService
+}
+
+// NewTagHandler creates a new TagHandler
+func NewTagHandler(tagService services.TagService, postService services.PostService, authService services.AuthService) *TagHandler {
+	return &TagHandler{
+		tagService:  tagService,
+		postService: postService,
+		authService: authService,
+	}
+}
+
+// ServeHTTP routes /api/tags requests:
+// GET /api/tags/trending, GET /api/tags/following, GET /api/tags/{tag}/posts
+// and POST/DELETE /api/tags/{tag}/follow
+func (h *TagHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tags"), "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "trending":
+		if r.Method != http.MethodGet {
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+		return h.trending(w, r)
+	case len(parts) == 1 && parts[0] == "following":
+		if r.Method != http.MethodGet {
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+		return h.listFollowed(w, r)
+	case len(parts) == 2 && parts[0] != "" && parts[1] == "posts":
+		if r.Method != http.MethodGet {
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+		return h.listPosts(w, r, parts[0])
+	case len(parts) == 2 && parts[0] != "" && parts[1] == "follow":
+		switch r.Method {
+		case http.MethodPost:
+			return h.follow(w, r, parts[0])
+		case http.MethodDelete:
+			return h.unfollow(w, r, parts[0])
+		default:
+			return httperr.NewMethodNotAllowed(nil, "")
+		}
+	default:
+		return httperr.NewNotFound(nil, "Tag endpoint not found.")
+	}
+}
+
+// trending handles GET /api/tags/trending
+// @Summary Trending tags
+// @Description Tags most used in public posts during a sliding window, ranked by number of distinct authors, then posts
+// @Tags tags
+// @Produce json
+// @Param window query string false "Window as a Go duration, e.g. 6h (default 24h, max 168h)"
+// @Param limit query int false "Number of tags (max 50)"
+// @Success 200 {object} map[string]interface{} "window and tags"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid window"
+// @Router /tags/trending [get]
+func (h *TagHandler) trending(w http.ResponseWriter, r *http.Request) error {
+	window := services.DefaultTrendingWindow
+	if value := r.URL.Query().Get("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return httperr.NewBadRequest(err, "Query parameter 'window' must be a positive duration such as 24h.")
+		}
+		window = min(parsed, services.MaxTrendingWindow)
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	tags, err := h.tagService.Trending(window, limit)
+	if err != nil {
+		return httperr.NewInternalServerError(err, "Failed to get trending tags.")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"window": window.String(),
+		"tags":   tags,
+	})
+}
+
+// listPosts handles GET /api/tags/{tag}/posts
+// @Summary Posts with a tag
+// @Description Non-group posts carrying the hashtag, newest first, with the same privacy rules as the feed
+// @Tags tags
+// @Produce json
+// @Param tag path string true "Tag, with or without '#'"
+// @Param limit query int false "Number of posts to return"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param offset query int false "Deprecated: number of posts to skip"
+// @Success 200 {object} map[string]interface{} "tag, is_following and posts"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid tag"
+// @Router /tags/{tag}/posts [get]
+func (h *TagHandler) listPosts(w http.ResponseWriter, r *http.Request, tag string) error {
+	requestingUserID := ""
+	if user, err := helpers.GetUserFromSession(r, h.authService); err == nil && user != nil {
+		requestingUserID = user.ID
+	}
+	page := helpers.GetPage(w, r, helpers.DefaultLimit)
+
+	posts, info, err := h.postService.ListByTag(tag, requestingUserID, page)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			return httperr.NewBadRequest(err, "Invalid cursor")
+		}
+		if errors.Is(err, services.ErrInvalidTag) {
+			return httperr.NewBadRequest(err, "Tags contain letters, digits and underscores, and at least one letter.")
+		}
+		return httperr.NewInternalServerError(err, "Failed to list posts with tag.")
+	}
+
+	isFollowing := false
+	if requestingUserID != "" {
+		if isFollowing, err = h.tagService.IsFollowing(requestingUserID, tag); err != nil {
+			return httperr.NewInternalServerError(err, "Failed to check tag follow.")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := helpers.PageEnvelope("posts", posts, len(posts), page, info)
+	response["tag"] = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	response["is_following"] = isFollowing
+	return json.NewEncoder(w).Encode(response)
+}
+
+// listFollowed handles GET /api/tags/following
+// @Summary Followed tags
+// @Description Tags the current user follows, most recently followed first
+// @Tags tags
+// @Produce json
+// @Success 200 {object} map[string]interface{} "tags"
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Router /tags/following [get]
+func (h *TagHandler) listFollowed(w http.ResponseWriter, r *http.Request) error {
+	user, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required to list followed tags.")
+	}
+
+	tags, err := h.tagService.ListFollowed(user.ID)
+	if err != nil {
+		return httperr.NewInternalServerError(err, "Failed to list followed tags.")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"tags": tags,
+	})
+}
+
+// follow handles POST /api/tags/{tag}/follow
+// @Summary Follow a tag
+// @Description Public posts with the tag show up in the following feed. Following a tag twice is not an error.
+// @Tags tags
+// @Produce json
+// @Param tag path string true "Tag, with or without '#'"
+// @Success 200 {object} map[string]string "Tag followed"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid tag"
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Router /tags/{tag}/follow [post]
+func (h *TagHandler) follow(w http.ResponseWriter, r *http.Request, tag string) error {
+	user, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required to follow tags.")
+	}
+
+	if err := h.tagService.Follow(user.ID, tag); err != nil {
+		if errors.Is(err, services.ErrInvalidTag) {
+			return httperr.NewBadRequest(err, "Tags contain letters, digits and underscores, and at least one letter.")
+		}
+		return httperr.NewInternalServerError(err, "Failed to follow tag.")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]string{"message": "Tag followed"})
+}
+
+// unfollow handles DELETE /api/tags/{tag}/follow
+// @Summary Unfollow a tag
+// @Tags tags
+// @Produce json
+// @Param tag path string true "Tag, with or without '#'"
+// @Success 200 {object} map[string]string "Tag unfollowed"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid tag"
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Router /tags/{tag}/follow [delete]
+func (h *TagHandler) unfollow(w http.ResponseWriter, r *http.Request, tag string) error {
+	user, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required to unfollow tags.")
+	}
+
+	if err := h.tagService.Unfollow(user.ID, tag); err != nil {
+		if errors.Is(err, services.ErrInvalidTag) {
+			return httperr.NewBadRequest(err, "Tags contain letters, digits and underscores, and at least one letter.")
+		}
+		return httperr.NewInternalServerError(err, "Failed to unfollow tag.")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]string{"message": "Tag unfollowed"})
+}
@@ -15,19 +15,28 @@ import (
 	"github.com/HASANALI117/social-network/pkg/services"
 )
 
+
 // UserHandler handles HTTP requests for users
 type UserHandler struct {
 	userService     services.UserService
 	authService     services.AuthService // Add AuthService
-	followerHandler *FollowerHandler     // Added FollowerHandler
+	mediaService    services.MediaService
+	feedService     services.FeedService
+	followerHandler *FollowerHandler // Added FollowerHandler
+	bookmarkHandler *BookmarkHandler
+	audienceHandler *AudienceListHandler
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(userService services.UserService, authService services.AuthService, followerHandler *FollowerHandler) *UserHandler {
+func NewUserHandler(userService services.UserService, authService services.AuthService, mediaService services.MediaService, feedService services.FeedService, followerHandler *FollowerHandler, bookmarkHandler *BookmarkHandler, audienceHandler *AudienceListHandler) *UserHandler {
 	return &UserHandler{
 		userService:     userService,
-		authService:     authService,     // Store AuthService
+		authService:     authService, // Store AuthService
+		mediaService:    mediaService,
+		feedService:     feedService,
 		followerHandler: followerHandler, // Store FollowerHandler
+		bookmarkHandler: bookmarkHandler,
+		audienceHandler: audienceHandler,
 	}
 }
 
@@ -54,6 +63,23 @@ func (h *UserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 					return h.ListMyGroups(w, r) // New method
 				}
 				return httperr.NewMethodNotAllowed(nil, "Method GET required for /users/me/groups")
+			case "storage":
+				if r.Method == http.MethodGet {
+					return h.getMyStorage(w, r)
+				}
+				return httperr.NewMethodNotAllowed(nil, "Method GET required for /users/me/storage")
+			case "feed":
+				switch r.Method {
+				case http.MethodGet:
+					return h.getMyFeedMode(w, r)
+				case http.MethodPut:
+					return h.setMyFeedMode(w, r)
+				}
+				return httperr.NewMethodNotAllowed(nil, "Method GET or PUT required for /users/me/feed")
+			case "bookmarks":
+				return h.bookmarkHandler.ServeHTTP(w, r)
+			case "audience-lists":
+				return h.audienceHandler.ServeHTTP(w, r)
 			// case "follow-requests": // Example if handled here, though it's likely separate
 			// if h.followRequestHandler != nil { // Assuming a separate handler for this
 			// return h.followRequestHandler.ServeHTTP(w, r)
@@ -296,6 +322,9 @@ func (h *UserHandler) updateUser(w http.ResponseWriter, r *http.Request, id stri
 		if errors.Is(err, repositories.ErrUserNotFound) {
 			return httperr.NewNotFound(err, "User not found")
 		}
+		if mediaErr := mediaReferenceError(err); mediaErr != nil {
+			return mediaErr
+		}
 		// Handle other potential errors like validation errors if service adds them
 		return httperr.NewInternalServerError(err, "Failed to update user")
 	}
@@ -497,3 +526,83 @@ func (h *UserHandler) ListMyGroups(w http.ResponseWriter, r *http.Request) error
 		"groups": groups,
 	})
 }
+
+// getMyStorage handles GET /api/users/me/storage
+// @Summary Get my storage usage
+// @Description Reports the current user's media usage and the upload quota of their role. Limits of 0 are unlimited.
+// @Tags users
+// @Produce json
+// @Success 200 {object} services.StorageUsageResponse
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Router /users/me/storage [get]
+func (h *UserHandler) getMyStorage(w http.ResponseWriter, r *http.Request) error {
+	currentUser, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required.")
+	}
+
+	usage, err := h.mediaService.StorageUsage(currentUser.ID)
+	if err != nil {
+		return httperr.NewInternalServerError(err, "Failed to retrieve storage usage")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(usage)
+}
+
+// getMyFeedMode handles GET /api/users/me/feed
+// @Summary Get my feed mode
+// @Description Whether the home and following feeds are ranked or chronological
+// @Tags users
+// @Produce json
+// @Success 200 {object} map[string]string "mode"
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Router /users/me/feed [get]
+func (h *UserHandler) getMyFeedMode(w http.ResponseWriter, r *http.Request) error {
+	currentUser, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required.")
+	}
+
+	mode, err := h.feedService.GetMode(currentUser.ID)
+	if err != nil {
+		return httperr.NewInternalServerError(err, "Failed to retrieve feed mode")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]models.FeedMode{"mode": mode})
+}
+
+// setMyFeedMode handles PUT /api/users/me/feed
+// @Summary Set my feed mode
+// @Description Choose "ranked" or "chronological" ordering for the home and following feeds
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body map[string]string true "mode"
+// @Success 200 {object} map[string]string "mode"
+// @Failure 400 {object} httperr.ErrorResponse "Invalid mode"
+// @Failure 401 {object} httperr.ErrorResponse "Unauthorized"
+// @Router /users/me/feed [put]
+func (h *UserHandler) setMyFeedMode(w http.ResponseWriter, r *http.Request) error {
+	currentUser, err := helpers.GetUserFromSession(r, h.authService)
+	if err != nil {
+		return httperr.NewUnauthorized(err, "Authentication required.")
+	}
+
+	var req struct {
+		Mode models.FeedMode `json:"mode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return httperr.NewBadRequest(err, "Invalid request body")
+	}
+	if err := h.feedService.SetMode(currentUser.ID, req.Mode); err != nil {
+		if errors.Is(err, services.ErrInvalidFeedMode) {
+			return httperr.NewBadRequest(err, "Mode must be 'ranked' or 'chronological'")
+		}
+		return httperr.NewInternalServerError(err, "Failed to update feed mode")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]models.FeedMode{"mode": req.Mode})
+}
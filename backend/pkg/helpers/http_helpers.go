@@ -3,30 +3,35 @@ package helpers
 import (
 	"net/http"
 	"strconv"
+
+	"github.com/HASANALI117/social-network/pkg/models"
 )
 
 const (
 	DefaultLimit  = 10
 	DefaultOffset = 0
-	MaxLimit      = 100 // Optional: Set a maximum limit
+	MaxLimit      = 100 // Larger limits are clamped
 )
 
 // GetPaginationParams extracts limit and offset from query parameters.
-// It applies default values and potentially a maximum limit.
+// It applies default values and a maximum limit.
 func GetPaginationParams(r *http.Request) (limit, offset int) {
+	return getPaginationParams(r, DefaultLimit)
+}
+
+func getPaginationParams(r *http.Request, defaultLimit int) (limit, offset int) {
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
 
-	limit = DefaultLimit
+	limit = defaultLimit
 	if limitStr != "" {
 		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
 			limit = parsedLimit
-			// Optional: Enforce a maximum limit
-			// if limit > MaxLimit {
-			// 	limit = MaxLimit
-			// }
 		}
 	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
 
 	offset = DefaultOffset
 	if offsetStr != "" {
@@ -37,3 +42,30 @@ func GetPaginationParams(r *http.Request) (limit, offset int) {
 
 	return limit, offset
 }
+
+// GetPage extracts cursor pagination from the limit and cursor query parameters.
+// The offset parameter is still honoured when no cursor is given, but responses
+// using it carry a Deprecation header since offsets skip or repeat items while
+// new ones are being added.
+func GetPage(w http.ResponseWriter, r *http.Request, defaultLimit int) models.Page {
+	limit, offset := getPaginationParams(r, defaultLimit)
+	page := models.Page{Limit: limit, After: r.URL.Query().Get("cursor")}
+	if page.After == "" && r.URL.Query().Has("offset") {
+		page.Offset = offset
+		w.Header().Set("Deprecation", "true")
+	}
+	return page
+}
+
+// PageEnvelope builds the JSON body of a paginated list: the items under key,
+// plus limit, offset, count, next_cursor and has_more
+func PageEnvelope(key string, items interface{}, count int, page models.Page, info models.PageInfo) map[string]interface{} {
+	return map[string]interface{}{
+		key:           items,
+		"limit":       page.Limit,
+		"offset":      page.Offset,
+		"count":       count,
+		"next_cursor": info.NextCursor,
+		"has_more":    info.HasMore,
+	}
+}
@@ -149,3 +149,35 @@ func NewInternalServerError(err error, userMessage string) *HTTPError {
 	}
 	return NewHTTPError(http.StatusInternalServerError, userMessage, err)
 }
+
+// NewRequestEntityTooLarge creates a 413 Request Entity Too Large error
+func NewRequestEntityTooLarge(err error, userMessage string) *HTTPError {
+	if userMessage == "" {
+		userMessage = "The request is too large"
+	}
+	return NewHTTPError(http.StatusRequestEntityTooLarge, userMessage, err)
+}
+
+// NewUnsupportedMediaType creates a 415 Unsupported Media Type error
+func NewUnsupportedMediaType(err error, userMessage string) *HTTPError {
+	if userMessage == "" {
+		userMessage = "The uploaded file type is not supported"
+	}
+	return NewHTTPError(http.StatusUnsupportedMediaType, userMessage, err)
+}
+
+// NewTooManyRequests creates a 429 Too Many Requests error
+func NewTooManyRequests(err error, userMessage string) *HTTPError {
+	if userMessage == "" {
+		userMessage = "Too many requests, please try again later"
+	}
+	return NewHTTPError(http.StatusTooManyRequests, userMessage, err)
+}
+
+// NewServiceUnavailable creates a 503 Service Unavailable error
+func NewServiceUnavailable(err error, userMessage string) *HTTPError {
+	if userMessage == "" {
+		userMessage = "The resource is temporarily unavailable"
+	}
+	return NewHTTPError(http.StatusServiceUnavailable, userMessage, err)
+}
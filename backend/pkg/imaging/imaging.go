@@ -0,0 +1,173 @@
+// Package imaging normalizes uploaded images: it fixes their orientation,
+// strips metadata by re-encoding, and renders sized variants and a blurhash
+// placeholder.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder
+)
+
+// ErrTooManyPixels is returned for images whose dimensions exceed the limit,
+// before they are decoded
+var ErrTooManyPixels = errors.New("image dimensions exceed the limit")
+
+// Variant describes a rendition of an image
+type Variant struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+	Crop      bool // Fill exactly MaxWidth x MaxHeight, cropping the center, instead of fitting inside
+}
+
+// Variants rendered for every image. "full" replaces the uploaded original.
+var (
+	Full      = Variant{Name: "full", MaxWidth: 2048, MaxHeight: 2048}
+	Thumbnail = Variant{Name: "thumb", MaxWidth: 640, MaxHeight: 640}
+	Avatar    = Variant{Name: "avatar", MaxWidth: 256, MaxHeight: 256, Crop: true}
+
+	DefaultVariants = []Variant{Full, Thumbnail, Avatar}
+)
+
+// Output is an encoded variant
+type Output struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Result is a processed image
+type Result struct {
+	Width    int // Of the upright original
+	Height   int
+	Blurhash string
+	Variants []Output
+}
+
+const jpegQuality = 85
+
+// Process decodes data and renders variants. Images with more than maxPixels
+// pixels are rejected with ErrTooManyPixels without being decoded.
+func Process(data []byte, variants []Variant, maxPixels int) (*Result, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image header: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	// Animated GIFs keep their animation at full size; other variants use the first frame
+	var anim *gif.GIF
+	var img image.Image
+	if format == "gif" {
+		anim, err = gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode gif: %w", err)
+		}
+		img = anim.Image[0]
+		if len(anim.Image) == 1 {
+			anim = nil
+		}
+	} else {
+		img, _, err = image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", format, err)
+		}
+	}
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	bounds := img.Bounds()
+	result := &Result{Width: bounds.Dx(), Height: bounds.Dy()}
+
+	result.Blurhash, err = blurhash.Encode(4, 3, resize(img, Variant{MaxWidth: 32, MaxHeight: 32}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute blurhash: %w", err)
+	}
+
+	for _, v := range variants {
+		var out Output
+		if anim != nil && v.Name == Full.Name && !v.Crop && anim.Config.Width <= v.MaxWidth && anim.Config.Height <= v.MaxHeight {
+			out, err = encodeGIF(anim)
+		} else {
+			out, err = encode(resize(img, v))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s variant: %w", v.Name, err)
+		}
+		out.Name = v.Name
+		result.Variants = append(result.Variants, out)
+	}
+	return result, nil
+}
+
+// resize scales img to fit within (or, for cropping variants, fill) the variant's box.
+// Images are never enlarged.
+func resize(img image.Image, v Variant) *image.NRGBA {
+	src := img.Bounds()
+	w, h := src.Dx(), src.Dy()
+
+	if v.Crop {
+		// Cut the largest centered region with the target aspect ratio, then scale it
+		cw, ch := w, w*v.MaxHeight/v.MaxWidth
+		if ch > h {
+			cw, ch = h*v.MaxWidth/v.MaxHeight, h
+		}
+		x0, y0 := src.Min.X+(w-cw)/2, src.Min.Y+(h-ch)/2
+		src = image.Rect(x0, y0, x0+cw, y0+ch)
+		w, h = min(cw, v.MaxWidth), min(ch, v.MaxHeight)
+	} else if w > v.MaxWidth || h > v.MaxHeight {
+		scale := min(float64(v.MaxWidth)/float64(w), float64(v.MaxHeight)/float64(h))
+		w, h = max(1, int(float64(w)*scale+0.5)), max(1, int(float64(h)*scale+0.5))
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+// encode writes opaque images as JPEG and images with transparency as PNG
+func encode(img *image.NRGBA) (Output, error) {
+	var buf bytes.Buffer
+	out := Output{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	if img.Opaque() {
+		out.ContentType = "image/jpeg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return out, err
+		}
+	} else {
+		out.ContentType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return out, err
+		}
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// encodeGIF re-encodes an animation, dropping comments and application extensions
+func encodeGIF(anim *gif.GIF) (Output, error) {
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return Output{}, err
+	}
+	return Output{
+		Data:        buf.Bytes(),
+		ContentType: "image/gif",
+		Width:       anim.Config.Width,
+		Height:      anim.Config.Height,
+	}, nil
+}
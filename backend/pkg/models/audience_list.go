@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AudienceList is a named list of a user's followers, such as "Close
+// friends", that private posts can be shared with. Posts shared with a list
+// are visible to its members at the time they're read.
+type AudienceList struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"-"`
+	Name        string    `json:"name"`
+	MemberCount int       `json:"member_count" db:"-"` // Computed when reading lists
+	CreatedAt   time.Time `json:"created_at"`
+}
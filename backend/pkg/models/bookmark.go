@@ -0,0 +1,24 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Bookmark is a post a user saved to read later. Bookmarks are only visible
+// to the user who made them.
+type Bookmark struct {
+	UserID       string         `json:"-"`
+	PostID       string         `json:"post_id"`
+	CollectionID sql.NullString `json:"collection_id,omitempty"` // NULL if not filed in a collection
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// BookmarkCollection is a named group of a user's bookmarks
+type BookmarkCollection struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"-"`
+	Name          string    `json:"name"`
+	BookmarkCount int       `json:"bookmark_count" db:"-"` // Computed when listing collections
+	CreatedAt     time.Time `json:"created_at"`
+}
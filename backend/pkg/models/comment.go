@@ -9,5 +9,6 @@ type Comment struct {
 	UserID    string    `json:"user_id"`
 	Content   string    `json:"content"`
 	ImageURL  string    `json:"image_url,omitempty"` // New field
+	ImageID   string    `json:"image_id,omitempty"`  // Uploaded media the image is served from
 	CreatedAt time.Time `json:"created_at"`
 }
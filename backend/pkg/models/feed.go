@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// FeedMode is how a user's home and following feeds are ordered
+type FeedMode string
+
+const (
+	FeedModeRanked        FeedMode = "ranked"        // Scored by recency, affinity, engagement and group activity
+	FeedModeChronological FeedMode = "chronological" // Newest first
+)
+
+// AuthorAffinity sums up how much a viewer interacts with an author
+type AuthorAffinity struct {
+	DirectMessages int       // Direct messages exchanged, in either direction
+	Comments       int       // Comments by the viewer on the author's posts
+	FollowedSince  time.Time // Zero if the viewer doesn't follow the author
+	GroupActivity  int       // Recent posts by the author in groups the viewer belongs to
+}
+
+// PostEngagement sums up the reactions to a post
+type PostEngagement struct {
+	Comments   int
+	Commenters int // Distinct commenters other than the author
+}
@@ -9,6 +9,7 @@ type Group struct {
 	Description string    `json:"description"`
 	CreatorID   string    `json:"creator_id"`
 	AvatarURL   string    `json:"avatar_url"`
+	AvatarID    string    `json:"avatar_id,omitempty"` // Uploaded media the avatar is served from
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -27,7 +28,11 @@ type GroupMessage struct {
 	GroupID   string    `json:"group_id"`
 	SenderID  string    `json:"sender_id"`
 	Content   string    `json:"content"`
+	ImageID   string    `json:"image_id,omitempty"` // Optional image uploaded through /api/media
 	CreatedAt time.Time `json:"created_at"` // Changed to time.Time for consistency
+
+	// Previews of the links in the content; not stored with the message
+	LinkPreviews []*LinkPreview `json:"link_previews,omitempty"`
 }
 
 // GroupInvitation represents an invitation for a user to join a group
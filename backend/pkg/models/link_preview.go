@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LinkPreview describes the page a URL in a post or message points to. Previews
+// are cached by URL and shared by every post and message that links there.
+type LinkPreview struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	Type        string    `json:"type,omitempty"` // e.g. "article" or "video"; "image" for links to images
+	Failed      bool      `json:"-"`              // The page couldn't be fetched
+	FetchedAt   time.Time `json:"-"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Media processing states
+const (
+	MediaStatusPending    = "pending"    // Uploaded, waiting for the image worker
+	MediaStatusProcessing = "processing" // Claimed by a worker
+	MediaStatusReady      = "ready"      // Normalized and servable
+	MediaStatusFailed     = "failed"     // Could not be processed; never served
+)
+
+// Media is a file uploaded through the media API. Its contents are kept in
+// the blob store under StorageKey.
+type Media struct {
+	ID              string         `json:"id"`
+	OwnerID         string         `json:"owner_id"`
+	StorageKey      string         `json:"-"`
+	ContentType     string         `json:"content_type"`
+	Size            int64          `json:"size"`
+	Status          string         `json:"status"`
+	Width           int            `json:"width,omitempty"`
+	Height          int            `json:"height,omitempty"`
+	Blurhash        string         `json:"blurhash,omitempty"`
+	ProcessingError string         `json:"-"`
+	CreatedAt       time.Time      `json:"created_at"`
+	ProcessedAt     *time.Time     `json:"processed_at,omitempty"`
+	Variants        []MediaVariant `json:"variants,omitempty" db:"-"` // Populated separately from media_variants
+}
+
+// MediaVariant is a smaller rendition of a processed image
+type MediaVariant struct {
+	MediaID     string `json:"-"`
+	Name        string `json:"name"` // e.g. "thumb", "avatar"
+	StorageKey  string `json:"-"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+}
+
+// Kinds of entities that reference media
+const (
+	MediaRefPost          = "post"
+	MediaRefComment       = "comment"
+	MediaRefUserAvatar    = "user_avatar"
+	MediaRefGroupAvatar   = "group_avatar"
+	MediaRefDirectMessage = "direct_message"
+	MediaRefGroupMessage  = "group_message"
+)
+
+// MediaReference is an entity that uses a media item. Who may view the media
+// follows from who may view the entity.
+type MediaReference struct {
+	Kind     string
+	EntityID string // Post ID (also for comments), user ID, group ID, or sender ID for direct messages
+	OtherID  string // Receiver ID for direct messages; empty otherwise
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Mention records that a post or comment referenced a user with @username
+type Mention struct {
+	ID        string         `json:"id" db:"id"`
+	UserID    string         `json:"user_id" db:"user_id"`     // Mentioned user
+	AuthorID  string         `json:"author_id" db:"author_id"` // Author of the post or comment
+	PostID    string         `json:"post_id" db:"post_id"`
+	CommentID sql.NullString `json:"comment_id" db:"comment_id"` // NULL for mentions in the post itself
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
@@ -5,5 +5,9 @@ type Message struct {
 	SenderID   string `json:"sender_id"`
 	ReceiverID string `json:"receiver_id"`
 	Content    string `json:"content"`
+	ImageID    string `json:"image_id,omitempty"` // Optional image uploaded through /api/media
 	CreatedAt  string `json:"created_at"`
+
+	// Previews of the links in the content; not stored with the message
+	LinkPreviews []*LinkPreview `json:"link_previews,omitempty"`
 }
@@ -16,6 +16,7 @@ const (
 	GroupInviteNotification   NotificationType = "group_invite"
 	GroupJoinRequestNotification NotificationType = "group_join_request"
 	GroupEventCreatedNotification NotificationType = "group_event_created"
+	MentionNotification           NotificationType = "mention" // @username in a post or comment
 	// Add other notification types here in the future
 )
 
@@ -23,6 +24,7 @@ const (
 	UserEntityType  EntityType = "user"
 	GroupEntityType EntityType = "group"
 	EventEntityType EntityType = "event"
+	PostEntityType  EntityType = "post"
 	// Add other entity types here
 )
 
@@ -0,0 +1,16 @@
+package models
+
+// Page selects a page of a list ordered by creation time and ID. Pages
+// continue from an opaque cursor; Offset is kept for clients that haven't
+// moved to cursors yet and is ignored when After is set.
+type Page struct {
+	Limit  int
+	Offset int    // Deprecated: use After
+	After  string // NextCursor of the previous page
+}
+
+// PageInfo tells where a page ended
+type PageInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"` // Empty on the last page
+	HasMore    bool   `json:"has_more"`
+}
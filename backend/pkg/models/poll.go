@@ -0,0 +1,29 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Poll is a vote attached to a post. It shares the post's ID and audience.
+type Poll struct {
+	PostID         string        `json:"post_id"`
+	MultipleChoice bool          `json:"multiple_choice"` // Voters may pick several options
+	Anonymous      bool          `json:"anonymous"`       // Only vote counts are shown, not the voters
+	ClosesAt       sql.NullTime  `json:"closes_at"`       // NULL if the poll never closes
+	Options        []*PollOption `json:"options"`         // In order
+}
+
+// PollOption is one of the answers of a poll
+type PollOption struct {
+	ID        string `json:"id"`
+	PostID    string `json:"-"`
+	Position  int    `json:"position"`
+	Text      string `json:"text"`
+	VoteCount int    `json:"vote_count" db:"-"` // Computed when loading the poll
+}
+
+// IsClosed reports whether the poll stopped taking votes at now
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosesAt.Valid && !now.Before(p.ClosesAt.Time)
+}
@@ -12,14 +12,45 @@ const (
 	PrivacyPrivate       = "private"      // Specific users only
 )
 
+// Publication states of posts. Drafts and scheduled posts are only visible to their author.
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled" // Published by the scheduler at ScheduledAt
+	PostStatusPublished = "published"
+)
+
+// Share types of posts that share another post
+const (
+	ShareTypeRepost = "repost" // Shares the post as is
+	ShareTypeQuote  = "quote"  // Shares the post with the sharer's own text
+)
+
 type Post struct {
-	ID           string         `json:"id"`
-	UserID       string         `json:"user_id"`
-	Title        string         `json:"title"`
-	Content      string         `json:"content"`
-	ImageURL     string         `json:"image_url,omitempty"`
-	Privacy      string         `json:"privacy"`            // Should be one of the constants above
-	GroupID      sql.NullString `json:"group_id,omitempty"` // Nullable foreign key to groups table
-	CreatedAt    time.Time      `json:"created_at"`
-	AllowedUsers []string       `json:"-" db:"-"` // Not stored in posts table, populated separately for private posts
+	ID             string           `json:"id"`
+	UserID         string           `json:"user_id"`
+	Title          string           `json:"title"`
+	Content        string           `json:"content"`
+	ImageURL       string           `json:"image_url,omitempty"`
+	ImageID        string           `json:"image_id,omitempty"`           // Uploaded media the image is served from
+	Privacy        string           `json:"privacy"`                      // One of the privacy constants
+	GroupID        sql.NullString   `json:"group_id,omitempty"`           // Nullable foreign key to groups table
+	SharedPostID   sql.NullString   `json:"shared_post_id,omitempty"`     // Post shared by a repost or quote; NULL once that post is deleted
+	ShareType      string           `json:"share_type,omitempty"`         // One of the share type constants, empty for ordinary posts
+	Status         string           `json:"status"`                       // One of the post status constants
+	ScheduledAt    sql.NullTime     `json:"scheduled_at,omitempty"`       // Set while scheduled
+	PinnedAt       sql.NullTime     `json:"pinned_at,omitempty"`          // Set while pinned on the author's profile, or in the group for group posts
+	CreatedAt      time.Time        `json:"created_at"`                   // Publication time once published
+	AllowedUsers   []string         `json:"-" db:"-"`                     // Not stored in posts table, populated separately for private posts
+	AudienceListID string           `json:"-" db:"-"`                     // ID of the audience list a private post is shared with, populated separately
+	Attachments    []PostAttachment `json:"attachments,omitempty" db:"-"` // Populated separately from post_attachments
+	Tags           []string         `json:"tags,omitempty" db:"-"`        // Hashtags in the content, populated separately from post_tags
+	Poll           *Poll            `json:"poll,omitempty" db:"-"`        // Set when creating a post with a poll
+}
+
+// PostAttachment is an uploaded media item in a post's gallery
+type PostAttachment struct {
+	PostID   string `json:"post_id"`
+	MediaID  string `json:"media_id"`
+	Position int    `json:"position"` // 0-based order in the gallery
+	AltText  string `json:"alt_text"`
 }
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Kinds of entities returned by search
+const (
+	SearchTypePost    = "post"
+	SearchTypeComment = "comment"
+	SearchTypeUser    = "user"
+	SearchTypeGroup   = "group"
+)
+
+// SearchTypes lists every searchable kind, in the order results are grouped when ranks tie
+var SearchTypes = []string{SearchTypePost, SearchTypeComment, SearchTypeUser, SearchTypeGroup}
+
+// Markers placed around matched terms in SearchResult.Title and Snippet.
+// Control characters don't occur in ordinary text and pass through HTML
+// escaping unchanged, so the markers can be replaced by tags afterwards.
+const (
+	SearchMatchStart = "\x02"
+	SearchMatchEnd   = "\x03"
+)
+
+// SearchResult is a full-text search match
+type SearchResult struct {
+	Type      string    `json:"type"`               // One of the SearchType constants
+	ID        string    `json:"id"`                 // ID of the post, comment, user or group
+	Title     string    `json:"title"`              // Post title, user's name or group name, with matches marked
+	Snippet   string    `json:"snippet"`            // Excerpt of the body around the matches
+	Score     float64   `json:"score"`              // Relevance; higher is better
+	UserID    string    `json:"user_id,omitempty"`  // Author of a post or comment
+	PostID    string    `json:"post_id,omitempty"`  // Post a comment belongs to
+	GroupID   string    `json:"group_id,omitempty"` // Group a post was made in
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
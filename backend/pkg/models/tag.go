@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Tag is a hashtag used in post content. Name is lowercase, without the '#'.
+type Tag struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TrendingTag is a tag ranked by its recent use in public posts
+type TrendingTag struct {
+	Name        string `json:"name"`
+	PostCount   int    `json:"post_count"`   // Public posts using the tag in the window
+	AuthorCount int    `json:"author_count"` // Distinct authors of those posts
+}
+
+// FollowedTag is a tag a user follows
+type FollowedTag struct {
+	Name       string    `json:"name"`
+	FollowedAt time.Time `json:"followed_at"`
+}
@@ -13,6 +13,7 @@ type User struct {
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	AvatarURL string    `json:"avatar_url,omitempty"`
+	AvatarID  string    `json:"avatar_id,omitempty"` // Uploaded media the avatar is served from
 	AboutMe   string    `json:"about_me,omitempty"`
 	BirthDate string    `json:"birth_date"`
 	IsPrivate bool      `json:"is_private" db:"is_private"` // Added for profile privacy
@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAudienceListNotFound indicates that the user has no audience list with the given ID.
+	ErrAudienceListNotFound = errors.New("audience list not found")
+	// ErrAudienceListExists indicates that the user already has an audience list with the name.
+	ErrAudienceListExists = errors.New("audience list already exists")
+	// ErrAudienceListMemberNotFound indicates that the user isn't a member of the list.
+	ErrAudienceListMemberNotFound = errors.New("audience list member not found")
+)
+
+// AudienceListRepository defines the interface for audience list data access.
+// Membership changes take effect on every post shared with the list, so they
+// also refresh the timeline entries of those posts.
+type AudienceListRepository interface {
+	Create(list *models.AudienceList) error
+	GetByID(userID, listID string) (*models.AudienceList, error) // Only the user's own
+	ListByUser(userID string) ([]*models.AudienceList, error)    // By name, with member counts
+	Delete(userID, listID string) error                          // Posts shared with it stay private
+
+	ListMembers(listID string) ([]types.UserBasicInfo, error)
+	AddMember(listID, userID string) error // Adding a member again is a no-op
+	RemoveMember(listID, userID string) error
+	// RemoveFromLists removes a user from all of ownerID's lists, e.g. when
+	// they stop following them. It returns the posts shared with them through
+	// those lists.
+	RemoveFromLists(ownerID, userID string) ([]string, error)
+	// ListPostIDs returns the posts shared with a list
+	ListPostIDs(listID string) ([]string, error)
+}
+
+// audienceListRepository implements AudienceListRepository interface
+type audienceListRepository struct {
+	db DBTX
+}
+
+// NewAudienceListRepository creates a new AudienceListRepository
+func NewAudienceListRepository(db DBTX) AudienceListRepository {
+	return &audienceListRepository{db: db}
+}
+
+// Create inserts an audience list
+func (r *audienceListRepository) Create(list *models.AudienceList) error {
+	list.ID = uuid.New().String()
+	list.CreatedAt = time.Now()
+	_, err := r.db.Exec(
+		"INSERT INTO audience_lists (id, user_id, name, created_at) VALUES (?, ?, ?, ?)",
+		list.ID, list.UserID, list.Name, list.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "audience_lists.user_id", "audience_lists.name") {
+			return ErrAudienceListExists
+		}
+		return fmt.Errorf("failed to create audience list: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of a user's audience lists
+func (r *audienceListRepository) GetByID(userID, listID string) (*models.AudienceList, error) {
+	list := models.AudienceList{UserID: userID}
+	var createdAt string
+	err := r.db.QueryRow(`
+        SELECT l.id, l.name, l.created_at, (SELECT COUNT(*) FROM audience_list_members m WHERE m.list_id = l.id)
+        FROM audience_lists l
+        WHERE l.id = ? AND l.user_id = ?
+    `, listID, userID).Scan(&list.ID, &list.Name, &createdAt, &list.MemberCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAudienceListNotFound
+		}
+		return nil, fmt.Errorf("failed to get audience list %s: %w", listID, err)
+	}
+	if list.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse audience list timestamp: %w", err)
+	}
+	return &list, nil
+}
+
+// ListByUser retrieves a user's audience lists
+func (r *audienceListRepository) ListByUser(userID string) ([]*models.AudienceList, error) {
+	rows, err := r.db.Query(`
+        SELECT l.id, l.name, l.created_at, COUNT(m.user_id)
+        FROM audience_lists l
+        LEFT JOIN audience_list_members m ON m.list_id = l.id
+        WHERE l.user_id = ?
+        GROUP BY l.id, l.name, l.created_at
+        ORDER BY l.name
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audience lists of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	lists := make([]*models.AudienceList, 0)
+	for rows.Next() {
+		list := models.AudienceList{UserID: userID}
+		var createdAt string
+		if err := rows.Scan(&list.ID, &list.Name, &createdAt, &list.MemberCount); err != nil {
+			return nil, fmt.Errorf("failed to scan audience list: %w", err)
+		}
+		if list.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse audience list timestamp: %w", err)
+		}
+		lists = append(lists, &list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audience lists: %w", err)
+	}
+	return lists, nil
+}
+
+// Delete removes one of a user's audience lists. The posts shared with it
+// remain visible to their allowed users only.
+func (r *audienceListRepository) Delete(userID, listID string) error {
+	return runInTx(r.db, func(tx DBTX) error {
+		postIDs, err := listPostIDs(tx, listID)
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec("DELETE FROM audience_lists WHERE id = ? AND user_id = ?", listID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete audience list %s: %w", listID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected for audience list delete: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrAudienceListNotFound
+		}
+		return refreshPostTimelines(tx, postIDs)
+	})
+}
+
+// ListMembers retrieves the members of a list, by name
+func (r *audienceListRepository) ListMembers(listID string) ([]types.UserBasicInfo, error) {
+	rows, err := r.db.Query(`
+        SELECT u.id, u.first_name, u.last_name, u.username, u.avatar_url
+        FROM audience_list_members m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.list_id = ?
+        ORDER BY u.first_name, u.last_name, u.id
+    `, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members of audience list %s: %w", listID, err)
+	}
+	defer rows.Close()
+
+	members := make([]types.UserBasicInfo, 0)
+	for rows.Next() {
+		var member types.UserBasicInfo
+		var avatarURL sql.NullString
+		if err := rows.Scan(&member.UserID, &member.FirstName, &member.LastName, &member.Username, &avatarURL); err != nil {
+			return nil, fmt.Errorf("failed to scan audience list member: %w", err)
+		}
+		member.AvatarURL = avatarURL.String
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audience list members: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds a user to a list and to the timelines of its posts
+func (r *audienceListRepository) AddMember(listID, userID string) error {
+	return runInTx(r.db, func(tx DBTX) error {
+		_, err := tx.Exec(
+			"INSERT INTO audience_list_members (list_id, user_id, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+			listID, userID, time.Now(),
+		)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to add user %s to audience list %s: %w", userID, listID, err)
+		}
+		postIDs, err := listPostIDs(tx, listID)
+		if err != nil {
+			return err
+		}
+		return refreshPostTimelines(tx, postIDs)
+	})
+}
+
+// RemoveMember removes a user from a list and from the timelines of its posts
+// they can no longer see
+func (r *audienceListRepository) RemoveMember(listID, userID string) error {
+	return runInTx(r.db, func(tx DBTX) error {
+		result, err := tx.Exec("DELETE FROM audience_list_members WHERE list_id = ? AND user_id = ?", listID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove user %s from audience list %s: %w", userID, listID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected for audience list member delete: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrAudienceListMemberNotFound
+		}
+		postIDs, err := listPostIDs(tx, listID)
+		if err != nil {
+			return err
+		}
+		return refreshPostTimelines(tx, postIDs)
+	})
+}
+
+// RemoveFromLists removes a user from every list of ownerID
+func (r *audienceListRepository) RemoveFromLists(ownerID, userID string) ([]string, error) {
+	var postIDs []string
+	err := runInTx(r.db, func(tx DBTX) error {
+		rows, err := tx.Query(`
+            SELECT pal.post_id FROM post_audience_lists pal
+            JOIN audience_list_members m ON m.list_id = pal.list_id
+            JOIN audience_lists l ON l.id = pal.list_id
+            WHERE l.user_id = ? AND m.user_id = ?
+        `, ownerID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to query posts shared with %s through lists of %s: %w", userID, ownerID, err)
+		}
+		if postIDs, err = scanPostIDs(rows); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+            DELETE FROM audience_list_members
+            WHERE user_id = ? AND list_id IN (SELECT id FROM audience_lists WHERE user_id = ?)
+        `, userID, ownerID)
+		if err != nil {
+			return fmt.Errorf("failed to remove user %s from audience lists of %s: %w", userID, ownerID, err)
+		}
+		return refreshPostTimelines(tx, postIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return postIDs, nil
+}
+
+// ListPostIDs retrieves the IDs of the posts shared with a list
+func (r *audienceListRepository) ListPostIDs(listID string) ([]string, error) {
+	return listPostIDs(r.db, listID)
+}
+
+// listPostIDs implements ListPostIDs on tx
+func listPostIDs(tx DBTX, listID string) ([]string, error) {
+	rows, err := tx.Query("SELECT post_id FROM post_audience_lists WHERE list_id = ?", listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts of audience list %s: %w", listID, err)
+	}
+	return scanPostIDs(rows)
+}
+
+// scanPostIDs reads a column of post IDs and closes rows
+func scanPostIDs(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	postIDs := make([]string, 0)
+	for rows.Next() {
+		var postID string
+		if err := rows.Scan(&postID); err != nil {
+			return nil, fmt.Errorf("failed to scan post ID: %w", err)
+		}
+		postIDs = append(postIDs, postID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating post IDs: %w", err)
+	}
+	return postIDs, nil
+}
+
+// refreshPostTimelines rebuilds the timeline entries of fanned-out posts
+// after their audience changed
+func refreshPostTimelines(tx DBTX, postIDs []string) error {
+	for _, postID := range postIDs {
+		if err := refreshTimelines(tx, postID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
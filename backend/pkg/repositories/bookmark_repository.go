@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrBookmarkNotFound indicates that the user hasn't bookmarked the post.
+	ErrBookmarkNotFound = errors.New("bookmark not found")
+	// ErrCollectionNotFound indicates that the user has no bookmark collection with the given ID.
+	ErrCollectionNotFound = errors.New("bookmark collection not found")
+	// ErrCollectionExists indicates that the user already has a collection with the name.
+	ErrCollectionExists = errors.New("bookmark collection already exists")
+)
+
+// BookmarkRepository defines the interface for bookmark data access.
+// Bookmarks of deleted posts are removed by foreign keys.
+type BookmarkRepository interface {
+	// Save bookmarks a post, or moves an existing bookmark to another collection
+	Save(bookmark *models.Bookmark) error
+	Delete(userID, postID string) error
+	// List returns a user's bookmarks, newest first, optionally only those in a collection
+	List(userID, collectionID string, page models.Page) ([]*models.Bookmark, models.PageInfo, error)
+
+	CreateCollection(collection *models.BookmarkCollection) error
+	GetCollection(userID, collectionID string) (*models.BookmarkCollection, error) // Only the user's own
+	ListCollections(userID string) ([]*models.BookmarkCollection, error)           // By name, with bookmark counts
+	DeleteCollection(userID, collectionID string) error                            // Its bookmarks stay, unfiled
+}
+
+// bookmarkRepository implements BookmarkRepository interface
+type bookmarkRepository struct {
+	db DBTX
+}
+
+// NewBookmarkRepository creates a new BookmarkRepository
+func NewBookmarkRepository(db DBTX) BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// Save inserts a bookmark. Bookmarking a post again keeps its original time
+// and only updates the collection.
+func (r *bookmarkRepository) Save(bookmark *models.Bookmark) error {
+	bookmark.CreatedAt = time.Now()
+	_, err := r.db.Exec(`
+        INSERT INTO bookmarks (user_id, post_id, collection_id, created_at) VALUES (?, ?, ?, ?)
+        ON CONFLICT (user_id, post_id) DO UPDATE SET collection_id = excluded.collection_id
+    `, bookmark.UserID, bookmark.PostID, bookmark.CollectionID, bookmark.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrPostNotFound
+		}
+		return fmt.Errorf("failed to bookmark post %s for user %s: %w", bookmark.PostID, bookmark.UserID, err)
+	}
+	return nil
+}
+
+// Delete removes a bookmark
+func (r *bookmarkRepository) Delete(userID, postID string) error {
+	result, err := r.db.Exec("DELETE FROM bookmarks WHERE user_id = ? AND post_id = ?", userID, postID)
+	if err != nil {
+		return fmt.Errorf("failed to delete bookmark of post %s for user %s: %w", postID, userID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for bookmark delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrBookmarkNotFound
+	}
+	return nil
+}
+
+// List retrieves a page of a user's bookmarks. The posts themselves aren't
+// checked here; the caller decides which of them the user may still see.
+func (r *bookmarkRepository) List(userID, collectionID string, page models.Page) ([]*models.Bookmark, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "created_at", "post_id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+	args := []interface{}{userID}
+	inCollection := "1 = 1"
+	if collectionID != "" {
+		inCollection = "collection_id = ?"
+		args = append(args, collectionID)
+	}
+
+	query := `
+        SELECT post_id, collection_id, created_at, ` + sortKey("created_at") + `
+        FROM bookmarks
+        WHERE user_id = ? AND ` + inCollection + `
+        AND ` + after + `
+        ORDER BY created_at DESC, post_id DESC
+        LIMIT ? OFFSET ?
+    `
+	args = append(args, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list bookmarks of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	bookmarks := make([]*models.Bookmark, 0)
+	cursors := make([]string, 0)
+	for rows.Next() {
+		bookmark := models.Bookmark{UserID: userID}
+		var createdAt, cursorKey string
+		if err := rows.Scan(&bookmark.PostID, &bookmark.CollectionID, &createdAt, &cursorKey); err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+		if bookmark.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to parse bookmark timestamp: %w", err)
+		}
+		bookmarks = append(bookmarks, &bookmark)
+		cursors = append(cursors, encodeCursor(cursorKey, bookmark.PostID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating bookmarks of user %s: %w", userID, err)
+	}
+
+	bookmarks, info := trimPage(bookmarks, cursors, page)
+	return bookmarks, info, nil
+}
+
+// CreateCollection inserts a bookmark collection
+func (r *bookmarkRepository) CreateCollection(collection *models.BookmarkCollection) error {
+	collection.ID = uuid.New().String()
+	collection.CreatedAt = time.Now()
+	_, err := r.db.Exec(
+		"INSERT INTO bookmark_collections (id, user_id, name, created_at) VALUES (?, ?, ?, ?)",
+		collection.ID, collection.UserID, collection.Name, collection.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "bookmark_collections.user_id", "bookmark_collections.name") {
+			return ErrCollectionExists
+		}
+		return fmt.Errorf("failed to create bookmark collection: %w", err)
+	}
+	return nil
+}
+
+// GetCollection retrieves one of a user's bookmark collections
+func (r *bookmarkRepository) GetCollection(userID, collectionID string) (*models.BookmarkCollection, error) {
+	collection := models.BookmarkCollection{UserID: userID}
+	var createdAt string
+	err := r.db.QueryRow(`
+        SELECT c.id, c.name, c.created_at, (SELECT COUNT(*) FROM bookmarks b WHERE b.collection_id = c.id)
+        FROM bookmark_collections c
+        WHERE c.id = ? AND c.user_id = ?
+    `, collectionID, userID).Scan(&collection.ID, &collection.Name, &createdAt, &collection.BookmarkCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get bookmark collection %s: %w", collectionID, err)
+	}
+	if collection.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmark collection timestamp: %w", err)
+	}
+	return &collection, nil
+}
+
+// ListCollections retrieves a user's bookmark collections
+func (r *bookmarkRepository) ListCollections(userID string) ([]*models.BookmarkCollection, error) {
+	rows, err := r.db.Query(`
+        SELECT c.id, c.name, c.created_at, COUNT(b.post_id)
+        FROM bookmark_collections c
+        LEFT JOIN bookmarks b ON b.collection_id = c.id
+        WHERE c.user_id = ?
+        GROUP BY c.id, c.name, c.created_at
+        ORDER BY c.name
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmark collections of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	collections := make([]*models.BookmarkCollection, 0)
+	for rows.Next() {
+		collection := models.BookmarkCollection{UserID: userID}
+		var createdAt string
+		if err := rows.Scan(&collection.ID, &collection.Name, &createdAt, &collection.BookmarkCount); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark collection: %w", err)
+		}
+		if collection.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse bookmark collection timestamp: %w", err)
+		}
+		collections = append(collections, &collection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookmark collections: %w", err)
+	}
+	return collections, nil
+}
+
+// DeleteCollection removes one of a user's bookmark collections
+func (r *bookmarkRepository) DeleteCollection(userID, collectionID string) error {
+	result, err := r.db.Exec("DELETE FROM bookmark_collections WHERE id = ? AND user_id = ?", collectionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete bookmark collection %s: %w", collectionID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for bookmark collection delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
@@ -11,26 +11,26 @@ import (
 type ChatMessageRepository interface {
 	SaveDirectMessage(message *models.Message) error
 	SaveGroupMessage(message *models.GroupMessage) error
-	// GetDirectMessagesBetweenUsers retrieves paginated direct messages between two users and the total count.
-	GetDirectMessagesBetweenUsers(user1ID, user2ID string, limit, offset int) ([]models.Message, int64, error)
-	GetGroupMessages(groupID string, limit int, offset int, currentUserID string) ([]*models.GroupMessage, error)
+	// GetDirectMessagesBetweenUsers retrieves a page of direct messages between two users, newest first, and the total count.
+	GetDirectMessagesBetweenUsers(user1ID, user2ID string, page models.Page) ([]models.Message, models.PageInfo, int64, error)
+	GetGroupMessages(groupID string, page models.Page, currentUserID string) ([]*models.GroupMessage, models.PageInfo, error) // Newest first
 	GetChatPartners(currentUserID string) ([]models.ChatPartner, error) // Added method
 }
 
 // chatMessageRepository implements the ChatMessageRepository interface.
 type chatMessageRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewChatMessageRepository creates a new instance of ChatMessageRepository.
-func NewChatMessageRepository(db *sql.DB) ChatMessageRepository {
+func NewChatMessageRepository(db DBTX) ChatMessageRepository {
 	return &chatMessageRepository{db: db}
 }
 
 // SaveDirectMessage saves a direct message to the database.
 func (r *chatMessageRepository) SaveDirectMessage(message *models.Message) error {
-	query := `INSERT INTO messages (id, sender_id, receiver_id, content, created_at) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Exec(query, message.ID, message.SenderID, message.ReceiverID, message.Content, message.CreatedAt)
+	query := `INSERT INTO messages (id, sender_id, receiver_id, content, image_id, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(query, message.ID, message.SenderID, message.ReceiverID, message.Content, nullIfEmpty(message.ImageID), message.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save direct message: %w", err) // Added error wrapping
 	}
@@ -39,8 +39,8 @@ func (r *chatMessageRepository) SaveDirectMessage(message *models.Message) error
 
 // SaveGroupMessage saves a group message to the database.
 func (r *chatMessageRepository) SaveGroupMessage(message *models.GroupMessage) error {
-	query := `INSERT INTO group_messages (id, group_id, sender_id, content, created_at) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Exec(query, message.ID, message.GroupID, message.SenderID, message.Content, message.CreatedAt)
+	query := `INSERT INTO group_messages (id, group_id, sender_id, content, image_id, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(query, message.ID, message.GroupID, message.SenderID, message.Content, nullIfEmpty(message.ImageID), message.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save group message: %w", err) // Added error wrapping
 	}
@@ -48,10 +48,16 @@ func (r *chatMessageRepository) SaveGroupMessage(message *models.GroupMessage) e
 }
 
 // GetDirectMessagesBetweenUsers retrieves paginated direct messages between two users and the total count.
-func (r *chatMessageRepository) GetDirectMessagesBetweenUsers(user1ID, user2ID string, limit, offset int) ([]models.Message, int64, error) {
+func (r *chatMessageRepository) GetDirectMessagesBetweenUsers(user1ID, user2ID string, page models.Page) ([]models.Message, models.PageInfo, int64, error) {
 	var totalCount int64
 	var messages []models.Message
 
+	after, afterArgs, err := afterCursor(page, "created_at", "id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, 0, err
+	}
+	limit, offset := pageBounds(page)
+
 	// Query to get the total count
 	countQuery := `
 		SELECT COUNT(*)
@@ -59,89 +65,110 @@ func (r *chatMessageRepository) GetDirectMessagesBetweenUsers(user1ID, user2ID s
 		WHERE (sender_id = $1 AND receiver_id = $2)
 		   OR (sender_id = $3 AND receiver_id = $4)
 	`
-	err := r.db.QueryRow(countQuery, user1ID, user2ID, user2ID, user1ID).Scan(&totalCount)
+	err = r.db.QueryRow(countQuery, user1ID, user2ID, user2ID, user1ID).Scan(&totalCount)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count direct messages: %w", err)
+		return nil, models.PageInfo{}, 0, fmt.Errorf("failed to count direct messages: %w", err)
 	}
 
 	// If count is 0, no need to query for messages
 	if totalCount == 0 {
-		return messages, 0, nil
+		return messages, models.PageInfo{}, 0, nil
 	}
 
 	// Query to get the paginated messages
 	messagesQuery := `
-		SELECT id, sender_id, receiver_id, content, created_at
+		SELECT id, sender_id, receiver_id, content, COALESCE(image_id, ''), created_at, ` + sortKey("created_at") + `
 		FROM messages
-		WHERE (sender_id = $1 AND receiver_id = $2)
-		   OR (sender_id = $3 AND receiver_id = $4)
-		ORDER BY created_at DESC
-		LIMIT $5 OFFSET $6
+		WHERE ((sender_id = ? AND receiver_id = ?)
+		   OR (sender_id = ? AND receiver_id = ?))
+		  AND ` + after + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
 	`
-	rows, err := r.db.Query(messagesQuery, user1ID, user2ID, user2ID, user1ID, limit, offset)
+	args := append([]interface{}{user1ID, user2ID, user2ID, user1ID}, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(messagesQuery, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query direct messages: %w", err)
+		return nil, models.PageInfo{}, 0, fmt.Errorf("failed to query direct messages: %w", err)
 	}
 	defer rows.Close()
 
+	cursors := make([]string, 0)
 	for rows.Next() {
 		var msg models.Message // Use value type as returned by service
+		var cursorKey string
 		err := rows.Scan(
 			&msg.ID,
 			&msg.SenderID,
 			&msg.ReceiverID,
 			&msg.Content,
+			&msg.ImageID,
 			&msg.CreatedAt,
+			&cursorKey,
 		)
 		if err != nil {
 			// Log or handle scan error appropriately
-			return nil, 0, fmt.Errorf("failed to scan direct message row: %w", err)
+			return nil, models.PageInfo{}, 0, fmt.Errorf("failed to scan direct message row: %w", err)
 		}
 		messages = append(messages, msg)
+		cursors = append(cursors, encodeCursor(cursorKey, msg.ID))
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating direct message rows: %w", err)
+		return nil, models.PageInfo{}, 0, fmt.Errorf("error iterating direct message rows: %w", err)
 	}
 
-	return messages, totalCount, nil
+	messages, info := trimPage(messages, cursors, page)
+	return messages, info, totalCount, nil
 }
 
 // GetGroupMessages retrieves messages for a group with pagination.
-func (r *chatMessageRepository) GetGroupMessages(groupID string, limit int, offset int, currentUserID string) ([]*models.GroupMessage, error) {
+func (r *chatMessageRepository) GetGroupMessages(groupID string, page models.Page, currentUserID string) ([]*models.GroupMessage, models.PageInfo, error) {
 	// TODO: Implement actual logic for fetching group messages for ChatMessageRepository if needed
 	// This is a stub to satisfy the interface.
 	// The primary implementation is likely in sqlite_message_repository.go
 	// log.Printf("ChatMessageRepository: GetGroupMessages called with groupID: %s, limit: %d, offset: %d, currentUserID: %s (STUB)", groupID, limit, offset, currentUserID)
 	// return []*models.GroupMessage{}, nil
+	after, afterArgs, err := afterCursor(page, "created_at", "id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
 	query := `
-	       SELECT id, group_id, sender_id, content, created_at
+	       SELECT id, group_id, sender_id, content, COALESCE(image_id, ''), created_at, ` + sortKey("created_at") + `
 	       FROM group_messages
-	       WHERE group_id = $1
-	       ORDER BY created_at DESC
-	       LIMIT $2 OFFSET $3
-	   ` // Using $ placeholders for PostgreSQL compatibility
+	       WHERE group_id = ? AND ` + after + `
+	       ORDER BY created_at DESC, id DESC
+	       LIMIT ? OFFSET ?
+	   ` // Placeholders are rewritten for PostgreSQL
 
-	rows, err := r.db.Query(query, groupID, limit, offset)
+	args := append([]interface{}{groupID}, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query group messages: %w", err) // Added error wrapping
+		return nil, models.PageInfo{}, fmt.Errorf("failed to query group messages: %w", err) // Added error wrapping
 	}
 	defer rows.Close()
 
 	messages := make([]*models.GroupMessage, 0)
+	cursors := make([]string, 0)
 	for rows.Next() {
 		msg := &models.GroupMessage{}
 		var nullableID sql.NullString // Use sql.NullString for the ID
+		var cursorKey string
 
 		err := rows.Scan(
 			&nullableID, // Scan into the NullString
 			&msg.GroupID,
 			&msg.SenderID,
 			&msg.Content,
+			&msg.ImageID,
 			&msg.CreatedAt,
+			&cursorKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan group message row: %w", err) // Added error wrapping
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan group message row: %w", err) // Added error wrapping
 		}
 
 		// Assign the ID only if it's not NULL in the database
@@ -152,13 +179,15 @@ func (r *chatMessageRepository) GetGroupMessages(groupID string, limit int, offs
 		}
 
 		messages = append(messages, msg)
+		cursors = append(cursors, encodeCursor(cursorKey, msg.ID))
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating group message rows: %w", err) // Added error wrapping
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating group message rows: %w", err) // Added error wrapping
 	}
 
-	return messages, nil
+	messages, info := trimPage(messages, cursors, page)
+	return messages, info, nil
 }
 
 // GetChatPartners is a stub implementation to satisfy the ChatMessageRepository interface
@@ -19,18 +19,18 @@ ErrCommentNotFound = errors.New("comment not found")
 type CommentRepository interface {
 Create(comment *models.Comment) error
 GetByID(id string) (*models.Comment, error)
-GetByPostID(postID string, limit, offset int) ([]*models.Comment, error)
+GetByPostID(postID string, page models.Page) ([]*models.Comment, models.PageInfo, error) // Oldest first
 Delete(id string) error
 // TODO: Consider adding an Update method if needed
 }
 
 // commentRepository implements CommentRepository interface
 type commentRepository struct {
-db *sql.DB
+db DBTX
 }
 
 // NewCommentRepository creates a new CommentRepository
-func NewCommentRepository(db *sql.DB) CommentRepository {
+func NewCommentRepository(db DBTX) CommentRepository {
 return &commentRepository{
 db: db,
 }
@@ -39,8 +39,8 @@ db: db,
 // Create inserts a new comment record into the database
 func (r *commentRepository) Create(comment *models.Comment) error {
 query := `
-       INSERT INTO comments (id, post_id, user_id, content, image_url, created_at)
-       VALUES (?, ?, ?, ?, ?, ?)
+       INSERT INTO comments (id, post_id, user_id, content, image_url, image_id, created_at)
+       VALUES (?, ?, ?, ?, ?, ?, ?)
    `
 comment.ID = uuid.New().String()
 comment.CreatedAt = time.Now()
@@ -52,6 +52,7 @@ _, err := r.db.Exec(
 	comment.UserID,
 	comment.Content,
 	comment.ImageURL, // New parameter
+	nullIfEmpty(comment.ImageID),
 	comment.CreatedAt,
 )
 if err != nil {
@@ -64,7 +65,7 @@ return nil
 // GetByID retrieves a comment by its ID
 func (r *commentRepository) GetByID(id string) (*models.Comment, error) {
 query := `
-       SELECT id, post_id, user_id, content, image_url, created_at
+       SELECT id, post_id, user_id, content, image_url, COALESCE(image_id, ''), created_at
        FROM comments
        WHERE id = ?
    `
@@ -77,6 +78,7 @@ err := r.db.QueryRow(query, id).Scan(
 	&comment.UserID,
 	&comment.Content,
 	&comment.ImageURL, // New field to scan
+	&comment.ImageID,
 	&createdAt,
 )
 if err != nil {
@@ -99,34 +101,46 @@ return &comment, nil
 }
 
 // GetByPostID retrieves a paginated list of comments for a specific post
-func (r *commentRepository) GetByPostID(postID string, limit, offset int) ([]*models.Comment, error) {
+func (r *commentRepository) GetByPostID(postID string, page models.Page) ([]*models.Comment, models.PageInfo, error) {
+after, afterArgs, err := afterCursor(page, "created_at", "id", true)
+if err != nil {
+return nil, models.PageInfo{}, err
+}
+limit, offset := pageBounds(page)
+
 query := `
-       SELECT id, post_id, user_id, content, image_url, created_at
+       SELECT id, post_id, user_id, content, image_url, COALESCE(image_id, ''), created_at, ` + sortKey("created_at") + `
        FROM comments
-       WHERE post_id = ?
-       ORDER BY created_at ASC -- Or DESC depending on desired order
+       WHERE post_id = ? AND ` + after + `
+       ORDER BY created_at ASC, id ASC -- Conversation order
        LIMIT ? OFFSET ?
    `
-rows, err := r.db.Query(query, postID, limit, offset)
+args := append([]interface{}{postID}, afterArgs...)
+args = append(args, limit, offset)
+rows, err := r.db.Query(query, args...)
 if err != nil {
-return nil, fmt.Errorf("failed to get comments by post ID %s: %w", postID, err)
+return nil, models.PageInfo{}, fmt.Errorf("failed to get comments by post ID %s: %w", postID, err)
 }
 defer rows.Close()
 
 comments := make([]*models.Comment, 0)
+cursors := make([]string, 0)
 for rows.Next() {
 var comment models.Comment
 var createdAt string
+var cursorKey string
 err := rows.Scan(
 	&comment.ID,
 	&comment.PostID,
 	&comment.UserID,
 	&comment.Content,
 	&comment.ImageURL, // New field to scan
+	&comment.ImageID,
 	&createdAt,
+	&cursorKey,
 )
 if err != nil {
-	return nil, fmt.Errorf("failed to scan comment for post ID %s: %w", postID, err)
+	return nil, models.PageInfo{}, fmt.Errorf("failed to scan comment for post ID %s: %w", postID, err)
 }
 // Parse timestamp
 // Custom layout for "YYYY-MM-DD HH:MM:SS.FFFFFFFFF+ZZ:ZZ"
@@ -137,13 +151,15 @@ if err != nil {
 	comment.CreatedAt = time.Time{}
 }
 comments = append(comments, &comment)
+cursors = append(cursors, encodeCursor(cursorKey, comment.ID))
 }
 
 if err := rows.Err(); err != nil {
-return nil, fmt.Errorf("error iterating comment rows for post ID %s: %w", postID, err)
+return nil, models.PageInfo{}, fmt.Errorf("error iterating comment rows for post ID %s: %w", postID, err)
 }
 
-return comments, nil
+comments, info := trimPage(comments, cursors, page)
+return comments, info, nil
 }
 
 // Delete removes a comment record by its ID
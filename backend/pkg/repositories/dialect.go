@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/db"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// dialectDB rewrites the SQLite-style queries used throughout the repositories
+// for the dialect of the underlying connection or transaction.
+type dialectDB struct {
+	conn    DBTX
+	dialect db.Dialect
+}
+
+// withDialect wraps conn so queries are rebound for dialect. SQLite needs no
+// rewriting, so conn is returned as is.
+func withDialect(conn DBTX, dialect db.Dialect) DBTX {
+	if dialect == db.SQLite || dialect == "" {
+		return conn
+	}
+	if d, ok := conn.(*dialectDB); ok {
+		conn = d.conn
+	}
+	return &dialectDB{conn: conn, dialect: dialect}
+}
+
+// dialectOf returns the dialect spoken by conn
+func dialectOf(conn DBTX) db.Dialect {
+	if d, ok := conn.(*dialectDB); ok {
+		return d.dialect
+	}
+	return db.SQLite
+}
+
+func (d *dialectDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.conn.Exec(d.dialect.Rebind(query), args...)
+}
+
+func (d *dialectDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.conn.ExecContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+func (d *dialectDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.conn.Query(d.dialect.Rebind(query), args...)
+}
+
+func (d *dialectDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.conn.QueryContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+func (d *dialectDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.conn.QueryRow(d.dialect.Rebind(query), args...)
+}
+
+func (d *dialectDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.conn.QueryRowContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+func (d *dialectDB) Prepare(query string) (*sql.Stmt, error) {
+	return d.conn.Prepare(d.dialect.Rebind(query))
+}
+
+// PostgreSQL error codes (https://www.postgresql.org/docs/current/errcodes-appendix.html)
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// isUniqueViolation reports whether err is a unique or primary key violation.
+// If columns are given (as "table.column"), only a violation of the constraint
+// covering exactly those columns matches.
+func isUniqueViolation(err error, columns ...string) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		if sqliteErr.Code != sqlite3.ErrConstraint || !strings.Contains(sqliteErr.Error(), "UNIQUE constraint failed") {
+			return false
+		}
+		return len(columns) == 0 || strings.Contains(sqliteErr.Error(), "UNIQUE constraint failed: "+strings.Join(columns, ", "))
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if pqErr.Code != pgUniqueViolation {
+			return false
+		}
+		return len(columns) == 0 || pqErr.Constraint == pgUniqueConstraintName(columns)
+	}
+	return false
+}
+
+// pgUniqueConstraintName returns the name PostgreSQL gives an unnamed UNIQUE
+// constraint on columns, e.g. ["users.email"] -> "users_email_key"
+func pgUniqueConstraintName(columns []string) string {
+	parts := make([]string, 0, len(columns)+1)
+	for i, column := range columns {
+		table, name, _ := strings.Cut(column, ".")
+		if i == 0 {
+			parts = append(parts, table)
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, "_") + "_key"
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrConstraint && strings.Contains(sqliteErr.Error(), "FOREIGN KEY constraint failed")
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgForeignKeyViolation
+}
+
+// isCheckViolation reports whether err is a CHECK constraint violation
+func isCheckViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrConstraint && strings.Contains(sqliteErr.Error(), "CHECK constraint failed")
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgCheckViolation
+}
+
+// isConstraintViolation reports whether err is any integrity constraint violation
+func isConstraintViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrConstraint
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Class() == "23"
+}
+
+// parseTimestamp parses a timestamp column scanned into a string. SQLite
+// returns the layout the driver stored it with; PostgreSQL timestamps are
+// formatted as RFC 3339 by database/sql.
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{sqliteTimestampLayout, time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp format %q", value)
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/google/uuid"
+)
+
+// insertUser inserts a user row directly, returning the driver's error
+func insertUser(tdb *testDB, id, username, email string) error {
+	_, err := tdb.db.Exec(`
+        INSERT INTO users (id, username, email, password_hash, first_name, last_name, avatar_url, about_me, birth_date, is_private, created_at, updated_at)
+        VALUES (?, ?, ?, 'hash', 'First', 'Last', '', '', '1990-01-01', ?, ?, ?)
+    `, id, username, email, false, time.Now(), time.Now())
+	return err
+}
+
+func TestConstraintClassifiers(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		if err := insertUser(tdb, uuid.NewString(), "alice", "alice@example.com"); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+		dupEmail := insertUser(tdb, uuid.NewString(), "alice2", "alice@example.com")
+		dupUsername := insertUser(tdb, uuid.NewString(), "alice", "other@example.com")
+		_, missingUser := tdb.db.Exec(`
+            INSERT INTO posts (id, user_id, title, content, privacy, status, created_at)
+            VALUES (?, ?, 't', 'c', 'public', 'published', ?)
+        `, uuid.NewString(), uuid.NewString(), time.Now())
+
+		tests := []struct {
+			name       string
+			err        error
+			unique     bool
+			uniqueCols []string // Columns passed to isUniqueViolation
+			wantCols   bool
+			foreignKey bool
+		}{
+			{"duplicate email", dupEmail, true, []string{"users.email"}, true, false},
+			{"duplicate email, other column", dupEmail, true, []string{"users.username"}, false, false},
+			{"duplicate username", dupUsername, true, []string{"users.username"}, true, false},
+			{"missing foreign key", missingUser, false, []string{"users.email"}, false, true},
+			{"other error", errors.New("boom"), false, nil, false, false},
+			{"nil", nil, false, nil, false, false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if tt.unique || tt.foreignKey {
+					if tt.err == nil {
+						t.Fatal("expected a constraint error, got nil")
+					}
+					if !isConstraintViolation(tt.err) {
+						t.Errorf("isConstraintViolation(%v) = false", tt.err)
+					}
+				}
+				if got := isUniqueViolation(tt.err); got != tt.unique {
+					t.Errorf("isUniqueViolation(%v) = %v, want %v", tt.err, got, tt.unique)
+				}
+				if got := isUniqueViolation(tt.err, tt.uniqueCols...); len(tt.uniqueCols) > 0 && got != tt.wantCols {
+					t.Errorf("isUniqueViolation(%v, %v) = %v, want %v", tt.err, tt.uniqueCols, got, tt.wantCols)
+				}
+				if got := isForeignKeyViolation(tt.err); got != tt.foreignKey {
+					t.Errorf("isForeignKeyViolation(%v) = %v, want %v", tt.err, got, tt.foreignKey)
+				}
+				if isCheckViolation(tt.err) {
+					t.Errorf("isCheckViolation(%v) = true", tt.err)
+				}
+			})
+		}
+	})
+}
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2024, 3, 5, 14, 7, 9, 123456000, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2024-03-05 14:07:09.123456+00:00", want, false}, // SQLite, as stored by go-sqlite3
+		{"2024-03-05T14:07:09.123456Z", want, false},      // PostgreSQL, via database/sql
+		{"2024-03-05T16:07:09.123456+02:00", want, false},
+		{"2024-03-05 14:07:09", want.Truncate(time.Second), false}, // CURRENT_TIMESTAMP
+		{"05/03/2024", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimestamp(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTimestamp(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimestamp(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimestampRoundTrip(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		stored := time.Date(2024, 3, 5, 14, 7, 9, 123456000, time.FixedZone("CET", 3600))
+		if _, err := tdb.db.Exec(`INSERT INTO link_previews (url, fetched_at) VALUES (?, ?)`, "https://example.com", stored); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+		var raw string
+		if err := tdb.db.QueryRow(`SELECT fetched_at FROM link_previews`).Scan(&raw); err != nil {
+			t.Fatalf("select: %v", err)
+		}
+		got, err := parseTimestamp(raw)
+		if err != nil {
+			t.Fatalf("parseTimestamp(%q): %v", raw, err)
+		}
+		if !got.Equal(stored) {
+			t.Errorf("round trip of %v gave %v (stored as %q)", stored, got, raw)
+		}
+	})
+}
+
+func TestRunInTx(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		errRollback := errors.New("rollback")
+		committed, rolledBack := uuid.NewString(), uuid.NewString()
+
+		if err := runInTx(tdb.db, func(tx DBTX) error {
+			return insertUser(&testDB{db: tx}, committed, "committed", "committed@example.com")
+		}); err != nil {
+			t.Fatalf("runInTx: %v", err)
+		}
+		err := runInTx(tdb.db, func(tx DBTX) error {
+			if err := insertUser(&testDB{db: tx}, rolledBack, "rolledback", "rolledback@example.com"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("runInTx returned %v, want %v", err, errRollback)
+		}
+
+		if _, err := tdb.repos.User.GetByID(committed); err != nil {
+			t.Errorf("committed user: %v", err)
+		}
+		if _, err := tdb.repos.User.GetByID(rolledBack); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("rolled back user: got %v, want ErrUserNotFound", err)
+		}
+	})
+}
+
+func TestUnitOfWork(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		creator := createUser(t, tdb, "creator")
+		errRollback := errors.New("rollback")
+
+		// Group.Create runs its own runInTx, which must join the unit of work
+		var rolledBack, committed models.Group
+		err := tdb.uow.Do(func(repos *Repositories) error {
+			rolledBack = models.Group{CreatorID: creator.ID, Name: "Rolled back"}
+			if err := repos.Group.Create(&rolledBack); err != nil {
+				return err
+			}
+			if ok, err := repos.Group.IsMember(rolledBack.ID, creator.ID); err != nil || !ok {
+				t.Errorf("creator not a member inside the unit of work: %v, %v", ok, err)
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("Do returned %v, want %v", err, errRollback)
+		}
+		if _, err := tdb.repos.Group.GetByID(rolledBack.ID); !errors.Is(err, ErrGroupNotFound) {
+			t.Errorf("rolled back group: got %v, want ErrGroupNotFound", err)
+		}
+
+		if err := tdb.uow.Do(func(repos *Repositories) error {
+			committed = models.Group{CreatorID: creator.ID, Name: "Committed"}
+			return repos.Group.Create(&committed)
+		}); err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if ok, err := tdb.repos.Group.IsAdmin(committed.ID, creator.ID); err != nil || !ok {
+			t.Errorf("creator not an admin of the committed group: %v, %v", ok, err)
+		}
+	})
+}
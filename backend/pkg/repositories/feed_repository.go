@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+// FeedRepository defines the interface for feed preferences and the signals
+// used to rank feeds
+type FeedRepository interface {
+	GetMode(userID string) (models.FeedMode, error) // Empty if the user never chose
+	SetMode(userID string, mode models.FeedMode) error
+
+	// AuthorAffinity returns the viewer's interactions with each author up to
+	// until. Group activity only counts posts created after since.
+	AuthorAffinity(viewerID string, authorIDs []string, since, until time.Time) (map[string]models.AuthorAffinity, error)
+	// PostEngagement returns the reactions to each post up to until
+	PostEngagement(postIDs []string, until time.Time) (map[string]models.PostEngagement, error)
+}
+
+// feedRepository implements FeedRepository interface
+type feedRepository struct {
+	db DBTX
+}
+
+// NewFeedRepository creates a new FeedRepository
+func NewFeedRepository(db DBTX) FeedRepository {
+	return &feedRepository{db: db}
+}
+
+// inPlaceholders returns "?, ?, ..." with n placeholders for an IN list
+func inPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts IDs to query arguments
+func stringArgs(ids []string) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+// GetMode retrieves the feed mode chosen by a user
+func (r *feedRepository) GetMode(userID string) (models.FeedMode, error) {
+	var mode models.FeedMode
+	err := r.db.QueryRow("SELECT mode FROM feed_preferences WHERE user_id = ?", userID).Scan(&mode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get feed mode for user %s: %w", userID, err)
+	}
+	return mode, nil
+}
+
+// SetMode stores the feed mode chosen by a user
+func (r *feedRepository) SetMode(userID string, mode models.FeedMode) error {
+	_, err := r.db.Exec(`
+        INSERT INTO feed_preferences (user_id, mode, updated_at) VALUES (?, ?, ?)
+        ON CONFLICT (user_id) DO UPDATE SET mode = excluded.mode, updated_at = excluded.updated_at
+    `, userID, mode, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set feed mode for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// AuthorAffinity gathers direct messages, comments, follows and group posts
+// linking the viewer to each author, leaving out those after until so that
+// the affinity at a given time doesn't change. Authors without any
+// interaction are left out of the map.
+func (r *feedRepository) AuthorAffinity(viewerID string, authorIDs []string, since, until time.Time) (map[string]models.AuthorAffinity, error) {
+	affinity := make(map[string]models.AuthorAffinity)
+	if viewerID == "" || len(authorIDs) == 0 {
+		return affinity, nil
+	}
+	in := inPlaceholders(len(authorIDs))
+	authors := stringArgs(authorIDs)
+	ts := dialectOf(r.db).Timestamp
+	notAfter := func(column string) string { return ts(column) + " <= " + ts("?") }
+
+	counts := func(query string, args []interface{}, set func(a *models.AuthorAffinity, n int)) error {
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var authorID string
+			var n int
+			if err := rows.Scan(&authorID, &n); err != nil {
+				return err
+			}
+			a := affinity[authorID]
+			set(&a, n)
+			affinity[authorID] = a
+		}
+		return rows.Err()
+	}
+
+	dmQuery := `
+        SELECT CASE WHEN sender_id = ? THEN receiver_id ELSE sender_id END AS author_id, COUNT(*)
+        FROM messages
+        WHERE ((sender_id = ? AND receiver_id IN (` + in + `))
+           OR (receiver_id = ? AND sender_id IN (` + in + `)))
+          AND ` + notAfter("created_at") + `
+        GROUP BY author_id
+    `
+	dmArgs := append([]interface{}{viewerID, viewerID}, authors...)
+	dmArgs = append(dmArgs, viewerID)
+	dmArgs = append(dmArgs, authors...)
+	dmArgs = append(dmArgs, until)
+	if err := counts(dmQuery, dmArgs, func(a *models.AuthorAffinity, n int) { a.DirectMessages = n }); err != nil {
+		return nil, fmt.Errorf("failed to count direct messages for feed affinity: %w", err)
+	}
+
+	commentQuery := `
+        SELECT p.user_id, COUNT(*)
+        FROM comments c
+        JOIN posts p ON p.id = c.post_id
+        WHERE c.user_id = ? AND p.user_id IN (` + in + `) AND ` + notAfter("c.created_at") + `
+        GROUP BY p.user_id
+    `
+	commentArgs := append([]interface{}{viewerID}, authors...)
+	commentArgs = append(commentArgs, until)
+	if err := counts(commentQuery, commentArgs, func(a *models.AuthorAffinity, n int) { a.Comments = n }); err != nil {
+		return nil, fmt.Errorf("failed to count comments for feed affinity: %w", err)
+	}
+
+	groupQuery := `
+        SELECT p.user_id, COUNT(*)
+        FROM posts p
+        JOIN group_members gm ON gm.group_id = p.group_id AND gm.user_id = ?
+        WHERE p.user_id IN (` + in + `) AND p.created_at > ? AND ` + notAfter("p.created_at") + ` AND p.status = 'published'
+        GROUP BY p.user_id
+    `
+	groupArgs := append([]interface{}{viewerID}, authors...)
+	groupArgs = append(groupArgs, since, until)
+	if err := counts(groupQuery, groupArgs, func(a *models.AuthorAffinity, n int) { a.GroupActivity = n }); err != nil {
+		return nil, fmt.Errorf("failed to count group activity for feed affinity: %w", err)
+	}
+
+	followQuery := `
+        SELECT following_id, created_at
+        FROM followers
+        WHERE follower_id = ? AND status = 'accepted' AND following_id IN (` + in + `) AND ` + notAfter("created_at") + `
+    `
+	followArgs := append([]interface{}{viewerID}, authors...)
+	rows, err := r.db.Query(followQuery, append(followArgs, until)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follows for feed affinity: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var authorID, followedAt string
+		if err := rows.Scan(&authorID, &followedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan follow for feed affinity: %w", err)
+		}
+		a := affinity[authorID]
+		if a.FollowedSince, err = parseTimestamp(followedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse follow timestamp: %w", err)
+		}
+		affinity[authorID] = a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating follows for feed affinity: %w", err)
+	}
+
+	return affinity, nil
+}
+
+// PostEngagement counts the comments and distinct commenters of each post
+// made up to until. Posts without comments are left out of the map.
+func (r *feedRepository) PostEngagement(postIDs []string, until time.Time) (map[string]models.PostEngagement, error) {
+	engagement := make(map[string]models.PostEngagement)
+	if len(postIDs) == 0 {
+		return engagement, nil
+	}
+	ts := dialectOf(r.db).Timestamp
+
+	query := `
+        SELECT c.post_id, COUNT(*), COUNT(DISTINCT CASE WHEN c.user_id <> p.user_id THEN c.user_id END)
+        FROM comments c
+        JOIN posts p ON p.id = c.post_id
+        WHERE c.post_id IN (` + inPlaceholders(len(postIDs)) + `)
+          AND ` + ts("c.created_at") + ` <= ` + ts("?") + `
+        GROUP BY c.post_id
+    `
+	rows, err := r.db.Query(query, append(stringArgs(postIDs), until)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post engagement: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID string
+		var e models.PostEngagement
+		if err := rows.Scan(&postID, &e.Comments, &e.Commenters); err != nil {
+			return nil, fmt.Errorf("failed to scan post engagement: %w", err)
+		}
+		engagement[postID] = e
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating post engagement: %w", err)
+	}
+	return engagement, nil
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/google/uuid"
+)
+
+func TestFeedSignalsUntil(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Feed
+		alice := createUser(t, tdb, "alice")
+		bob := createUser(t, tdb, "bob")
+		carol := createUser(t, tdb, "carol")
+		post := createPost(t, tdb, alice, models.PrivacyPublic)
+
+		interact := func() {
+			t.Helper()
+			if err := tdb.repos.Comment.Create(&models.Comment{PostID: post.ID, UserID: bob.ID, Content: "Nice"}); err != nil {
+				t.Fatalf("comment: %v", err)
+			}
+			message := &models.Message{ID: uuid.NewString(), SenderID: bob.ID, ReceiverID: alice.ID, Content: "Hi",
+				CreatedAt: time.Now().UTC().Format(time.RFC3339Nano)}
+			if err := tdb.repos.ChatMessage.SaveDirectMessage(message); err != nil {
+				t.Fatalf("message: %v", err)
+			}
+		}
+		follow := func(followerID string, at time.Time) {
+			t.Helper()
+			_, err := tdb.db.Exec("INSERT INTO followers (follower_id, following_id, status, created_at) VALUES (?, ?, 'accepted', ?)",
+				followerID, alice.ID, at)
+			if err != nil {
+				t.Fatalf("follow: %v", err)
+			}
+		}
+
+		interact()
+		follow(bob.ID, time.Now().Add(-time.Hour))
+		time.Sleep(10 * time.Millisecond) // Timestamps are compared to the millisecond
+		anchor := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		interact()
+		follow(carol.ID, time.Now())
+
+		since := anchor.Add(-24 * time.Hour)
+		affinity, err := repo.AuthorAffinity(bob.ID, []string{alice.ID}, since, anchor)
+		if err != nil {
+			t.Fatalf("AuthorAffinity: %v", err)
+		}
+		if a := affinity[alice.ID]; a.DirectMessages != 1 || a.Comments != 1 || a.FollowedSince.IsZero() {
+			t.Errorf("affinity until the anchor = %+v, want 1 message, 1 comment and the follow", a)
+		}
+		if affinity, err := repo.AuthorAffinity(carol.ID, []string{alice.ID}, since, anchor); err != nil || !affinity[alice.ID].FollowedSince.IsZero() {
+			t.Errorf("affinity of a later follower = %+v, %v; want no follow", affinity[alice.ID], err)
+		}
+		engagement, err := repo.PostEngagement([]string{post.ID}, anchor)
+		if err != nil || engagement[post.ID] != (models.PostEngagement{Comments: 1, Commenters: 1}) {
+			t.Errorf("engagement until the anchor = %+v, %v; want 1 comment", engagement[post.ID], err)
+		}
+
+		now := time.Now()
+		if affinity, err := repo.AuthorAffinity(bob.ID, []string{alice.ID}, since, now); err != nil || affinity[alice.ID].DirectMessages != 2 || affinity[alice.ID].Comments != 2 {
+			t.Errorf("affinity until now = %+v, %v; want 2 messages and 2 comments", affinity[alice.ID], err)
+		}
+		if engagement, err := repo.PostEngagement([]string{post.ID}, now); err != nil || engagement[post.ID].Comments != 2 {
+			t.Errorf("engagement until now = %+v, %v; want 2 comments", engagement[post.ID], err)
+		}
+	})
+}
@@ -23,11 +23,11 @@ type FollowerRepository interface {
 
 // followerRepository implements FollowerRepository
 type followerRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewFollowerRepository creates a new instance of FollowerRepository
-func NewFollowerRepository(db *sql.DB) FollowerRepository {
+func NewFollowerRepository(db DBTX) FollowerRepository {
 	return &followerRepository{db: db}
 }
 
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"testing"
+)
+
+func TestFollowerRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Follower
+		alice := createUser(t, tdb, "alice")
+		bob := createUser(t, tdb, "bob")
+
+		if err := repo.CreateFollowRequest(bob.ID, alice.ID); err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		if err := repo.CreateFollowRequest(bob.ID, alice.ID); !isUniqueViolation(err) {
+			t.Errorf("duplicate request: got %v, want a unique violation", err)
+		}
+
+		pending, err := repo.GetPendingReceivedRequests(alice.ID)
+		if err != nil || len(pending) != 1 || pending[0].ID != bob.ID {
+			t.Errorf("pending received = %v, %v; want [bob]", pending, err)
+		}
+		if n, _ := repo.CountFollowers(alice.ID); n != 0 {
+			t.Errorf("followers before accepting = %d, want 0", n)
+		}
+
+		if err := repo.UpdateFollowStatus(bob.ID, alice.ID, "accepted"); err != nil {
+			t.Fatalf("accept: %v", err)
+		}
+		follow, err := repo.FindFollow(bob.ID, alice.ID)
+		if err != nil || follow == nil || follow.Status != "accepted" {
+			t.Fatalf("find = %+v, %v; want accepted", follow, err)
+		}
+		if n, _ := repo.CountFollowers(alice.ID); n != 1 {
+			t.Errorf("followers = %d, want 1", n)
+		}
+		if n, _ := repo.CountFollowing(bob.ID); n != 1 {
+			t.Errorf("following = %d, want 1", n)
+		}
+		followers, err := repo.GetFollowers(alice.ID, 10, 0)
+		if err != nil || len(followers) != 1 || followers[0].ID != bob.ID {
+			t.Errorf("followers = %v, %v; want [bob]", followers, err)
+		}
+
+		if err := repo.DeleteFollow(bob.ID, alice.ID); err != nil {
+			t.Fatalf("unfollow: %v", err)
+		}
+		if follow, err := repo.FindFollow(bob.ID, alice.ID); err != nil || follow != nil {
+			t.Errorf("find after unfollow = %+v, %v; want nil", follow, err)
+		}
+	})
+}
@@ -30,11 +30,11 @@ type GroupEventRepository interface {
 
 // groupEventRepository implements GroupEventRepository interface
 type groupEventRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewGroupEventRepository creates a new GroupEventRepository
-func NewGroupEventRepository(db *sql.DB) GroupEventRepository {
+func NewGroupEventRepository(db DBTX) GroupEventRepository {
 	return &groupEventRepository{
 		db: db,
 	}
@@ -62,7 +62,7 @@ func (r *groupEventRepository) Create(event *models.GroupEvent) error {
 		event.UpdatedAt, // Pass time.Time directly
 	)
 	if err != nil {
-		if err.Error() == "FOREIGN KEY constraint failed" {
+		if isForeignKeyViolation(err) {
 			return fmt.Errorf("failed to create event: group or creator doesn't exist: %w", err)
 		}
 		return fmt.Errorf("failed to create event: %w", err)
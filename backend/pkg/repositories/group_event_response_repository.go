@@ -3,7 +3,6 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
-	"strings"
 	"time"
 
 	// Needed for error checking
@@ -26,11 +25,11 @@ type GroupEventResponseRepository interface {
 
 // groupEventResponseRepository implements GroupEventResponseRepository
 type groupEventResponseRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewGroupEventResponseRepository creates a new GroupEventResponseRepository
-func NewGroupEventResponseRepository(db *sql.DB) GroupEventResponseRepository {
+func NewGroupEventResponseRepository(db DBTX) GroupEventResponseRepository {
 	return &groupEventResponseRepository{
 		db: db,
 	}
@@ -69,11 +68,11 @@ func (r *groupEventResponseRepository) CreateOrUpdate(response *models.GroupEven
 
 	if err != nil {
 		// Check for foreign key violation (event or user doesn't exist)
-		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		if isForeignKeyViolation(err) {
 			return fmt.Errorf("failed to create/update response: event or user not found: %w", err)
 		}
 		// Check for CHECK constraint violation (invalid response value)
-		if strings.Contains(err.Error(), "CHECK constraint failed") {
+		if isCheckViolation(err) {
 			return fmt.Errorf("failed to create/update response: invalid response value '%s': %w", response.Response, err)
 		}
 		return fmt.Errorf("failed to create/update group event response: %w", err)
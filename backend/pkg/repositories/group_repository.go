@@ -72,11 +72,11 @@ type GroupRepository interface {
 
 // groupRepository implements GroupRepository interface
 type groupRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewGroupRepository creates a new GroupRepository
-func NewGroupRepository(db *sql.DB) GroupRepository {
+func NewGroupRepository(db DBTX) GroupRepository {
 	return &groupRepository{
 		db: db,
 	}
@@ -84,60 +84,52 @@ func NewGroupRepository(db *sql.DB) GroupRepository {
 
 // Create inserts a new group record into the database
 func (r *groupRepository) Create(group *models.Group) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback() // Rollback if anything fails
-
-	// Insert group
-	queryGroup := `
-        INSERT INTO groups (id, creator_id, name, description, avatar_url, created_at)
-        VALUES (?, ?, ?, ?, ?, ?)
+	// Insert the group and its creator membership together (joins the caller's transaction if there is one)
+	return runInTx(r.db, func(tx DBTX) error {
+		// Insert group
+		queryGroup := `
+        INSERT INTO groups (id, creator_id, name, description, avatar_url, avatar_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
     `
-	group.ID = uuid.New().String()
-	group.CreatedAt = time.Now()
-
-	_, err = tx.Exec(
-		queryGroup,
-		group.ID,
-		group.CreatorID,
-		group.Name,
-		group.Description,
-		group.AvatarURL,
-		group.CreatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create group: %w", err)
-	}
+		group.ID = uuid.New().String()
+		group.CreatedAt = time.Now()
+
+		_, err := tx.Exec(
+			queryGroup,
+			group.ID,
+			group.CreatorID,
+			group.Name,
+			group.Description,
+			group.AvatarURL,
+			nullIfEmpty(group.AvatarID),
+			group.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create group: %w", err)
+		}
 
-	// Add creator as the first member (admin)
-	queryMember := `
+		// Add creator as the first member (admin)
+		queryMember := `
         INSERT INTO group_members (group_id, user_id, role, joined_at)
         VALUES (?, ?, ?, ?)
     `
-	_, err = tx.Exec(queryMember, group.ID, group.CreatorID, "admin", time.Now())
-	if err != nil {
-		// Check for unique constraint violation (shouldn't happen for creator normally)
-		// but handle just in case
-		// Use strings.Contains for error checking
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			return fmt.Errorf("failed to add creator as member (already exists?): %w", ErrAlreadyGroupMember)
+		_, err = tx.Exec(queryMember, group.ID, group.CreatorID, "admin", time.Now())
+		if err != nil {
+			// Check for unique constraint violation (shouldn't happen for creator normally)
+			// but handle just in case
+			if isUniqueViolation(err) {
+				return fmt.Errorf("failed to add creator as member (already exists?): %w", ErrAlreadyGroupMember)
+			}
+			return fmt.Errorf("failed to add creator as group member: %w", err)
 		}
-		return fmt.Errorf("failed to add creator as group member: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // GetByID retrieves a group by its ID
 func (r *groupRepository) GetByID(id string) (*models.Group, error) {
 	query := `
-        SELECT id, creator_id, name, description, avatar_url, created_at, updated_at
+        SELECT id, creator_id, name, description, avatar_url, COALESCE(avatar_id, ''), created_at, updated_at
         FROM groups
         WHERE id = ?
     `
@@ -151,6 +143,7 @@ func (r *groupRepository) GetByID(id string) (*models.Group, error) {
 		&group.Name,
 		&group.Description,
 		&avatarUrl,
+		&group.AvatarID,
 		&createdAt,
 		&updatedAt,
 	)
@@ -197,7 +190,7 @@ func (r *groupRepository) GetGroupDetailsByID(id string) (*types.GroupDetailResp
             g.created_at,
             g.updated_at,
             (SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id) as members_count,
-            (SELECT COUNT(*) FROM posts p WHERE p.group_id = g.id) as posts_count,
+            (SELECT COUNT(*) FROM posts p WHERE p.group_id = g.id AND p.status = 'published') as posts_count,
             (SELECT COUNT(*) FROM group_events e WHERE e.group_id = g.id) as events_count
         FROM groups g
         WHERE g.id = ?
@@ -262,7 +255,7 @@ func (r *groupRepository) List(limit, offset int, searchQuery string) ([]*types.
             g.created_at,
             g.updated_at,
             (SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id) as members_count,
-            (SELECT COUNT(*) FROM posts p WHERE p.group_id = g.id) as posts_count,
+            (SELECT COUNT(*) FROM posts p WHERE p.group_id = g.id AND p.status = 'published') as posts_count,
             (SELECT COUNT(*) FROM group_events e WHERE e.group_id = g.id) as events_count
         FROM groups g
     `
@@ -344,7 +337,7 @@ func (r *groupRepository) List(limit, offset int, searchQuery string) ([]*types.
 func (r *groupRepository) Update(group *models.Group) error {
 	query := `
         UPDATE groups
-        SET name = ?, description = ?, avatar_url = ?, updated_at = ?
+        SET name = ?, description = ?, avatar_url = ?, avatar_id = ?, updated_at = ?
         WHERE id = ?
     `
 	group.UpdatedAt = time.Now()
@@ -353,6 +346,7 @@ func (r *groupRepository) Update(group *models.Group) error {
 		group.Name,
 		group.Description,
 		group.AvatarURL,
+		nullIfEmpty(group.AvatarID),
 		group.UpdatedAt,
 		group.ID,
 	)
@@ -376,33 +370,34 @@ func (r *groupRepository) Update(group *models.Group) error {
 func (r *groupRepository) Delete(id string) error {
 	// Using CASCADE DELETE defined in schema is simpler.
 	// If not using CASCADE, delete members and messages manually within a transaction.
-	tx, err := r.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction for group delete: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Delete group (assuming cascade delete handles members/messages)
-	query := "DELETE FROM groups WHERE id = ?"
-	result, err := tx.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete group: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected after deleting group: %w", err)
-	}
+	return runInTx(r.db, func(tx DBTX) error {
+		// group_members and group_messages reference groups without ON DELETE CASCADE
+		for _, query := range []string{
+			"DELETE FROM group_messages WHERE group_id = ?",
+			"DELETE FROM group_members WHERE group_id = ?",
+		} {
+			if _, err := tx.Exec(query, id); err != nil {
+				return fmt.Errorf("failed to delete group: %w", err)
+			}
+		}
 
-	if rowsAffected == 0 {
-		return ErrGroupNotFound // Return error if no rows were deleted
-	}
+		// Other tables cascade
+		query := "DELETE FROM groups WHERE id = ?"
+		result, err := tx.Exec(query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete group: %w", err)
+		}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction for group delete: %w", err)
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected after deleting group: %w", err)
+		}
 
-	return nil
+		if rowsAffected == 0 {
+			return ErrGroupNotFound // Return error if no rows were deleted
+		}
+		return nil
+	})
 }
 
 // AddMember adds a user to a group
@@ -410,25 +405,29 @@ func (r *groupRepository) AddMember(groupID, userID, role string) error {
 	if role == "" {
 		role = "member" // Default role
 	}
+	// ON CONFLICT DO NOTHING instead of relying on the constraint error, so an
+	// existing membership doesn't abort an enclosing PostgreSQL transaction.
 	query := `
         INSERT INTO group_members (group_id, user_id, role, joined_at)
         VALUES (?, ?, ?, ?)
+        ON CONFLICT (group_id, user_id) DO NOTHING
     `
-	_, err := r.db.Exec(query, groupID, userID, role, time.Now())
+	result, err := r.db.Exec(query, groupID, userID, role, time.Now())
 	if err != nil {
-		// Check for unique constraint violation
-		// Use strings.Contains for error checking
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			return ErrAlreadyGroupMember
-		}
 		// Check for foreign key violation (group or user doesn't exist)
-		// Use strings.Contains for error checking
-		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		if isForeignKeyViolation(err) {
 			// Could check which FK failed, but returning a generic error might be okay
 			return fmt.Errorf("failed to add member: group or user not found")
 		}
 		return fmt.Errorf("failed to add group member: %w", err)
 	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after adding member: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAlreadyGroupMember
+	}
 	return nil
 }
 
@@ -674,10 +673,10 @@ func (r *groupRepository) CreateInvitation(invitation *models.GroupInvitation) e
 		invitation.UpdatedAt,
 	)
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: group_invitations.group_id, group_invitations.invitee_id") {
+		if isUniqueViolation(err, "group_invitations.group_id", "group_invitations.invitee_id") {
 			return ErrAlreadyInvited
 		}
-		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		if isForeignKeyViolation(err) {
 			// Could be group, inviter, or invitee not found
 			return fmt.Errorf("failed to create invitation: invalid group, inviter, or invitee ID: %w", err)
 		}
@@ -926,10 +925,10 @@ func (r *groupRepository) CreateJoinRequest(request *models.GroupJoinRequest) er
 		request.UpdatedAt,
 	)
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: group_join_requests.group_id, group_join_requests.requester_id") {
+		if isUniqueViolation(err, "group_join_requests.group_id", "group_join_requests.requester_id") {
 			return ErrAlreadyRequested
 		}
-		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		if isForeignKeyViolation(err) {
 			// Could be group or requester not found
 			return fmt.Errorf("failed to create join request: invalid group or requester ID: %w", err)
 		}
@@ -1114,7 +1113,7 @@ func (r *groupRepository) GetGroupsByUserIDWithCounts(userID string) ([]*types.G
 			g.updated_at,
 			g.avatar_url,
 			(SELECT COUNT(*) FROM group_members gm_count WHERE gm_count.group_id = g.id) as member_count,
-			(SELECT COUNT(*) FROM posts p WHERE p.group_id = g.id) as post_count,
+			(SELECT COUNT(*) FROM posts p WHERE p.group_id = g.id AND p.status = 'published') as post_count,
 			(SELECT COUNT(*) FROM group_events e WHERE e.group_id = g.id) as event_count
 		FROM groups g
 		JOIN group_members gm ON g.id = gm.group_id
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+func TestGroupRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Group
+		alice := createUser(t, tdb, "alice")
+		bob := createUser(t, tdb, "bob")
+
+		group := &models.Group{CreatorID: alice.ID, Name: "Climbers", Description: "Rocks"}
+		if err := repo.Create(group); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		got, err := repo.GetByID(group.ID)
+		if err != nil || got.Name != "Climbers" || got.CreatorID != alice.ID {
+			t.Fatalf("get = %+v, %v", got, err)
+		}
+		if ok, _ := repo.IsAdmin(group.ID, alice.ID); !ok {
+			t.Error("creator is not an admin")
+		}
+
+		if err := repo.AddMember(group.ID, bob.ID, "member"); err != nil {
+			t.Fatalf("add member: %v", err)
+		}
+		if err := repo.AddMember(group.ID, bob.ID, "member"); !errors.Is(err, ErrAlreadyGroupMember) {
+			t.Errorf("add member twice: got %v, want ErrAlreadyGroupMember", err)
+		}
+		if ok, _ := repo.IsMember(group.ID, bob.ID); !ok {
+			t.Error("bob is not a member")
+		}
+		if ok, _ := repo.IsAdmin(group.ID, bob.ID); ok {
+			t.Error("bob is an admin")
+		}
+		ids, err := repo.GetMemberIDsByGroupID(group.ID)
+		if err != nil || len(ids) != 2 {
+			t.Errorf("member ids = %v, %v; want 2", ids, err)
+		}
+
+		// The search term is matched case-insensitively on both dialects
+		groups, err := repo.List(10, 0, "CLIMB")
+		if err != nil || len(groups) != 1 || groups[0].ID != group.ID {
+			t.Errorf("list matching CLIMB = %v, %v; want the group", groups, err)
+		}
+
+		if err := repo.RemoveMember(group.ID, bob.ID); err != nil {
+			t.Fatalf("remove member: %v", err)
+		}
+		if ok, _ := repo.IsMember(group.ID, bob.ID); ok {
+			t.Error("bob is still a member")
+		}
+
+		if err := repo.Delete(group.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repo.GetByID(group.ID); !errors.Is(err, ErrGroupNotFound) {
+			t.Errorf("get deleted: got %v, want ErrGroupNotFound", err)
+		}
+	})
+}
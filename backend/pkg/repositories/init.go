@@ -1,8 +1,6 @@
 package repositories
 
-import (
-	"database/sql"
-)
+import "github.com/HASANALI117/social-network/pkg/db"
 
 // Repositories holds all repository instances.
 type Repositories struct {
@@ -16,10 +14,23 @@ type Repositories struct {
 	GroupEventResponse GroupEventResponseRepository // Added GroupEventResponse repository
 	ChatMessage        ChatMessageRepository        // Added ChatMessage repository
 	Notification       NotificationRepository       // Added Notification repository
+	Media              MediaRepository
+	Search             SearchRepository
+	Tag                TagRepository
+	Mention            MentionRepository
+	Feed               FeedRepository
+	Timeline           TimelineRepository
+	Bookmark           BookmarkRepository
+	Poll               PollRepository
+	AudienceList       AudienceListRepository
+	LinkPreview        LinkPreviewRepository
 }
 
 // InitRepositories initializes all repositories.
-func InitRepositories(db *sql.DB) *Repositories {
+// conn may be the connection pool or a transaction opened by a UnitOfWork;
+// queries are rewritten for dialect.
+func InitRepositories(conn DBTX, dialect db.Dialect) *Repositories {
+	db := withDialect(conn, dialect)
 	userRepo := NewUserRepository(db)
 	postRepo := NewPostRepository(db)                             // Initialize PostRepository
 	groupRepo := NewGroupRepository(db)                           // Initialize GroupRepository
@@ -30,6 +41,16 @@ func InitRepositories(db *sql.DB) *Repositories {
 	groupEventResponseRepo := NewGroupEventResponseRepository(db) // Initialize GroupEventResponseRepository
 	chatMessageRepo := NewChatMessageRepository(db)               // Initialize ChatMessageRepository
 	notificationRepo := NewNotificationRepository(db)             // Initialize NotificationRepository
+	mediaRepo := NewMediaRepository(db)
+	searchRepo := NewSearchRepository(db)
+	tagRepo := NewTagRepository(db)
+	mentionRepo := NewMentionRepository(db)
+	feedRepo := NewFeedRepository(db)
+	timelineRepo := NewTimelineRepository(db)
+	bookmarkRepo := NewBookmarkRepository(db)
+	pollRepo := NewPollRepository(db)
+	audienceListRepo := NewAudienceListRepository(db)
+	linkPreviewRepo := NewLinkPreviewRepository(db)
 
 	return &Repositories{
 		User:               userRepo,
@@ -42,5 +63,15 @@ func InitRepositories(db *sql.DB) *Repositories {
 		GroupEventResponse: groupEventResponseRepo, // Assign initialized GroupEventResponseRepository
 		ChatMessage:        chatMessageRepo,        // Assign initialized ChatMessageRepository
 		Notification:       notificationRepo,       // Assign initialized NotificationRepository
+		Media:              mediaRepo,
+		Search:             searchRepo,
+		Tag:                tagRepo,
+		Mention:            mentionRepo,
+		Feed:               feedRepo,
+		Timeline:           timelineRepo,
+		Bookmark:           bookmarkRepo,
+		Poll:               pollRepo,
+		AudienceList:       audienceListRepo,
+		LinkPreview:        linkPreviewRepo,
 	}
 }
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+// LinkPreviewRepository defines the interface for the link preview cache
+type LinkPreviewRepository interface {
+	// GetByURLs returns the cached previews of urls, by URL. URLs without a preview are left out.
+	GetByURLs(urls []string) (map[string]*models.LinkPreview, error)
+	// Save stores a preview, replacing the cached one
+	Save(preview *models.LinkPreview) error
+}
+
+// linkPreviewRepository implements LinkPreviewRepository interface
+type linkPreviewRepository struct {
+	db DBTX
+}
+
+// NewLinkPreviewRepository creates a new LinkPreviewRepository
+func NewLinkPreviewRepository(db DBTX) LinkPreviewRepository {
+	return &linkPreviewRepository{db: db}
+}
+
+// GetByURLs retrieves cached previews
+func (r *linkPreviewRepository) GetByURLs(urls []string) (map[string]*models.LinkPreview, error) {
+	previews := make(map[string]*models.LinkPreview, len(urls))
+	if len(urls) == 0 {
+		return previews, nil
+	}
+	rows, err := r.db.Query(`
+        SELECT url, title, description, image_url, site_name, type, failed, fetched_at
+        FROM link_previews
+        WHERE url IN (`+inPlaceholders(len(urls))+`)
+    `, stringArgs(urls)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query link previews: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var preview models.LinkPreview
+		var fetchedAt string
+		err := rows.Scan(&preview.URL, &preview.Title, &preview.Description, &preview.ImageURL,
+			&preview.SiteName, &preview.Type, &preview.Failed, &fetchedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan link preview: %w", err)
+		}
+		if preview.FetchedAt, err = parseTimestamp(fetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse link preview timestamp: %w", err)
+		}
+		previews[preview.URL] = &preview
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating link previews: %w", err)
+	}
+	return previews, nil
+}
+
+// Save upserts a preview
+func (r *linkPreviewRepository) Save(preview *models.LinkPreview) error {
+	_, err := r.db.Exec(`
+        INSERT INTO link_previews (url, title, description, image_url, site_name, type, failed, fetched_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT (url) DO UPDATE SET
+            title = excluded.title, description = excluded.description, image_url = excluded.image_url,
+            site_name = excluded.site_name, type = excluded.type, failed = excluded.failed,
+            fetched_at = excluded.fetched_at
+    `, preview.URL, preview.Title, preview.Description, preview.ImageURL,
+		preview.SiteName, preview.Type, preview.Failed, preview.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save link preview of %s: %w", preview.URL, err)
+	}
+	return nil
+}
@@ -0,0 +1,483 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+var (
+	// ErrMediaNotFound indicates that no media record exists with the given ID.
+	ErrMediaNotFound = errors.New("media not found")
+	// ErrMediaVariantNotFound indicates that the media has no variant with the given name.
+	ErrMediaVariantNotFound = errors.New("media variant not found")
+)
+
+// MediaRepository defines the interface for uploaded media records
+type MediaRepository interface {
+	Create(media *models.Media) error                        // Also records the upload in the upload log
+	GetByID(id string) (*models.Media, error)                // Includes variants
+	GetByIDs(ids []string) (map[string]*models.Media, error) // By ID; missing media is left out
+	GetVariant(mediaID, name string) (*models.MediaVariant, error)
+	Delete(id string) error
+	// GetReferences lists the entities that use the media
+	GetReferences(id string) ([]models.MediaReference, error)
+
+	// Quotas
+	GetUsage(ownerID string) (bytes int64, files int, err error)          // Size includes processed variants
+	ListUploadTimes(ownerID string, since time.Time) ([]time.Time, error) // Oldest first, deleted media included
+	PruneUploadLog(before time.Time) (int64, error)                       // Forgets uploads older than before
+
+	// Processing state
+	ListIDsByStatus(status string, limit int) ([]string, error)
+	Claim(id string) (bool, error)              // pending -> processing; false if another worker got it first
+	ResetStatus(from, to string) (int64, error) // e.g. requeue work interrupted by a restart
+	MarkReady(media *models.Media) error        // Stores the processed file, dimensions and variants
+	MarkFailed(id string, reason string) error
+
+	// Garbage collection
+	MarkOrphans(now time.Time) (int64, error)         // Records when processed media became unused
+	CountUnmarkedOrphans() (int64, error)             // Media MarkOrphans would mark, for dry runs
+	ClearOrphans() (int64, error)                     // Unmarks orphans that are in use again
+	ListOrphanIDs(before time.Time) ([]string, error) // Media unused since before the given time, and still unused
+	DeleteOrphan(id string) (bool, error)             // Deletes the media if it is still an unused orphan
+	StorageKeyExists(key string) (bool, error)        // Whether a media record or variant owns the blob
+}
+
+// mediaRepository implements MediaRepository interface
+type mediaRepository struct {
+	db DBTX
+}
+
+// NewMediaRepository creates a new MediaRepository
+func NewMediaRepository(db DBTX) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+// Create inserts a new media record and logs the upload
+func (r *mediaRepository) Create(media *models.Media) error {
+	query := `
+        INSERT INTO media (id, owner_id, storage_key, content_type, size, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `
+	if media.Status == "" {
+		media.Status = models.MediaStatusPending
+	}
+	return runInTx(r.db, func(tx DBTX) error {
+		_, err := tx.Exec(query, media.ID, media.OwnerID, media.StorageKey, media.ContentType, media.Size, media.Status, media.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create media: %w", err)
+		}
+		_, err = tx.Exec("INSERT INTO media_uploads (media_id, owner_id, created_at) VALUES (?, ?, ?)",
+			media.ID, media.OwnerID, media.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to log upload of media %s: %w", media.ID, err)
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a media record and its variants by ID
+func (r *mediaRepository) GetByID(id string) (*models.Media, error) {
+	media, err := r.GetByIDs([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	if media[id] == nil {
+		return nil, ErrMediaNotFound
+	}
+	return media[id], nil
+}
+
+// GetByIDs retrieves several media records and their variants
+func (r *mediaRepository) GetByIDs(ids []string) (map[string]*models.Media, error) {
+	found := make(map[string]*models.Media, len(ids))
+	if len(ids) == 0 {
+		return found, nil
+	}
+	in := inPlaceholders(len(ids))
+	rows, err := r.db.Query(`
+        SELECT id, owner_id, storage_key, content_type, size, status,
+               COALESCE(width, 0), COALESCE(height, 0), COALESCE(blurhash, ''), COALESCE(processing_error, ''),
+               created_at, processed_at
+        FROM media
+        WHERE id IN (`+in+`)
+    `, stringArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media by ID: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var media models.Media
+		var processedAt sql.NullTime
+		err := rows.Scan(
+			&media.ID,
+			&media.OwnerID,
+			&media.StorageKey,
+			&media.ContentType,
+			&media.Size,
+			&media.Status,
+			&media.Width,
+			&media.Height,
+			&media.Blurhash,
+			&media.ProcessingError,
+			&media.CreatedAt,
+			&processedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan media: %w", err)
+		}
+		if processedAt.Valid {
+			media.ProcessedAt = &processedAt.Time
+		}
+		found[media.ID] = &media
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating media: %w", err)
+	}
+	if len(found) == 0 {
+		return found, nil
+	}
+
+	variants, err := r.db.Query(`
+        SELECT media_id, name, storage_key, content_type, width, height, size
+        FROM media_variants
+        WHERE media_id IN (`+in+`)
+        ORDER BY media_id, width DESC
+    `, stringArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media variants: %w", err)
+	}
+	defer variants.Close()
+	for variants.Next() {
+		var v models.MediaVariant
+		if err := variants.Scan(&v.MediaID, &v.Name, &v.StorageKey, &v.ContentType, &v.Width, &v.Height, &v.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan media variant: %w", err)
+		}
+		if media := found[v.MediaID]; media != nil {
+			media.Variants = append(media.Variants, v)
+		}
+	}
+	if err := variants.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating media variants: %w", err)
+	}
+	return found, nil
+}
+
+// GetVariant retrieves a single variant of a media record
+func (r *mediaRepository) GetVariant(mediaID, name string) (*models.MediaVariant, error) {
+	query := `
+        SELECT media_id, name, storage_key, content_type, width, height, size
+        FROM media_variants
+        WHERE media_id = ? AND name = ?
+    `
+	var v models.MediaVariant
+	err := r.db.QueryRow(query, mediaID, name).Scan(&v.MediaID, &v.Name, &v.StorageKey, &v.ContentType, &v.Width, &v.Height, &v.Size)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMediaVariantNotFound
+		}
+		return nil, fmt.Errorf("failed to get media variant: %w", err)
+	}
+	return &v, nil
+}
+
+// Delete removes a media record. References to it are cleared by the database.
+func (r *mediaRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM media WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete media: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for media delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrMediaNotFound
+	}
+	return nil
+}
+
+// mediaReferenceSource is a column that references media, and how to describe
+// the entity of a row that does
+type mediaReferenceSource struct {
+	kind     string // A models.MediaRef* constant
+	table    string
+	column   string // Holds the media ID
+	entityID string // Expression for MediaReference.EntityID
+	otherID  string // Expression for MediaReference.OtherID
+}
+
+// mediaReferenceSources lists everything that can use media. Both
+// GetReferences and mediaInUse are built from it, so the media a viewer may
+// open and the media the garbage collector keeps are always the same.
+var mediaReferenceSources = []mediaReferenceSource{
+	{models.MediaRefPost, "posts", "image_id", "id", "''"},
+	{models.MediaRefPost, "post_attachments", "media_id", "post_id", "''"},
+	{models.MediaRefComment, "comments", "image_id", "post_id", "''"},
+	{models.MediaRefUserAvatar, "users", "avatar_id", "id", "''"},
+	{models.MediaRefGroupAvatar, "groups", "avatar_id", "id", "''"},
+	{models.MediaRefDirectMessage, "messages", "image_id", "sender_id", "receiver_id"},
+	{models.MediaRefGroupMessage, "group_messages", "image_id", "group_id", "''"},
+}
+
+// GetReferences lists the posts (cover image or gallery), comments, avatars and chat messages that use the media
+func (r *mediaRepository) GetReferences(id string) ([]models.MediaReference, error) {
+	selects := make([]string, len(mediaReferenceSources))
+	args := make([]interface{}, len(mediaReferenceSources))
+	for i, source := range mediaReferenceSources {
+		selects[i] = fmt.Sprintf("SELECT '%s', %s, %s FROM %s WHERE %s = ?",
+			source.kind, source.entityID, source.otherID, source.table, source.column)
+		args[i] = id
+	}
+	rows, err := r.db.Query(strings.Join(selects, " UNION ALL "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []models.MediaReference
+	for rows.Next() {
+		var ref models.MediaReference
+		if err := rows.Scan(&ref.Kind, &ref.EntityID, &ref.OtherID); err != nil {
+			return nil, fmt.Errorf("failed to scan media reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// mediaInUse is true for rows of media that any of mediaReferenceSources references
+var mediaInUse = func() string {
+	conditions := make([]string, len(mediaReferenceSources))
+	for i, source := range mediaReferenceSources {
+		conditions[i] = fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s = media.id)", source.table, source.column)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}()
+
+// GetUsage returns the total size and number of the media owned by a user
+func (r *mediaRepository) GetUsage(ownerID string) (int64, int, error) {
+	var bytes int64
+	var files int
+	err := r.db.QueryRow(`
+        SELECT COALESCE(SUM(media.size), 0) + COALESCE((
+                   SELECT SUM(media_variants.size)
+                   FROM media_variants JOIN media m ON m.id = media_variants.media_id
+                   WHERE m.owner_id = ?
+               ), 0),
+               COUNT(*)
+        FROM media
+        WHERE owner_id = ?
+    `, ownerID, ownerID).Scan(&bytes, &files)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get media usage: %w", err)
+	}
+	return bytes, files, nil
+}
+
+// ListUploadTimes returns when the user uploaded media after since. Uploads
+// are read from the upload log, so media deleted since still counts.
+func (r *mediaRepository) ListUploadTimes(ownerID string, since time.Time) ([]time.Time, error) {
+	rows, err := r.db.Query(`SELECT created_at FROM media_uploads WHERE owner_id = ? AND created_at > ? ORDER BY created_at`, ownerID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload times: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("failed to scan upload time: %w", err)
+		}
+		times = append(times, t)
+	}
+	return times, rows.Err()
+}
+
+// PruneUploadLog deletes the upload log entries older than before
+func (r *mediaRepository) PruneUploadLog(before time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM media_uploads WHERE created_at < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune upload log: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// MarkOrphans sets orphaned_at on processed (ready or failed) media that
+// nothing references. Media still being processed is left alone.
+func (r *mediaRepository) MarkOrphans(now time.Time) (int64, error) {
+	result, err := r.db.Exec(`
+        UPDATE media SET orphaned_at = ?
+        WHERE orphaned_at IS NULL AND status IN (?, ?) AND NOT `+mediaInUse,
+		now, models.MediaStatusReady, models.MediaStatusFailed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark orphaned media: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CountUnmarkedOrphans counts the processed media that nothing references
+// but that isn't marked as orphaned yet
+func (r *mediaRepository) CountUnmarkedOrphans() (int64, error) {
+	var count int64
+	err := r.db.QueryRow(`
+        SELECT COUNT(*) FROM media
+        WHERE orphaned_at IS NULL AND status IN (?, ?) AND NOT `+mediaInUse,
+		models.MediaStatusReady, models.MediaStatusFailed).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count orphaned media: %w", err)
+	}
+	return count, nil
+}
+
+// ClearOrphans resets orphaned_at on media that is referenced again
+func (r *mediaRepository) ClearOrphans() (int64, error) {
+	result, err := r.db.Exec(`UPDATE media SET orphaned_at = NULL WHERE orphaned_at IS NOT NULL AND ` + mediaInUse)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear orphaned media: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListOrphanIDs returns media marked as orphaned before the given time that
+// is still unused, whether or not ClearOrphans has run since
+func (r *mediaRepository) ListOrphanIDs(before time.Time) ([]string, error) {
+	rows, err := r.db.Query(`SELECT id FROM media WHERE orphaned_at IS NOT NULL AND orphaned_at < ? AND NOT `+mediaInUse+` ORDER BY orphaned_at`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orphaned media: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan media ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteOrphan deletes the media (and, by cascade, its variant records) unless
+// it has been referenced since it was marked. It reports whether it was deleted.
+func (r *mediaRepository) DeleteOrphan(id string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM media WHERE id = ? AND orphaned_at IS NOT NULL AND NOT `+mediaInUse, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete orphaned media: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for orphaned media delete: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// StorageKeyExists reports whether a media record or variant stores its contents under key
+func (r *mediaRepository) StorageKeyExists(key string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM media WHERE storage_key = ?)
+            OR EXISTS (SELECT 1 FROM media_variants WHERE storage_key = ?)
+    `, key, key).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up storage key: %w", err)
+	}
+	return exists, nil
+}
+
+// ListIDsByStatus returns up to limit media IDs in the given state, oldest first
+func (r *mediaRepository) ListIDsByStatus(status string, limit int) ([]string, error) {
+	rows, err := r.db.Query(`SELECT id FROM media WHERE status = ? ORDER BY created_at LIMIT ?`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media by status: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan media ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Claim marks pending media as processing. It reports false if the media is
+// no longer pending, e.g. because another worker claimed it.
+func (r *mediaRepository) Claim(id string) (bool, error) {
+	result, err := r.db.Exec(`UPDATE media SET status = ? WHERE id = ? AND status = ?`, models.MediaStatusProcessing, id, models.MediaStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim media: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for media claim: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// ResetStatus moves all media in state from to state to
+func (r *mediaRepository) ResetStatus(from, to string) (int64, error) {
+	result, err := r.db.Exec(`UPDATE media SET status = ? WHERE status = ?`, to, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset media status: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// MarkReady records the processed file and replaces the media's variants
+func (r *mediaRepository) MarkReady(media *models.Media) error {
+	now := time.Now()
+	return runInTx(r.db, func(tx DBTX) error {
+		_, err := tx.Exec(`
+            UPDATE media
+            SET storage_key = ?, content_type = ?, size = ?, status = ?, width = ?, height = ?,
+                blurhash = ?, processing_error = NULL, processed_at = ?
+            WHERE id = ?
+        `, media.StorageKey, media.ContentType, media.Size, models.MediaStatusReady, media.Width, media.Height,
+			nullIfEmpty(media.Blurhash), now, media.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update processed media: %w", err)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM media_variants WHERE media_id = ?`, media.ID); err != nil {
+			return fmt.Errorf("failed to clear media variants: %w", err)
+		}
+		for _, v := range media.Variants {
+			_, err := tx.Exec(`
+                INSERT INTO media_variants (media_id, name, storage_key, content_type, width, height, size)
+                VALUES (?, ?, ?, ?, ?, ?, ?)
+            `, media.ID, v.Name, v.StorageKey, v.ContentType, v.Width, v.Height, v.Size)
+			if err != nil {
+				return fmt.Errorf("failed to insert media variant %s: %w", v.Name, err)
+			}
+		}
+		media.Status = models.MediaStatusReady
+		media.ProcessedAt = &now
+		return nil
+	})
+}
+
+// MarkFailed records why media could not be processed
+func (r *mediaRepository) MarkFailed(id string, reason string) error {
+	_, err := r.db.Exec(`UPDATE media SET status = ?, processing_error = ?, processed_at = ? WHERE id = ?`,
+		models.MediaStatusFailed, reason, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark media as failed: %w", err)
+	}
+	return nil
+}
+
+// nullIfEmpty stores an empty optional reference (such as a media ID) as NULL
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+func TestMediaUploadLog(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Media
+		alice := createUser(t, tdb, "alice")
+		now := time.Now().UTC().Truncate(time.Second)
+		for i, id := range []string{"old", "recent"} {
+			media := &models.Media{ID: id, OwnerID: alice.ID, StorageKey: "media/" + id, ContentType: "image/png", Size: 1,
+				CreatedAt: now.Add(time.Duration(i-1) * 2 * time.Hour)}
+			if err := repo.Create(media); err != nil {
+				t.Fatalf("create media: %v", err)
+			}
+		}
+		if err := repo.Delete("recent"); err != nil {
+			t.Fatalf("delete media: %v", err)
+		}
+
+		// Deleting the media doesn't take back the upload
+		since := now.Add(-time.Hour)
+		if times, err := repo.ListUploadTimes(alice.ID, since); err != nil || len(times) != 1 {
+			t.Errorf("ListUploadTimes after delete = %v, %v; want 1 upload", times, err)
+		}
+		if times, err := repo.ListUploadTimes(alice.ID, now.Add(-3*time.Hour)); err != nil || len(times) != 2 || !times[0].Before(times[1]) {
+			t.Errorf("ListUploadTimes = %v, %v; want 2 uploads, oldest first", times, err)
+		}
+
+		if pruned, err := repo.PruneUploadLog(since); err != nil || pruned != 1 {
+			t.Errorf("PruneUploadLog = %d, %v; want 1", pruned, err)
+		}
+		if times, err := repo.ListUploadTimes(alice.ID, now.Add(-3*time.Hour)); err != nil || len(times) != 1 {
+			t.Errorf("ListUploadTimes after prune = %v, %v; want 1 upload", times, err)
+		}
+	})
+}
+
+func TestMediaOrphans(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Media
+		alice := createUser(t, tdb, "alice")
+		now := time.Now().UTC().Truncate(time.Second)
+		for _, id := range []string{"unused", "avatar", "pending"} {
+			media := &models.Media{ID: id, OwnerID: alice.ID, StorageKey: "media/" + id, ContentType: "image/png", Size: 1, CreatedAt: now}
+			if err := repo.Create(media); err != nil {
+				t.Fatalf("create media: %v", err)
+			}
+		}
+		if _, err := tdb.db.Exec("UPDATE media SET status = ? WHERE id <> ?", models.MediaStatusReady, "pending"); err != nil {
+			t.Fatalf("mark media ready: %v", err)
+		}
+		if _, err := tdb.db.Exec("UPDATE users SET avatar_id = ? WHERE id = ?", "avatar", alice.ID); err != nil {
+			t.Fatalf("set avatar: %v", err)
+		}
+
+		refs, err := repo.GetReferences("avatar")
+		if err != nil || len(refs) != 1 || refs[0] != (models.MediaReference{Kind: models.MediaRefUserAvatar, EntityID: alice.ID}) {
+			t.Errorf("GetReferences = %v, %v", refs, err)
+		}
+		if count, err := repo.CountUnmarkedOrphans(); err != nil || count != 1 {
+			t.Errorf("CountUnmarkedOrphans = %d, %v; want 1", count, err)
+		}
+		if marked, err := repo.MarkOrphans(now.Add(-time.Hour)); err != nil || marked != 1 {
+			t.Fatalf("MarkOrphans = %d, %v; want 1", marked, err)
+		}
+		if count, err := repo.CountUnmarkedOrphans(); err != nil || count != 0 {
+			t.Errorf("CountUnmarkedOrphans after marking = %d, %v; want 0", count, err)
+		}
+		if ids, err := repo.ListOrphanIDs(now); err != nil || len(ids) != 1 || ids[0] != "unused" {
+			t.Errorf("ListOrphanIDs = %v, %v; want [unused]", ids, err)
+		}
+
+		// Media used again is left out even before ClearOrphans unmarks it
+		if _, err := tdb.db.Exec("UPDATE users SET avatar_id = ? WHERE id = ?", "unused", alice.ID); err != nil {
+			t.Fatalf("set avatar: %v", err)
+		}
+		if ids, err := repo.ListOrphanIDs(now); err != nil || len(ids) != 0 {
+			t.Errorf("ListOrphanIDs after reuse = %v, %v; want none", ids, err)
+		}
+	})
+}
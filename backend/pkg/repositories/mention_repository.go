@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/google/uuid"
+)
+
+// MentionRepository defines the interface for @mention data access
+type MentionRepository interface {
+	Create(mentions []models.Mention) error // Assigns IDs and creation times
+	// ListByPost returns the mentions in a post and in the comments on it
+	ListByPost(postID string) ([]models.Mention, error)
+	// DeleteForUser removes a user's mentions in a post and in the comments on it
+	DeleteForUser(postID, userID string) error
+}
+
+// mentionRepository implements MentionRepository interface
+type mentionRepository struct {
+	db DBTX
+}
+
+// NewMentionRepository creates a new MentionRepository
+func NewMentionRepository(db DBTX) MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// Create inserts the mentions of one post or comment
+func (r *mentionRepository) Create(mentions []models.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	// Joins the caller's transaction if there is one
+	return runInTx(r.db, func(tx DBTX) error {
+		now := time.Now()
+		for i := range mentions {
+			m := &mentions[i]
+			m.ID = uuid.New().String()
+			m.CreatedAt = now
+			_, err := tx.Exec(
+				"INSERT INTO mentions (id, user_id, author_id, post_id, comment_id, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+				m.ID, m.UserID, m.AuthorID, m.PostID, m.CommentID, m.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert mention of user %s in post %s: %w", m.UserID, m.PostID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListByPost retrieves the mentions of a post and its comments
+func (r *mentionRepository) ListByPost(postID string) ([]models.Mention, error) {
+	rows, err := r.db.Query(
+		"SELECT id, user_id, author_id, post_id, comment_id, created_at FROM mentions WHERE post_id = ? ORDER BY created_at, id",
+		postID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mentions in post %s: %w", postID, err)
+	}
+	defer rows.Close()
+
+	mentions := make([]models.Mention, 0)
+	for rows.Next() {
+		var m models.Mention
+		if err := rows.Scan(&m.ID, &m.UserID, &m.AuthorID, &m.PostID, &m.CommentID, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		mentions = append(mentions, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mentions in post %s: %w", postID, err)
+	}
+	return mentions, nil
+}
+
+// DeleteForUser deletes the mentions of a user in a post and its comments
+func (r *mentionRepository) DeleteForUser(postID, userID string) error {
+	if _, err := r.db.Exec("DELETE FROM mentions WHERE post_id = ? AND user_id = ?", postID, userID); err != nil {
+		return fmt.Errorf("failed to delete mentions of user %s in post %s: %w", userID, postID, err)
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package repositories
 
 import (
-	"database/sql"
 	"fmt" // Added import
 	"log"
 	"github.com/HASANALI117/social-network/pkg/models"
@@ -11,17 +10,17 @@ import (
 // It now includes methods previously expected from ChatMessageRepository.
 type MessageRepository interface {
 	GetChatPartners(currentUserID string) ([]models.ChatPartner, error)
-	GetDirectMessagesBetweenUsers(user1ID, user2ID string, limit, offset int) ([]models.Message, int64, error)
-	GetGroupMessages(groupID string, limit, offset int, requestingUserID string) ([]*models.GroupMessage, error) // Corrected parameter name
+	GetDirectMessagesBetweenUsers(user1ID, user2ID string, page models.Page) ([]models.Message, models.PageInfo, int64, error)
+	GetGroupMessages(groupID string, page models.Page, requestingUserID string) ([]*models.GroupMessage, models.PageInfo, error) // Corrected parameter name
 	// Add other message-related methods here if any, e.g., CreateMessage
 }
 
 type sqliteMessageRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewMessageRepository creates a new instance of MessageRepository.
-func NewMessageRepository(db *sql.DB) MessageRepository {
+func NewMessageRepository(db DBTX) MessageRepository {
 	return &sqliteMessageRepository{db: db}
 }
 
@@ -105,21 +104,21 @@ func (r *sqliteMessageRepository) GetChatPartners(currentUserID string) ([]model
 
 // GetDirectMessagesBetweenUsers is a stub implementation to satisfy the MessageRepository interface.
 // TODO: Replace with actual logic if this repository is meant to handle direct messages.
-func (r *sqliteMessageRepository) GetDirectMessagesBetweenUsers(user1ID, user2ID string, limit, offset int) ([]models.Message, int64, error) {
-	log.Printf("STUB: GetDirectMessagesBetweenUsers called for user1: %s, user2: %s, limit: %d, offset: %d", user1ID, user2ID, limit, offset)
+func (r *sqliteMessageRepository) GetDirectMessagesBetweenUsers(user1ID, user2ID string, page models.Page) ([]models.Message, models.PageInfo, int64, error) {
+	log.Printf("STUB: GetDirectMessagesBetweenUsers called for user1: %s, user2: %s, page: %+v", user1ID, user2ID, page)
 	// This is a placeholder. Real implementation would query the database.
 	// If another repository (e.g., an actual ChatMessageRepository implementation) handles this,
 	// this sqliteMessageRepository might not be the correct one to use in init.go,
 	// or it needs to be properly implemented.
-	return []models.Message{}, 0, fmt.Errorf("GetDirectMessagesBetweenUsers not implemented in this version of sqliteMessageRepository")
+	return []models.Message{}, models.PageInfo{}, 0, fmt.Errorf("GetDirectMessagesBetweenUsers not implemented in this version of sqliteMessageRepository")
 }
 
 // GetGroupMessages is a stub implementation to satisfy the MessageRepository interface.
 // TODO: Replace with actual logic if this repository is meant to handle group messages.
-func (r *sqliteMessageRepository) GetGroupMessages(groupID string, limit, offset int, requestingUserID string) ([]*models.GroupMessage, error) {
-	log.Printf("STUB: GetGroupMessages called for groupID: %s, limit: %d, offset: %d, user: %s", groupID, limit, offset, requestingUserID)
+func (r *sqliteMessageRepository) GetGroupMessages(groupID string, page models.Page, requestingUserID string) ([]*models.GroupMessage, models.PageInfo, error) {
+	log.Printf("STUB: GetGroupMessages called for groupID: %s, page: %+v, user: %s", groupID, page, requestingUserID)
 	// Placeholder
-	return []*models.GroupMessage{}, fmt.Errorf("GetGroupMessages not implemented in this version of sqliteMessageRepository")
+	return []*models.GroupMessage{}, models.PageInfo{}, fmt.Errorf("GetGroupMessages not implemented in this version of sqliteMessageRepository")
 }
 
 // Note: Ensure your 'messages' table has 'sender_id', 'receiver_id', 'content', 'created_at'
@@ -15,18 +15,19 @@ const sqliteTimestampLayout = "2006-01-02 15:04:05.999999999Z07:00"
 
 type NotificationRepository interface {
 	Create(ctx context.Context, notification *models.Notification) error
-	GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Notification, error)
+	GetByUserID(ctx context.Context, userID string, page models.Page) ([]*models.Notification, models.PageInfo, error) // Newest first
 	MarkAsRead(ctx context.Context, notificationID string, userID string) error
 	MarkAllAsRead(ctx context.Context, userID string) error
 	GetUnreadCount(ctx context.Context, userID string) (int, error)
+	// DeleteByEntity removes a user's notifications about an entity
+	DeleteByEntity(ctx context.Context, userID string, entityType models.EntityType, entityID string) error
 }
 
 type notificationRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewNotificationRepository(db *sql.DB) NotificationRepository {
-log.Printf("NEW_NOTIFICATION_REPO: DB instance for storage: %p", db)
+func NewNotificationRepository(db DBTX) NotificationRepository {
 	return &notificationRepository{db: db}
 }
 
@@ -37,12 +38,6 @@ func (r *notificationRepository) Create(ctx context.Context, notification *model
 
 	query := `INSERT INTO notifications (id, user_id, type, entity_type, message, entity_id, is_read, created_at)
               VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-	log.Printf("NOTIFICATION_REPO_CREATE: DB Stats: %+v", r.db.Stats())
-log.Printf("NOTIFICATION_REPO_CREATE: Context error before PingContext: %v", ctx.Err())
-	if errPing := r.db.PingContext(ctx); errPing != nil {
-		log.Printf("NOTIFICATION_REPO_CREATE: CRITICAL - DB Ping failed: %v. DB instance: %p", errPing, r.db)
-		// Consider returning a specific error here if ping fails, for now, just log.
-	}
 	_, err := r.db.ExecContext(ctx, query, notification.ID, notification.UserID, notification.Type, notification.EntityType, notification.Message, notification.EntityID, notification.IsRead, notification.CreatedAt)
 	if err != nil {
 		log.Printf("Error creating notification: %v", err)
@@ -51,23 +46,33 @@ log.Printf("NOTIFICATION_REPO_CREATE: Context error before PingContext: %v", ctx
 	return nil
 }
 
-func (r *notificationRepository) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Notification, error) {
-	query := `SELECT id, user_id, type, entity_type, message, entity_id, is_read, created_at
+func (r *notificationRepository) GetByUserID(ctx context.Context, userID string, page models.Page) ([]*models.Notification, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "created_at", "id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
+	query := `SELECT id, user_id, type, entity_type, message, entity_id, is_read, created_at, ` + sortKey("created_at") + `
               FROM notifications
-              WHERE user_id = $1
-              ORDER BY created_at DESC
-              LIMIT $2 OFFSET $3`
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+              WHERE user_id = ? AND ` + after + `
+              ORDER BY created_at DESC, id DESC
+              LIMIT ? OFFSET ?`
+	args := append([]interface{}{userID}, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Printf("Error getting notifications by user ID %s: %v", userID, err)
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
 	defer rows.Close()
 
 	notifications := make([]*models.Notification, 0)
+	cursors := make([]string, 0)
 	for rows.Next() {
 		var n models.Notification
 		var createdAtStr string
+		var cursorKey string
 		// Ensure other fields are scanned into their respective notification struct fields.
 		err := rows.Scan(
 			&n.ID,
@@ -78,18 +83,19 @@ func (r *notificationRepository) GetByUserID(ctx context.Context, userID string,
 			&n.EntityID,
 			&n.IsRead,
 			&createdAtStr, // Scan into intermediate string
+			&cursorKey,
 		)
 		if err != nil {
 			log.Printf("Error scanning notification row: %v", err)
-			return nil, err
+			return nil, models.PageInfo{}, err
 		}
 
 		if createdAtStr != "" {
-			parsedTime, parseErr := time.Parse(sqliteTimestampLayout, createdAtStr)
+			parsedTime, parseErr := parseTimestamp(createdAtStr)
 			if parseErr != nil {
-				log.Printf("Error parsing created_at string '%s' with layout '%s': %v", createdAtStr, sqliteTimestampLayout, parseErr)
+				log.Printf("Error parsing created_at string '%s': %v", createdAtStr, parseErr)
 				// Return an error as per instruction
-				return nil, fmt.Errorf("parsing created_at for notification %s: %w", n.ID, parseErr)
+				return nil, models.PageInfo{}, fmt.Errorf("parsing created_at for notification %s: %w", n.ID, parseErr)
 			}
 			n.CreatedAt = parsedTime
 		} else {
@@ -97,12 +103,14 @@ func (r *notificationRepository) GetByUserID(ctx context.Context, userID string,
 			n.CreatedAt = time.Time{} // Default to zero time if string is empty
 		}
 		notifications = append(notifications, &n)
+		cursors = append(cursors, encodeCursor(cursorKey, n.ID))
 	}
 	if err = rows.Err(); err != nil {
 		log.Printf("Error iterating notification rows: %v", err)
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
-	return notifications, nil
+	notifications, info := trimPage(notifications, cursors, page)
+	return notifications, info, nil
 }
 
 func (r *notificationRepository) MarkAsRead(ctx context.Context, notificationID string, userID string) error {
@@ -143,4 +151,14 @@ func (r *notificationRepository) GetUnreadCount(ctx context.Context, userID stri
 		return 0, err
 	}
 	return count, nil
-}
\ No newline at end of file
+}
+
+func (r *notificationRepository) DeleteByEntity(ctx context.Context, userID string, entityType models.EntityType, entityID string) error {
+	query := `DELETE FROM notifications WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3`
+	_, err := r.db.ExecContext(ctx, query, userID, entityType, entityID)
+	if err != nil {
+		log.Printf("Error deleting notifications about %s %s for user %s: %v", entityType, entityID, userID, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+// ErrInvalidCursor indicates that a page cursor wasn't produced by this server
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// Lists are paginated by keyset over (created_at, id). A cursor holds the
+// created_at of the last row as the database renders it as text, so that the
+// comparison matches ORDER BY created_at exactly whatever format a row was
+// stored in, plus the ID that breaks ties between rows created together.
+
+// sortKey is the select expression that gives the created_at half of a cursor.
+// Queries select it last and pass it to encodeCursor.
+func sortKey(createdAtColumn string) string {
+	return "CAST(" + createdAtColumn + " AS TEXT)"
+}
+
+// encodeCursor builds the opaque cursor of a row
+func encodeCursor(createdAt, id string) string {
+	data, _ := json.Marshal([2]string{createdAt, id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor reads a cursor made by encodeCursor
+func decodeCursor(cursor string) (createdAt, id string, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	var key [2]string
+	if err := json.Unmarshal(data, &key); err != nil || key[0] == "" || key[1] == "" {
+		return "", "", ErrInvalidCursor
+	}
+	return key[0], key[1], nil
+}
+
+// afterCursor returns the condition selecting the rows that come after the
+// cursor of page in a list ordered by createdAtColumn and idColumn, newest
+// first unless ascending, and its arguments. Without a cursor it matches every row.
+func afterCursor(page models.Page, createdAtColumn, idColumn string, ascending bool) (string, []interface{}, error) {
+	if page.After == "" {
+		return "1 = 1", nil, nil
+	}
+	createdAt, id, err := decodeCursor(page.After)
+	if err != nil {
+		return "", nil, err
+	}
+	op := "<"
+	if ascending {
+		op = ">"
+	}
+	condition := fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", createdAtColumn, idColumn, op)
+	return condition, []interface{}{createdAt, createdAt, id}, nil
+}
+
+// pageBounds returns the LIMIT and OFFSET to query for page. One row more than
+// the page holds is fetched to tell whether another page follows, and the
+// offset only applies to offset pagination.
+func pageBounds(page models.Page) (limit, offset int) {
+	if page.After != "" {
+		return page.Limit + 1, 0
+	}
+	return page.Limit + 1, page.Offset
+}
+
+// trimPage drops the extra row fetched by pageBounds and returns the page's
+// rows with their PageInfo. cursors holds the cursor of each row.
+func trimPage[T any](rows []T, cursors []string, page models.Page) ([]T, models.PageInfo) {
+	if len(rows) <= page.Limit {
+		return rows, models.PageInfo{}
+	}
+	rows = rows[:page.Limit]
+	info := models.PageInfo{HasMore: true}
+	if page.Limit > 0 {
+		info.NextCursor = cursors[page.Limit-1]
+	}
+	return rows, info
+}
@@ -0,0 +1,323 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPollNotFound indicates that the post has no poll.
+	ErrPollNotFound = errors.New("poll not found")
+	// ErrVoteNotFound indicates that the user hasn't voted in the poll.
+	ErrVoteNotFound = errors.New("vote not found")
+)
+
+// PollRepository defines the interface for poll data access.
+// Polls and their votes are deleted with their post by foreign keys.
+type PollRepository interface {
+	Create(poll *models.Poll) error                  // Assigns the option IDs
+	GetByPostID(postID string) (*models.Poll, error) // With options and their vote counts
+	// The methods below read several polls at once, for a page of posts.
+	// Posts without a poll, or without votes, are left out of the maps.
+	GetByPostIDs(postIDs []string) (map[string]*models.Poll, error) // By post ID
+	CountVoters(postIDs []string) (map[string]int, error)           // By post ID
+	// GetUserVotes returns the IDs of the options the user voted for, by post ID
+	GetUserVotes(postIDs []string, userID string) (map[string][]string, error)
+	// ListVoters returns the first perOption voters of each option, by option ID, in voting order
+	ListVoters(postIDs []string, perOption int) (map[string][]types.UserBasicInfo, error)
+	// ListOptionVoters returns a page of the voters of an option, in voting order
+	ListOptionVoters(optionID string, page models.Page) ([]types.UserBasicInfo, models.PageInfo, error)
+	// SetVotes replaces the user's votes in a poll
+	SetVotes(postID, userID string, optionIDs []string) error
+	DeleteVotes(postID, userID string) error
+}
+
+// pollRepository implements PollRepository interface
+type pollRepository struct {
+	db DBTX
+}
+
+// NewPollRepository creates a new PollRepository
+func NewPollRepository(db DBTX) PollRepository {
+	return &pollRepository{db: db}
+}
+
+// Create inserts a poll and its options
+func (r *pollRepository) Create(poll *models.Poll) error {
+	return runInTx(r.db, func(tx DBTX) error {
+		_, err := tx.Exec("INSERT INTO polls (post_id, multiple_choice, anonymous, closes_at) VALUES (?, ?, ?, ?)",
+			poll.PostID, poll.MultipleChoice, poll.Anonymous, poll.ClosesAt)
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return ErrPostNotFound
+			}
+			return fmt.Errorf("failed to create poll of post %s: %w", poll.PostID, err)
+		}
+		for i, option := range poll.Options {
+			option.ID = uuid.New().String()
+			option.PostID = poll.PostID
+			option.Position = i
+			_, err := tx.Exec("INSERT INTO poll_options (id, post_id, position, text) VALUES (?, ?, ?, ?)",
+				option.ID, option.PostID, option.Position, option.Text)
+			if err != nil {
+				return fmt.Errorf("failed to add option %d to poll of post %s: %w", i, poll.PostID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByPostID retrieves the poll of a post
+func (r *pollRepository) GetByPostID(postID string) (*models.Poll, error) {
+	polls, err := r.GetByPostIDs([]string{postID})
+	if err != nil {
+		return nil, err
+	}
+	if polls[postID] == nil {
+		return nil, ErrPollNotFound
+	}
+	return polls[postID], nil
+}
+
+// GetByPostIDs retrieves the polls of several posts
+func (r *pollRepository) GetByPostIDs(postIDs []string) (map[string]*models.Poll, error) {
+	polls := make(map[string]*models.Poll)
+	if len(postIDs) == 0 {
+		return polls, nil
+	}
+	in := inPlaceholders(len(postIDs))
+	rows, err := r.db.Query("SELECT post_id, multiple_choice, anonymous, closes_at FROM polls WHERE post_id IN ("+in+")",
+		stringArgs(postIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get polls: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		poll := &models.Poll{Options: make([]*models.PollOption, 0)}
+		if err := rows.Scan(&poll.PostID, &poll.MultipleChoice, &poll.Anonymous, &poll.ClosesAt); err != nil {
+			return nil, fmt.Errorf("failed to scan poll: %w", err)
+		}
+		polls[poll.PostID] = poll
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating polls: %w", err)
+	}
+	if len(polls) == 0 {
+		return polls, nil
+	}
+
+	options, err := r.db.Query(`
+        SELECT o.post_id, o.id, o.position, o.text, COUNT(v.user_id)
+        FROM poll_options o
+        LEFT JOIN poll_votes v ON v.option_id = o.id
+        WHERE o.post_id IN (`+in+`)
+        GROUP BY o.post_id, o.id, o.position, o.text
+        ORDER BY o.post_id, o.position
+    `, stringArgs(postIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll options: %w", err)
+	}
+	defer options.Close()
+	for options.Next() {
+		option := &models.PollOption{}
+		if err := options.Scan(&option.PostID, &option.ID, &option.Position, &option.Text, &option.VoteCount); err != nil {
+			return nil, fmt.Errorf("failed to scan poll option: %w", err)
+		}
+		if poll := polls[option.PostID]; poll != nil {
+			poll.Options = append(poll.Options, option)
+		}
+	}
+	if err := options.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating poll options: %w", err)
+	}
+	return polls, nil
+}
+
+// CountVoters counts the users who voted in several polls
+func (r *pollRepository) CountVoters(postIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	rows, err := r.db.Query(`
+        SELECT post_id, COUNT(DISTINCT user_id)
+        FROM poll_votes
+        WHERE post_id IN (`+inPlaceholders(len(postIDs))+`)
+        GROUP BY post_id
+    `, stringArgs(postIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count poll voters: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID string
+		var count int
+		if err := rows.Scan(&postID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan poll voter count: %w", err)
+		}
+		counts[postID] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetUserVotes retrieves the options a user voted for in several polls
+func (r *pollRepository) GetUserVotes(postIDs []string, userID string) (map[string][]string, error) {
+	votes := make(map[string][]string)
+	if len(postIDs) == 0 {
+		return votes, nil
+	}
+	rows, err := r.db.Query(`
+        SELECT v.post_id, v.option_id FROM poll_votes v
+        JOIN poll_options o ON o.id = v.option_id
+        WHERE v.post_id IN (`+inPlaceholders(len(postIDs))+`) AND v.user_id = ?
+        ORDER BY v.post_id, o.position
+    `, append(stringArgs(postIDs), userID)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll votes of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID, optionID string
+		if err := rows.Scan(&postID, &optionID); err != nil {
+			return nil, fmt.Errorf("failed to scan poll vote: %w", err)
+		}
+		votes[postID] = append(votes[postID], optionID)
+	}
+	return votes, rows.Err()
+}
+
+// ListVoters retrieves the first voters of each option of several polls
+func (r *pollRepository) ListVoters(postIDs []string, perOption int) (map[string][]types.UserBasicInfo, error) {
+	voters := make(map[string][]types.UserBasicInfo)
+	if len(postIDs) == 0 {
+		return voters, nil
+	}
+	rows, err := r.db.Query(`
+        SELECT option_id, id, first_name, last_name, username, avatar_url
+        FROM (
+            SELECT v.option_id, u.id, u.first_name, u.last_name, u.username, u.avatar_url,
+                ROW_NUMBER() OVER (PARTITION BY v.option_id ORDER BY v.created_at, u.id) AS rn
+            FROM poll_votes v
+            JOIN users u ON u.id = v.user_id
+            WHERE v.post_id IN (`+inPlaceholders(len(postIDs))+`)
+        ) ranked
+        WHERE rn <= ?
+        ORDER BY option_id, rn
+    `, append(stringArgs(postIDs), perOption)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list poll voters: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var optionID string
+		var voter types.UserBasicInfo
+		var avatarURL sql.NullString
+		if err := rows.Scan(&optionID, &voter.UserID, &voter.FirstName, &voter.LastName, &voter.Username, &avatarURL); err != nil {
+			return nil, fmt.Errorf("failed to scan poll voter: %w", err)
+		}
+		voter.AvatarURL = avatarURL.String
+		voters[optionID] = append(voters[optionID], voter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating poll voters: %w", err)
+	}
+	return voters, nil
+}
+
+// ListOptionVoters retrieves a page of the voters of a poll option
+func (r *pollRepository) ListOptionVoters(optionID string, page models.Page) ([]types.UserBasicInfo, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "v.created_at", "v.user_id", true)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+	args := append([]interface{}{optionID}, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(`
+        SELECT u.id, u.first_name, u.last_name, u.username, u.avatar_url, `+sortKey("v.created_at")+`
+        FROM poll_votes v
+        JOIN users u ON u.id = v.user_id
+        WHERE v.option_id = ? AND `+after+`
+        ORDER BY v.created_at, v.user_id
+        LIMIT ? OFFSET ?
+    `, args...)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list voters of poll option %s: %w", optionID, err)
+	}
+	defer rows.Close()
+
+	voters := make([]types.UserBasicInfo, 0)
+	cursors := make([]string, 0)
+	for rows.Next() {
+		var voter types.UserBasicInfo
+		var avatarURL sql.NullString
+		var cursorKey string
+		if err := rows.Scan(&voter.UserID, &voter.FirstName, &voter.LastName, &voter.Username, &avatarURL, &cursorKey); err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan poll voter: %w", err)
+		}
+		voter.AvatarURL = avatarURL.String
+		voters = append(voters, voter)
+		cursors = append(cursors, encodeCursor(cursorKey, voter.UserID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating voters of poll option %s: %w", optionID, err)
+	}
+
+	voters, info := trimPage(voters, cursors, page)
+	return voters, info, nil
+}
+
+// SetVotes deletes the user's previous votes and records the new ones. The
+// poll row is locked first so that concurrent votes of the same user run one
+// after the other instead of both replacing the old votes.
+func (r *pollRepository) SetVotes(postID, userID string, optionIDs []string) error {
+	return runInTx(r.db, func(tx DBTX) error {
+		var lockedID string
+		err := tx.QueryRow("SELECT post_id FROM polls WHERE post_id = ?"+dialectOf(tx).ForUpdate(), postID).Scan(&lockedID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPollNotFound
+			}
+			return fmt.Errorf("failed to lock poll %s: %w", postID, err)
+		}
+		if _, err := tx.Exec("DELETE FROM poll_votes WHERE post_id = ? AND user_id = ?", postID, userID); err != nil {
+			return fmt.Errorf("failed to clear votes of user %s in poll %s: %w", userID, postID, err)
+		}
+		now := time.Now()
+		for _, optionID := range optionIDs {
+			_, err := tx.Exec("INSERT INTO poll_votes (option_id, user_id, post_id, created_at) VALUES (?, ?, ?, ?)",
+				optionID, userID, postID, now)
+			if err != nil {
+				if isForeignKeyViolation(err) {
+					return ErrPollNotFound
+				}
+				return fmt.Errorf("failed to record vote of user %s in poll %s: %w", userID, postID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteVotes removes all of the user's votes in a poll
+func (r *pollRepository) DeleteVotes(postID, userID string) error {
+	result, err := r.db.Exec("DELETE FROM poll_votes WHERE post_id = ? AND user_id = ?", postID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete votes of user %s in poll %s: %w", userID, postID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for vote delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrVoteNotFound
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+func TestPollRepositoryVoters(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Poll
+		alice := createUser(t, tdb, "alice")
+		post := createPost(t, tdb, alice, models.PrivacyPublic)
+		poll := &models.Poll{PostID: post.ID, Options: []*models.PollOption{{Text: "Yes"}, {Text: "No"}}}
+		if err := repo.Create(poll); err != nil {
+			t.Fatalf("create poll: %v", err)
+		}
+		yes := poll.Options[0].ID
+		for _, username := range []string{"bob", "carol", "dave"} {
+			voter := createUser(t, tdb, username)
+			if err := repo.SetVotes(post.ID, voter.ID, []string{yes}); err != nil {
+				t.Fatalf("vote: %v", err)
+			}
+		}
+
+		all, err := repo.ListVoters([]string{post.ID}, 10)
+		if err != nil || len(all[yes]) != 3 || all[poll.Options[1].ID] != nil {
+			t.Fatalf("ListVoters = %v, %v", all, err)
+		}
+		capped, err := repo.ListVoters([]string{post.ID}, 2)
+		if err != nil || len(capped[yes]) != 2 || capped[yes][0] != all[yes][0] || capped[yes][1] != all[yes][1] {
+			t.Errorf("ListVoters capped at 2 = %v, %v", capped, err)
+		}
+
+		first, info, err := repo.ListOptionVoters(yes, models.Page{Limit: 2})
+		if err != nil || len(first) != 2 || !info.HasMore || first[0] != all[yes][0] || first[1] != all[yes][1] {
+			t.Fatalf("first page = %v, %+v, %v", first, info, err)
+		}
+		rest, info, err := repo.ListOptionVoters(yes, models.Page{Limit: 2, After: info.NextCursor})
+		if err != nil || len(rest) != 1 || info.HasMore || rest[0] != all[yes][2] {
+			t.Errorf("second page = %v, %+v, %v", rest, info, err)
+		}
+	})
+}
+
+func TestPollRepositorySetVotes(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Poll
+		alice := createUser(t, tdb, "alice")
+		bob := createUser(t, tdb, "bob")
+		post := createPost(t, tdb, alice, models.PrivacyPublic)
+		poll := &models.Poll{PostID: post.ID, Options: []*models.PollOption{{Text: "Yes"}, {Text: "No"}}}
+		if err := repo.Create(poll); err != nil {
+			t.Fatalf("create poll: %v", err)
+		}
+
+		// Concurrent votes of one user each replace the others
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(option *models.PollOption) {
+				defer wg.Done()
+				errs <- repo.SetVotes(post.ID, bob.ID, []string{option.ID})
+			}(poll.Options[i%2])
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("concurrent vote: %v", err)
+			}
+		}
+		var votes int
+		if err := tdb.db.QueryRow("SELECT COUNT(*) FROM poll_votes WHERE post_id = ? AND user_id = ?", post.ID, bob.ID).Scan(&votes); err != nil || votes != 1 {
+			t.Errorf("votes after concurrent votes = %d, %v; want 1", votes, err)
+		}
+
+		other := createPost(t, tdb, alice, models.PrivacyPublic)
+		if err := repo.SetVotes(other.ID, bob.ID, []string{poll.Options[0].ID}); !errors.Is(err, ErrPollNotFound) {
+			t.Errorf("vote in a post without a poll = %v, want ErrPollNotFound", err)
+		}
+	})
+}
@@ -13,34 +13,69 @@ import (
 var (
 	// ErrPostNotFound indicates that a post with the given ID was not found.
 	ErrPostNotFound = errors.New("post not found")
+	// ErrAlreadyReposted indicates that the user already reposted the post.
+	ErrAlreadyReposted = errors.New("post already reposted")
 )
 
 // PostRepository defines the interface for post data access
 type PostRepository interface {
 	Create(post *models.Post) error
 	GetByID(id string) (*models.Post, error)
-	List(requestingUserID string, limit, offset int) ([]*models.Post, error)                     // General feed (non-group posts)
-	ListByUser(targetUserID, requestingUserID string, limit, offset int) ([]*models.Post, error) // User profile posts (non-group)
-	ListByGroupID(groupID string, limit, offset int) ([]*models.Post, error)                     // Group-specific posts
-	ListPublic(limit, offset int) ([]*models.Post, error)                                        // For "Explore" feed
-	ListFollowedByUser(requestingUserID string, limit, offset int) ([]*models.Post, error)
-	// Update(post *models.Post) error // TODO: Implement Update
+	GetByIDs(ids []string) (map[string]*models.Post, error) // By ID; posts that don't exist are left out
+	// Lists are newest first and paginated by cursor or, for older clients, offset (see models.Page)
+	List(requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error)                     // General feed (non-group posts)
+	ListByUser(targetUserID, requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error) // User profile posts (non-group)
+	ListByGroupID(groupID string, page models.Page) ([]*models.Post, models.PageInfo, error)                     // Group-specific posts
+	ListPublic(page models.Page) ([]*models.Post, models.PageInfo, error)                                        // For "Explore" feed
+	ListFollowedByUser(requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error)
+	ListByTag(tag, requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error) // Tag page (non-group)
+	Update(post *models.Post) error                                                                    // Title and content only
 	Delete(id string) error
 
 	// Methods for managing allowed users for private posts (Only applicable if post.GroupID is NULL)
 	AddAllowedUsers(postID string, userIDs []string) error
-	RemoveAllowedUsers(postID string, userIDs []string) error // Optional: For editing allowed list
+	RemoveAllowedUsers(postID string, userIDs []string) error
 	IsUserAllowed(postID, userID string) (bool, error)
-	GetAllowedUsers(postID string) ([]string, error) // Optional: For editing/display
+	GetAllowedUsers(postID string) ([]string, error)
+	UpdatePrivacy(postID, privacy string) error    // Rebuilds the post's timeline entries
+	GetAudienceList(postID string) (string, error) // "" if the post isn't shared with an audience list
+	SetAudienceList(postID, listID string) error   // "" unshares it; rebuilds the post's timeline entries
+	// GetAudienceLists is GetAudienceList for several posts, by post ID. Posts
+	// not shared with an audience list are left out.
+	GetAudienceLists(postIDs []string) (map[string]string, error)
+
+	// Methods for the post's gallery
+	AddAttachments(postID string, attachments []models.PostAttachment) error
+	GetAttachments(postID string) ([]models.PostAttachment, error) // Ordered by position
+	// GetAttachmentsByPostIDs is GetAttachments for several posts, by post ID
+	GetAttachmentsByPostIDs(postIDs []string) (map[string][]models.PostAttachment, error)
+
+	// Methods for reposts and quote posts
+	FindRepost(userID, sharedPostID string) (*models.Post, error) // ErrPostNotFound if the user hasn't reposted it
+	CountShares(postIDs []string) (map[string]int, error)         // Reposts and quotes, by post ID; posts without shares are left out
+
+	// Methods for drafts and scheduled posts
+	ListUnpublished(userID string, page models.Page) ([]*models.Post, models.PageInfo, error) // The author's drafts and scheduled posts, newest first
+	ListDueScheduled(now time.Time, limit int) ([]*models.Post, error)                        // Scheduled posts due at now, oldest schedule first
+	SetSchedule(postID, status string, scheduledAt sql.NullTime) error                        // Unpublished posts only
+	// Publish marks an unpublished post as published at publishedAt. It
+	// returns false if the post was already published, e.g. by a concurrent run.
+	Publish(postID string, publishedAt time.Time) (bool, error)
+
+	// Methods for pinned posts, on profiles for user posts and in groups for group posts
+	ListPinnedByUser(targetUserID, requestingUserID string) ([]*models.Post, error) // Visible to requestingUserID, last pinned first
+	ListPinnedByGroupID(groupID string) ([]*models.Post, error)                     // Last pinned first
+	CountPinned(userID, groupID string) (int, error)                                // On the user's profile, or in the group if groupID is set
+	SetPinned(postID string, pinnedAt sql.NullTime) error                           // NULL unpins the post
 }
 
 // postRepository implements PostRepository interface
 type postRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPostRepository creates a new PostRepository
-func NewPostRepository(db *sql.DB) PostRepository {
+func NewPostRepository(db DBTX) PostRepository {
 	return &postRepository{
 		db: db,
 	}
@@ -49,11 +84,14 @@ func NewPostRepository(db *sql.DB) PostRepository {
 // Create inserts a new post record into the database
 func (r *postRepository) Create(post *models.Post) error {
 	query := `
-        INSERT INTO posts (id, user_id, title, content, image_url, privacy, group_id, created_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO posts (id, user_id, title, content, image_url, image_id, privacy, group_id, shared_post_id, share_type, status, scheduled_at, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 	post.ID = uuid.New().String()
 	post.CreatedAt = time.Now()
+	if post.Status == "" {
+		post.Status = models.PostStatusPublished
+	}
 
 	// If it's a group post, privacy is implicitly handled by group membership, set to public for simplicity within the group context.
 	// If it's not a group post, use the specified privacy.
@@ -69,11 +107,20 @@ func (r *postRepository) Create(post *models.Post) error {
 		post.Title,
 		post.Content,
 		post.ImageURL,
+		nullIfEmpty(post.ImageID),
 		privacy,      // Use determined privacy
 		post.GroupID, // Can be NULL
+		post.SharedPostID,
+		nullIfEmpty(post.ShareType),
+		post.Status,
+		post.ScheduledAt,
 		post.CreatedAt,
 	)
 	if err != nil {
+		// The only unique index besides the primary key allows one repost per user and post
+		if post.ShareType == models.ShareTypeRepost && isUniqueViolation(err) {
+			return ErrAlreadyReposted
+		}
 		return fmt.Errorf("failed to create post: %w", err)
 	}
 	return nil
@@ -82,7 +129,7 @@ func (r *postRepository) Create(post *models.Post) error {
 // GetByID retrieves a post by its ID
 func (r *postRepository) GetByID(id string) (*models.Post, error) {
 	query := `
-        SELECT id, user_id, title, content, image_url, privacy, group_id, created_at
+        SELECT id, user_id, title, content, image_url, COALESCE(image_id, ''), privacy, group_id, shared_post_id, COALESCE(share_type, ''), status, scheduled_at, pinned_at, created_at
         FROM posts
         WHERE id = ?
     `
@@ -95,9 +142,15 @@ func (r *postRepository) GetByID(id string) (*models.Post, error) {
 		&post.Title,
 		&post.Content,
 		&post.ImageURL,
+		&post.ImageID,
 		&post.Privacy,
 		&post.GroupID, // Scan GroupID
-		&createdAt,    // Scan into string
+		&post.SharedPostID,
+		&post.ShareType,
+		&post.Status,
+		&post.ScheduledAt,
+		&post.PinnedAt,
+		&createdAt, // Scan into string
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -120,61 +173,86 @@ func (r *postRepository) GetByID(id string) (*models.Post, error) {
 
 // List retrieves a paginated list of non-group posts for the general feed,
 // filtered by privacy rules based on the requesting user.
-func (r *postRepository) List(requestingUserID string, limit, offset int) ([]*models.Post, error) {
+func (r *postRepository) List(requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error) {
 	// Base query selects posts based on privacy rules, EXCLUDING group posts
 	// 1. Public posts (non-group)
-	// 2. User's own posts (non-group)
-	// 3. Posts from users the requesting user follows (almost_private, non-group)
-	// 4. Private posts where the requesting user is specifically allowed (non-group)
+	// 2. Posts on the requesting user's timeline: own posts, and posts fanned
+	//    out to them as a follower or allowed user
+	// 3. Posts that weren't fanned out (celebrity authors, older posts) are
+	//    checked the slow way: own posts, followed almost_private authors and
+	//    private posts where the requesting user is specifically allowed
+	after, afterArgs, err := afterCursor(page, "p.created_at", "p.id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
 	query := `
-SELECT DISTINCT p.id, p.user_id, p.title, p.content, p.image_url, p.privacy, p.group_id, p.created_at
+SELECT p.id, p.user_id, p.title, p.content, p.image_url, COALESCE(p.image_id, ''), p.privacy, p.group_id, p.shared_post_id, COALESCE(p.share_type, ''), p.status, p.scheduled_at, p.pinned_at, p.created_at, ` + sortKey("p.created_at") + `
 FROM posts p
-LEFT JOIN followers f ON p.user_id = f.following_id AND f.follower_id = ? AND f.status = 'accepted' -- requestingUserID for almost_private check
-LEFT JOIN post_allowed_users pau ON p.id = pau.post_id AND pau.user_id = ? -- requestingUserID for private check
 WHERE
     p.group_id IS NULL -- Exclude group posts
 AND (
     p.privacy = ? -- models.PrivacyPublic
-    OR p.user_id = ? -- requestingUserID (own posts)
-    OR (p.privacy = ? AND f.follower_id IS NOT NULL) -- models.PrivacyAlmostPrivate and follower relationship exists
-    OR (p.privacy = ? AND pau.user_id IS NOT NULL) -- models.PrivacyPrivate and user is allowed
+    OR EXISTS (SELECT 1 FROM timelines t WHERE t.user_id = ? AND t.post_id = p.id) -- requestingUserID's timeline
+    OR (p.fanned_out = ? AND (
+        p.user_id = ? -- requestingUserID (own posts)
+        OR (p.privacy = ? AND EXISTS ( -- models.PrivacyAlmostPrivate and follower relationship exists
+            SELECT 1 FROM followers f WHERE f.following_id = p.user_id AND f.follower_id = ? AND f.status = 'accepted'))
+        OR (p.privacy = ? AND EXISTS ( -- models.PrivacyPrivate and user is allowed
+            SELECT 1 FROM post_audience pau WHERE pau.post_id = p.id AND pau.user_id = ?))
+    ))
 )
-ORDER BY p.created_at DESC
+AND p.status = 'published' -- Drafts and scheduled posts stay hidden
+AND ` + after + `
+ORDER BY p.created_at DESC, p.id DESC
 LIMIT ? OFFSET ?;
 `
 
-	rows, err := r.db.Query(query,
-		requestingUserID, // For follower check
-		requestingUserID, // For allowed user check
+	args := []interface{}{
 		models.PrivacyPublic,
+		requestingUserID, // For timeline check
+		false,            // Not fanned out
 		requestingUserID, // For own post check
 		models.PrivacyAlmostPrivate,
+		requestingUserID, // For follower check
 		models.PrivacyPrivate,
-		limit,
-		offset,
-	)
+		requestingUserID, // For allowed user check
+	}
+	args = append(args, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list non-group posts with privacy filter: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list non-group posts with privacy filter: %w", err)
 	}
 	defer rows.Close()
 
 	posts := make([]*models.Post, 0)
+	cursors := make([]string, 0)
 	for rows.Next() {
 		var post models.Post
 		var createdAt string
+		var cursorKey string
 		err := rows.Scan(
 			&post.ID,
 			&post.UserID,
 			&post.Title,
 			&post.Content,
 			&post.ImageURL,
+			&post.ImageID,
 			&post.Privacy,
 			&post.GroupID, // Scan GroupID
+			&post.SharedPostID,
+			&post.ShareType,
+			&post.Status,
+			&post.ScheduledAt,
+			&post.PinnedAt,
 			&createdAt,
+			&cursorKey,
 		)
 		if err != nil {
 			// Log or return error? Return for now.
-			return nil, fmt.Errorf("failed to scan post during filtered list: %w", err)
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan post during filtered list: %w", err)
 		}
 		// Parse timestamp
 		post.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
@@ -183,24 +261,32 @@ LIMIT ? OFFSET ?;
 			post.CreatedAt = time.Time{}
 		}
 		posts = append(posts, &post)
+		cursors = append(cursors, encodeCursor(cursorKey, post.ID))
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating filtered post list rows: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating filtered post list rows: %w", err)
 	}
 
-	return posts, nil
+	posts, info := trimPage(posts, cursors, page)
+	return posts, info, nil
 }
 
 // ListByUser retrieves a paginated list of non-group posts for a specific user's profile,
 // filtered by privacy rules based on the requesting user.
-func (r *postRepository) ListByUser(targetUserID, requestingUserID string, limit, offset int) ([]*models.Post, error) {
+func (r *postRepository) ListByUser(targetUserID, requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error) {
 	// Similar logic to List, but initially filtered by targetUserID and excludes group posts
+	after, afterArgs, err := afterCursor(page, "p.created_at", "p.id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
 	query := `
-SELECT DISTINCT p.id, p.user_id, p.title, p.content, p.image_url, p.privacy, p.group_id, p.created_at
+SELECT DISTINCT p.id, p.user_id, p.title, p.content, p.image_url, COALESCE(p.image_id, ''), p.privacy, p.group_id, p.shared_post_id, COALESCE(p.share_type, ''), p.status, p.scheduled_at, p.pinned_at, p.created_at, ` + sortKey("p.created_at") + `
 FROM posts p
 LEFT JOIN followers f ON p.user_id = f.following_id AND f.follower_id = ? AND f.status = 'accepted' -- requestingUserID for almost_private check
-LEFT JOIN post_allowed_users pau ON p.id = pau.post_id AND pau.user_id = ? -- requestingUserID for private check
+LEFT JOIN post_audience pau ON p.id = pau.post_id AND pau.user_id = ? -- requestingUserID for private check
 WHERE
     p.user_id = ? -- targetUserID
 AND p.group_id IS NULL -- Exclude group posts
@@ -210,11 +296,13 @@ AND ( -- Privacy conditions based on requestingUserID
     OR (p.privacy = ? AND f.follower_id IS NOT NULL) -- models.PrivacyAlmostPrivate and follower relationship exists
     OR (p.privacy = ? AND pau.user_id IS NOT NULL) -- models.PrivacyPrivate and user is allowed
 )
-ORDER BY p.created_at DESC
+AND p.status = 'published' -- Drafts and scheduled posts stay hidden
+AND ` + after + `
+ORDER BY p.created_at DESC, p.id DESC
 LIMIT ? OFFSET ?;
 `
 
-	rows, err := r.db.Query(query,
+	args := []interface{}{
 		requestingUserID, // For follower check
 		requestingUserID, // For allowed user check
 		targetUserID,     // Filter by post owner
@@ -222,30 +310,40 @@ LIMIT ? OFFSET ?;
 		requestingUserID, // For own post check (redundant here but harmless)
 		models.PrivacyAlmostPrivate,
 		models.PrivacyPrivate,
-		limit,
-		offset,
-	)
+	}
+	args = append(args, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list non-group posts by user with privacy filter: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list non-group posts by user with privacy filter: %w", err)
 	}
 	defer rows.Close()
 
 	posts := make([]*models.Post, 0)
+	cursors := make([]string, 0)
 	for rows.Next() {
 		var post models.Post
 		var createdAt string
+		var cursorKey string
 		err := rows.Scan(
 			&post.ID,
 			&post.UserID,
 			&post.Title,
 			&post.Content,
 			&post.ImageURL,
+			&post.ImageID,
 			&post.Privacy,
 			&post.GroupID, // Scan GroupID
+			&post.SharedPostID,
+			&post.ShareType,
+			&post.Status,
+			&post.ScheduledAt,
+			&post.PinnedAt,
 			&createdAt,
+			&cursorKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan post during filtered list by user: %w", err)
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan post during filtered list by user: %w", err)
 		}
 		// Parse timestamp
 		post.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
@@ -254,47 +352,68 @@ LIMIT ? OFFSET ?;
 			post.CreatedAt = time.Time{}
 		}
 		posts = append(posts, &post)
+		cursors = append(cursors, encodeCursor(cursorKey, post.ID))
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating filtered post list by user rows: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating filtered post list by user rows: %w", err)
 	}
 
-	return posts, nil
+	posts, info := trimPage(posts, cursors, page)
+	return posts, info, nil
 }
 
 // ListByGroupID retrieves a paginated list of posts belonging to a specific group.
 // Assumes authorization (checking if requesting user is a member) is done in the service layer.
-func (r *postRepository) ListByGroupID(groupID string, limit, offset int) ([]*models.Post, error) {
+func (r *postRepository) ListByGroupID(groupID string, page models.Page) ([]*models.Post, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "created_at", "id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
 	query := `
-        SELECT id, user_id, title, content, image_url, privacy, group_id, created_at
+        SELECT id, user_id, title, content, image_url, COALESCE(image_id, ''), privacy, group_id, shared_post_id, COALESCE(share_type, ''), status, scheduled_at, pinned_at, created_at, ` + sortKey("created_at") + `
         FROM posts
         WHERE group_id = ?
-        ORDER BY created_at DESC
+        AND status = 'published' -- Drafts and scheduled posts stay hidden
+        AND ` + after + `
+ORDER BY created_at DESC, id DESC
         LIMIT ? OFFSET ?
     `
-	rows, err := r.db.Query(query, groupID, limit, offset)
+	args := append([]interface{}{groupID}, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list posts by group ID %s: %w", groupID, err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list posts by group ID %s: %w", groupID, err)
 	}
 	defer rows.Close()
 
 	posts := make([]*models.Post, 0)
+	cursors := make([]string, 0)
 	for rows.Next() {
 		var post models.Post
 		var createdAt string
+		var cursorKey string
 		err := rows.Scan(
 			&post.ID,
 			&post.UserID,
 			&post.Title,
 			&post.Content,
 			&post.ImageURL,
+			&post.ImageID,
 			&post.Privacy,
 			&post.GroupID, // Scan GroupID
+			&post.SharedPostID,
+			&post.ShareType,
+			&post.Status,
+			&post.ScheduledAt,
+			&post.PinnedAt,
 			&createdAt,
+			&cursorKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan post during list by group ID %s: %w", groupID, err)
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan post during list by group ID %s: %w", groupID, err)
 		}
 		// Parse timestamp
 		post.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
@@ -303,13 +422,123 @@ func (r *postRepository) ListByGroupID(groupID string, limit, offset int) ([]*mo
 			post.CreatedAt = time.Time{}
 		}
 		posts = append(posts, &post)
+		cursors = append(cursors, encodeCursor(cursorKey, post.ID))
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating post list by group ID %s rows: %w", groupID, err)
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating post list by group ID %s rows: %w", groupID, err)
 	}
 
-	return posts, nil
+	posts, info := trimPage(posts, cursors, page)
+	return posts, info, nil
+}
+
+// ListByTag retrieves a paginated list of non-group posts carrying the given tag,
+// filtered by the same privacy rules as List.
+func (r *postRepository) ListByTag(tag, requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "p.created_at", "p.id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
+	query := `
+SELECT DISTINCT p.id, p.user_id, p.title, p.content, p.image_url, COALESCE(p.image_id, ''), p.privacy, p.group_id, p.shared_post_id, COALESCE(p.share_type, ''), p.status, p.scheduled_at, p.pinned_at, p.created_at, ` + sortKey("p.created_at") + `
+FROM posts p
+JOIN post_tags pt ON pt.post_id = p.id
+JOIN tags t ON t.id = pt.tag_id AND t.name = ?
+LEFT JOIN followers f ON p.user_id = f.following_id AND f.follower_id = ? AND f.status = 'accepted' -- requestingUserID for almost_private check
+LEFT JOIN post_audience pau ON p.id = pau.post_id AND pau.user_id = ? -- requestingUserID for private check
+WHERE
+    p.group_id IS NULL -- Exclude group posts
+AND (
+    p.privacy = ? -- models.PrivacyPublic
+    OR p.user_id = ? -- requestingUserID (own posts)
+    OR (p.privacy = ? AND f.follower_id IS NOT NULL) -- models.PrivacyAlmostPrivate and follower relationship exists
+    OR (p.privacy = ? AND pau.user_id IS NOT NULL) -- models.PrivacyPrivate and user is allowed
+)
+AND p.status = 'published' -- Drafts and scheduled posts stay hidden
+AND ` + after + `
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT ? OFFSET ?;
+`
+
+	args := []interface{}{
+		tag,
+		requestingUserID, // For follower check
+		requestingUserID, // For allowed user check
+		models.PrivacyPublic,
+		requestingUserID, // For own post check
+		models.PrivacyAlmostPrivate,
+		models.PrivacyPrivate,
+	}
+	args = append(args, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list posts by tag %q: %w", tag, err)
+	}
+	defer rows.Close()
+
+	posts := make([]*models.Post, 0)
+	cursors := make([]string, 0)
+	for rows.Next() {
+		var post models.Post
+		var createdAt string
+		var cursorKey string
+		err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.Title,
+			&post.Content,
+			&post.ImageURL,
+			&post.ImageID,
+			&post.Privacy,
+			&post.GroupID,
+			&post.SharedPostID,
+			&post.ShareType,
+			&post.Status,
+			&post.ScheduledAt,
+			&post.PinnedAt,
+			&createdAt,
+			&cursorKey,
+		)
+		if err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan post during list by tag %q: %w", tag, err)
+		}
+		post.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+		if err != nil {
+			fmt.Printf("Warning: Failed to parse post created_at timestamp '%s': %v\n", createdAt, err)
+			post.CreatedAt = time.Time{}
+		}
+		posts = append(posts, &post)
+		cursors = append(cursors, encodeCursor(cursorKey, post.ID))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating post list by tag %q rows: %w", tag, err)
+	}
+
+	posts, info := trimPage(posts, cursors, page)
+	return posts, info, nil
+}
+
+// Update saves the title and content of a post. Privacy, group and media
+// can't be changed after creation.
+func (r *postRepository) Update(post *models.Post) error {
+	result, err := r.db.Exec("UPDATE posts SET title = ?, content = ? WHERE id = ?", post.Title, post.Content, post.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update post: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after updating post: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrPostNotFound
+	}
+	return nil
 }
 
 // Delete removes a post record by its ID
@@ -341,32 +570,23 @@ func (r *postRepository) AddAllowedUsers(postID string, userIDs []string) error
 		return nil // Nothing to add
 	}
 
-	// Use transaction for multiple inserts
-	tx, err := r.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction for adding allowed users: %w", err)
-	}
-	defer tx.Rollback() // Rollback if anything fails
-
-	stmt, err := tx.Prepare("INSERT OR IGNORE INTO post_allowed_users (post_id, user_id) VALUES (?, ?)")
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement for adding allowed users: %w", err)
-	}
-	defer stmt.Close()
-
-	for _, userID := range userIDs {
-		_, err := stmt.Exec(postID, userID)
+	// Use transaction for multiple inserts (joins the caller's transaction if there is one)
+	return runInTx(r.db, func(tx DBTX) error {
+		stmt, err := tx.Prepare("INSERT INTO post_allowed_users (post_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING")
 		if err != nil {
-			// Consider logging the specific user ID that failed
-			return fmt.Errorf("failed to insert allowed user %s for post %s: %w", userID, postID, err)
+			return fmt.Errorf("failed to prepare statement for adding allowed users: %w", err)
 		}
-	}
+		defer stmt.Close()
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction for adding allowed users: %w", err)
-	}
-
-	return nil
+		for _, userID := range userIDs {
+			_, err := stmt.Exec(postID, userID)
+			if err != nil {
+				// Consider logging the specific user ID that failed
+				return fmt.Errorf("failed to insert allowed user %s for post %s: %w", userID, postID, err)
+			}
+		}
+		return refreshTimelines(tx, postID)
+	})
 }
 
 // RemoveAllowedUsers removes specified user IDs from the allowed list for a post.
@@ -375,38 +595,50 @@ func (r *postRepository) RemoveAllowedUsers(postID string, userIDs []string) err
 		return nil // Nothing to remove
 	}
 
-	// Use transaction for multiple deletes
-	tx, err := r.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction for removing allowed users: %w", err)
-	}
-	defer tx.Rollback()
-
-	stmt, err := tx.Prepare("DELETE FROM post_allowed_users WHERE post_id = ? AND user_id = ?")
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement for removing allowed users: %w", err)
-	}
-	defer stmt.Close()
-
-	for _, userID := range userIDs {
-		_, err := stmt.Exec(postID, userID)
+	// Use transaction for multiple deletes (joins the caller's transaction if there is one)
+	return runInTx(r.db, func(tx DBTX) error {
+		stmt, err := tx.Prepare("DELETE FROM post_allowed_users WHERE post_id = ? AND user_id = ?")
 		if err != nil {
-			// Log or handle error - e.g., user wasn't in the list anyway
-			fmt.Printf("Warning: Failed to remove allowed user %s for post %s (may not have existed): %v\n", userID, postID, err)
-			// Continue trying to remove others
+			return fmt.Errorf("failed to prepare statement for removing allowed users: %w", err)
 		}
-	}
+		defer stmt.Close()
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction for removing allowed users: %w", err)
-	}
+		for _, userID := range userIDs {
+			_, err := stmt.Exec(postID, userID)
+			if err != nil {
+				// Log or handle error - e.g., user wasn't in the list anyway
+				fmt.Printf("Warning: Failed to remove allowed user %s for post %s (may not have existed): %v\n", userID, postID, err)
+				// Continue trying to remove others
+			}
+		}
+		return refreshTimelines(tx, postID)
+	})
+}
 
-	return nil
+// UpdatePrivacy changes the privacy of a non-group post and rebuilds its
+// timeline entries for the new audience
+func (r *postRepository) UpdatePrivacy(postID, privacy string) error {
+	// Joins the caller's transaction if there is one
+	return runInTx(r.db, func(tx DBTX) error {
+		result, err := tx.Exec("UPDATE posts SET privacy = ? WHERE id = ? AND group_id IS NULL", privacy, postID)
+		if err != nil {
+			return fmt.Errorf("failed to update privacy of post %s: %w", postID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected for privacy update: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrPostNotFound
+		}
+		return refreshTimelines(tx, postID)
+	})
 }
 
-// IsUserAllowed checks if a specific user is in the allowed list for a private post.
+// IsUserAllowed checks if a specific user is in the audience of a private post:
+// its allowed users or the members of its audience list.
 func (r *postRepository) IsUserAllowed(postID, userID string) (bool, error) {
-	query := "SELECT 1 FROM post_allowed_users WHERE post_id = ? AND user_id = ? LIMIT 1"
+	query := "SELECT 1 FROM post_audience WHERE post_id = ? AND user_id = ? LIMIT 1"
 	var exists int
 	err := r.db.QueryRow(query, postID, userID).Scan(&exists)
 	if err != nil {
@@ -443,25 +675,100 @@ func (r *postRepository) GetAllowedUsers(postID string) ([]string, error) {
 	return allowedUserIDs, nil
 }
 
+// GetAudienceList retrieves the ID of the audience list a private post is
+// shared with
+func (r *postRepository) GetAudienceList(postID string) (string, error) {
+	var listID string
+	err := r.db.QueryRow("SELECT list_id FROM post_audience_lists WHERE post_id = ?", postID).Scan(&listID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get audience list of post %s: %w", postID, err)
+	}
+	return listID, nil
+}
+
+// GetAudienceLists retrieves the audience lists several posts are shared with
+func (r *postRepository) GetAudienceLists(postIDs []string) (map[string]string, error) {
+	lists := make(map[string]string)
+	if len(postIDs) == 0 {
+		return lists, nil
+	}
+	rows, err := r.db.Query(`
+        SELECT post_id, list_id
+        FROM post_audience_lists
+        WHERE post_id IN (`+inPlaceholders(len(postIDs))+`)
+    `, stringArgs(postIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audience lists of posts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID, listID string
+		if err := rows.Scan(&postID, &listID); err != nil {
+			return nil, fmt.Errorf("failed to scan audience list: %w", err)
+		}
+		lists[postID] = listID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audience lists: %w", err)
+	}
+	return lists, nil
+}
+
+// SetAudienceList shares a private post with the members of an audience list,
+// replacing the list it was shared with, and rebuilds its timeline entries
+func (r *postRepository) SetAudienceList(postID, listID string) error {
+	// Joins the caller's transaction if there is one
+	return runInTx(r.db, func(tx DBTX) error {
+		if _, err := tx.Exec("DELETE FROM post_audience_lists WHERE post_id = ?", postID); err != nil {
+			return fmt.Errorf("failed to clear audience list of post %s: %w", postID, err)
+		}
+		if listID != "" {
+			_, err := tx.Exec("INSERT INTO post_audience_lists (post_id, list_id) VALUES (?, ?)", postID, listID)
+			if err != nil {
+				if isForeignKeyViolation(err) {
+					return ErrAudienceListNotFound
+				}
+				return fmt.Errorf("failed to set audience list of post %s: %w", postID, err)
+			}
+		}
+		return refreshTimelines(tx, postID)
+	})
+}
+
 // ListPublic retrieves a paginated list of public, non-group posts.
-func (r *postRepository) ListPublic(limit, offset int) ([]*models.Post, error) {
+func (r *postRepository) ListPublic(page models.Page) ([]*models.Post, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "created_at", "id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
 	query := `
-		SELECT id, user_id, title, content, image_url, privacy, group_id, created_at
+		SELECT id, user_id, title, content, image_url, COALESCE(image_id, ''), privacy, group_id, shared_post_id, COALESCE(share_type, ''), status, scheduled_at, pinned_at, created_at, ` + sortKey("created_at") + `
 		FROM posts
 		WHERE privacy = ? AND group_id IS NULL
-		ORDER BY created_at DESC
+		AND status = 'published' -- Drafts and scheduled posts stay hidden
+		AND ` + after + `
+ORDER BY created_at DESC, id DESC
 		LIMIT ? OFFSET ?;
 	`
-	rows, err := r.db.Query(query, models.PrivacyPublic, limit, offset)
+	args := append([]interface{}{models.PrivacyPublic}, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list public posts: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list public posts: %w", err)
 	}
 	defer rows.Close()
 
 	posts := make([]*models.Post, 0)
+	cursors := make([]string, 0)
 	for rows.Next() {
 		var post models.Post
 		var createdAtStr string
+		var cursorKey string
 		var groupID sql.NullString // Ensure GroupID is scanned as sql.NullString
 
 		err := rows.Scan(
@@ -470,12 +777,19 @@ func (r *postRepository) ListPublic(limit, offset int) ([]*models.Post, error) {
 			&post.Title,
 			&post.Content,
 			&post.ImageURL, // This is string, can be empty
+			&post.ImageID,
 			&post.Privacy,
 			&groupID, // Scan into sql.NullString
+			&post.SharedPostID,
+			&post.ShareType,
+			&post.Status,
+			&post.ScheduledAt,
+			&post.PinnedAt,
 			&createdAtStr,
+			&cursorKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan public post row: %w", err)
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan public post row: %w", err)
 		}
 
 		post.GroupID = groupID // Assign scanned NullString
@@ -489,62 +803,84 @@ func (r *postRepository) ListPublic(limit, offset int) ([]*models.Post, error) {
 			post.CreatedAt = parsedTime
 		}
 		posts = append(posts, &post)
+		cursors = append(cursors, encodeCursor(cursorKey, post.ID))
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating public post rows: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating public post rows: %w", err)
 	}
-	return posts, nil
+	posts, info := trimPage(posts, cursors, page)
+	return posts, info, nil
 }
 
 // ListFollowedByUser retrieves posts from users that the requestingUserID follows.
 // It includes 'public', 'semi-private' (almost_private), and 'private' posts (if the user is allowed) and excludes group posts.
-func (r *postRepository) ListFollowedByUser(requestingUserID string, limit, offset int) ([]*models.Post, error) {
+// Public posts carrying a tag the user follows are included too, whoever wrote them.
+func (r *postRepository) ListFollowedByUser(requestingUserID string, page models.Page) ([]*models.Post, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "p.created_at", "p.id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
 	query := `
-		SELECT DISTINCT p.id, p.user_id, p.title, p.content, p.image_url, p.privacy, p.group_id, p.created_at
+		SELECT DISTINCT p.id, p.user_id, p.title, p.content, p.image_url, COALESCE(p.image_id, ''), p.privacy, p.group_id, p.shared_post_id, COALESCE(p.share_type, ''), p.status, p.scheduled_at, p.pinned_at, p.created_at, ` + sortKey("p.created_at") + `
 		FROM posts p
-		JOIN followers f ON p.user_id = f.following_id AND f.follower_id = ? AND f.status = 'accepted'
-		LEFT JOIN post_allowed_users pau ON p.id = pau.post_id AND pau.user_id = ? -- For checking private post access
+		LEFT JOIN followers f ON p.user_id = f.following_id AND f.follower_id = ? AND f.status = 'accepted'
+		LEFT JOIN post_audience pau ON p.id = pau.post_id AND pau.user_id = ? -- For checking private post access
 		WHERE
-		    f.follower_id = ? -- Ensures we are only getting posts from followed users
-		  AND f.status = 'accepted'
-		  AND p.group_id IS NULL
+		    p.group_id IS NULL
 		  AND (
-		    p.privacy = ? -- Public posts from followed user
-		    OR p.privacy = ? -- Semi-private posts from followed user
-		    OR (p.privacy = ? AND pau.user_id IS NOT NULL) -- Private posts from followed user where requestingUser is allowed
+		    (f.follower_id IS NOT NULL AND ( -- Ensures we are only getting posts from followed users
+		      p.privacy = ? -- Public posts from followed user
+		      OR p.privacy = ? -- Semi-private posts from followed user
+		      OR (p.privacy = ? AND pau.user_id IS NOT NULL) -- Private posts from followed user where requestingUser is allowed
+		    ))
+		    OR (p.privacy = ? AND EXISTS ( -- Public posts with a followed tag
+		      SELECT 1 FROM post_tags pt
+		      JOIN tag_follows tf ON tf.tag_id = pt.tag_id AND tf.user_id = ?
+		      WHERE pt.post_id = p.id
+		    ))
 		  )
-		ORDER BY p.created_at DESC
+		AND p.status = 'published' -- Drafts and scheduled posts stay hidden
+		AND ` + after + `
+ORDER BY p.created_at DESC, p.id DESC
 		LIMIT ? OFFSET ?;
 	`
 	// Parameters for the query:
-	// 1. requestingUserID (for JOIN followers f ON p.user_id = f.following_id AND f.follower_id = ?)
-	// 2. requestingUserID (for LEFT JOIN post_allowed_users pau ON p.id = pau.post_id AND pau.user_id = ?)
-	// 3. requestingUserID (for WHERE f.follower_id = ?)
-	// 4. models.PrivacyPublic
-	// 5. models.PrivacyAlmostPrivate
-	// 6. models.PrivacyPrivate
-	// 7. limit
-	// 8. offset
-	rows, err := r.db.Query(query,
-		requestingUserID,
+	// 1. requestingUserID (for LEFT JOIN followers f ON p.user_id = f.following_id AND f.follower_id = ?)
+	// 2. requestingUserID (for LEFT JOIN post_audience pau ON p.id = pau.post_id AND pau.user_id = ?)
+	// 3. models.PrivacyPublic
+	// 4. models.PrivacyAlmostPrivate
+	// 5. models.PrivacyPrivate
+	// 6. models.PrivacyPublic (followed tags)
+	// 7. requestingUserID (for JOIN tag_follows tf ... AND tf.user_id = ?)
+	// 8. the cursor condition's arguments, if any
+	// 9. limit
+	// 10. offset
+	args := []interface{}{
 		requestingUserID,
 		requestingUserID,
 		models.PrivacyPublic,
 		models.PrivacyAlmostPrivate,
 		models.PrivacyPrivate,
-		limit,
-		offset,
-	)
+		models.PrivacyPublic,
+		requestingUserID,
+	}
+	args = append(args, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list posts followed by user %s: %w", requestingUserID, err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list posts followed by user %s: %w", requestingUserID, err)
 	}
 	defer rows.Close()
 
 	posts := make([]*models.Post, 0)
+	cursors := make([]string, 0)
 	for rows.Next() {
 		var post models.Post
 		var createdAtStr string
+		var cursorKey string
 		var groupID sql.NullString // Handles potential NULL group_id
 
 		err := rows.Scan(
@@ -553,12 +889,19 @@ func (r *postRepository) ListFollowedByUser(requestingUserID string, limit, offs
 			&post.Title,
 			&post.Content,
 			&post.ImageURL,
+			&post.ImageID,
 			&post.Privacy,
 			&groupID,
+			&post.SharedPostID,
+			&post.ShareType,
+			&post.Status,
+			&post.ScheduledAt,
+			&post.PinnedAt,
 			&createdAtStr,
+			&cursorKey,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan post row for followed user feed: %w", err)
+			return nil, models.PageInfo{}, fmt.Errorf("failed to scan post row for followed user feed: %w", err)
 		}
 		post.GroupID = groupID // Assign scanned NullString
 		parsedTime, timeErr := time.Parse(time.RFC3339, createdAtStr)
@@ -571,10 +914,352 @@ func (r *postRepository) ListFollowedByUser(requestingUserID string, limit, offs
 			post.CreatedAt = parsedTime
 		}
 		posts = append(posts, &post)
+		cursors = append(cursors, encodeCursor(cursorKey, post.ID))
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating post rows for followed user feed: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("error iterating post rows for followed user feed: %w", err)
+	}
+	posts, info := trimPage(posts, cursors, page)
+	return posts, info, nil
+}
+
+// --- Methods for post_attachments ---
+
+// AddAttachments inserts the gallery of a post; positions are taken from the attachments
+func (r *postRepository) AddAttachments(postID string, attachments []models.PostAttachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	// Joins the caller's transaction if there is one
+	return runInTx(r.db, func(tx DBTX) error {
+		for _, a := range attachments {
+			_, err := tx.Exec(
+				"INSERT INTO post_attachments (post_id, media_id, position, alt_text) VALUES (?, ?, ?, ?)",
+				postID, a.MediaID, a.Position, a.AltText,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert attachment %s for post %s: %w", a.MediaID, postID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetAttachments retrieves the gallery of a post in order
+func (r *postRepository) GetAttachments(postID string) ([]models.PostAttachment, error) {
+	attachments, err := r.GetAttachmentsByPostIDs([]string{postID})
+	if err != nil {
+		return nil, err
+	}
+	return attachments[postID], nil
+}
+
+// GetAttachmentsByPostIDs retrieves the galleries of several posts, each in order
+func (r *postRepository) GetAttachmentsByPostIDs(postIDs []string) (map[string][]models.PostAttachment, error) {
+	attachments := make(map[string][]models.PostAttachment)
+	if len(postIDs) == 0 {
+		return attachments, nil
+	}
+	query := `
+        SELECT post_id, media_id, position, alt_text
+        FROM post_attachments
+        WHERE post_id IN (` + inPlaceholders(len(postIDs)) + `)
+        ORDER BY post_id, position
+    `
+	rows, err := r.db.Query(query, stringArgs(postIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post attachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.PostAttachment
+		if err := rows.Scan(&a.PostID, &a.MediaID, &a.Position, &a.AltText); err != nil {
+			return nil, fmt.Errorf("failed to scan post attachment: %w", err)
+		}
+		attachments[a.PostID] = append(attachments[a.PostID], a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating post attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// FindRepost retrieves the repost of a post by a user
+func (r *postRepository) FindRepost(userID, sharedPostID string) (*models.Post, error) {
+	var postID string
+	err := r.db.QueryRow(
+		"SELECT id FROM posts WHERE user_id = ? AND shared_post_id = ? AND share_type = ?",
+		userID, sharedPostID, models.ShareTypeRepost,
+	).Scan(&postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, fmt.Errorf("failed to find repost of post %s by user %s: %w", sharedPostID, userID, err)
+	}
+	return r.GetByID(postID)
+}
+
+// CountShares counts the reposts and quote posts of several posts
+func (r *postRepository) CountShares(postIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	rows, err := r.db.Query(`
+        SELECT shared_post_id, COUNT(*)
+        FROM posts
+        WHERE shared_post_id IN (`+inPlaceholders(len(postIDs))+`)
+        GROUP BY shared_post_id
+    `, stringArgs(postIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count shares of posts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID string
+		var count int
+		if err := rows.Scan(&postID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan share count: %w", err)
+		}
+		counts[postID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating share counts: %w", err)
+	}
+	return counts, nil
+}
+
+// GetByIDs retrieves several posts at once
+func (r *postRepository) GetByIDs(ids []string) (map[string]*models.Post, error) {
+	posts := make(map[string]*models.Post, len(ids))
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	rows, err := r.db.Query(`
+        SELECT `+postColumns+`
+        FROM posts
+        WHERE id IN (`+inPlaceholders(len(ids))+`)
+    `, stringArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by ID: %w", err)
+	}
+	list, _, err := scanPosts(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range list {
+		posts[post.ID] = post
 	}
 	return posts, nil
 }
+
+// postColumns are the columns scanned by scanPosts
+var postColumns = `id, user_id, title, content, image_url, COALESCE(image_id, ''), privacy, group_id, shared_post_id, COALESCE(share_type, ''), status, scheduled_at, pinned_at, created_at, ` + sortKey("created_at")
+
+// scanPosts reads the rows of a query selecting postColumns,
+// along with the cursor of each row
+func scanPosts(rows *sql.Rows) ([]*models.Post, []string, error) {
+	defer rows.Close()
+	posts := make([]*models.Post, 0)
+	cursors := make([]string, 0)
+	for rows.Next() {
+		var post models.Post
+		var createdAt, cursorKey string
+		err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.Title,
+			&post.Content,
+			&post.ImageURL,
+			&post.ImageID,
+			&post.Privacy,
+			&post.GroupID,
+			&post.SharedPostID,
+			&post.ShareType,
+			&post.Status,
+			&post.ScheduledAt,
+			&post.PinnedAt,
+			&createdAt,
+			&cursorKey,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		if post.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse post timestamp: %w", err)
+		}
+		posts = append(posts, &post)
+		cursors = append(cursors, encodeCursor(cursorKey, post.ID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+	return posts, cursors, nil
+}
+
+// ListUnpublished retrieves a page of a user's drafts and scheduled posts, including group posts
+func (r *postRepository) ListUnpublished(userID string, page models.Page) ([]*models.Post, models.PageInfo, error) {
+	after, afterArgs, err := afterCursor(page, "created_at", "id", false)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	limit, offset := pageBounds(page)
+
+	query := `
+        SELECT ` + postColumns + `
+        FROM posts
+        WHERE user_id = ? AND status <> ?
+        AND ` + after + `
+        ORDER BY created_at DESC, id DESC
+        LIMIT ? OFFSET ?
+    `
+	args := append([]interface{}{userID, models.PostStatusPublished}, afterArgs...)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list unpublished posts of user %s: %w", userID, err)
+	}
+	posts, cursors, err := scanPosts(rows)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	posts, info := trimPage(posts, cursors, page)
+	return posts, info, nil
+}
+
+// ListDueScheduled retrieves scheduled posts whose time has come
+func (r *postRepository) ListDueScheduled(now time.Time, limit int) ([]*models.Post, error) {
+	query := `
+        SELECT ` + postColumns + `
+        FROM posts
+        WHERE status = ? AND scheduled_at <= ?
+        ORDER BY scheduled_at, id
+        LIMIT ?
+    `
+	rows, err := r.db.Query(query, models.PostStatusScheduled, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due scheduled posts: %w", err)
+	}
+	posts, _, err := scanPosts(rows)
+	return posts, err
+}
+
+// SetSchedule turns an unpublished post into a draft or (re)schedules it
+func (r *postRepository) SetSchedule(postID, status string, scheduledAt sql.NullTime) error {
+	result, err := r.db.Exec(
+		"UPDATE posts SET status = ?, scheduled_at = ? WHERE id = ? AND status <> ?",
+		status, scheduledAt, postID, models.PostStatusPublished,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule post %s: %w", postID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for post schedule: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// Publish publishes an unpublished post. Its creation time becomes the
+// publication time so it shows up at the top of feeds.
+func (r *postRepository) Publish(postID string, publishedAt time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE posts SET status = ?, scheduled_at = NULL, created_at = ? WHERE id = ? AND status <> ?",
+		models.PostStatusPublished, publishedAt, postID, models.PostStatusPublished,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to publish post %s: %w", postID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for post publish: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ListPinnedByUser retrieves the posts pinned on a user's profile that the
+// requesting user may see, using the same privacy rules as ListByUser
+func (r *postRepository) ListPinnedByUser(targetUserID, requestingUserID string) ([]*models.Post, error) {
+	query := `
+        SELECT ` + postColumns + `
+        FROM posts p
+        WHERE p.user_id = ? AND p.group_id IS NULL AND p.pinned_at IS NOT NULL
+        AND p.status = 'published'
+        AND (
+            p.privacy = ?
+            OR p.user_id = ?
+            OR (p.privacy = ? AND EXISTS (
+                SELECT 1 FROM followers f WHERE f.following_id = p.user_id AND f.follower_id = ? AND f.status = 'accepted'))
+            OR (p.privacy = ? AND EXISTS (
+                SELECT 1 FROM post_audience pau WHERE pau.post_id = p.id AND pau.user_id = ?))
+        )
+        ORDER BY p.pinned_at DESC, p.id DESC
+    `
+	rows, err := r.db.Query(query,
+		targetUserID,
+		models.PrivacyPublic,
+		requestingUserID,
+		models.PrivacyAlmostPrivate, requestingUserID,
+		models.PrivacyPrivate, requestingUserID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pinned posts of user %s: %w", targetUserID, err)
+	}
+	posts, _, err := scanPosts(rows)
+	return posts, err
+}
+
+// ListPinnedByGroupID retrieves the posts pinned in a group. Membership is
+// checked by the service layer.
+func (r *postRepository) ListPinnedByGroupID(groupID string) ([]*models.Post, error) {
+	query := `
+        SELECT ` + postColumns + `
+        FROM posts
+        WHERE group_id = ? AND pinned_at IS NOT NULL AND status = 'published'
+        ORDER BY pinned_at DESC, id DESC
+    `
+	rows, err := r.db.Query(query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pinned posts of group %s: %w", groupID, err)
+	}
+	posts, _, err := scanPosts(rows)
+	return posts, err
+}
+
+// CountPinned counts the posts pinned on a user's profile or in a group
+func (r *postRepository) CountPinned(userID, groupID string) (int, error) {
+	query := "SELECT COUNT(*) FROM posts WHERE user_id = ? AND group_id IS NULL AND pinned_at IS NOT NULL"
+	args := []interface{}{userID}
+	if groupID != "" {
+		query = "SELECT COUNT(*) FROM posts WHERE group_id = ? AND pinned_at IS NOT NULL"
+		args = []interface{}{groupID}
+	}
+	var count int
+	if err := r.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pinned posts: %w", err)
+	}
+	return count, nil
+}
+
+// SetPinned pins or unpins a post
+func (r *postRepository) SetPinned(postID string, pinnedAt sql.NullTime) error {
+	result, err := r.db.Exec("UPDATE posts SET pinned_at = ? WHERE id = ?", pinnedAt, postID)
+	if err != nil {
+		return fmt.Errorf("failed to pin post %s: %w", postID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for post pin: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+func TestPostRepositoryCRUD(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Post
+		alice := createUser(t, tdb, "alice")
+		post := createPost(t, tdb, alice, models.PrivacyPublic)
+
+		got, err := repo.GetByID(post.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.UserID != alice.ID || got.Title != post.Title || got.Status != models.PostStatusPublished || got.GroupID.Valid {
+			t.Errorf("get = %+v", got)
+		}
+
+		post.Title, post.Content = "Edited", "New content"
+		if err := repo.Update(post); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if got, _ := repo.GetByID(post.ID); got == nil || got.Title != "Edited" || got.Content != "New content" {
+			t.Errorf("after update = %+v", got)
+		}
+
+		if err := repo.Delete(post.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repo.GetByID(post.ID); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("get deleted: got %v, want ErrPostNotFound", err)
+		}
+		if err := repo.Delete(post.ID); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("delete twice: got %v, want ErrPostNotFound", err)
+		}
+	})
+}
+
+func TestPostRepositoryListPrivacy(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Post
+		alice := createUser(t, tdb, "alice")
+		follower := createUser(t, tdb, "follower")
+		allowed := createUser(t, tdb, "allowed")
+		stranger := createUser(t, tdb, "stranger")
+
+		if err := tdb.repos.Follower.CreateFollowRequest(follower.ID, alice.ID); err != nil {
+			t.Fatalf("follow: %v", err)
+		}
+		if err := tdb.repos.Follower.UpdateFollowStatus(follower.ID, alice.ID, "accepted"); err != nil {
+			t.Fatalf("accept: %v", err)
+		}
+
+		public := createPost(t, tdb, alice, models.PrivacyPublic)
+		followersOnly := createPost(t, tdb, alice, models.PrivacyAlmostPrivate)
+		private := createPost(t, tdb, alice, models.PrivacyPrivate)
+		if err := repo.AddAllowedUsers(private.ID, []string{allowed.ID}); err != nil {
+			t.Fatalf("allow: %v", err)
+		}
+
+		tests := []struct {
+			name   string
+			viewer *models.User
+			want   []string // Newest first
+		}{
+			{"author", alice, []string{private.ID, followersOnly.ID, public.ID}},
+			{"follower", follower, []string{followersOnly.ID, public.ID}},
+			{"allowed user", allowed, []string{private.ID, public.ID}},
+			{"stranger", stranger, []string{public.ID}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				posts, _, err := repo.List(tt.viewer.ID, models.Page{Limit: 10})
+				if err != nil {
+					t.Fatalf("list: %v", err)
+				}
+				if got := postIDs(posts); !slices.Equal(got, tt.want) {
+					t.Errorf("list = %v, want %v", got, tt.want)
+				}
+				for _, p := range []*models.Post{public, followersOnly, private} {
+					allowedToSee := slices.Contains(tt.want, p.ID)
+					if p.Privacy != models.PrivacyPrivate || tt.viewer == alice {
+						continue
+					}
+					if ok, err := repo.IsUserAllowed(p.ID, tt.viewer.ID); err != nil || ok != allowedToSee {
+						t.Errorf("IsUserAllowed(%s) = %v, %v; want %v", p.Privacy, ok, err, allowedToSee)
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestPostRepositoryPagination(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		alice := createUser(t, tdb, "alice")
+		var want []string
+		for i := 0; i < 5; i++ {
+			want = append([]string{createPost(t, tdb, alice, models.PrivacyPublic).ID}, want...)
+		}
+
+		var got []string
+		page := models.Page{Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("pagination did not end")
+			}
+			posts, info, err := tdb.repos.Post.ListPublic(page)
+			if err != nil {
+				t.Fatalf("list public: %v", err)
+			}
+			got = append(got, postIDs(posts)...)
+			if !info.HasMore {
+				if info.NextCursor != "" {
+					t.Errorf("last page has cursor %q", info.NextCursor)
+				}
+				break
+			}
+			page.After = info.NextCursor
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("pages = %v, want %v", got, want)
+		}
+
+		if _, _, err := tdb.repos.Post.ListPublic(models.Page{Limit: 2, After: "not a cursor"}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("invalid cursor: got %v, want ErrInvalidCursor", err)
+		}
+	})
+}
+
+func TestCommentRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Comment
+		alice := createUser(t, tdb, "alice")
+		bob := createUser(t, tdb, "bob")
+		post := createPost(t, tdb, alice, models.PrivacyPublic)
+
+		first := &models.Comment{PostID: post.ID, UserID: bob.ID, Content: "First"}
+		second := &models.Comment{PostID: post.ID, UserID: alice.ID, Content: "Second"}
+		for _, c := range []*models.Comment{first, second} {
+			if err := repo.Create(c); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+
+		got, err := repo.GetByID(first.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Content != "First" || got.UserID != bob.ID || got.PostID != post.ID {
+			t.Errorf("get = %+v", got)
+		}
+
+		comments, _, err := repo.GetByPostID(post.ID, models.Page{Limit: 10})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(comments) != 2 || comments[0].ID != first.ID || comments[1].ID != second.ID {
+			t.Errorf("list = %+v, want oldest first", comments)
+		}
+
+		if err := repo.Delete(first.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if err := repo.Delete(first.ID); !errors.Is(err, ErrCommentNotFound) {
+			t.Errorf("delete twice: got %v, want ErrCommentNotFound", err)
+		}
+
+		// Comments go with their post
+		if err := tdb.repos.Post.Delete(post.ID); err != nil {
+			t.Fatalf("delete post: %v", err)
+		}
+		if _, err := repo.GetByID(second.ID); !errors.Is(err, ErrCommentNotFound) {
+			t.Errorf("comment of deleted post: got %v, want ErrCommentNotFound", err)
+		}
+	})
+}
+
+func TestPostRepositoryBatchReads(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		alice := createUser(t, tdb, "alice")
+		bob := createUser(t, tdb, "bob")
+		first := createPost(t, tdb, alice, models.PrivacyPublic)
+		second := createPost(t, tdb, bob, models.PrivacyPublic)
+		ids := []string{first.ID, second.ID, "missing"}
+
+		posts, err := tdb.repos.Post.GetByIDs(ids)
+		if err != nil || len(posts) != 2 || posts[first.ID].UserID != alice.ID || posts[second.ID].UserID != bob.ID {
+			t.Fatalf("GetByIDs = %v, %v", posts, err)
+		}
+		users, err := tdb.repos.User.GetByIDs([]string{alice.ID, bob.ID, "missing"})
+		if err != nil || len(users) != 2 || users[bob.ID].Username != "bob" {
+			t.Errorf("users GetByIDs = %v, %v", users, err)
+		}
+
+		repost := &models.Post{UserID: bob.ID, Privacy: models.PrivacyPublic, ShareType: "repost"}
+		repost.SharedPostID.String, repost.SharedPostID.Valid = first.ID, true
+		if err := tdb.repos.Post.Create(repost); err != nil {
+			t.Fatalf("create repost: %v", err)
+		}
+		if shares, err := tdb.repos.Post.CountShares(ids); err != nil || shares[first.ID] != 1 || shares[second.ID] != 0 {
+			t.Errorf("CountShares = %v, %v; want 1 for the first post", shares, err)
+		}
+
+		if err := tdb.repos.Tag.SetPostTags(first.ID, []string{"go", "db"}, first.CreatedAt); err != nil {
+			t.Fatalf("set tags: %v", err)
+		}
+		if tags, err := tdb.repos.Tag.GetPostTags(ids); err != nil || !slices.Equal(tags[first.ID], []string{"db", "go"}) || tags[second.ID] != nil {
+			t.Errorf("GetPostTags = %v, %v", tags, err)
+		}
+
+		for _, id := range []string{"m1", "m2"} {
+			media := &models.Media{ID: id, OwnerID: alice.ID, StorageKey: id, ContentType: "image/png", Size: 1, CreatedAt: first.CreatedAt}
+			if err := tdb.repos.Media.Create(media); err != nil {
+				t.Fatalf("create media: %v", err)
+			}
+		}
+		if media, err := tdb.repos.Media.GetByIDs([]string{"m1", "m2", "missing"}); err != nil || len(media) != 2 || media["m2"].OwnerID != alice.ID {
+			t.Errorf("media GetByIDs = %v, %v", media, err)
+		}
+		err = tdb.repos.Post.AddAttachments(first.ID, []models.PostAttachment{{MediaID: "m2", Position: 0}, {MediaID: "m1", Position: 1}})
+		if err != nil {
+			t.Fatalf("add attachments: %v", err)
+		}
+		attachments, err := tdb.repos.Post.GetAttachmentsByPostIDs(ids)
+		if err != nil || len(attachments[first.ID]) != 2 || attachments[first.ID][0].MediaID != "m2" || attachments[second.ID] != nil {
+			t.Errorf("GetAttachmentsByPostIDs = %v, %v", attachments, err)
+		}
+
+		poll := &models.Poll{PostID: second.ID, Options: []*models.PollOption{{Text: "Yes"}, {Text: "No"}}}
+		if err := tdb.repos.Poll.Create(poll); err != nil {
+			t.Fatalf("create poll: %v", err)
+		}
+		if err := tdb.repos.Poll.SetVotes(second.ID, alice.ID, []string{poll.Options[1].ID}); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+		polls, err := tdb.repos.Poll.GetByPostIDs(ids)
+		if err != nil || len(polls) != 1 || len(polls[second.ID].Options) != 2 || polls[second.ID].Options[1].VoteCount != 1 {
+			t.Errorf("polls GetByPostIDs = %v, %v", polls, err)
+		}
+		if counts, err := tdb.repos.Poll.CountVoters(ids); err != nil || counts[second.ID] != 1 {
+			t.Errorf("CountVoters = %v, %v", counts, err)
+		}
+		if votes, err := tdb.repos.Poll.GetUserVotes(ids, alice.ID); err != nil || !slices.Equal(votes[second.ID], []string{poll.Options[1].ID}) {
+			t.Errorf("GetUserVotes = %v, %v", votes, err)
+		}
+		if voters, err := tdb.repos.Poll.ListVoters(ids, 10); err != nil || len(voters[poll.Options[1].ID]) != 1 || voters[poll.Options[1].ID][0].UserID != alice.ID {
+			t.Errorf("ListVoters = %v, %v", voters, err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// readWriteDB sends queries to the read pool and statements that modify data
+// (Exec, Prepare and transactions) to the writer, which SQLite needs
+// serialized on a single connection.
+type readWriteDB struct {
+	reader *sql.DB
+	writer *sql.DB
+}
+
+// NewReadWriteDB combines a read pool and a write pool into one DBTX.
+// If both are the same pool it is returned unchanged.
+func NewReadWriteDB(reader, writer *sql.DB) DBTX {
+	if reader == writer {
+		return reader
+	}
+	return &readWriteDB{reader: reader, writer: writer}
+}
+
+// Begin starts a transaction on the writer
+func (d *readWriteDB) Begin() (*sql.Tx, error) {
+	return d.writer.Begin()
+}
+
+func (d *readWriteDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.writer.Exec(query, args...)
+}
+
+func (d *readWriteDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.writer.ExecContext(ctx, query, args...)
+}
+
+func (d *readWriteDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.reader.Query(query, args...)
+}
+
+func (d *readWriteDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.reader.QueryContext(ctx, query, args...)
+}
+
+func (d *readWriteDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.reader.QueryRow(query, args...)
+}
+
+func (d *readWriteDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.reader.QueryRowContext(ctx, query, args...)
+}
+
+func (d *readWriteDB) Prepare(query string) (*sql.Stmt, error) {
+	return d.writer.Prepare(query)
+}
@@ -0,0 +1,295 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HASANALI117/social-network/pkg/db"
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+// SearchRepository defines the interface for full-text search over posts,
+// comments, users and groups
+type SearchRepository interface {
+	// Search returns the entities of the given types that contain every term
+	// (the last characters of a word may be missing) and that requestingUserID
+	// may see, best match first
+	Search(terms []string, types []string, requestingUserID string, limit, offset int) ([]models.SearchResult, error)
+}
+
+// searchRepository implements SearchRepository. SQLite databases are searched
+// through the FTS5 tables of migration 000024, PostgreSQL databases through
+// the generated search_vector columns.
+type searchRepository struct {
+	db DBTX
+}
+
+// NewSearchRepository creates a new SearchRepository
+func NewSearchRepository(db DBTX) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// visiblePost restricts the posts aliased p to those the requesting user may
+// view: the rules of postRepository.List for posts outside groups, and group
+// membership for group posts. Drafts and scheduled posts are never found.
+// Takes the arguments of visiblePostArgs.
+const visiblePost = `p.status = 'published' AND (
+            (p.group_id IS NULL AND (
+                p.privacy = ?
+                OR p.user_id = ?
+                OR (p.privacy = ? AND EXISTS (SELECT 1 FROM followers f WHERE f.following_id = p.user_id AND f.follower_id = ? AND f.status = 'accepted'))
+                OR (p.privacy = ? AND EXISTS (SELECT 1 FROM post_audience pau WHERE pau.post_id = p.id AND pau.user_id = ?))
+            ))
+            OR (p.group_id IS NOT NULL AND EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = p.group_id AND gm.user_id = ?))
+        )`
+
+func visiblePostArgs(requestingUserID string) []interface{} {
+	return []interface{}{
+		models.PrivacyPublic,
+		requestingUserID, // Own posts
+		models.PrivacyAlmostPrivate,
+		requestingUserID, // Follower check
+		models.PrivacyPrivate,
+		requestingUserID, // Allowed user check
+		requestingUserID, // Group membership check
+	}
+}
+
+// visibleAboutMe holds for the users aliased u whose bio the requesting user
+// may see, as on their profile: public profiles, their own and those they
+// follow. Takes the arguments of visibleAboutMeArgs.
+const visibleAboutMe = `(u.is_private = ? OR u.id = ?
+            OR EXISTS (SELECT 1 FROM followers f WHERE f.following_id = u.id AND f.follower_id = ? AND f.status = 'accepted'))`
+
+func visibleAboutMeArgs(requestingUserID string) []interface{} {
+	return []interface{}{false, requestingUserID, requestingUserID}
+}
+
+// concatArgs joins lists of query arguments
+func concatArgs(lists ...[]interface{}) []interface{} {
+	var args []interface{}
+	for _, list := range lists {
+		args = append(args, list...)
+	}
+	return args
+}
+
+// Search runs one query per type and merges them by score
+func (r *searchRepository) Search(terms []string, types []string, requestingUserID string, limit, offset int) ([]models.SearchResult, error) {
+	if len(terms) == 0 || len(types) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	build := r.sqliteQuery
+	if dialectOf(r.db) == db.Postgres {
+		build = r.postgresQuery
+	}
+	var parts []string
+	var args []interface{}
+	for _, t := range types {
+		query, queryArgs, err := build(t, terms, requestingUserID)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, query)
+		args = append(args, queryArgs...)
+	}
+
+	query := `
+SELECT type, id, title, snippet, score, user_id, post_id, group_id, avatar_url, created_at
+FROM (` + strings.Join(parts, "\n    UNION ALL") + `
+) AS results
+ORDER BY score DESC, created_at DESC, id
+LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]models.SearchResult, 0)
+	for rows.Next() {
+		var result models.SearchResult
+		var createdAt string
+		err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.Title,
+			&result.Snippet,
+			&result.Score,
+			&result.UserID,
+			&result.PostID,
+			&result.GroupID,
+			&result.AvatarURL,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if result.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse search result timestamp: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+	return results, nil
+}
+
+// sqliteQuery builds the FTS5 query for one type. Scores are negated bm25
+// ranks, so higher is better; titles and names weigh more than bodies.
+func (r *searchRepository) sqliteQuery(searchType string, terms []string, requestingUserID string) (string, []interface{}, error) {
+	start, end := models.SearchMatchStart, models.SearchMatchEnd
+
+	// Every term must match, the last characters of each may be missing
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	match := strings.Join(quoted, " ")
+
+	switch searchType {
+	case models.SearchTypePost:
+		return `
+    SELECT 'post' AS type, p.id AS id,
+           highlight(posts_fts, 1, ?, ?) AS title,
+           snippet(posts_fts, 2, ?, ?, '…', 24) AS snippet,
+           -bm25(posts_fts, 0, 4.0, 1.0) AS score,
+           p.user_id AS user_id, '' AS post_id, COALESCE(p.group_id, '') AS group_id, '' AS avatar_url,
+           p.created_at AS created_at
+    FROM posts_fts
+    JOIN posts p ON p.id = posts_fts.post_id
+    WHERE posts_fts MATCH ? AND ` + visiblePost,
+			append([]interface{}{start, end, start, end, match}, visiblePostArgs(requestingUserID)...), nil
+	case models.SearchTypeComment:
+		return `
+    SELECT 'comment' AS type, c.id AS id,
+           '' AS title,
+           snippet(comments_fts, 1, ?, ?, '…', 24) AS snippet,
+           -bm25(comments_fts) AS score,
+           c.user_id AS user_id, c.post_id AS post_id, COALESCE(p.group_id, '') AS group_id, '' AS avatar_url,
+           c.created_at AS created_at
+    FROM comments_fts
+    JOIN comments c ON c.id = comments_fts.comment_id
+    JOIN posts p ON p.id = c.post_id
+    WHERE comments_fts MATCH ? AND ` + visiblePost,
+			append([]interface{}{start, end, match}, visiblePostArgs(requestingUserID)...), nil
+	case models.SearchTypeUser:
+		// The bio of a private profile is neither matched nor shown unless
+		// visibleAboutMe holds; such users are found by their names alone
+		return `
+    SELECT 'user' AS type, u.id AS id,
+           highlight(users_fts, 2, ?, ?) || ' ' || highlight(users_fts, 3, ?, ?) AS title,
+           CASE WHEN ` + visibleAboutMe + ` THEN snippet(users_fts, -1, ?, ?, '…', 24)
+                ELSE highlight(users_fts, 1, ?, ?) END AS snippet,
+           CASE WHEN ` + visibleAboutMe + ` THEN -bm25(users_fts, 0, 4.0, 4.0, 4.0, 1.0)
+                ELSE -bm25(users_fts, 0, 4.0, 4.0, 4.0, 0.0) END AS score,
+           '' AS user_id, '' AS post_id, '' AS group_id, COALESCE(u.avatar_url, '') AS avatar_url,
+           u.created_at AS created_at
+    FROM users_fts
+    JOIN users u ON u.id = users_fts.user_id
+    WHERE users_fts MATCH ? AND (` + visibleAboutMe + `
+        OR u.id IN (SELECT user_id FROM users_fts WHERE users_fts MATCH ?))`,
+			concatArgs(
+				[]interface{}{start, end, start, end},
+				visibleAboutMeArgs(requestingUserID), []interface{}{start, end, start, end},
+				visibleAboutMeArgs(requestingUserID),
+				[]interface{}{match},
+				visibleAboutMeArgs(requestingUserID), []interface{}{"{username first_name last_name} : (" + match + ")"},
+			), nil
+	case models.SearchTypeGroup:
+		return `
+    SELECT 'group' AS type, g.id AS id,
+           highlight(groups_fts, 1, ?, ?) AS title,
+           snippet(groups_fts, 2, ?, ?, '…', 24) AS snippet,
+           -bm25(groups_fts, 0, 4.0, 1.0) AS score,
+           '' AS user_id, '' AS post_id, '' AS group_id, COALESCE(g.avatar_url, '') AS avatar_url,
+           g.created_at AS created_at
+    FROM groups_fts
+    JOIN groups g ON g.id = groups_fts.group_id
+    WHERE groups_fts MATCH ?`,
+			[]interface{}{start, end, start, end, match}, nil
+	}
+	return "", nil, fmt.Errorf("unknown search type %q", searchType)
+}
+
+// postgresQuery builds the tsvector query for one type. Scores are ts_rank
+// values; the search_vector columns weigh titles and names more than bodies.
+func (r *searchRepository) postgresQuery(searchType string, terms []string, requestingUserID string) (string, []interface{}, error) {
+	titleOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, models.SearchMatchStart, models.SearchMatchEnd)
+	snippetOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=24, MinWords=8, MaxFragments=1, FragmentDelimiter=" … "`, models.SearchMatchStart, models.SearchMatchEnd)
+
+	// Every term must match as a prefix
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		term = strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(term)
+		quoted[i] = "'" + term + "':*"
+	}
+	tsquery := strings.Join(quoted, " & ")
+
+	switch searchType {
+	case models.SearchTypePost:
+		return `
+    SELECT 'post' AS type, p.id AS id,
+           ts_headline('simple', COALESCE(p.title, ''), q, ?) AS title,
+           ts_headline('simple', COALESCE(p.content, ''), q, ?) AS snippet,
+           ts_rank(p.search_vector, q) AS score,
+           p.user_id AS user_id, '' AS post_id, COALESCE(p.group_id, '') AS group_id, '' AS avatar_url,
+           p.created_at AS created_at
+    FROM posts p, to_tsquery('simple', ?) q
+    WHERE p.search_vector @@ q AND ` + visiblePost,
+			append([]interface{}{titleOptions, snippetOptions, tsquery}, visiblePostArgs(requestingUserID)...), nil
+	case models.SearchTypeComment:
+		return `
+    SELECT 'comment' AS type, c.id AS id,
+           '' AS title,
+           ts_headline('simple', c.content, q, ?) AS snippet,
+           ts_rank(c.search_vector, q) AS score,
+           c.user_id AS user_id, c.post_id AS post_id, COALESCE(p.group_id, '') AS group_id, '' AS avatar_url,
+           c.created_at AS created_at
+    FROM comments c
+    JOIN posts p ON p.id = c.post_id,
+         to_tsquery('simple', ?) q
+    WHERE c.search_vector @@ q AND ` + visiblePost,
+			append([]interface{}{snippetOptions, tsquery}, visiblePostArgs(requestingUserID)...), nil
+	case models.SearchTypeUser:
+		// As for SQLite, private bios are only searched when visibleAboutMe
+		// holds. search_vector covers every column, so it still narrows down
+		// the candidates through its index.
+		const names = `(setweight(to_tsvector('simple', COALESCE(u.username, '')), 'A') ||
+                setweight(to_tsvector('simple', COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')), 'A'))`
+		return `
+    SELECT 'user' AS type, u.id AS id,
+           ts_headline('simple', u.first_name || ' ' || u.last_name, q, ?) AS title,
+           CASE WHEN ` + visibleAboutMe + ` THEN ts_headline('simple', COALESCE(u.username, '') || ' ' || COALESCE(u.about_me, ''), q, ?)
+                ELSE ts_headline('simple', COALESCE(u.username, ''), q, ?) END AS snippet,
+           CASE WHEN ` + visibleAboutMe + ` THEN ts_rank(u.search_vector, q)
+                ELSE ts_rank(` + names + `, q) END AS score,
+           '' AS user_id, '' AS post_id, '' AS group_id, COALESCE(u.avatar_url, '') AS avatar_url,
+           u.created_at AS created_at
+    FROM users u, to_tsquery('simple', ?) q
+    WHERE u.search_vector @@ q AND (` + visibleAboutMe + ` OR ` + names + ` @@ q)`,
+			concatArgs(
+				[]interface{}{titleOptions},
+				visibleAboutMeArgs(requestingUserID), []interface{}{snippetOptions, snippetOptions},
+				visibleAboutMeArgs(requestingUserID),
+				[]interface{}{tsquery},
+				visibleAboutMeArgs(requestingUserID),
+			), nil
+	case models.SearchTypeGroup:
+		return `
+    SELECT 'group' AS type, g.id AS id,
+           ts_headline('simple', g.name, q, ?) AS title,
+           ts_headline('simple', COALESCE(g.description, ''), q, ?) AS snippet,
+           ts_rank(g.search_vector, q) AS score,
+           '' AS user_id, '' AS post_id, '' AS group_id, COALESCE(g.avatar_url, '') AS avatar_url,
+           g.created_at AS created_at
+    FROM groups g, to_tsquery('simple', ?) q
+    WHERE g.search_vector @@ q`,
+			[]interface{}{titleOptions, snippetOptions, tsquery}, nil
+	}
+	return "", nil, fmt.Errorf("unknown search type %q", searchType)
+}
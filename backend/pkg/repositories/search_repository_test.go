@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+func TestSearchUsersHidesPrivateAboutMe(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		alice := createUser(t, tdb, "alice")
+		bob := createUser(t, tdb, "bob")
+		carol := createUser(t, tdb, "carol")
+
+		alice.AboutMe = "Secret mountaineer"
+		alice.IsPrivate = true
+		if err := tdb.repos.User.Update(alice); err != nil {
+			t.Fatalf("update alice: %v", err)
+		}
+		carol.AboutMe = "Public mountaineer"
+		if err := tdb.repos.User.Update(carol); err != nil {
+			t.Fatalf("update carol: %v", err)
+		}
+
+		search := func(viewer *models.User, term string) map[string]models.SearchResult {
+			t.Helper()
+			results, err := tdb.repos.Search.Search([]string{term}, []string{models.SearchTypeUser}, viewer.ID, 10, 0)
+			if err != nil {
+				t.Fatalf("search %q as %s: %v", term, viewer.Username, err)
+			}
+			byID := make(map[string]models.SearchResult)
+			for _, r := range results {
+				byID[r.ID] = r
+			}
+			return byID
+		}
+
+		// Strangers only find the private profile by name
+		found := search(bob, "mountaineer")
+		if _, ok := found[alice.ID]; ok {
+			t.Error("a stranger matched the private bio")
+		}
+		if _, ok := found[carol.ID]; !ok {
+			t.Error("the public bio was not matched")
+		}
+		found = search(bob, "alice")
+		if r, ok := found[alice.ID]; !ok {
+			t.Error("the private profile was not found by name")
+		} else if strings.Contains(strings.ToLower(r.Snippet), "secret") {
+			t.Errorf("snippet %q shows the private bio", r.Snippet)
+		}
+
+		// The user themselves and accepted followers see the bio
+		if _, ok := search(alice, "mountaineer")[alice.ID]; !ok {
+			t.Error("the user did not match their own bio")
+		}
+		if err := tdb.repos.Follower.CreateFollowRequest(bob.ID, alice.ID); err != nil {
+			t.Fatalf("follow request: %v", err)
+		}
+		if _, ok := search(bob, "mountaineer")[alice.ID]; ok {
+			t.Error("a pending follower matched the private bio")
+		}
+		if err := tdb.repos.Follower.UpdateFollowStatus(bob.ID, alice.ID, "accepted"); err != nil {
+			t.Fatalf("accept follow: %v", err)
+		}
+		r, ok := search(bob, "mountaineer")[alice.ID]
+		if !ok {
+			t.Error("a follower did not match the private bio")
+		} else if !strings.Contains(strings.ToLower(r.Snippet), "secret") {
+			t.Errorf("follower's snippet %q lacks the bio", r.Snippet)
+		}
+	})
+}
@@ -24,11 +24,11 @@ type SessionRepository interface {
 
 // sessionRepository implements SessionRepository interface
 type sessionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewSessionRepository creates a new SessionRepository
-func NewSessionRepository(db *sql.DB) SessionRepository {
+func NewSessionRepository(db DBTX) SessionRepository {
 	return &sessionRepository{
 		db: db,
 	}
@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/google/uuid"
+)
+
+// TagRepository defines the interface for hashtag data access
+type TagRepository interface {
+	SetPostTags(postID string, names []string, postCreatedAt time.Time) error // Replaces the tags of a post, creating missing tags
+	GetPostTags(postIDs []string) (map[string][]string, error)                // By post ID, each alphabetical
+	Trending(since time.Time, limit int) ([]models.TrendingTag, error)        // Tags of public posts created after since
+
+	// Followed tags
+	Follow(userID, name string) error
+	Unfollow(userID, name string) error
+	IsFollowing(userID, name string) (bool, error)
+	ListFollowed(userID string) ([]models.FollowedTag, error) // Most recently followed first
+}
+
+// tagRepository implements TagRepository interface
+type tagRepository struct {
+	db DBTX
+}
+
+// NewTagRepository creates a new TagRepository
+func NewTagRepository(db DBTX) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// ensureTag returns the ID of the tag with the given name, creating it if needed
+func ensureTag(db DBTX, name string) (string, error) {
+	_, err := db.Exec(
+		"INSERT INTO tags (id, name, created_at) VALUES (?, ?, ?) ON CONFLICT (name) DO NOTHING",
+		uuid.New().String(), name, time.Now(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tag %q: %w", name, err)
+	}
+
+	var id string
+	if err := db.QueryRow("SELECT id FROM tags WHERE name = ?", name).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to get tag %q: %w", name, err)
+	}
+	return id, nil
+}
+
+// SetPostTags replaces the tags of a post. The post's creation time is copied
+// so tag pages and trending don't need to join posts to filter by time.
+func (r *tagRepository) SetPostTags(postID string, names []string, postCreatedAt time.Time) error {
+	// Joins the caller's transaction if there is one
+	return runInTx(r.db, func(tx DBTX) error {
+		if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID); err != nil {
+			return fmt.Errorf("failed to clear tags of post %s: %w", postID, err)
+		}
+		for _, name := range names {
+			tagID, err := ensureTag(tx, name)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(
+				"INSERT INTO post_tags (post_id, tag_id, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+				postID, tagID, postCreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to tag post %s with %q: %w", postID, name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetPostTags retrieves the tag names of several posts
+func (r *tagRepository) GetPostTags(postIDs []string) (map[string][]string, error) {
+	tags := make(map[string][]string)
+	if len(postIDs) == 0 {
+		return tags, nil
+	}
+	query := `
+        SELECT pt.post_id, t.name
+        FROM post_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.post_id IN (` + inPlaceholders(len(postIDs)) + `)
+        ORDER BY pt.post_id, t.name
+    `
+	rows, err := r.db.Query(query, stringArgs(postIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags of posts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID, name string
+		if err := rows.Scan(&postID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan post tag: %w", err)
+		}
+		tags[postID] = append(tags[postID], name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating post tags: %w", err)
+	}
+	return tags, nil
+}
+
+// Trending ranks the tags of public, non-group posts created after since.
+// Tags used by more people rank first, so one user posting the same tag
+// repeatedly can't push it to the top.
+func (r *tagRepository) Trending(since time.Time, limit int) ([]models.TrendingTag, error) {
+	query := `
+        SELECT t.name, COUNT(*) AS post_count, COUNT(DISTINCT p.user_id) AS author_count
+        FROM post_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        JOIN posts p ON p.id = pt.post_id
+        WHERE pt.created_at > ? AND p.privacy = ? AND p.group_id IS NULL
+        GROUP BY t.name
+        ORDER BY author_count DESC, post_count DESC, t.name
+        LIMIT ?
+    `
+	rows, err := r.db.Query(query, since, models.PrivacyPublic, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]models.TrendingTag, 0)
+	for rows.Next() {
+		var tag models.TrendingTag
+		if err := rows.Scan(&tag.Name, &tag.PostCount, &tag.AuthorCount); err != nil {
+			return nil, fmt.Errorf("failed to scan trending tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trending tags: %w", err)
+	}
+	return tags, nil
+}
+
+// Follow makes the user follow a tag, creating the tag if nobody used it yet.
+// Following a tag twice is not an error.
+func (r *tagRepository) Follow(userID, name string) error {
+	return runInTx(r.db, func(tx DBTX) error {
+		tagID, err := ensureTag(tx, name)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			"INSERT INTO tag_follows (user_id, tag_id, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+			userID, tagID, time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to follow tag %q: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// Unfollow removes a followed tag. Unfollowing a tag that isn't followed is not an error.
+func (r *tagRepository) Unfollow(userID, name string) error {
+	_, err := r.db.Exec(
+		"DELETE FROM tag_follows WHERE user_id = ? AND tag_id IN (SELECT id FROM tags WHERE name = ?)",
+		userID, name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow tag %q: %w", name, err)
+	}
+	return nil
+}
+
+// IsFollowing checks whether the user follows a tag
+func (r *tagRepository) IsFollowing(userID, name string) (bool, error) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM tag_follows tf
+            JOIN tags t ON t.id = tf.tag_id
+            WHERE tf.user_id = ? AND t.name = ?
+        )
+    `
+	var following bool
+	if err := r.db.QueryRow(query, userID, name).Scan(&following); err != nil {
+		return false, fmt.Errorf("failed to check tag follow: %w", err)
+	}
+	return following, nil
+}
+
+// ListFollowed retrieves the tags a user follows
+func (r *tagRepository) ListFollowed(userID string) ([]models.FollowedTag, error) {
+	query := `
+        SELECT t.name, tf.created_at
+        FROM tag_follows tf
+        JOIN tags t ON t.id = tf.tag_id
+        WHERE tf.user_id = ?
+        ORDER BY tf.created_at DESC, t.name
+    `
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query followed tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]models.FollowedTag, 0)
+	for rows.Next() {
+		var tag models.FollowedTag
+		var followedAt string
+		if err := rows.Scan(&tag.Name, &followedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan followed tag: %w", err)
+		}
+		if tag.FollowedAt, err = parseTimestamp(followedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse tag follow timestamp: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating followed tags: %w", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+// TimelineRepository defines the interface for precomputed home timelines.
+// Entries of deleted posts and users are removed by foreign keys.
+type TimelineRepository interface {
+	// FanOut (re)builds the timeline entries of a non-group post from its
+	// current privacy and allowed users, and marks it as fanned out
+	FanOut(postID string) error
+	// AddAuthor copies the fanned-out posts of authorID that userID may see
+	// to userID's timeline, e.g. when a follow is accepted
+	AddAuthor(userID, authorID string) error
+	// RemoveAuthor drops authorID's posts from userID's timeline, e.g. on
+	// unfollow. Private posts userID is explicitly allowed to see stay.
+	RemoveAuthor(userID, authorID string) error
+}
+
+// timelineRepository implements TimelineRepository interface
+type timelineRepository struct {
+	db DBTX
+}
+
+// NewTimelineRepository creates a new TimelineRepository
+func NewTimelineRepository(db DBTX) TimelineRepository {
+	return &timelineRepository{db: db}
+}
+
+// FanOut copies a post to the timelines of its author, its accepted
+// followers and, for private posts, only the followers and other users on
+// its allowed list. Group posts are left alone.
+func (r *timelineRepository) FanOut(postID string) error {
+	// Joins the caller's transaction if there is one
+	return runInTx(r.db, func(tx DBTX) error {
+		return fanOutPost(tx, postID)
+	})
+}
+
+// refreshTimelines rebuilds the timeline entries of a post after its
+// audience changed. Posts that weren't fanned out have no entries to fix.
+func refreshTimelines(tx DBTX, postID string) error {
+	var fannedOut bool
+	if err := tx.QueryRow("SELECT fanned_out FROM posts WHERE id = ?", postID).Scan(&fannedOut); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to check fan-out of post %s: %w", postID, err)
+	}
+	if !fannedOut {
+		return nil
+	}
+	return fanOutPost(tx, postID)
+}
+
+// fanOutPost implements FanOut inside a transaction
+func fanOutPost(tx DBTX, postID string) error {
+	if _, err := tx.Exec("DELETE FROM timelines WHERE post_id = ?", postID); err != nil {
+		return fmt.Errorf("failed to clear timeline entries of post %s: %w", postID, err)
+	}
+
+	statements := []string{
+		// Author
+		`INSERT INTO timelines (user_id, post_id, author_id)
+            SELECT p.user_id, p.id, p.user_id FROM posts p
+            WHERE p.id = ? AND p.group_id IS NULL AND p.status = 'published'
+            ON CONFLICT DO NOTHING`,
+		// Followers, limited to the audience of private posts
+		`INSERT INTO timelines (user_id, post_id, author_id)
+            SELECT f.follower_id, p.id, p.user_id FROM posts p
+            JOIN followers f ON f.following_id = p.user_id AND f.status = 'accepted'
+            WHERE p.id = ? AND p.group_id IS NULL AND p.status = 'published'
+              AND (p.privacy <> ? OR EXISTS (
+                  SELECT 1 FROM post_audience pau WHERE pau.post_id = p.id AND pau.user_id = f.follower_id))
+            ON CONFLICT DO NOTHING`,
+		// Audience of private posts, followers or not
+		`INSERT INTO timelines (user_id, post_id, author_id)
+            SELECT pau.user_id, p.id, p.user_id FROM posts p
+            JOIN post_audience pau ON pau.post_id = p.id
+            WHERE p.id = ? AND p.group_id IS NULL AND p.status = 'published' AND p.privacy = ?
+            ON CONFLICT DO NOTHING`,
+	}
+	argSets := [][]interface{}{
+		{postID},
+		{postID, models.PrivacyPrivate},
+		{postID, models.PrivacyPrivate},
+	}
+	for i, statement := range statements {
+		if _, err := tx.Exec(statement, argSets[i]...); err != nil {
+			return fmt.Errorf("failed to fan out post %s: %w", postID, err)
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE posts SET fanned_out = ? WHERE id = ? AND group_id IS NULL", true, postID); err != nil {
+		return fmt.Errorf("failed to mark post %s as fanned out: %w", postID, err)
+	}
+	return nil
+}
+
+// AddAuthor backfills a timeline with an author's fanned-out posts. Posts
+// that weren't fanned out are found at read time and need no entries.
+func (r *timelineRepository) AddAuthor(userID, authorID string) error {
+	query := `
+        INSERT INTO timelines (user_id, post_id, author_id)
+        SELECT ?, p.id, p.user_id FROM posts p
+        WHERE p.user_id = ? AND p.group_id IS NULL AND p.fanned_out = ?
+          AND (p.privacy <> ? OR EXISTS (
+              SELECT 1 FROM post_audience pau WHERE pau.post_id = p.id AND pau.user_id = ?))
+        ON CONFLICT DO NOTHING
+    `
+	if _, err := r.db.Exec(query, userID, authorID, true, models.PrivacyPrivate, userID); err != nil {
+		return fmt.Errorf("failed to add posts of %s to timeline of %s: %w", authorID, userID, err)
+	}
+	return nil
+}
+
+// RemoveAuthor drops an author's posts from a timeline, keeping private posts
+// the user is in the audience of
+func (r *timelineRepository) RemoveAuthor(userID, authorID string) error {
+	query := `
+        DELETE FROM timelines
+        WHERE user_id = ? AND author_id = ?
+          AND NOT EXISTS (
+              SELECT 1 FROM post_audience pau
+              WHERE pau.post_id = timelines.post_id AND pau.user_id = timelines.user_id)
+    `
+	if _, err := r.db.Exec(query, userID, authorID); err != nil {
+		return fmt.Errorf("failed to remove posts of %s from timeline of %s: %w", authorID, userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/HASANALI117/social-network/pkg/db"
+)
+
+// DBTX is the subset of *sql.DB and *sql.Tx used by the repositories.
+// Repositories hold a DBTX instead of a *sql.DB so the same implementation
+// can run either directly against the pool or inside a transaction.
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// UnitOfWork runs several repository calls atomically.
+type UnitOfWork interface {
+	// Do begins a transaction and passes fn a set of repositories bound to it.
+	// The transaction is committed if fn returns nil and rolled back otherwise.
+	Do(fn func(repos *Repositories) error) error
+}
+
+// unitOfWork implements UnitOfWork on top of a *sql.DB
+type unitOfWork struct {
+	db      *sql.DB
+	dialect db.Dialect
+}
+
+// NewUnitOfWork creates a new UnitOfWork
+func NewUnitOfWork(conn *sql.DB, dialect db.Dialect) UnitOfWork {
+	return &unitOfWork{db: conn, dialect: dialect}
+}
+
+// Do runs fn inside a single sql.Tx
+func (u *unitOfWork) Do(fn func(repos *Repositories) error) error {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin unit of work: %w", err)
+	}
+	defer tx.Rollback() // No-op once the transaction has been committed
+
+	if err := fn(InitRepositories(tx, u.dialect)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit unit of work: %w", err)
+	}
+	return nil
+}
+
+// txBeginner is implemented by the handles runInTx can open a transaction on
+// (*sql.DB and readWriteDB)
+type txBeginner interface {
+	Begin() (*sql.Tx, error)
+}
+
+// runInTx runs fn inside a transaction. If db is already a transaction (the
+// repository was handed out by a UnitOfWork), fn joins it and committing or
+// rolling back is left to the owner of that transaction.
+func runInTx(conn DBTX, fn func(tx DBTX) error) error {
+	dialect := dialectOf(conn)
+	if d, ok := conn.(*dialectDB); ok {
+		conn = d.conn
+	}
+
+	switch c := conn.(type) {
+	case *sql.Tx:
+		return fn(withDialect(c, dialect))
+	case txBeginner:
+		tx, err := c.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if err := fn(withDialect(tx, dialect)); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported database handle %T", conn)
+	}
+}
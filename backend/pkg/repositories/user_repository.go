@@ -4,12 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/HASANALI117/social-network/pkg/models"
 	"github.com/HASANALI117/social-network/pkg/types"
-	"github.com/mattn/go-sqlite3" // Import sqlite3 driver for error checking
 )
 
 var (
@@ -21,6 +19,7 @@ var (
 type UserRepository interface {
 	Create(user *models.User) error
 	GetByID(id string) (*models.User, error)
+	GetByIDs(ids []string) (map[string]*models.User, error) // By ID; users that don't exist are left out
 	GetByUsername(username string) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	Update(user *models.User) error
@@ -32,11 +31,11 @@ type UserRepository interface {
 
 // userRepository implements UserRepository interface
 type userRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewUserRepository creates a new UserRepository
-func NewUserRepository(db *sql.DB) UserRepository {
+func NewUserRepository(db DBTX) UserRepository {
 	return &userRepository{
 		db: db,
 	}
@@ -44,8 +43,8 @@ func NewUserRepository(db *sql.DB) UserRepository {
 
 func (r *userRepository) Create(user *models.User) error {
 	query := `
-INSERT INTO users (id, username, email, password_hash, first_name, last_name, avatar_url, about_me, birth_date, is_private, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO users (id, username, email, password_hash, first_name, last_name, avatar_url, avatar_id, about_me, birth_date, is_private, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 	// Added is_private to INSERT
 
@@ -58,6 +57,7 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		user.FirstName,
 		user.LastName,
 		user.AvatarURL,
+		nullIfEmpty(user.AvatarID),
 		user.AboutMe,
 		user.BirthDate,
 		user.IsPrivate, // Added is_private value
@@ -66,15 +66,12 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	)
 
 	if err != nil {
-		var sqliteErr sqlite3.Error
-		if errors.As(err, &sqliteErr) {
-			// Check for unique constraint violation (code 19 -> SQLITE_CONSTRAINT)
-			if sqliteErr.Code == sqlite3.ErrConstraint && strings.Contains(sqliteErr.Error(), "UNIQUE constraint failed: users.email") {
-				return ErrUserAlreadyExists // Return specific error for duplicate email
-			}
-			if sqliteErr.Code == sqlite3.ErrConstraint && strings.Contains(sqliteErr.Error(), "UNIQUE constraint failed: users.username") {
-				return ErrUserAlreadyExists // Could define a more specific ErrUsernameExists
-			}
+		// Check for unique constraint violation
+		if isUniqueViolation(err, "users.email") {
+			return ErrUserAlreadyExists // Return specific error for duplicate email
+		}
+		if isUniqueViolation(err, "users.username") {
+			return ErrUserAlreadyExists // Could define a more specific ErrUsernameExists
 		}
 		// Return generic error for other DB issues
 		return fmt.Errorf("failed to create user: %w", err)
@@ -85,7 +82,7 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 
 func (r *userRepository) GetByID(id string) (*models.User, error) {
 	query := `
-SELECT id, username, email, password_hash, first_name, last_name, avatar_url, about_me, birth_date, is_private, created_at, updated_at
+SELECT id, username, email, password_hash, first_name, last_name, avatar_url, COALESCE(avatar_id, ''), about_me, birth_date, is_private, created_at, updated_at
 FROM users
 WHERE id = ?
 `
@@ -100,6 +97,7 @@ WHERE id = ?
 		&user.FirstName,
 		&user.LastName,
 		&user.AvatarURL,
+		&user.AvatarID,
 		&user.AboutMe,
 		&user.BirthDate,
 		&user.IsPrivate, // Added is_private scan target
@@ -117,9 +115,54 @@ WHERE id = ?
 	return &user, nil
 }
 
+// GetByIDs retrieves several users at once
+func (r *userRepository) GetByIDs(ids []string) (map[string]*models.User, error) {
+	users := make(map[string]*models.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+	query := `
+SELECT id, username, email, password_hash, first_name, last_name, avatar_url, COALESCE(avatar_id, ''), about_me, birth_date, is_private, created_at, updated_at
+FROM users
+WHERE id IN (` + inPlaceholders(len(ids)) + `)
+`
+	rows, err := r.db.Query(query, stringArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by id: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.FirstName,
+			&user.LastName,
+			&user.AvatarURL,
+			&user.AvatarID,
+			&user.AboutMe,
+			&user.BirthDate,
+			&user.IsPrivate,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users[user.ID] = &user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+	return users, nil
+}
+
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	query := `
-SELECT id, username, email, password_hash, first_name, last_name, avatar_url, about_me, birth_date, is_private, created_at, updated_at
+SELECT id, username, email, password_hash, first_name, last_name, avatar_url, COALESCE(avatar_id, ''), about_me, birth_date, is_private, created_at, updated_at
 FROM users
 WHERE username = ?
 `
@@ -134,6 +177,7 @@ WHERE username = ?
 		&user.FirstName,
 		&user.LastName,
 		&user.AvatarURL,
+		&user.AvatarID,
 		&user.AboutMe,
 		&user.BirthDate,
 		&user.IsPrivate, // Added is_private scan target
@@ -153,7 +197,7 @@ WHERE username = ?
 
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	query := `
-SELECT id, username, email, password_hash, first_name, last_name, avatar_url, about_me, birth_date, is_private, created_at, updated_at
+SELECT id, username, email, password_hash, first_name, last_name, avatar_url, COALESCE(avatar_id, ''), about_me, birth_date, is_private, created_at, updated_at
 FROM users
 WHERE email = ?
 `
@@ -168,6 +212,7 @@ WHERE email = ?
 		&user.FirstName,
 		&user.LastName,
 		&user.AvatarURL,
+		&user.AvatarID,
 		&user.AboutMe,
 		&user.BirthDate,
 		&user.IsPrivate, // Added is_private scan target
@@ -192,7 +237,7 @@ func (r *userRepository) Update(user *models.User) error {
 	// is_private is included here, but might be better handled by UpdatePrivacy.
 	query := `
 UPDATE users
-SET username = ?, email = ?, password_hash = ?, first_name = ?, last_name = ?, avatar_url = ?, about_me = ?, birth_date = ?, is_private = ?, updated_at = ?
+SET username = ?, email = ?, password_hash = ?, first_name = ?, last_name = ?, avatar_url = ?, avatar_id = ?, about_me = ?, birth_date = ?, is_private = ?, updated_at = ?
 WHERE id = ?
 `
 	// Added is_private to SET clause
@@ -205,6 +250,7 @@ WHERE id = ?
 		user.FirstName,
 		user.LastName,
 		user.AvatarURL,
+		nullIfEmpty(user.AvatarID),
 		user.AboutMe,
 		user.BirthDate,
 		user.IsPrivate, // Added is_private value
@@ -214,12 +260,9 @@ WHERE id = ?
 
 	if err != nil {
 		// Add check for constraint violations if email/username are updated
-		var sqliteErr sqlite3.Error
-		if errors.As(err, &sqliteErr) {
-			if sqliteErr.Code == sqlite3.ErrConstraint {
-				// Could check specific constraint message if needed
-				return ErrUserAlreadyExists // Or a more specific error
-			}
+		if isConstraintViolation(err) {
+			// Could check specific constraint if needed
+			return ErrUserAlreadyExists // Or a more specific error
 		}
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -259,7 +302,7 @@ func (r *userRepository) Delete(id string) error {
 
 func (r *userRepository) List(limit, offset int) ([]*models.User, error) {
 	query := `
-SELECT id, username, email, password_hash, first_name, last_name, avatar_url, about_me, birth_date, is_private, created_at, updated_at
+SELECT id, username, email, password_hash, first_name, last_name, avatar_url, COALESCE(avatar_id, ''), about_me, birth_date, is_private, created_at, updated_at
 FROM users
 ORDER BY created_at DESC
 LIMIT ? OFFSET ?
@@ -283,6 +326,7 @@ LIMIT ? OFFSET ?
 			&user.FirstName,
 			&user.LastName,
 			&user.AvatarURL,
+			&user.AvatarID,
 			&user.AboutMe,
 			&user.BirthDate,
 			&user.IsPrivate, // Added is_private scan target
@@ -331,10 +375,11 @@ WHERE id = ?
 
 // SearchUsers searches for users by username, first name, or last name.
 func (r *userRepository) SearchUsers(query string, limit int) ([]types.UserSearchResultDTO, error) {
+	like := dialectOf(r.db).Like() // Case-insensitive match on every dialect
 	sqlQuery := `
 SELECT id, username, first_name, last_name, avatar_url
 FROM users
-WHERE username LIKE ? OR first_name LIKE ? OR last_name LIKE ?
+WHERE username ` + like + ` ? OR first_name ` + like + ` ? OR last_name ` + like + ` ?
 LIMIT ?
 `
 	searchTerm := "%" + query + "%"
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/google/uuid"
+)
+
+func TestUserRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.User
+		alice := createUser(t, tdb, "alice")
+
+		lookups := []struct {
+			name string
+			get  func() (*models.User, error)
+		}{
+			{"by id", func() (*models.User, error) { return repo.GetByID(alice.ID) }},
+			{"by username", func() (*models.User, error) { return repo.GetByUsername("alice") }},
+			{"by email", func() (*models.User, error) { return repo.GetByEmail("alice@example.com") }},
+		}
+		for _, l := range lookups {
+			got, err := l.get()
+			if err != nil {
+				t.Fatalf("get %s: %v", l.name, err)
+			}
+			if got.ID != alice.ID || got.Username != "alice" || got.FirstName != "Alice" || got.IsPrivate {
+				t.Errorf("get %s = %+v", l.name, got)
+			}
+		}
+
+		dup := *alice
+		dup.ID = uuid.NewString()
+		dup.Username = "alice2"
+		if err := repo.Create(&dup); !errors.Is(err, ErrUserAlreadyExists) {
+			t.Errorf("duplicate email: got %v, want ErrUserAlreadyExists", err)
+		}
+		dup.Username, dup.Email = "alice", "alice2@example.com"
+		if err := repo.Create(&dup); !errors.Is(err, ErrUserAlreadyExists) {
+			t.Errorf("duplicate username: got %v, want ErrUserAlreadyExists", err)
+		}
+
+		alice.AboutMe = "Hi"
+		alice.IsPrivate = true
+		if err := repo.Update(alice); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, err := repo.GetByID(alice.ID)
+		if err != nil {
+			t.Fatalf("get after update: %v", err)
+		}
+		if got.AboutMe != "Hi" || !got.IsPrivate {
+			t.Errorf("after update = %+v", got)
+		}
+		if got.UpdatedAt.Before(alice.CreatedAt.Add(-time.Second)) {
+			t.Errorf("updated_at %v not refreshed", got.UpdatedAt)
+		}
+
+		if err := repo.UpdatePrivacy(alice.ID, false); err != nil {
+			t.Fatalf("update privacy: %v", err)
+		}
+		if got, _ := repo.GetByID(alice.ID); got == nil || got.IsPrivate {
+			t.Errorf("after UpdatePrivacy(false) = %+v", got)
+		}
+
+		if err := repo.Delete(alice.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repo.GetByID(alice.ID); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("get deleted: got %v, want ErrUserNotFound", err)
+		}
+		if _, err := repo.GetByUsername("nobody"); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("get unknown username: got %v, want ErrUserNotFound", err)
+		}
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, tdb *testDB) {
+		repo := tdb.repos.Session
+		alice := createUser(t, tdb, "alice")
+
+		active := &models.Session{Token: "active", UserID: alice.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		expired := &models.Session{Token: "expired", UserID: alice.ID, ExpiresAt: time.Now().Add(-time.Hour)}
+		for _, s := range []*models.Session{active, expired} {
+			if err := repo.Create(s); err != nil {
+				t.Fatalf("create %s: %v", s.Token, err)
+			}
+		}
+
+		got, err := repo.GetByToken("active")
+		if err != nil {
+			t.Fatalf("get active: %v", err)
+		}
+		// PostgreSQL keeps microseconds
+		if got.UserID != alice.ID || got.ExpiresAt.Sub(active.ExpiresAt).Abs() >= time.Microsecond {
+			t.Errorf("get active = %+v, want user %s expiring at %v", got, alice.ID, active.ExpiresAt)
+		}
+		if _, err := repo.GetByToken("expired"); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("get expired: got %v, want ErrSessionNotFound", err)
+		}
+
+		if err := repo.CleanExpired(); err != nil {
+			t.Fatalf("clean expired: %v", err)
+		}
+		if err := repo.DeleteByToken("active"); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repo.GetByToken("active"); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("get deleted: got %v, want ErrSessionNotFound", err)
+		}
+	})
+}
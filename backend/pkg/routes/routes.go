@@ -1,24 +1,29 @@
 package routes
 
 import (
-	"database/sql"
+	"context"
 	"net/http"
 
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/db"
 	"github.com/HASANALI117/social-network/pkg/handlers"
 	"github.com/HASANALI117/social-network/pkg/httperr"
 	"github.com/HASANALI117/social-network/pkg/repositories" // Import repositories for Init
 	"github.com/HASANALI117/social-network/pkg/services"     // Import services for Init
+	"github.com/HASANALI117/social-network/pkg/storage"
 )
 
 // Setup sets up all API routes
-func Setup(dbConn *sql.DB) http.Handler {
+func Setup(dbConn *db.DB, store storage.BlobStore, cfg *config.Config) http.Handler {
 	// Initialize Repositories
-	repos := repositories.InitRepositories(dbConn) // Initialize all repositories
+	conn := repositories.NewReadWriteDB(dbConn.DB, dbConn.Writer)    // Reads use the pool, writes the serialized writer
+	repos := repositories.InitRepositories(conn, dbConn.Dialect)     // Initialize all repositories
+	uow := repositories.NewUnitOfWork(dbConn.Writer, dbConn.Dialect) // Used by services for multi-step writes
 
 	// Initialize Websocket Hub first, as it's needed by NotificationService
 	// handlers.InitWebsocket stores the hub in handlers.WebSocketHub
 	// Initialize Websocket Hub with GroupRepository
-	handlers.InitWebsocket(repos.ChatMessage, repos.Group) // Pass GroupRepository
+	handlers.InitWebsocket(repos.ChatMessage, repos.Group, repos.Media) // Pass GroupRepository
 	// If GroupService is truly needed for Hub's core (not just chat), this needs re-evaluation or a different Hub structure.
 	// For now, assuming NotificationService needs the Hub (RealTimeNotifier) and GroupService is for chat features within Hub.
 	// Let's assume for now that GroupService is not a direct dependency for the Hub's construction for notifications.
@@ -65,11 +70,22 @@ func Setup(dbConn *sql.DB) http.Handler {
 	// handlers.InitWebsocket(repos.ChatMessage, tempGroupService) // Pass the temporary GroupService - No longer needed as Hub uses GroupRepository
 
 	// Now initialize all services, including the "final" GroupService and NotificationService
-	allServices := services.InitServices(repos, handlers.WebSocketHub) // Pass the initialized Hub
+	allServices := services.InitServices(repos, uow, handlers.WebSocketHub, dbConn, store, cfg) // Pass the initialized Hub
+
+	// Process uploaded images in the background
+	allServices.Media.StartProcessor(context.Background())
+	// Delete media nothing uses anymore every MEDIA_GC_INTERVAL (0 disables it)
+	allServices.MediaGC.Start(context.Background())
+	// Publish scheduled posts every POST_PUBLISH_INTERVAL (0 disables it)
+	allServices.Post.StartScheduler(context.Background(), cfg.Posts.PublishInterval)
+	// Fetch previews of links found in posts and messages in the background
+	allServices.LinkPreview.StartFetcher(context.Background())
+	// Chat messages get their link previews before they are delivered
+	handlers.WebSocketHub.SetLinkPreviewer(allServices.LinkPreview)
 
 	// --- Dependency Injection (Handlers) ---
 	// Repositories and Services are already initialized above
-	controllers := handlers.InitHandlers(allServices) // Initialize all handlers with all services
+	controllers := handlers.InitHandlers(allServices, cfg) // Initialize all handlers with all services
 	// --- End Dependency Injection ---
 
 	mux := http.NewServeMux()
@@ -112,5 +128,18 @@ func Setup(dbConn *sql.DB) http.Handler {
 	mux.Handle("/api/notifications", httperr.ErrorHandler(controllers.Notification.ServeHTTP))
 	mux.Handle("/api/notifications/", httperr.ErrorHandler(controllers.Notification.ServeHTTP))
 
+	// Media routes: POST /api/media uploads, GET /api/media/{id} serves
+	mux.Handle("/api/media", httperr.ErrorHandler(controllers.Media.ServeHTTP))
+	mux.Handle("/api/media/", httperr.ErrorHandler(controllers.Media.ServeHTTP))
+
+	// Search route: GET /api/search?q=&type=
+	mux.HandleFunc("/api/search", httperr.ErrorHandler(controllers.Search.Search))
+
+	// Tag routes: trending, followed tags, tag pages and tag follows
+	mux.Handle("/api/tags/", httperr.ErrorHandler(controllers.Tag.ServeHTTP))
+
+	// Admin routes (restricted to ADMIN_USER_IDS)
+	mux.Handle("/api/admin/", httperr.ErrorHandler(controllers.Admin.ServeHTTP))
+
 	return mux
 }
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/types"
+)
+
+// maxAudienceListNameLength limits audience list names, in characters
+const maxAudienceListNameLength = 50
+
+var (
+	ErrInvalidAudienceListName = errors.New("invalid audience list name")
+	ErrNotFollower             = errors.New("user is not a follower")
+)
+
+// AudienceListResponse is the DTO for an audience list with its members
+type AudienceListResponse struct {
+	*models.AudienceList
+	Members []types.UserBasicInfo `json:"members"`
+}
+
+// AudienceListService defines the interface for audience list business logic.
+// Lists belong to a user and are only visible to them.
+type AudienceListService interface {
+	Create(userID, name string) (*models.AudienceList, error)
+	List(userID string) ([]*models.AudienceList, error)
+	Get(userID, listID string) (*AudienceListResponse, error)
+	// Delete removes a list. Posts shared with it stay private to their allowed users.
+	Delete(userID, listID string) error
+
+	// AddMember adds one of the user's followers to a list, granting them
+	// access to the posts already shared with it
+	AddMember(userID, listID, memberID string) error
+	RemoveMember(userID, listID, memberID string) error
+}
+
+// audienceListService implements AudienceListService
+type audienceListService struct {
+	audienceListRepo repositories.AudienceListRepository
+	followerRepo     repositories.FollowerRepository
+	mentionService   MentionService // Keeps mentions in posts shared with a list in line with its members
+}
+
+// NewAudienceListService creates a new AudienceListService
+func NewAudienceListService(audienceListRepo repositories.AudienceListRepository, followerRepo repositories.FollowerRepository, mentionService MentionService) AudienceListService {
+	return &audienceListService{
+		audienceListRepo: audienceListRepo,
+		followerRepo:     followerRepo,
+		mentionService:   mentionService,
+	}
+}
+
+// Create adds a named audience list for the user
+func (s *audienceListService) Create(userID, name string) (*models.AudienceList, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxAudienceListNameLength {
+		return nil, fmt.Errorf("%w: names have 1 to %d characters", ErrInvalidAudienceListName, maxAudienceListNameLength)
+	}
+	list := &models.AudienceList{UserID: userID, Name: name}
+	if err := s.audienceListRepo.Create(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// List returns the user's audience lists by name
+func (s *audienceListService) List(userID string) ([]*models.AudienceList, error) {
+	return s.audienceListRepo.ListByUser(userID)
+}
+
+// Get returns one of the user's audience lists with its members
+func (s *audienceListService) Get(userID, listID string) (*AudienceListResponse, error) {
+	list, err := s.audienceListRepo.GetByID(userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.audienceListRepo.ListMembers(listID)
+	if err != nil {
+		return nil, err
+	}
+	return &AudienceListResponse{AudienceList: list, Members: members}, nil
+}
+
+// Delete removes one of the user's audience lists
+func (s *audienceListService) Delete(userID, listID string) error {
+	postIDs, err := s.audienceListRepo.ListPostIDs(listID)
+	if err != nil {
+		return err
+	}
+	if err := s.audienceListRepo.Delete(userID, listID); err != nil {
+		return err
+	}
+	refreshPostMentions(s.mentionService, postIDs)
+	return nil
+}
+
+// AddMember adds a follower of the user to one of their lists
+func (s *audienceListService) AddMember(userID, listID, memberID string) error {
+	if _, err := s.audienceListRepo.GetByID(userID, listID); err != nil {
+		return err
+	}
+	follow, err := s.followerRepo.FindFollow(memberID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check follow status: %w", err)
+	}
+	if follow == nil || follow.Status != "accepted" {
+		return ErrNotFollower
+	}
+	if err := s.audienceListRepo.AddMember(listID, memberID); err != nil {
+		return err
+	}
+	s.refreshListMentions(listID)
+	return nil
+}
+
+// RemoveMember removes a user from one of the user's lists
+func (s *audienceListService) RemoveMember(userID, listID, memberID string) error {
+	if _, err := s.audienceListRepo.GetByID(userID, listID); err != nil {
+		return err
+	}
+	if err := s.audienceListRepo.RemoveMember(listID, memberID); err != nil {
+		return err
+	}
+	s.refreshListMentions(listID)
+	return nil
+}
+
+// refreshListMentions updates the mentions of the posts shared with a list
+// after its members changed
+func (s *audienceListService) refreshListMentions(listID string) {
+	postIDs, err := s.audienceListRepo.ListPostIDs(listID)
+	if err != nil {
+		log.Printf("Error listing posts of audience list %s: %v", listID, err)
+		return
+	}
+	refreshPostMentions(s.mentionService, postIDs)
+}
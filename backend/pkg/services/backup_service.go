@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/db"
+)
+
+// BackupService defines the interface for database backup operations
+type BackupService interface {
+	CreateBackup(ctx context.Context) (*db.BackupInfo, error)
+	ListBackups() ([]db.BackupInfo, error)
+}
+
+// backupService implements BackupService on top of the live database
+type backupService struct {
+	database *db.DB
+	cfg      config.BackupConfig
+}
+
+// NewBackupService creates a new BackupService
+func NewBackupService(database *db.DB, cfg config.BackupConfig) BackupService {
+	return &backupService{
+		database: database,
+		cfg:      cfg,
+	}
+}
+
+// CreateBackup takes an online backup using the configured directory, compression and retention
+func (s *backupService) CreateBackup(ctx context.Context) (*db.BackupInfo, error) {
+	return s.database.Backup(ctx, s.cfg)
+}
+
+// ListBackups lists existing backups, newest first
+func (s *backupService) ListBackups() ([]db.BackupInfo, error) {
+	return db.ListBackups(s.cfg.Dir)
+}
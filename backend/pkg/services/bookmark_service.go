@@ -0,0 +1,147 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+)
+
+// maxCollectionNameLength limits bookmark collection names, in characters
+const maxCollectionNameLength = 50
+
+var ErrInvalidCollectionName = errors.New("invalid bookmark collection name")
+
+// BookmarkResponse is the DTO for a bookmark. Post is left out and Unavailable
+// set when the user can no longer see the post.
+type BookmarkResponse struct {
+	PostID       string        `json:"post_id"`
+	CollectionID *string       `json:"collection_id,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Post         *PostResponse `json:"post,omitempty"`
+	Unavailable  bool          `json:"unavailable,omitempty"`
+}
+
+// BookmarkService defines the interface for bookmark business logic
+type BookmarkService interface {
+	// Bookmark saves a post the user can see, optionally in one of their
+	// collections. Bookmarking a post again moves it to the given collection.
+	Bookmark(userID, postID, collectionID string) (*BookmarkResponse, error)
+	Unbookmark(userID, postID string) error
+	// List returns the user's bookmarks, newest first, re-checking that each post is still visible
+	List(userID, collectionID string, page models.Page) ([]*BookmarkResponse, models.PageInfo, error)
+
+	CreateCollection(userID, name string) (*models.BookmarkCollection, error)
+	ListCollections(userID string) ([]*models.BookmarkCollection, error)
+	DeleteCollection(userID, collectionID string) error
+}
+
+// bookmarkService implements BookmarkService
+type bookmarkService struct {
+	bookmarkRepo repositories.BookmarkRepository
+	postService  PostService // Applies the visibility rules of posts
+}
+
+// NewBookmarkService creates a new BookmarkService
+func NewBookmarkService(bookmarkRepo repositories.BookmarkRepository, postService PostService) BookmarkService {
+	return &bookmarkService{
+		bookmarkRepo: bookmarkRepo,
+		postService:  postService,
+	}
+}
+
+// Bookmark saves a post for the user
+func (s *bookmarkService) Bookmark(userID, postID, collectionID string) (*BookmarkResponse, error) {
+	post, err := s.postService.GetByID(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	bookmark := &models.Bookmark{UserID: userID, PostID: postID}
+	if collectionID != "" {
+		if _, err := s.bookmarkRepo.GetCollection(userID, collectionID); err != nil {
+			return nil, err
+		}
+		bookmark.CollectionID = sql.NullString{String: collectionID, Valid: true}
+	}
+	if err := s.bookmarkRepo.Save(bookmark); err != nil {
+		return nil, err
+	}
+	response := mapBookmarkToResponse(bookmark)
+	response.Post = post
+	return response, nil
+}
+
+// Unbookmark removes a post from the user's bookmarks, whether or not they can still see it
+func (s *bookmarkService) Unbookmark(userID, postID string) error {
+	return s.bookmarkRepo.Delete(userID, postID)
+}
+
+// List returns a page of the user's bookmarks. Bookmarks of deleted posts are
+// gone; posts the user can't see anymore are marked unavailable.
+func (s *bookmarkService) List(userID, collectionID string, page models.Page) ([]*BookmarkResponse, models.PageInfo, error) {
+	if collectionID != "" {
+		if _, err := s.bookmarkRepo.GetCollection(userID, collectionID); err != nil {
+			return nil, models.PageInfo{}, err
+		}
+	}
+	bookmarks, info, err := s.bookmarkRepo.List(userID, collectionID, page)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	postIDs := make([]string, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		postIDs[i] = bookmark.PostID
+	}
+	posts, err := s.postService.GetByIDs(postIDs, userID)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to get bookmarked posts: %w", err)
+	}
+
+	responses := make([]*BookmarkResponse, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		responses[i] = mapBookmarkToResponse(bookmark)
+		responses[i].Post = posts[bookmark.PostID]
+		responses[i].Unavailable = responses[i].Post == nil
+	}
+	return responses, info, nil
+}
+
+// mapBookmarkToResponse converts a bookmark without its post
+func mapBookmarkToResponse(bookmark *models.Bookmark) *BookmarkResponse {
+	response := &BookmarkResponse{
+		PostID:    bookmark.PostID,
+		CreatedAt: bookmark.CreatedAt,
+	}
+	if bookmark.CollectionID.Valid {
+		response.CollectionID = &bookmark.CollectionID.String
+	}
+	return response
+}
+
+// CreateCollection adds a named bookmark collection for the user
+func (s *bookmarkService) CreateCollection(userID, name string) (*models.BookmarkCollection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxCollectionNameLength {
+		return nil, fmt.Errorf("%w: names have 1 to %d characters", ErrInvalidCollectionName, maxCollectionNameLength)
+	}
+	collection := &models.BookmarkCollection{UserID: userID, Name: name}
+	if err := s.bookmarkRepo.CreateCollection(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// ListCollections returns the user's bookmark collections by name
+func (s *bookmarkService) ListCollections(userID string) ([]*models.BookmarkCollection, error) {
+	return s.bookmarkRepo.ListCollections(userID)
+}
+
+// DeleteCollection removes one of the user's collections; its bookmarks are kept
+func (s *bookmarkService) DeleteCollection(userID, collectionID string) error {
+	return s.bookmarkRepo.DeleteCollection(userID, collectionID)
+}
@@ -17,6 +17,7 @@ type CommentResponse struct {
 	UserID        string    `json:"user_id"`
 	Content       string    `json:"content"`
 	ImageURL      string    `json:"image_url,omitempty"` // New field
+	ImageID       string    `json:"image_id,omitempty"`
 	CreatedAt     time.Time `json:"created_at"`
 	UserFirstName string    `json:"user_first_name,omitempty"`
 	UserLastName  string    `json:"user_last_name,omitempty"`
@@ -26,10 +27,10 @@ type CommentResponse struct {
 
 // CommentCreateRequest is the DTO for creating a new comment
 type CommentCreateRequest struct {
-	UserID   string `json:"-"` // Set internally from authenticated user
-	PostID   string `json:"-"` // Set from URL parameter
-	Content  string `json:"content" validate:"required,max=500"`
-	ImageURL string `json:"image_url" validate:"omitempty,url"` // New field
+	UserID  string `json:"-"` // Set internally from authenticated user
+	PostID  string `json:"-"` // Set from URL parameter
+	Content string `json:"content" validate:"required,max=500"`
+	ImageID string `json:"image_id,omitempty"` // Media uploaded through /api/media
 }
 
 var (
@@ -41,26 +42,30 @@ var (
 // CommentService defines the interface for comment business logic
 type CommentService interface {
 	CreateComment(request *CommentCreateRequest) (*CommentResponse, error)
-	GetCommentsByPost(postID string, requestingUserID string, limit, offset int) ([]*CommentResponse, error)
+	GetCommentsByPost(postID string, requestingUserID string, page models.Page) ([]*CommentResponse, models.PageInfo, error)
 	DeleteComment(commentID string, requestingUserID string) error
 }
 
 // commentService implements CommentService interface
 type commentService struct {
-	commentRepo repositories.CommentRepository
-	postService PostService                  // Use PostService to check post view permissions
-	groupRepo   repositories.GroupRepository // Needed for group admin check on delete
-	userRepo    repositories.UserRepository  // New dependency
+	commentRepo    repositories.CommentRepository
+	postService    PostService                  // Use PostService to check post view permissions
+	groupRepo      repositories.GroupRepository // Needed for group admin check on delete
+	userRepo       repositories.UserRepository  // New dependency
+	mediaService   MediaService                 // Resolves attached images
+	mentionService MentionService               // Notifies @mentioned users
 	// authService AuthService // Potentially needed if complex auth logic arises
 }
 
 // NewCommentService creates a new CommentService
-func NewCommentService(commentRepo repositories.CommentRepository, postService PostService, groupRepo repositories.GroupRepository, userRepo repositories.UserRepository) CommentService {
+func NewCommentService(commentRepo repositories.CommentRepository, postService PostService, groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, mediaService MediaService, mentionService MentionService) CommentService {
 	return &commentService{
-		commentRepo: commentRepo,
-		postService: postService,
-		groupRepo:   groupRepo,
-		userRepo:    userRepo, // Store userRepo
+		commentRepo:    commentRepo,
+		postService:    postService,
+		groupRepo:      groupRepo,
+		userRepo:       userRepo, // Store userRepo
+		mediaService:   mediaService,
+		mentionService: mentionService,
 	}
 }
 
@@ -75,6 +80,7 @@ func (s *commentService) mapCommentToResponse(comment *models.Comment) *CommentR
 		UserID:    comment.UserID,
 		Content:   comment.Content,
 		ImageURL:  comment.ImageURL, // Map ImageURL
+		ImageID:   comment.ImageID,
 		CreatedAt: comment.CreatedAt,
 	}
 
@@ -126,10 +132,17 @@ func (s *commentService) CreateComment(request *CommentCreateRequest) (*CommentR
 
 	// 3. Create the comment model
 	comment := &models.Comment{
-		PostID:   request.PostID,
-		UserID:   request.UserID, // Assumes UserID is set correctly before calling
-		Content:  request.Content,
-		ImageURL: request.ImageURL, // Map ImageURL from request
+		PostID:  request.PostID,
+		UserID:  request.UserID, // Assumes UserID is set correctly before calling
+		Content: request.Content,
+	}
+	if request.ImageID != "" {
+		media, err := s.mediaService.ResolveImage(request.ImageID, request.UserID)
+		if err != nil {
+			return nil, err
+		}
+		comment.ImageID = media.ID
+		comment.ImageURL = media.URL
 	}
 
 	// 4. Save the comment to the repository
@@ -140,33 +153,38 @@ func (s *commentService) CreateComment(request *CommentCreateRequest) (*CommentR
 		return nil, fmt.Errorf("failed to save comment: %w", err)
 	}
 
-	// 5. Return the response DTO
+	// 5. Notify @mentioned users who can see the post. The comment exists now, so failures are only logged
+	if _, err := s.mentionService.ProcessMentions(comment.UserID, comment.PostID, comment.ID, comment.Content); err != nil {
+		log.Printf("Error processing mentions in comment %s: %v", comment.ID, err)
+	}
+
+	// 6. Return the response DTO
 	return s.mapCommentToResponse(comment), nil
 }
 
 // GetCommentsByPost retrieves comments for a post, checking view permissions first
-func (s *commentService) GetCommentsByPost(postID string, requestingUserID string, limit, offset int) ([]*CommentResponse, error) {
+func (s *commentService) GetCommentsByPost(postID string, requestingUserID string, page models.Page) ([]*CommentResponse, models.PageInfo, error) {
 	// 1. Check if the user can view the post
 	_, err := s.postService.GetByID(postID, requestingUserID)
 	if err != nil {
 		if errors.Is(err, ErrPostNotFound) {
 			// If post not found or user cannot view it, they cannot see comments
-			return nil, ErrPostNotFound // Return NotFound
+			return nil, models.PageInfo{}, ErrPostNotFound // Return NotFound
 		}
 		// Handle other potential errors from GetByID
 		log.Printf("Error checking post view permission before getting comments for post %s by user %s: %v", postID, requestingUserID, err)
-		return nil, fmt.Errorf("failed to verify post access: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to verify post access: %w", err)
 	}
 
 	// 2. Fetch comments from the repository
-	comments, err := s.commentRepo.GetByPostID(postID, limit, offset)
+	comments, info, err := s.commentRepo.GetByPostID(postID, page)
 	if err != nil {
 		log.Printf("Error getting comments from repository for post %s: %v", postID, err)
-		return nil, fmt.Errorf("failed to retrieve comments: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to retrieve comments: %w", err)
 	}
 
 	// 3. Map to response DTOs
-	return s.mapCommentsToResponse(comments), nil
+	return s.mapCommentsToResponse(comments), info, nil
 }
 
 // DeleteComment handles the deletion of a comment, checking ownership or group admin status
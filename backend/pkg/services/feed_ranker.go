@@ -0,0 +1,105 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+// FeedCandidate is a post considered for a ranked feed, with the signals used to score it
+type FeedCandidate struct {
+	Post       *models.Post
+	Affinity   models.AuthorAffinity // Between the viewer and the post's author
+	Engagement models.PostEngagement
+}
+
+// FeedRanker scores candidate posts of a ranked feed. Scores must depend only
+// on the candidate and now, so the same inputs always give the same order.
+type FeedRanker interface {
+	Score(candidate FeedCandidate, now time.Time) float64 // Higher ranks first
+}
+
+// WeightedFeedRanker decays a post's score with age and boosts it by the
+// viewer's affinity with the author, the post's engagement and the author's
+// activity in the viewer's groups. Each signal is scaled to [0, 1) first so
+// no single one can dominate.
+type WeightedFeedRanker struct {
+	HalfLife         time.Duration // Age at which a post's score is halved
+	AffinityWeight   float64
+	EngagementWeight float64
+	GroupWeight      float64
+}
+
+// DefaultFeedRanker is the ranker used when none is configured
+var DefaultFeedRanker = WeightedFeedRanker{
+	HalfLife:         12 * time.Hour,
+	AffinityWeight:   2,
+	EngagementWeight: 1,
+	GroupWeight:      0.5,
+}
+
+// saturate maps a count to [0, 1), reaching 0.5 at half
+func saturate(value, half float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	return value / (value + half)
+}
+
+// Score implements FeedRanker
+func (r WeightedFeedRanker) Score(c FeedCandidate, now time.Time) float64 {
+	age := now.Sub(c.Post.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+	recency := 1.0
+	if r.HalfLife > 0 {
+		recency = math.Pow(0.5, age.Hours()/r.HalfLife.Hours())
+	}
+
+	followAge := 0.0
+	if !c.Affinity.FollowedSince.IsZero() {
+		// Following counts from day one and grows with time
+		followAge = 0.5 + 0.5*saturate(now.Sub(c.Affinity.FollowedSince).Hours()/24, 30)
+	}
+	affinity := (saturate(float64(c.Affinity.DirectMessages), 10) +
+		saturate(float64(c.Affinity.Comments), 5) +
+		followAge) / 3
+	engagement := (saturate(float64(c.Engagement.Comments), 10) +
+		saturate(float64(c.Engagement.Commenters), 5)) / 2
+	group := saturate(float64(c.Affinity.GroupActivity), 10)
+
+	return recency * (1 + r.AffinityWeight*affinity + r.EngagementWeight*engagement + r.GroupWeight*group)
+}
+
+// rankCandidates orders candidates by score. Equal scores fall back to newest
+// first, then post ID, so the order is total.
+func rankCandidates(ranker FeedRanker, candidates []FeedCandidate, now time.Time) []*models.Post {
+	scores := make([]float64, len(candidates))
+	for i, c := range candidates {
+		scores[i] = ranker.Score(c, now)
+	}
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if scores[i] != scores[j] {
+			return scores[i] > scores[j]
+		}
+		pi, pj := candidates[i].Post, candidates[j].Post
+		if !pi.CreatedAt.Equal(pj.CreatedAt) {
+			return pi.CreatedAt.After(pj.CreatedAt)
+		}
+		return pi.ID > pj.ID
+	})
+
+	posts := make([]*models.Post, len(order))
+	for rank, i := range order {
+		posts[rank] = candidates[i].Post
+	}
+	return posts
+}
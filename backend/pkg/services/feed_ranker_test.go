@@ -0,0 +1,166 @@
+package services
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+var rankerNow = time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+// candidate returns a candidate for a post with the given ID and age
+func candidate(id string, age time.Duration) FeedCandidate {
+	return FeedCandidate{Post: &models.Post{ID: id, UserID: "author-" + id, CreatedAt: rankerNow.Add(-age)}}
+}
+
+func TestWeightedFeedRankerRecency(t *testing.T) {
+	ranker := DefaultFeedRanker
+	fresh := ranker.Score(candidate("a", 0), rankerNow)
+	if fresh != 1 {
+		t.Errorf("score of a new post without signals = %v, want 1", fresh)
+	}
+	if got := ranker.Score(candidate("a", ranker.HalfLife), rankerNow); math.Abs(got-fresh/2) > 1e-9 {
+		t.Errorf("score after one half-life = %v, want %v", got, fresh/2)
+	}
+	if got := ranker.Score(candidate("a", 2*ranker.HalfLife), rankerNow); math.Abs(got-fresh/4) > 1e-9 {
+		t.Errorf("score after two half-lives = %v, want %v", got, fresh/4)
+	}
+	// Posts from the future, e.g. with a skewed clock, count as brand new
+	if got := ranker.Score(candidate("a", -time.Hour), rankerNow); got != fresh {
+		t.Errorf("score of a future post = %v, want %v", got, fresh)
+	}
+	if got := (WeightedFeedRanker{}).Score(candidate("a", 1000*time.Hour), rankerNow); got != 1 {
+		t.Errorf("score without a half-life = %v, want no decay", got)
+	}
+}
+
+func TestWeightedFeedRankerSignals(t *testing.T) {
+	ranker := DefaultFeedRanker
+	base := ranker.Score(candidate("a", time.Hour), rankerNow)
+
+	tests := []struct {
+		name   string
+		modify func(c *FeedCandidate)
+	}{
+		{"direct messages", func(c *FeedCandidate) { c.Affinity.DirectMessages = 3 }},
+		{"comments on the author's posts", func(c *FeedCandidate) { c.Affinity.Comments = 2 }},
+		{"following", func(c *FeedCandidate) { c.Affinity.FollowedSince = rankerNow.Add(-time.Hour) }},
+		{"group activity", func(c *FeedCandidate) { c.Affinity.GroupActivity = 4 }},
+		{"comments", func(c *FeedCandidate) { c.Engagement.Comments = 5 }},
+		{"commenters", func(c *FeedCandidate) { c.Engagement.Commenters = 2 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := candidate("a", time.Hour)
+			tt.modify(&c)
+			if got := ranker.Score(c, rankerNow); got <= base {
+				t.Errorf("score = %v, want more than %v without the signal", got, base)
+			}
+		})
+	}
+
+	t.Run("longer follows count more", func(t *testing.T) {
+		recent, old := candidate("a", time.Hour), candidate("a", time.Hour)
+		recent.Affinity.FollowedSince = rankerNow.Add(-24 * time.Hour)
+		old.Affinity.FollowedSince = rankerNow.Add(-365 * 24 * time.Hour)
+		if ranker.Score(old, rankerNow) <= ranker.Score(recent, rankerNow) {
+			t.Error("an old follow scores no higher than a recent one")
+		}
+	})
+
+	t.Run("signals saturate", func(t *testing.T) {
+		// However large the signals, a post is boosted by at most the sum of the weights
+		c := candidate("a", 0)
+		c.Affinity = models.AuthorAffinity{DirectMessages: 1e9, Comments: 1e9, FollowedSince: rankerNow.Add(-1e5 * time.Hour), GroupActivity: 1e9}
+		c.Engagement = models.PostEngagement{Comments: 1e9, Commenters: 1e9}
+		limit := 1 + ranker.AffinityWeight + ranker.EngagementWeight + ranker.GroupWeight
+		if got := ranker.Score(c, rankerNow); got > limit {
+			t.Errorf("score = %v, want at most %v", got, limit)
+		}
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		c := candidate("a", 0)
+		c.Engagement.Comments = 10
+		unweighted := WeightedFeedRanker{HalfLife: time.Hour}
+		if got := unweighted.Score(c, rankerNow); got != 1 {
+			t.Errorf("score with zero weights = %v, want 1", got)
+		}
+	})
+}
+
+func TestWeightedFeedRankerAffinityOutranksRecency(t *testing.T) {
+	// A friend's post from a few hours ago beats a stranger's post from just now
+	friend := candidate("friend", 3*time.Hour)
+	friend.Affinity = models.AuthorAffinity{DirectMessages: 20, Comments: 10, FollowedSince: rankerNow.Add(-90 * 24 * time.Hour)}
+	stranger := candidate("stranger", 0)
+
+	got := rankCandidates(DefaultFeedRanker, []FeedCandidate{stranger, friend}, rankerNow)
+	if got[0].ID != "friend" {
+		t.Errorf("order = %v, want the friend's post first", postIDsOf(got))
+	}
+
+	// But not a week later
+	friend.Post.CreatedAt = rankerNow.Add(-7 * 24 * time.Hour)
+	got = rankCandidates(DefaultFeedRanker, []FeedCandidate{friend, stranger}, rankerNow)
+	if got[0].ID != "stranger" {
+		t.Errorf("order = %v, want the new post first", postIDsOf(got))
+	}
+}
+
+// fixedRanker scores every candidate the same
+type fixedRanker float64
+
+func (r fixedRanker) Score(FeedCandidate, time.Time) float64 { return float64(r) }
+
+func TestRankCandidatesTieBreaking(t *testing.T) {
+	candidates := []FeedCandidate{
+		candidate("b", 2*time.Hour),
+		candidate("a", time.Hour),
+		candidate("c", 2*time.Hour),
+		candidate("d", 3*time.Hour),
+	}
+	// Equal scores: newest first, then the higher post ID
+	want := []string{"a", "c", "b", "d"}
+	if got := postIDsOf(rankCandidates(fixedRanker(1), candidates, rankerNow)); !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+
+	// The order doesn't depend on the order of the input
+	slices.Reverse(candidates)
+	if got := postIDsOf(rankCandidates(fixedRanker(1), candidates, rankerNow)); !slices.Equal(got, want) {
+		t.Errorf("order of reversed input = %v, want %v", got, want)
+	}
+
+	if got := rankCandidates(DefaultFeedRanker, nil, rankerNow); len(got) != 0 {
+		t.Errorf("ranking nothing = %v", got)
+	}
+}
+
+func TestRankedCursor(t *testing.T) {
+	anchor := rankerNow.Add(123 * time.Nanosecond)
+	gotAnchor, gotOffset, gotAfter, err := decodeRankedCursor(encodeRankedCursor(anchor, 40, ""))
+	if err != nil || !gotAnchor.Equal(anchor) || gotOffset != 40 || gotAfter != "" {
+		t.Errorf("round trip = %v, %d, %q, %v; want %v, 40", gotAnchor, gotOffset, gotAfter, err, anchor)
+	}
+	if _, _, gotAfter, err = decodeRankedCursor(encodeRankedCursor(anchor, 0, "older")); err != nil || gotAfter != "older" {
+		t.Errorf("round trip of a chronological cursor = %q, %v; want older", gotAfter, err)
+	}
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "eyJhIjowLCJvIjoxfQ", "eyJhIjoxLCJvIjotMX0"} {
+		if _, _, _, err := decodeRankedCursor(cursor); err == nil {
+			t.Errorf("decodeRankedCursor(%q) succeeded", cursor)
+		}
+	}
+}
+
+// postIDsOf lists the IDs of posts in order
+func postIDsOf(posts []*models.Post) []string {
+	ids := make([]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return ids
+}
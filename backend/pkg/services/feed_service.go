@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+)
+
+const (
+	// maxFeedCandidates is how many of the newest visible posts a ranked feed
+	// scores; older posts follow them in chronological order
+	maxFeedCandidates = 200
+	// groupActivityWindow is how far back group posts count towards affinity
+	groupActivityWindow = 30 * 24 * time.Hour
+)
+
+var ErrInvalidFeedMode = errors.New("invalid feed mode")
+
+// FeedService defines the interface for feed preferences and ranking.
+// The feeds themselves are listed through PostService.
+type FeedService interface {
+	GetMode(userID string) (models.FeedMode, error) // Ranked unless the user chose otherwise
+	SetMode(userID string, mode models.FeedMode) error
+	// Rank orders posts for the viewer as seen at time now. Interactions after
+	// now are ignored, so ranking the same posts at the same time always gives
+	// the same order.
+	Rank(viewerID string, posts []*models.Post, now time.Time) ([]*models.Post, error)
+}
+
+// feedService implements FeedService
+type feedService struct {
+	feedRepo repositories.FeedRepository
+	ranker   FeedRanker
+}
+
+// NewFeedService creates a new FeedService. A nil ranker uses DefaultFeedRanker.
+func NewFeedService(feedRepo repositories.FeedRepository, ranker FeedRanker) FeedService {
+	if ranker == nil {
+		ranker = DefaultFeedRanker
+	}
+	return &feedService{
+		feedRepo: feedRepo,
+		ranker:   ranker,
+	}
+}
+
+// GetMode returns the feed mode of a user
+func (s *feedService) GetMode(userID string) (models.FeedMode, error) {
+	mode, err := s.feedRepo.GetMode(userID)
+	if err != nil {
+		return "", err
+	}
+	if mode == "" {
+		return models.FeedModeRanked, nil
+	}
+	return mode, nil
+}
+
+// SetMode changes the feed mode of a user
+func (s *feedService) SetMode(userID string, mode models.FeedMode) error {
+	if mode != models.FeedModeRanked && mode != models.FeedModeChronological {
+		return ErrInvalidFeedMode
+	}
+	return s.feedRepo.SetMode(userID, mode)
+}
+
+// Rank loads the affinity and engagement signals of the posts and orders
+// them with the configured ranker
+func (s *feedService) Rank(viewerID string, posts []*models.Post, now time.Time) ([]*models.Post, error) {
+	authorIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	postIDs := make([]string, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+		if !seen[post.UserID] {
+			seen[post.UserID] = true
+			authorIDs = append(authorIDs, post.UserID)
+		}
+	}
+
+	affinity, err := s.feedRepo.AuthorAffinity(viewerID, authorIDs, now.Add(-groupActivityWindow), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feed affinity: %w", err)
+	}
+	engagement, err := s.feedRepo.PostEngagement(postIDs, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feed engagement: %w", err)
+	}
+
+	candidates := make([]FeedCandidate, len(posts))
+	for i, post := range posts {
+		candidates[i] = FeedCandidate{
+			Post:       post,
+			Affinity:   affinity[post.UserID],
+			Engagement: engagement[post.ID],
+		}
+	}
+	return rankCandidates(s.ranker, candidates, now), nil
+}
+
+// rankedCursor points into a ranked feed: the anchor time of its first page
+// and the number of posts already returned, or once the ranked posts ran out,
+// the cursor of the chronological feed that follows them
+type rankedCursor struct {
+	Anchor int64  `json:"a"` // Unix nanoseconds
+	Offset int    `json:"o"`
+	After  string `json:"c,omitempty"`
+}
+
+// encodeRankedCursor returns the opaque cursor of a ranked feed page
+func encodeRankedCursor(anchor time.Time, offset int, after string) string {
+	data, _ := json.Marshal(rankedCursor{Anchor: anchor.UnixNano(), Offset: offset, After: after})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeRankedCursor reverses encodeRankedCursor. Cursors of chronological
+// feeds are rejected with repositories.ErrInvalidCursor.
+func decodeRankedCursor(cursor string) (time.Time, int, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, "", repositories.ErrInvalidCursor
+	}
+	var c rankedCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Anchor <= 0 || c.Offset < 0 {
+		return time.Time{}, 0, "", repositories.ErrInvalidCursor
+	}
+	return time.Unix(0, c.Anchor), c.Offset, c.After, nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+)
+
+// stubFeedRepository serves fixed signals and records what was asked for
+type stubFeedRepository struct {
+	mode       models.FeedMode
+	affinity   map[string]models.AuthorAffinity
+	engagement map[string]models.PostEngagement
+	err        error
+
+	authorIDs []string
+	since     time.Time
+	until     time.Time
+}
+
+func (r *stubFeedRepository) GetMode(userID string) (models.FeedMode, error) { return r.mode, r.err }
+
+func (r *stubFeedRepository) SetMode(userID string, mode models.FeedMode) error {
+	r.mode = mode
+	return r.err
+}
+
+func (r *stubFeedRepository) AuthorAffinity(viewerID string, authorIDs []string, since, until time.Time) (map[string]models.AuthorAffinity, error) {
+	r.authorIDs, r.since, r.until = authorIDs, since, until
+	return r.affinity, r.err
+}
+
+func (r *stubFeedRepository) PostEngagement(postIDs []string, until time.Time) (map[string]models.PostEngagement, error) {
+	r.until = until
+	return r.engagement, r.err
+}
+
+func TestFeedServiceRank(t *testing.T) {
+	posts := []*models.Post{
+		{ID: "p1", UserID: "stranger", CreatedAt: rankerNow},
+		{ID: "p2", UserID: "friend", CreatedAt: rankerNow.Add(-2 * time.Hour)},
+		{ID: "p3", UserID: "stranger", CreatedAt: rankerNow.Add(-time.Hour)},
+		{ID: "p4", UserID: "popular", CreatedAt: rankerNow.Add(-time.Hour)},
+	}
+	repo := &stubFeedRepository{
+		affinity: map[string]models.AuthorAffinity{
+			"friend": {DirectMessages: 30, Comments: 20, FollowedSince: rankerNow.Add(-365 * 24 * time.Hour)},
+		},
+		engagement: map[string]models.PostEngagement{"p4": {Comments: 40, Commenters: 20}},
+	}
+	service := NewFeedService(repo, nil)
+
+	ranked, err := service.Rank("viewer", posts, rankerNow)
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	if want := []string{"p2", "p4", "p1", "p3"}; !slices.Equal(postIDsOf(ranked), want) {
+		t.Errorf("order = %v, want %v", postIDsOf(ranked), want)
+	}
+	if want := []string{"stranger", "friend", "popular"}; !slices.Equal(repo.authorIDs, want) {
+		t.Errorf("affinity loaded for %v, want each author once: %v", repo.authorIDs, want)
+	}
+	if !repo.since.Equal(rankerNow.Add(-groupActivityWindow)) {
+		t.Errorf("group activity since %v, want %v", repo.since, rankerNow.Add(-groupActivityWindow))
+	}
+	if !repo.until.Equal(rankerNow) {
+		t.Errorf("signals until %v, want the ranking time %v", repo.until, rankerNow)
+	}
+
+	repo.err = errors.New("database is down")
+	if _, err := service.Rank("viewer", posts, rankerNow); !errors.Is(err, repo.err) {
+		t.Errorf("Rank with a failing repository = %v", err)
+	}
+}
+
+func TestFeedServiceMode(t *testing.T) {
+	repo := &stubFeedRepository{}
+	service := NewFeedService(repo, nil)
+
+	if mode, err := service.GetMode("viewer"); err != nil || mode != models.FeedModeRanked {
+		t.Errorf("default mode = %q, %v; want ranked", mode, err)
+	}
+	if err := service.SetMode("viewer", models.FeedModeChronological); err != nil {
+		t.Fatalf("SetMode: %v", err)
+	}
+	if mode, _ := service.GetMode("viewer"); mode != models.FeedModeChronological {
+		t.Errorf("mode = %q, want chronological", mode)
+	}
+	if err := service.SetMode("viewer", "random"); !errors.Is(err, ErrInvalidFeedMode) {
+		t.Errorf("SetMode(random) = %v, want ErrInvalidFeedMode", err)
+	}
+}
+
+// commentFeedRepository serves the engagement of comments made at given times
+type commentFeedRepository struct {
+	stubFeedRepository
+	comments map[string][]time.Time // Comment times by post ID
+}
+
+func (r *commentFeedRepository) PostEngagement(postIDs []string, until time.Time) (map[string]models.PostEngagement, error) {
+	engagement := make(map[string]models.PostEngagement)
+	for _, id := range postIDs {
+		for _, at := range r.comments[id] {
+			if !at.After(until) {
+				e := engagement[id]
+				e.Comments++
+				e.Commenters++
+				engagement[id] = e
+			}
+		}
+	}
+	return engagement, nil
+}
+
+// listPosts pages through posts, newest first, like a repository listing
+func listPosts(posts []*models.Post) func(models.Page) ([]*models.Post, models.PageInfo, error) {
+	return func(page models.Page) ([]*models.Post, models.PageInfo, error) {
+		start := page.Offset
+		if page.After != "" {
+			start, _ = strconv.Atoi(page.After)
+		}
+		start = min(start, len(posts))
+		end := min(start+page.Limit, len(posts))
+		info := models.PageInfo{HasMore: end < len(posts)}
+		if info.HasMore {
+			info.NextCursor = strconv.Itoa(end)
+		}
+		return posts[start:end], info, nil
+	}
+}
+
+func TestRankedPageIsStableAcrossPages(t *testing.T) {
+	posts := make([]*models.Post, 30)
+	for i := range posts {
+		posts[i] = &models.Post{ID: "p" + strconv.Itoa(i), UserID: "author", CreatedAt: rankerNow.Add(-time.Duration(i) * time.Minute)}
+	}
+	repo := &commentFeedRepository{comments: map[string][]time.Time{}}
+	s := &postService{feedService: NewFeedService(repo, nil)}
+	list := listPosts(posts)
+
+	first, info, err := s.rankedPage("viewer", models.Page{Limit: 10}, rankerNow, list)
+	if err != nil || !info.HasMore {
+		t.Fatalf("first page: %v, %+v", err, info)
+	}
+
+	// The oldest post gets popular after the first page was served
+	later := rankerNow.Add(time.Minute)
+	for i := 0; i < 50; i++ {
+		repo.comments["p29"] = append(repo.comments["p29"], later)
+	}
+	if fresh, _, _ := s.rankedPage("viewer", models.Page{Limit: 10}, later, list); fresh[0].ID != "p29" {
+		t.Fatalf("a new first page starts with %s, want the popular post", fresh[0].ID)
+	}
+
+	seen := postIDsOf(first)
+	for info.HasMore {
+		var next []*models.Post
+		next, info, err = s.rankedPage("viewer", models.Page{Limit: 10, After: info.NextCursor}, later, list)
+		if err != nil {
+			t.Fatalf("next page: %v", err)
+		}
+		seen = append(seen, postIDsOf(next)...)
+	}
+	want := postIDsOf(posts)
+	if !slices.Equal(seen, want) {
+		t.Errorf("pages = %v, want every post once in the order of the first page: %v", seen, want)
+	}
+}
+
+func TestRankedPageContinuesPastCandidates(t *testing.T) {
+	posts := make([]*models.Post, maxFeedCandidates+50)
+	for i := range posts {
+		posts[i] = &models.Post{ID: "p" + strconv.Itoa(i), UserID: "author", CreatedAt: rankerNow.Add(-time.Duration(i) * time.Minute)}
+	}
+	s := &postService{feedService: NewFeedService(&stubFeedRepository{}, nil)}
+	list := listPosts(posts)
+
+	// Pages of 30 cross from the ranked posts to the older ones mid-page
+	var seen []string
+	info := models.PageInfo{HasMore: true}
+	for pages := 0; info.HasMore; pages++ {
+		if pages > len(posts) {
+			t.Fatal("the feed doesn't end")
+		}
+		var next []*models.Post
+		var err error
+		next, info, err = s.rankedPage("viewer", models.Page{Limit: 30, After: info.NextCursor}, rankerNow, list)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		seen = append(seen, postIDsOf(next)...)
+	}
+	if want := postIDsOf(posts); !slices.Equal(seen, want) {
+		t.Errorf("pages = %v, want every post once: %v", seen, want)
+	}
+
+	// A page ending with the ranked posts points to the older ones
+	page, info, err := s.rankedPage("viewer", models.Page{Limit: 20, After: encodeRankedCursor(rankerNow, 180, "")}, rankerNow, list)
+	if err != nil || len(page) != 20 || !info.HasMore {
+		t.Fatalf("last ranked page = %d posts, %+v, %v", len(page), info, err)
+	}
+	if page, _, _ = s.rankedPage("viewer", models.Page{Limit: 20, After: info.NextCursor}, rankerNow, list); len(page) != 20 || page[0].ID != "p200" {
+		t.Errorf("page after the ranked posts = %v, want p200 onwards", postIDsOf(page))
+	}
+
+	// Offsets go on past the ranked posts too
+	page, _, err = s.rankedPage("viewer", models.Page{Limit: 10, Offset: 240}, rankerNow, list)
+	if want := postIDsOf(posts[240:]); err != nil || !slices.Equal(postIDsOf(page), want) {
+		t.Errorf("page at offset 240 = %v, %v; want %v", postIDsOf(page), err, want)
+	}
+}
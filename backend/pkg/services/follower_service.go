@@ -31,6 +31,8 @@ type followerService struct {
 	followerRepo        repositories.FollowerRepository
 	userRepo            repositories.UserRepository // Assuming UserRepository exists and is needed
 	notificationService NotificationService         // Added NotificationService
+	mentionService      MentionService              // Withdraws mentions in posts that unfollowers can no longer see
+	uow                 repositories.UnitOfWork     // Runs multi-step writes atomically
 }
 
 // NewFollowerService creates a new instance of FollowerService
@@ -38,11 +40,15 @@ func NewFollowerService(
 	followerRepo repositories.FollowerRepository,
 	userRepo repositories.UserRepository,
 	notificationService NotificationService, // Added NotificationService
+	mentionService MentionService,
+	uow repositories.UnitOfWork,
 ) FollowerService {
 	return &followerService{
 		followerRepo:        followerRepo,
 		userRepo:            userRepo,
 		notificationService: notificationService, // Store NotificationService
+		mentionService:      mentionService,
+		uow:                 uow,
 	}
 }
 
@@ -118,19 +124,25 @@ func (s *followerService) RequestFollow(ctx context.Context, requesterID, target
 		}
 
 	} else {
-		// Public profile: Create request and immediately accept it
-		err = s.followerRepo.CreateFollowRequest(requesterID, targetID)
-		if err != nil {
-			// Handle potential duplicate error if CreateFollowRequest fails uniquely
-			log.Printf("Error creating initial follow record for public profile: %v", err)
-			return fmt.Errorf("failed to initiate follow for public profile")
-		}
-		err = s.followerRepo.UpdateFollowStatus(requesterID, targetID, "accepted")
+		// Public profile: Create request and immediately accept it in one transaction
+		err = s.uow.Do(func(repos *repositories.Repositories) error {
+			if err := repos.Follower.CreateFollowRequest(requesterID, targetID); err != nil {
+				// Handle potential duplicate error if CreateFollowRequest fails uniquely
+				log.Printf("Error creating initial follow record for public profile: %v", err)
+				return fmt.Errorf("failed to initiate follow for public profile")
+			}
+			if err := repos.Follower.UpdateFollowStatus(requesterID, targetID, "accepted"); err != nil {
+				log.Printf("Error auto-accepting follow for public profile: %v", err)
+				return fmt.Errorf("failed to finalize follow for public profile")
+			}
+			if err := repos.Timeline.AddAuthor(requesterID, targetID); err != nil {
+				log.Printf("Error adding posts of %s to timeline of %s: %v", targetID, requesterID, err)
+				return fmt.Errorf("failed to finalize follow for public profile")
+			}
+			return nil
+		})
 		if err != nil {
-			log.Printf("Error auto-accepting follow for public profile: %v", err)
-			// Consider cleanup: Delete the pending request if acceptance fails?
-			// s.followerRepo.DeleteFollow(requesterID, targetID) // Optional cleanup
-			return fmt.Errorf("failed to finalize follow for public profile")
+			return err
 		}
 		log.Printf("User %s automatically followed public user %s", requesterID, targetID)
 	}
@@ -150,8 +162,13 @@ func (s *followerService) AcceptFollow(ctx context.Context, accepterID, requeste
 		return errors.New("no pending follow request found from this user")
 	}
 
-	// Update status to accepted
-	err = s.followerRepo.UpdateFollowStatus(requesterID, accepterID, "accepted")
+	// Update status to accepted and show the accepter's posts on the requester's timeline
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Follower.UpdateFollowStatus(requesterID, accepterID, "accepted"); err != nil {
+			return err
+		}
+		return repos.Timeline.AddAuthor(requesterID, accepterID)
+	})
 	if err != nil {
 		log.Printf("Error accepting follow request: %v", err)
 		return fmt.Errorf("failed to accept follow request")
@@ -207,11 +224,22 @@ func (s *followerService) RejectFollow(rejecterID, requesterID string) error {
 	// }
 
 	// Delete the follow record (whether pending or accepted)
-	err = s.followerRepo.DeleteFollow(requesterID, rejecterID)
+	var listedPostIDs []string // Posts shared with the requester through audience lists
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Follower.DeleteFollow(requesterID, rejecterID); err != nil {
+			return err
+		}
+		var err error
+		if listedPostIDs, err = repos.AudienceList.RemoveFromLists(rejecterID, requesterID); err != nil {
+			return err
+		}
+		return repos.Timeline.RemoveAuthor(requesterID, rejecterID)
+	})
 	if err != nil {
 		log.Printf("Error rejecting/deleting follow request: %v", err)
 		return fmt.Errorf("failed to reject follow request")
 	}
+	refreshPostMentions(s.mentionService, listedPostIDs)
 
 	return nil
 }
@@ -228,12 +256,24 @@ func (s *followerService) Unfollow(unfollowerID, targetID string) error {
 		return errors.New("not following this user")
 	}
 
-	// Delete the follow record
-	err = s.followerRepo.DeleteFollow(unfollowerID, targetID)
+	// Delete the follow record, the unfollower from the target's audience lists,
+	// and the target's posts from the unfollower's timeline
+	var listedPostIDs []string // Posts shared with the unfollower through audience lists
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Follower.DeleteFollow(unfollowerID, targetID); err != nil {
+			return err
+		}
+		var err error
+		if listedPostIDs, err = repos.AudienceList.RemoveFromLists(targetID, unfollowerID); err != nil {
+			return err
+		}
+		return repos.Timeline.RemoveAuthor(unfollowerID, targetID)
+	})
 	if err != nil {
 		log.Printf("Error unfollowing user: %v", err)
 		return fmt.Errorf("failed to unfollow user")
 	}
+	refreshPostMentions(s.mentionService, listedPostIDs)
 
 	return nil
 }
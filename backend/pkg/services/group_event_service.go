@@ -96,6 +96,7 @@ type groupEventService struct {
 	userRepo               repositories.UserRepository
 	groupEventResponseRepo repositories.GroupEventResponseRepository // Added
 	notificationService    NotificationService
+	uow                    repositories.UnitOfWork // Runs multi-step writes atomically
 }
 
 // NewGroupEventService creates a new GroupEventService
@@ -105,6 +106,7 @@ func NewGroupEventService(
 	userRepo repositories.UserRepository,
 	groupEventResponseRepo repositories.GroupEventResponseRepository, // Added
 	notificationService NotificationService,
+	uow repositories.UnitOfWork,
 ) GroupEventService {
 	return &groupEventService{
 		groupEventRepo:         groupEventRepo,
@@ -112,6 +114,7 @@ func NewGroupEventService(
 		userRepo:               userRepo,
 		groupEventResponseRepo: groupEventResponseRepo, // Added
 		notificationService:    notificationService,
+		uow:                    uow,
 	}
 }
 
@@ -428,40 +431,44 @@ func (s *groupEventService) Delete(eventID string, requestingUserID string) erro
 
 // RespondToEvent handles a user responding to an event
 func (s *groupEventService) RespondToEvent(eventID, userID string, request *GroupEventResponseRequest) error {
-	// 1. Get the event to find the group ID
-	event, err := s.groupEventRepo.GetByID(eventID)
-	if err != nil {
-		if errors.Is(err, repositories.ErrEventNotFound) {
-			return repositories.ErrEventNotFound // Or a more specific "event not found" error
+	// The event lookup, membership check and upsert run in one transaction so the
+	// response can't be recorded against an event or membership removed in between.
+	return s.uow.Do(func(repos *repositories.Repositories) error {
+		// 1. Get the event to find the group ID
+		event, err := repos.GroupEvent.GetByID(eventID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrEventNotFound) {
+				return repositories.ErrEventNotFound // Or a more specific "event not found" error
+			}
+			return fmt.Errorf("failed to get event details: %w", err)
 		}
-		return fmt.Errorf("failed to get event details: %w", err)
-	}
 
-	// 2. Check if the responding user is a member of the group
-	isMember, err := s.groupRepo.IsMember(event.GroupID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to check group membership for response: %w", err)
-	}
-	if !isMember {
-		return ErrGroupMemberRequired // User must be a member to respond
-	}
+		// 2. Check if the responding user is a member of the group
+		isMember, err := repos.Group.IsMember(event.GroupID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check group membership for response: %w", err)
+		}
+		if !isMember {
+			return ErrGroupMemberRequired // User must be a member to respond
+		}
 
-	// 3. Create the response model
-	responseModel := &models.GroupEventResponse{
-		EventID:  eventID,
-		UserID:   userID,
-		Response: request.Response,
-		// ID, CreatedAt, UpdatedAt are handled by the repository/DB
-	}
+		// 3. Create the response model
+		responseModel := &models.GroupEventResponse{
+			EventID:  eventID,
+			UserID:   userID,
+			Response: request.Response,
+			// ID, CreatedAt, UpdatedAt are handled by the repository/DB
+		}
 
-	// 4. Call repository to create or update the response
-	err = s.groupEventResponseRepo.CreateOrUpdate(responseModel)
-	if err != nil {
-		// Specific errors (like constraint violations) are already handled in the repo
-		return fmt.Errorf("failed to save event response: %w", err)
-	}
+		// 4. Call repository to create or update the response
+		err = repos.GroupEventResponse.CreateOrUpdate(responseModel)
+		if err != nil {
+			// Specific errors (like constraint violations) are already handled in the repo
+			return fmt.Errorf("failed to save event response: %w", err)
+		}
 
-	return nil // Success
+		return nil // Success
+	})
 }
 
 // ListEventResponses lists all responses for a given event, including usernames
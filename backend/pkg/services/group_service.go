@@ -18,6 +18,7 @@ type GroupResponse struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description,omitempty"`
 	AvatarURL   string    `json:"avatar_url,omitempty"`
+	AvatarID    string    `json:"avatar_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at,omitempty"`
 	// TODO: Add member count? Creator details?
@@ -28,14 +29,14 @@ type GroupCreateRequest struct {
 	CreatorID   string `json:"-"` // Set internally from authenticated user
 	Name        string `json:"name" validate:"required,max=50"`
 	Description string `json:"description" validate:"max=255"`
-	AvatarURL   string `json:"avatar_url" validate:"omitempty,url"`
+	AvatarID    string `json:"avatar_id,omitempty"` // Media uploaded through /api/media
 }
 
 // GroupUpdateRequest is the DTO for updating a group
 type GroupUpdateRequest struct {
 	Name        string `json:"name" validate:"required,max=50"`
 	Description string `json:"description" validate:"max=255"`
-	AvatarURL   string `json:"avatar_url" validate:"omitempty,url"`
+	AvatarID    string `json:"avatar_id,omitempty"` // Media uploaded through /api/media; empty removes the avatar
 }
 
 // GroupMemberResponse is the DTO for group member data
@@ -151,16 +152,20 @@ type groupService struct {
 	postRepo            repositories.PostRepository
 	eventRepo           repositories.GroupEventRepository
 	notificationService NotificationService
+	uow                 repositories.UnitOfWork // Runs multi-step writes atomically
+	mediaService        MediaService            // Resolves avatar uploads
 }
 
 // NewGroupService creates a new GroupService
-func NewGroupService(groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, postRepo repositories.PostRepository, eventRepo repositories.GroupEventRepository, notificationService NotificationService) GroupService {
+func NewGroupService(groupRepo repositories.GroupRepository, userRepo repositories.UserRepository, postRepo repositories.PostRepository, eventRepo repositories.GroupEventRepository, notificationService NotificationService, uow repositories.UnitOfWork, mediaService MediaService) GroupService {
 	return &groupService{
 		groupRepo:           groupRepo,
 		userRepo:            userRepo,
 		postRepo:            postRepo,
 		eventRepo:           eventRepo,
 		notificationService: notificationService,
+		uow:                 uow,
+		mediaService:        mediaService,
 	}
 }
 
@@ -176,6 +181,7 @@ func mapGroupToResponse(group *models.Group) *GroupResponse {
 		Name:        group.Name,
 		Description: group.Description,
 		AvatarURL:   group.AvatarURL,
+		AvatarID:    group.AvatarID,
 		CreatedAt:   group.CreatedAt,
 		UpdatedAt:   group.UpdatedAt,
 	}
@@ -326,7 +332,13 @@ func (s *groupService) Create(request *GroupCreateRequest) (*GroupResponse, erro
 		CreatorID:   request.CreatorID, // Assumes CreatorID is set correctly
 		Name:        request.Name,
 		Description: request.Description,
-		AvatarURL:   request.AvatarURL,
+	}
+	if request.AvatarID != "" {
+		media, err := s.mediaService.ResolveImage(request.AvatarID, request.CreatorID)
+		if err != nil {
+			return nil, err
+		}
+		group.AvatarID, group.AvatarURL = media.ID, media.URL
 	}
 
 	err := s.groupRepo.Create(group) // Repository handles adding creator as admin member
@@ -380,7 +392,7 @@ func (s *groupService) GetByID(groupID string, requestingUserID string) (*types.
 
 		// Fetch recent posts (e.g., 10 most recent)
 		// Fetch recent posts (e.g., 10 most recent)
-		modelPosts, err := s.postRepo.ListByGroupID(groupID, 10, 0) // Limit 10, offset 0
+		modelPosts, _, err := s.postRepo.ListByGroupID(groupID, models.Page{Limit: 10})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get posts for group %s: %w", groupID, err)
 		}
@@ -508,7 +520,18 @@ func (s *groupService) Update(groupID string, request *GroupUpdateRequest, reque
 	// Apply updates
 	group.Name = request.Name
 	group.Description = request.Description
-	group.AvatarURL = request.AvatarURL
+	switch {
+	case request.AvatarID == group.AvatarID:
+		// Unchanged; it may have been uploaded by another admin
+	case request.AvatarID == "":
+		group.AvatarID, group.AvatarURL = "", ""
+	default:
+		media, err := s.mediaService.ResolveImage(request.AvatarID, requestingUserID)
+		if err != nil {
+			return nil, err
+		}
+		group.AvatarID, group.AvatarURL = media.ID, media.URL
+	}
 
 	// Save updated group
 	err = s.groupRepo.Update(group)
@@ -804,25 +827,20 @@ func (s *groupService) AcceptInvitation(invitationID string, userID string) erro
 		return ErrInvalidInvitationStatus // Already accepted/rejected
 	}
 
-	// 4. Update invitation status
-	err = s.groupRepo.UpdateInvitationStatus(invitationID, "accepted")
-	if err != nil {
-		// If update fails, return error
-		return fmt.Errorf("failed to update invitation status: %w", err)
-	}
-
-	// 5. Add user as member (Best effort after status update)
-	err = s.groupRepo.AddMember(inv.GroupID, inv.InviteeID, "member")
-	if err != nil {
-		// If adding member fails, log it but don't necessarily revert status.
+	// 4. Update invitation status and add user as member in one transaction
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Group.UpdateInvitationStatus(invitationID, "accepted"); err != nil {
+			return fmt.Errorf("failed to update invitation status: %w", err)
+		}
 		// The user might already be a member due to a race condition, which is acceptable.
-		// Or another error occurred.
-		fmt.Printf("Warning: Failed to add member %s to group %s after accepting invite %s: %v\n", inv.InviteeID, inv.GroupID, invitationID, err)
-		// If it's specifically ErrAlreadyGroupMember, it's fine.
-		if !errors.Is(err, repositories.ErrAlreadyGroupMember) {
-			// For other errors, we might consider trying to revert the status, but for now, just log.
-			// return fmt.Errorf("failed to add member after accepting invitation: %w", err) // Optionally return error
+		err := repos.Group.AddMember(inv.GroupID, inv.InviteeID, "member")
+		if err != nil && !errors.Is(err, repositories.ErrAlreadyGroupMember) {
+			return fmt.Errorf("failed to add member after accepting invitation: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// TODO: Send notification?
@@ -961,19 +979,19 @@ func (s *groupService) AcceptJoinRequest(requestID string, adminUserID string) e
 		return ErrInvalidJoinRequestStatus // Already accepted/rejected
 	}
 
-	// 4. Update request status
-	err = s.groupRepo.UpdateJoinRequestStatus(requestID, "accepted")
-	if err != nil {
-		return fmt.Errorf("failed to update join request status: %w", err)
-	}
-
-	// 5. Add user as member (Best effort)
-	err = s.groupRepo.AddMember(req.GroupID, req.RequesterID, "member")
-	if err != nil {
-		fmt.Printf("Warning: Failed to add member %s to group %s after accepting join request %s: %v\n", req.RequesterID, req.GroupID, requestID, err)
-		if !errors.Is(err, repositories.ErrAlreadyGroupMember) {
-			// Log or handle other errors if necessary
+	// 4. Update request status and add user as member in one transaction
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Group.UpdateJoinRequestStatus(requestID, "accepted"); err != nil {
+			return fmt.Errorf("failed to update join request status: %w", err)
 		}
+		err := repos.Group.AddMember(req.GroupID, req.RequesterID, "member")
+		if err != nil && !errors.Is(err, repositories.ErrAlreadyGroupMember) {
+			return fmt.Errorf("failed to add member after accepting join request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// TODO: Send notification?
@@ -1,6 +1,12 @@
 package services
 
-import "github.com/HASANALI117/social-network/pkg/repositories"
+import (
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/db"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/storage"
+	"github.com/HASANALI117/social-network/pkg/unfurl"
+)
 
 // Services holds all service instances.
 type Services struct {
@@ -13,27 +19,74 @@ type Services struct {
 	GroupEvent         GroupEventService // Added GroupEvent service
 	Message            MessageService    // Added Message service
 	Notification       NotificationService // Added Notification service
+	Backup             BackupService
+	Media              MediaService
+	MediaGC            MediaGCService
+	Search             SearchService
+	Tag                TagService
+	Mention            MentionService
+	Feed               FeedService
+	Bookmark           BookmarkService
+	Poll               PollService
+	AudienceList       AudienceListService
+	LinkPreview        LinkPreviewService
 }
 
 // InitServices initializes all services.
-// It now requires a RealTimeNotifier (e.g., the websocket.Hub) for the NotificationService.
-func InitServices(repos *repositories.Repositories, notifier RealTimeNotifier) *Services {
+// It now requires a RealTimeNotifier (e.g., the websocket.Hub) for the NotificationService,
+// and a UnitOfWork for services that perform multi-step writes.
+// The database handle and config are used by infrastructure services such as backups,
+// and store holds uploaded media.
+func InitServices(repos *repositories.Repositories, uow repositories.UnitOfWork, notifier RealTimeNotifier, database *db.DB, store storage.BlobStore, cfg *config.Config) *Services {
 	authService := NewAuthService(repos.User, repos.Session)
-	postService := NewPostService(repos.Post, repos.Follower, repos.Group, repos.User)
+	mediaService := NewMediaService(repos.Media, repos.Post, repos.Follower, repos.Group, store, cfg.Media, cfg.AdminUserIDs)
 	// Initialize NotificationService first as other services might depend on it
 	notificationService := NewNotificationService(repos.Notification, notifier) // Initialize NotificationService
-	groupService := NewGroupService(repos.Group, repos.User, repos.Post, repos.GroupEvent, notificationService)
+	mentionService := NewMentionService(repos.Mention, repos.User, repos.Post, repos.Follower, repos.Group, notificationService)
+	feedService := NewFeedService(repos.Feed, DefaultFeedRanker)
+	linkPreviewService := NewLinkPreviewService(repos.LinkPreview, unfurl.NewHTTPFetcher(unfurl.HTTPFetcherOptions{
+		Timeout:      cfg.LinkPreviews.Timeout,
+		MaxBytes:     cfg.LinkPreviews.MaxBytes,
+		MaxRedirects: cfg.LinkPreviews.MaxRedirects,
+		UserAgent:    cfg.LinkPreviews.UserAgent,
+		AllowedHosts: cfg.LinkPreviews.AllowedHosts,
+	}), cfg.LinkPreviews)
+	postService := NewPostService(PostServiceDeps{
+		PostRepo:       repos.Post,
+		TagRepo:        repos.Tag,
+		FollowerRepo:   repos.Follower,
+		GroupRepo:      repos.Group,
+		PollRepo:       repos.Poll,
+		AudienceRepo:   repos.AudienceList,
+		UserRepo:       repos.User,
+		UOW:            uow,
+		MediaService:   mediaService,
+		MentionService: mentionService,
+		FeedService:    feedService,
+		LinkPreviews:   linkPreviewService,
+		MaxAttachments: cfg.Media.MaxPostAttachments,
+		MaxFanout:      cfg.Feed.FanoutMaxFollowers,
+		MaxPinned:      cfg.Posts.MaxPinned,
+	})
+	groupService := NewGroupService(repos.Group, repos.User, repos.Post, repos.GroupEvent, notificationService, uow, mediaService)
 	// NotificationService needs to be initialized before services that depend on it.
 	// It's already initialized further down, so we can use it here.
 	// followerService := NewFollowerService(repos.Follower, repos.User) // Old call
-	commentService := NewCommentService(repos.Comment, postService, repos.Group, repos.User)
+	commentService := NewCommentService(repos.Comment, postService, repos.Group, repos.User, mediaService, mentionService)
 	// Update NewGroupEventService to include GroupEventResponseRepository
-	groupEventService := NewGroupEventService(repos.GroupEvent, repos.Group, repos.User, repos.GroupEventResponse, notificationService)
+	groupEventService := NewGroupEventService(repos.GroupEvent, repos.Group, repos.User, repos.GroupEventResponse, notificationService, uow)
 	
 	// Now initialize services that might depend on NotificationService
-	followerService := NewFollowerService(repos.Follower, repos.User, notificationService) // Pass NotificationService
-	userService := NewUserService(repos.User, postService, followerService, repos.Group) // Pass GroupRepository
-	messageService := NewMessageService(repos.ChatMessage, repos.Group) // Initialize MessageService
+	followerService := NewFollowerService(repos.Follower, repos.User, notificationService, mentionService, uow) // Pass NotificationService
+	userService := NewUserService(repos.User, postService, followerService, repos.Group, mediaService) // Pass GroupRepository
+	messageService := NewMessageService(repos.ChatMessage, repos.Group, linkPreviewService) // Initialize MessageService
+	backupService := NewBackupService(database, cfg.Backup)
+	mediaGCService := NewMediaGCService(repos.Media, store, cfg.Media)
+	searchService := NewSearchService(repos.Search)
+	tagService := NewTagService(repos.Tag)
+	bookmarkService := NewBookmarkService(repos.Bookmark, postService)
+	pollService := NewPollService(repos.Poll, postService)
+	audienceListService := NewAudienceListService(repos.AudienceList, repos.Follower, mentionService)
 
 
 	return &Services{
@@ -46,5 +99,16 @@ func InitServices(repos *repositories.Repositories, notifier RealTimeNotifier) *
 		GroupEvent:         groupEventService, // Assign initialized GroupEventService
 		Message:            messageService,    // Assign initialized MessageService
 		Notification:       notificationService, // Assign initialized NotificationService
+		Backup:             backupService,
+		Media:              mediaService,
+		MediaGC:            mediaGCService,
+		Search:             searchService,
+		Tag:                tagService,
+		Mention:            mentionService,
+		Feed:               feedService,
+		Bookmark:           bookmarkService,
+		Poll:               pollService,
+		AudienceList:       audienceListService,
+		LinkPreview:        linkPreviewService,
 	}
 }
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/unfurl"
+)
+
+// linkPreviewQueueSize bounds the links waiting for a background fetcher;
+// links found while it is full are picked up on a later read
+const linkPreviewQueueSize = 100
+
+// LinkPreviewService defines the interface for previews of the links in posts
+// and chat messages. Previews are cached by URL; failures are cached too, and
+// a text's links that can't be previewed are simply left out.
+type LinkPreviewService interface {
+	// Previews returns the cached previews of the links in each text, in
+	// order. Links that aren't cached, or whose preview is stale, are fetched
+	// in the background and show up on a later read.
+	Previews(contents []string) [][]*models.LinkPreview
+	// Unfurl is like Previews but fetches missing previews right away, taking
+	// at most the configured timeout. Used for chat messages, which are only
+	// delivered once.
+	Unfurl(ctx context.Context, content string) []*models.LinkPreview
+	// StartFetcher runs the background fetchers until ctx is done
+	StartFetcher(ctx context.Context)
+}
+
+// linkPreviewService implements LinkPreviewService
+type linkPreviewService struct {
+	linkPreviewRepo repositories.LinkPreviewRepository
+	fetcher         unfurl.Fetcher
+	cfg             config.LinkPreviewConfig
+	queue           chan string // Links waiting for a background fetcher
+
+	mu      sync.Mutex
+	pending map[string]bool // Queued or being fetched
+}
+
+// NewLinkPreviewService creates a new LinkPreviewService. The fetcher is
+// responsible for keeping requests away from private addresses.
+func NewLinkPreviewService(linkPreviewRepo repositories.LinkPreviewRepository, fetcher unfurl.Fetcher, cfg config.LinkPreviewConfig) LinkPreviewService {
+	return &linkPreviewService{
+		linkPreviewRepo: linkPreviewRepo,
+		fetcher:         fetcher,
+		cfg:             cfg,
+		queue:           make(chan string, linkPreviewQueueSize),
+		pending:         make(map[string]bool),
+	}
+}
+
+// Previews looks up the previews of the links in several texts at once
+func (s *linkPreviewService) Previews(contents []string) [][]*models.LinkPreview {
+	urls, cached := s.lookup(contents...)
+	previews := make([][]*models.LinkPreview, len(contents))
+	for i := range contents {
+		previews[i] = make([]*models.LinkPreview, 0, len(urls[i]))
+		for _, url := range urls[i] {
+			preview := cached[url]
+			if preview == nil || s.isStale(preview) {
+				s.enqueue(url)
+			}
+			if showable(preview) {
+				previews[i] = append(previews[i], preview)
+			}
+		}
+	}
+	return previews
+}
+
+// Unfurl looks up the previews of the links in content, fetching missing ones in parallel
+func (s *linkPreviewService) Unfurl(ctx context.Context, content string) []*models.LinkPreview {
+	links, cached := s.lookup(content)
+	urls := links[0]
+	found := make([]*models.LinkPreview, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		preview := cached[url]
+		if preview != nil && !s.isStale(preview) {
+			found[i] = preview
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found[i] = s.fetch(ctx, url)
+		}()
+	}
+	wg.Wait()
+
+	previews := make([]*models.LinkPreview, 0, len(urls))
+	for _, preview := range found {
+		if showable(preview) {
+			previews = append(previews, preview)
+		}
+	}
+	return previews
+}
+
+// lookup extracts the links of each text and reads their cached previews
+// with one query. Links are returned by text.
+func (s *linkPreviewService) lookup(contents ...string) ([][]string, map[string]*models.LinkPreview) {
+	links := make([][]string, len(contents))
+	if !s.cfg.Enabled {
+		return links, nil
+	}
+	var all []string
+	for i, content := range contents {
+		links[i] = unfurl.ExtractURLs(content, s.cfg.MaxPerText)
+		all = append(all, links[i]...)
+	}
+	if len(all) == 0 {
+		return links, nil
+	}
+	cached, err := s.linkPreviewRepo.GetByURLs(all)
+	if err != nil {
+		log.Printf("Error loading link previews: %v", err)
+		return make([][]string, len(contents)), nil
+	}
+	return links, cached
+}
+
+// isStale reports whether a cached preview should be fetched again
+func (s *linkPreviewService) isStale(preview *models.LinkPreview) bool {
+	ttl := s.cfg.CacheTTL
+	if preview.Failed {
+		ttl = s.cfg.FailureTTL
+	}
+	return time.Since(preview.FetchedAt) > ttl
+}
+
+// showable reports whether a preview has something to show
+func showable(preview *models.LinkPreview) bool {
+	return preview != nil && !preview.Failed && (preview.Title != "" || preview.ImageURL != "")
+}
+
+// fetch unfurls a link and caches the result, including failures. A stale
+// preview is replaced even if fetching it again fails. Nothing is cached if the
+// caller cancels ctx, since the link may be fine.
+func (s *linkPreviewService) fetch(ctx context.Context, url string) *models.LinkPreview {
+	fetchCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	preview := &models.LinkPreview{URL: url, FetchedAt: time.Now()}
+	meta, err := unfurl.Unfurl(fetchCtx, s.fetcher, url)
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error fetching link preview of %s: %v", url, err)
+		preview.Failed = true
+	} else {
+		preview.Title = meta.Title
+		preview.Description = meta.Description
+		preview.ImageURL = meta.ImageURL
+		preview.SiteName = meta.SiteName
+		preview.Type = meta.Type
+	}
+	if err := s.linkPreviewRepo.Save(preview); err != nil {
+		log.Printf("Error caching link preview of %s: %v", url, err)
+	}
+	return preview
+}
+
+// enqueue hands a link to the background fetchers unless it is already
+// waiting. Links are dropped if the queue is full.
+func (s *linkPreviewService) enqueue(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[url] {
+		return
+	}
+	select {
+	case s.queue <- url:
+		s.pending[url] = true
+	default:
+	}
+}
+
+// StartFetcher starts cfg.Workers background fetchers
+func (s *linkPreviewService) StartFetcher(ctx context.Context) {
+	if !s.cfg.Enabled || s.cfg.Workers <= 0 {
+		log.Printf("Link preview fetching disabled; only cached previews and chat messages are unfurled")
+		return
+	}
+	for i := 0; i < s.cfg.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case url := <-s.queue:
+					s.fetch(ctx, url)
+					s.mu.Lock()
+					delete(s.pending, url)
+					s.mu.Unlock()
+				}
+			}
+		}()
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/storage"
+)
+
+// ErrMediaGCInProgress is returned when a collection is requested while one is running
+var ErrMediaGCInProgress = errors.New("media garbage collection already in progress")
+
+// MediaGCReport summarizes a garbage collection run
+type MediaGCReport struct {
+	DryRun         bool      `json:"dry_run"`
+	StartedAt      time.Time `json:"started_at"`
+	Duration       string    `json:"duration"`
+	MediaMarked    int64     `json:"media_marked"`    // Media found unused for the first time
+	MediaDeleted   int       `json:"media_deleted"`   // Media unused for longer than the grace period
+	BlobsDeleted   int       `json:"blobs_deleted"`   // Files deleted, including those of deleted media
+	BytesReclaimed int64     `json:"bytes_reclaimed"` // Size of the deleted files
+}
+
+// MediaGCService defines the interface for removing media nothing uses anymore
+type MediaGCService interface {
+	// Collect deletes unused media and stray blobs. With dryRun it only reports what it would delete.
+	Collect(ctx context.Context, dryRun bool) (*MediaGCReport, error)
+	// Start runs Collect every cfg.GCInterval until ctx is done
+	Start(ctx context.Context)
+}
+
+// mediaGCService implements MediaGCService
+type mediaGCService struct {
+	mediaRepo repositories.MediaRepository
+	store     storage.BlobStore
+	cfg       config.MediaConfig
+	running   sync.Mutex
+}
+
+// NewMediaGCService creates a new MediaGCService
+func NewMediaGCService(mediaRepo repositories.MediaRepository, store storage.BlobStore, cfg config.MediaConfig) MediaGCService {
+	return &mediaGCService{
+		mediaRepo: mediaRepo,
+		store:     store,
+		cfg:       cfg,
+	}
+}
+
+// Collect works in two passes, both honoring the grace period:
+//
+//  1. Media records: records that nothing references are marked with the time
+//     they were first found unused, and deleted with their files once they have
+//     stayed unused for the grace period.
+//  2. Blobs: files under "media/" that no record owns (e.g. left behind by
+//     deleted users or interrupted uploads) are deleted once they are older
+//     than the grace period.
+//
+// Real runs also prune the upload log of uploads that no longer count against
+// the hourly upload limit. Dry runs change nothing: they only query what a real
+// run at the same time would mark and delete.
+func (s *mediaGCService) Collect(ctx context.Context, dryRun bool) (*MediaGCReport, error) {
+	if !s.running.TryLock() {
+		return nil, ErrMediaGCInProgress
+	}
+	defer s.running.Unlock()
+
+	now := time.Now().UTC()
+	cutoff := now.Add(-s.cfg.GCGracePeriod)
+	report := &MediaGCReport{DryRun: dryRun, StartedAt: now}
+
+	var marked int64
+	var err error
+	if dryRun {
+		marked, err = s.mediaRepo.CountUnmarkedOrphans()
+	} else {
+		if _, err = s.mediaRepo.ClearOrphans(); err == nil {
+			marked, err = s.mediaRepo.MarkOrphans(now)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.MediaMarked = marked
+
+	ids, err := s.mediaRepo.ListOrphanIDs(cutoff)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		media, err := s.mediaRepo.GetByID(id)
+		if err != nil {
+			if errors.Is(err, repositories.ErrMediaNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		keys := []string{media.StorageKey}
+		size := media.Size
+		for _, v := range media.Variants {
+			keys = append(keys, v.StorageKey)
+			size += v.Size
+		}
+
+		if !dryRun {
+			deleted, err := s.mediaRepo.DeleteOrphan(id)
+			if err != nil {
+				return nil, err
+			}
+			if !deleted {
+				continue // Referenced again since it was marked
+			}
+			for _, key := range keys {
+				if err := s.store.Delete(ctx, key); err != nil {
+					log.Printf("Error removing blob %s of orphaned media %s: %v", key, id, err)
+				}
+			}
+		}
+		report.MediaDeleted++
+		report.BlobsDeleted += len(keys)
+		report.BytesReclaimed += size
+	}
+
+	err = s.store.List(ctx, "media/", func(blob storage.BlobInfo) error {
+		if !blob.ModTime.Before(cutoff) {
+			return nil // May belong to an upload or processing run that has not been recorded yet
+		}
+		owned, err := s.mediaRepo.StorageKeyExists(blob.Key)
+		if err != nil || owned {
+			return err
+		}
+		if !dryRun {
+			if err := s.store.Delete(ctx, blob.Key); err != nil {
+				log.Printf("Error removing stray blob %s: %v", blob.Key, err)
+				return nil
+			}
+		}
+		report.BlobsDeleted++
+		report.BytesReclaimed += blob.Size
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		if _, err := s.mediaRepo.PruneUploadLog(now.Add(-uploadRateWindow)); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Duration = time.Since(now).Round(time.Millisecond).String()
+	return report, nil
+}
+
+// Start collects garbage on a schedule. It does nothing if the interval is zero.
+func (s *mediaGCService) Start(ctx context.Context) {
+	if s.cfg.GCInterval <= 0 {
+		return
+	}
+
+	log.Printf("Media garbage collection every %s (grace period %s, dry run %t)", s.cfg.GCInterval, s.cfg.GCGracePeriod, s.cfg.GCDryRun)
+	go func() {
+		ticker := time.NewTicker(s.cfg.GCInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.Collect(ctx, s.cfg.GCDryRun)
+				if err != nil {
+					log.Printf("Media garbage collection failed: %v", err)
+					continue
+				}
+				log.Printf("Media garbage collection: %d media and %d files deleted, %d bytes reclaimed (dry run %t)",
+					report.MediaDeleted, report.BlobsDeleted, report.BytesReclaimed, report.DryRun)
+			}
+		}
+	}()
+}
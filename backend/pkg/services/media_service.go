@@ -0,0 +1,690 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HASANALI117/social-network/pkg/config"
+	"github.com/HASANALI117/social-network/pkg/imaging"
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// MediaResponse is the DTO for uploaded media sent to clients
+type MediaResponse struct {
+	ID          string            `json:"id"`
+	URL         string            `json:"url"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Status      string            `json:"status"`
+	Width       int               `json:"width,omitempty"`
+	Height      int               `json:"height,omitempty"`
+	Blurhash    string            `json:"blurhash,omitempty"`
+	Variants    map[string]string `json:"variants,omitempty"` // Variant name -> URL, once processed
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// StorageUsageResponse reports a user's media usage against the quota of their role.
+// Limits of zero are unlimited.
+type StorageUsageResponse struct {
+	Role            string `json:"role"`
+	UsedBytes       int64  `json:"used_bytes"`
+	MaxBytes        int64  `json:"max_bytes"`
+	FileCount       int    `json:"file_count"`
+	MaxFiles        int    `json:"max_files"`
+	UploadsLastHour int    `json:"uploads_last_hour"`
+	UploadsPerHour  int    `json:"uploads_per_hour"`
+}
+
+// uploadRateWindow is the rolling window of the UploadsPerHour quota. Uploads
+// count against it even if the media is deleted before the window ends.
+const uploadRateWindow = time.Hour
+
+// UploadRateLimitError is returned by Upload when the user has used up their
+// hourly uploads. It wraps ErrUploadRateLimited.
+type UploadRateLimitError struct {
+	RetryAfter time.Duration // Until the oldest upload in the window leaves it
+}
+
+func (e *UploadRateLimitError) Error() string {
+	return fmt.Sprintf("%v, retry in %s", ErrUploadRateLimited, e.RetryAfter.Round(time.Second))
+}
+
+func (e *UploadRateLimitError) Unwrap() error {
+	return ErrUploadRateLimited
+}
+
+var (
+	ErrMediaNotFound         = repositories.ErrMediaNotFound // Alias for convenience
+	ErrMediaForbidden        = errors.New("media belongs to another user")
+	ErrMediaTooLarge         = errors.New("file exceeds the maximum upload size")
+	ErrUnsupportedMediaType  = errors.New("file type is not allowed")
+	ErrMediaNotImage         = errors.New("media is not an image")
+	ErrMediaNotAttachable    = errors.New("media is not an image or video")
+	ErrMediaProcessing       = errors.New("media is still being processed")
+	ErrMediaProcessingFailed = errors.New("media could not be processed")
+	ErrMediaVariantNotFound  = repositories.ErrMediaVariantNotFound // Alias for convenience
+	ErrStorageQuotaExceeded  = errors.New("upload would exceed the storage quota")
+	ErrFileQuotaExceeded     = errors.New("upload would exceed the file quota")
+	ErrUploadRateLimited     = errors.New("hourly upload limit reached")
+)
+
+// MediaService defines the interface for uploading and serving media
+type MediaService interface {
+	Upload(ctx context.Context, ownerID string, file io.ReadSeeker, size int64) (*MediaResponse, error)
+	GetByID(mediaID string) (*MediaResponse, error)
+	GetByIDs(mediaIDs []string) (map[string]*MediaResponse, error) // By ID; missing media is left out
+	// Open returns the contents of processed media, or of one of its variants
+	// if variant is not empty
+	Open(ctx context.Context, mediaID, variant string) (io.ReadCloser, *MediaResponse, error)
+	// ResolveImage validates that userID may reference mediaID as an image
+	// (post image, comment image or avatar) and returns it.
+	ResolveImage(mediaID, userID string) (*MediaResponse, error)
+	// ResolveAttachment is like ResolveImage but also accepts videos, for post galleries
+	ResolveAttachment(mediaID, userID string) (*MediaResponse, error)
+	// DeleteIfUnused removes media, including its files, once nothing references it
+	DeleteIfUnused(ctx context.Context, mediaID string) error
+	MaxUploadBytes() int64
+	// StorageUsage reports what userID has uploaded and the limits that apply
+	StorageUsage(userID string) (*StorageUsageResponse, error)
+	// StartProcessor runs the background workers that process uploads until ctx is done
+	StartProcessor(ctx context.Context)
+
+	// CanView reports whether userID (empty for anonymous requests) may view
+	// the media, following the rules of the entities that use it
+	CanView(mediaID, userID string) (bool, error)
+	// SignedURL returns a short-lived URL that serves the media without further checks
+	SignedURL(mediaID, variant string) string
+	// VerifySignedURL checks the signature in query and returns when it expires
+	VerifySignedURL(mediaID, variant string, query url.Values) (time.Time, bool)
+}
+
+// mediaService implements MediaService interface
+type mediaService struct {
+	mediaRepo    repositories.MediaRepository
+	postRepo     repositories.PostRepository     // Access checks for post and comment images
+	followerRepo repositories.FollowerRepository // ...
+	groupRepo    repositories.GroupRepository    // ... and group chat images
+	store        storage.BlobStore
+	cfg          config.MediaConfig
+	adminIDs     map[string]bool // Users with the admin quota
+	urlKey       []byte          // HMAC key for signed URLs
+	queue        chan string     // IDs of uploads waiting for a worker
+}
+
+// NewMediaService creates a new MediaService
+func NewMediaService(mediaRepo repositories.MediaRepository, postRepo repositories.PostRepository, followerRepo repositories.FollowerRepository, groupRepo repositories.GroupRepository, store storage.BlobStore, cfg config.MediaConfig, adminUserIDs []string) MediaService {
+	urlKey := []byte(cfg.URLSecret)
+	if len(urlKey) == 0 {
+		urlKey = make([]byte, 32)
+		if _, err := rand.Read(urlKey); err != nil {
+			log.Fatalf("Failed to generate media URL key: %v", err)
+		}
+		log.Printf("MEDIA_URL_SECRET is not set; signed media URLs will not survive a restart or work across instances")
+	}
+	adminIDs := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		adminIDs[id] = true
+	}
+	return &mediaService{
+		mediaRepo:    mediaRepo,
+		postRepo:     postRepo,
+		followerRepo: followerRepo,
+		groupRepo:    groupRepo,
+		store:        store,
+		cfg:          cfg,
+		adminIDs:     adminIDs,
+		urlKey:       urlKey,
+		queue:        make(chan string, 100),
+	}
+}
+
+// MediaURL returns the URL media is served from
+func MediaURL(mediaID string) string {
+	return "/api/media/" + mediaID
+}
+
+func mapMediaToResponse(media *models.Media) *MediaResponse {
+	resp := &MediaResponse{
+		ID:          media.ID,
+		URL:         MediaURL(media.ID),
+		ContentType: media.ContentType,
+		Size:        media.Size,
+		Status:      media.Status,
+		Width:       media.Width,
+		Height:      media.Height,
+		Blurhash:    media.Blurhash,
+		CreatedAt:   media.CreatedAt,
+	}
+	if media.Status == models.MediaStatusReady && len(media.Variants) > 0 {
+		resp.Variants = map[string]string{imaging.Full.Name: resp.URL}
+		for _, v := range media.Variants {
+			resp.Variants[v.Name] = resp.URL + "/" + v.Name
+		}
+	}
+	return resp
+}
+
+// MaxUploadBytes returns the largest file Upload accepts
+func (s *mediaService) MaxUploadBytes() int64 {
+	return s.cfg.MaxUploadBytes
+}
+
+// Upload validates and stores a file. The content type is sniffed from the
+// file contents; the type claimed by the client is ignored.
+func (s *mediaService) Upload(ctx context.Context, ownerID string, file io.ReadSeeker, size int64) (*MediaResponse, error) {
+	if size > s.cfg.MaxUploadBytes {
+		return nil, ErrMediaTooLarge
+	}
+	if size <= 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	if !slices.Contains(s.cfg.AllowedTypes, contentType) {
+		return nil, ErrUnsupportedMediaType
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind upload: %w", err)
+	}
+	if err := s.checkQuota(ownerID, size); err != nil {
+		return nil, err
+	}
+
+	media := &models.Media{
+		ID:          uuid.New().String(),
+		OwnerID:     ownerID,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}
+	media.StorageKey = "media/" + media.ID
+
+	if err := s.store.Put(ctx, media.StorageKey, file, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+	if err := s.mediaRepo.Create(media); err != nil {
+		// Don't leave an unreferenced blob behind
+		if delErr := s.store.Delete(ctx, media.StorageKey); delErr != nil {
+			log.Printf("Error removing blob %s after failed media insert: %v", media.StorageKey, delErr)
+		}
+		return nil, fmt.Errorf("failed to save media: %w", err)
+	}
+
+	// Hand the upload to a worker; if the queue is full the poller picks it up later
+	select {
+	case s.queue <- media.ID:
+	default:
+	}
+	return mapMediaToResponse(media), nil
+}
+
+// role returns the role whose quota applies to userID
+func (s *mediaService) role(userID string) string {
+	if s.adminIDs[userID] {
+		return config.RoleAdmin
+	}
+	return config.RoleUser
+}
+
+// StorageUsage reports the user's usage and quota
+func (s *mediaService) StorageUsage(userID string) (*StorageUsageResponse, error) {
+	role := s.role(userID)
+	quota := s.cfg.Quotas[role]
+	bytes, files, err := s.mediaRepo.GetUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+	uploads, err := s.mediaRepo.ListUploadTimes(userID, time.Now().Add(-uploadRateWindow))
+	if err != nil {
+		return nil, err
+	}
+	return &StorageUsageResponse{
+		Role:            role,
+		UsedBytes:       bytes,
+		MaxBytes:        quota.MaxBytes,
+		FileCount:       files,
+		MaxFiles:        quota.MaxFiles,
+		UploadsLastHour: len(uploads),
+		UploadsPerHour:  quota.UploadsPerHour,
+	}, nil
+}
+
+// checkQuota rejects an upload of size bytes that would take the owner over
+// the quota of their role. Concurrent uploads are checked independently, so
+// they can overshoot a limit by a few files.
+func (s *mediaService) checkQuota(ownerID string, size int64) error {
+	quota := s.cfg.Quotas[s.role(ownerID)]
+
+	if quota.UploadsPerHour > 0 {
+		now := time.Now()
+		uploads, err := s.mediaRepo.ListUploadTimes(ownerID, now.Add(-uploadRateWindow))
+		if err != nil {
+			return err
+		}
+		if len(uploads) >= quota.UploadsPerHour {
+			// Another upload is allowed once enough of them have left the window
+			oldest := uploads[len(uploads)-quota.UploadsPerHour]
+			return &UploadRateLimitError{RetryAfter: oldest.Add(uploadRateWindow).Sub(now)}
+		}
+	}
+
+	if quota.MaxBytes > 0 || quota.MaxFiles > 0 {
+		bytes, files, err := s.mediaRepo.GetUsage(ownerID)
+		if err != nil {
+			return err
+		}
+		if quota.MaxBytes > 0 && bytes+size > quota.MaxBytes {
+			return ErrStorageQuotaExceeded
+		}
+		if quota.MaxFiles > 0 && files >= quota.MaxFiles {
+			return ErrFileQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// GetByID retrieves media metadata
+func (s *mediaService) GetByID(mediaID string) (*MediaResponse, error) {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	return mapMediaToResponse(media), nil
+}
+
+// GetByIDs retrieves the metadata of several media at once
+func (s *mediaService) GetByIDs(mediaIDs []string) (map[string]*MediaResponse, error) {
+	media, err := s.mediaRepo.GetByIDs(mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+	responses := make(map[string]*MediaResponse, len(media))
+	for id, m := range media {
+		responses[id] = mapMediaToResponse(m)
+	}
+	return responses, nil
+}
+
+// Open returns the contents of a media file; the caller must close the reader.
+// The returned response describes the file actually served, so for variants its
+// content type and size are the variant's.
+func (s *mediaService) Open(ctx context.Context, mediaID, variant string) (io.ReadCloser, *MediaResponse, error) {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch media.Status {
+	case models.MediaStatusReady:
+	case models.MediaStatusFailed:
+		return nil, nil, ErrMediaProcessingFailed
+	default:
+		// The original may still carry metadata, so nothing is served before processing
+		return nil, nil, ErrMediaProcessing
+	}
+
+	resp := mapMediaToResponse(media)
+	key := media.StorageKey
+	if variant != "" && variant != imaging.Full.Name {
+		v, err := s.mediaRepo.GetVariant(mediaID, variant)
+		if err != nil {
+			return nil, nil, err
+		}
+		key = v.StorageKey
+		resp.ContentType, resp.Size, resp.Width, resp.Height = v.ContentType, v.Size, v.Width, v.Height
+	}
+
+	body, err := s.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			log.Printf("Media %s has no blob at %s", media.ID, key)
+			return nil, nil, ErrMediaNotFound
+		}
+		return nil, nil, err
+	}
+	return body, resp, nil
+}
+
+// ResolveImage checks that mediaID exists, was uploaded by userID and is an image
+func (s *mediaService) ResolveImage(mediaID, userID string) (*MediaResponse, error) {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media.OwnerID != userID {
+		return nil, ErrMediaForbidden
+	}
+	if !strings.HasPrefix(media.ContentType, "image/") {
+		return nil, ErrMediaNotImage
+	}
+	if media.Status == models.MediaStatusFailed {
+		return nil, ErrMediaProcessingFailed
+	}
+	return mapMediaToResponse(media), nil
+}
+
+// ResolveAttachment checks that mediaID exists, was uploaded by userID and is an image or video
+func (s *mediaService) ResolveAttachment(mediaID, userID string) (*MediaResponse, error) {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media.OwnerID != userID {
+		return nil, ErrMediaForbidden
+	}
+	if !strings.HasPrefix(media.ContentType, "image/") && !strings.HasPrefix(media.ContentType, "video/") {
+		return nil, ErrMediaNotAttachable
+	}
+	if media.Status == models.MediaStatusFailed {
+		return nil, ErrMediaProcessingFailed
+	}
+	return mapMediaToResponse(media), nil
+}
+
+// DeleteIfUnused deletes the media record and its blobs if no post, comment,
+// avatar or message references it. Missing media is not an error.
+func (s *mediaService) DeleteIfUnused(ctx context.Context, mediaID string) error {
+	refs, err := s.mediaRepo.GetReferences(mediaID)
+	if err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		return nil
+	}
+
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		if errors.Is(err, ErrMediaNotFound) {
+			return nil
+		}
+		return err
+	}
+	// Remove the record first: a leftover blob is harmless, a record without its blob is not
+	if err := s.mediaRepo.Delete(mediaID); err != nil && !errors.Is(err, ErrMediaNotFound) {
+		return err
+	}
+	keys := []string{media.StorageKey}
+	for _, v := range media.Variants {
+		keys = append(keys, v.StorageKey)
+	}
+	s.deleteBlobs(ctx, keys)
+	return nil
+}
+
+// CanView allows the uploader, and otherwise anyone who may view one of the
+// entities using the media: the post (or the post of the comment) it is
+// attached to, the participants of the direct message and the members of the
+// group chat it was sent in. Avatars are visible to every signed-in user, as
+// they are shown wherever the user or group is listed. Media that is not used
+// anywhere yet is private to its uploader.
+func (s *mediaService) CanView(mediaID, userID string) (bool, error) {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return false, err
+	}
+	if userID != "" && media.OwnerID == userID {
+		return true, nil
+	}
+
+	refs, err := s.mediaRepo.GetReferences(mediaID)
+	if err != nil {
+		return false, err
+	}
+	for _, ref := range refs {
+		switch ref.Kind {
+		case models.MediaRefPost, models.MediaRefComment:
+			post, err := s.postRepo.GetByID(ref.EntityID)
+			if err != nil {
+				if !errors.Is(err, repositories.ErrPostNotFound) {
+					log.Printf("Error loading post %s for media %s: %v", ref.EntityID, mediaID, err)
+				}
+				continue
+			}
+			if canViewPost(post, userID, s.postRepo, s.followerRepo, s.groupRepo) {
+				return true, nil
+			}
+		case models.MediaRefUserAvatar, models.MediaRefGroupAvatar:
+			if userID != "" {
+				return true, nil
+			}
+		case models.MediaRefDirectMessage:
+			if userID != "" && (userID == ref.EntityID || userID == ref.OtherID) {
+				return true, nil
+			}
+		case models.MediaRefGroupMessage:
+			if userID == "" {
+				continue
+			}
+			isMember, err := s.groupRepo.IsMember(ref.EntityID, userID)
+			if err != nil {
+				log.Printf("Error checking group membership for user %s in group %s for media %s: %v", userID, ref.EntityID, mediaID, err)
+				continue
+			}
+			if isMember {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// SignedURL signs the media path with an expiry. Expiries are rounded up to a
+// multiple of the TTL, so URLs stay stable (and cacheable by the browser) for
+// at least one TTL and at most two.
+func (s *mediaService) SignedURL(mediaID, variant string) string {
+	variant = normalizeVariant(variant)
+	expires := time.Now().Truncate(s.cfg.URLTTL).Add(2 * s.cfg.URLTTL).Unix()
+
+	path := MediaURL(mediaID)
+	if variant != "" {
+		path += "/" + variant
+	}
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(mediaID, variant, expires))
+	return path + "?" + query.Encode()
+}
+
+// VerifySignedURL checks a signature produced by SignedURL
+func (s *mediaService) VerifySignedURL(mediaID, variant string, query url.Values) (time.Time, bool) {
+	signature := query.Get("signature")
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if signature == "" || err != nil {
+		return time.Time{}, false
+	}
+	expiresAt := time.Unix(expires, 0)
+	if time.Now().After(expiresAt) {
+		return time.Time{}, false
+	}
+	want := s.sign(mediaID, normalizeVariant(variant), expires)
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+func (s *mediaService) sign(mediaID, variant string, expires int64) string {
+	mac := hmac.New(sha256.New, s.urlKey)
+	fmt.Fprintf(mac, "%s/%s:%d", mediaID, variant, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeVariant maps the full-size variant to the media's own URL
+func normalizeVariant(variant string) string {
+	if variant == imaging.Full.Name {
+		return ""
+	}
+	return variant
+}
+
+// StartProcessor requeues work interrupted by a restart, then starts
+// cfg.Workers workers. Uploads are normally handed over as they arrive; a
+// poller also scans for pending media every cfg.ProcessInterval in case the
+// queue was full or the server stopped before a worker got to them.
+func (s *mediaService) StartProcessor(ctx context.Context) {
+	if s.cfg.Workers <= 0 {
+		log.Printf("Media processing disabled; uploads will stay pending")
+		return
+	}
+	if n, err := s.mediaRepo.ResetStatus(models.MediaStatusProcessing, models.MediaStatusPending); err != nil {
+		log.Printf("Error requeueing interrupted media processing: %v", err)
+	} else if n > 0 {
+		log.Printf("Requeued %d media items interrupted during processing", n)
+	}
+
+	for i := 0; i < s.cfg.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-s.queue:
+					s.process(ctx, id)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		s.enqueuePending(ctx)
+		ticker := time.NewTicker(s.cfg.ProcessInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.enqueuePending(ctx)
+			}
+		}
+	}()
+}
+
+// enqueuePending hands pending media to the workers, waiting while the queue is full
+func (s *mediaService) enqueuePending(ctx context.Context) {
+	ids, err := s.mediaRepo.ListIDsByStatus(models.MediaStatusPending, cap(s.queue))
+	if err != nil {
+		log.Printf("Error listing pending media: %v", err)
+		return
+	}
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			return
+		case s.queue <- id:
+		}
+	}
+}
+
+// process claims and processes one upload, recording failures on the media
+func (s *mediaService) process(ctx context.Context, mediaID string) {
+	claimed, err := s.mediaRepo.Claim(mediaID)
+	if err != nil {
+		log.Printf("Error claiming media %s: %v", mediaID, err)
+		return
+	}
+	if !claimed {
+		return // Already processed, or being processed by another worker
+	}
+
+	if err := s.processImage(ctx, mediaID); err != nil {
+		log.Printf("Error processing media %s: %v", mediaID, err)
+		if err := s.mediaRepo.MarkFailed(mediaID, err.Error()); err != nil {
+			log.Printf("Error marking media %s as failed: %v", mediaID, err)
+		}
+	}
+}
+
+// processImage replaces the uploaded original with a re-encoded, upright copy
+// without metadata, and stores the smaller variants next to it. The original
+// is deleted once the media points at the processed files.
+func (s *mediaService) processImage(ctx context.Context, mediaID string) error {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(media.ContentType, "image/") {
+		// Nothing to normalize
+		return s.mediaRepo.MarkReady(media)
+	}
+
+	body, err := s.store.Get(ctx, media.StorageKey)
+	if err != nil {
+		return fmt.Errorf("failed to read original: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, s.cfg.MaxUploadBytes+1))
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read original: %w", err)
+	}
+
+	result, err := imaging.Process(data, imaging.DefaultVariants, s.cfg.MaxPixels)
+	if err != nil {
+		return err
+	}
+
+	originalKey := media.StorageKey
+	processed := *media
+	processed.Width, processed.Height, processed.Blurhash = result.Width, result.Height, result.Blurhash
+	processed.Variants = nil
+	var written []string
+	for _, out := range result.Variants {
+		key := fmt.Sprintf("media/%s.%s", media.ID, out.Name)
+		if err := s.store.Put(ctx, key, bytes.NewReader(out.Data), int64(len(out.Data)), out.ContentType); err != nil {
+			s.deleteBlobs(ctx, written)
+			return fmt.Errorf("failed to store %s variant: %w", out.Name, err)
+		}
+		written = append(written, key)
+
+		if out.Name == imaging.Full.Name {
+			processed.StorageKey, processed.ContentType, processed.Size = key, out.ContentType, int64(len(out.Data))
+			continue
+		}
+		processed.Variants = append(processed.Variants, models.MediaVariant{
+			MediaID:     media.ID,
+			Name:        out.Name,
+			StorageKey:  key,
+			ContentType: out.ContentType,
+			Width:       out.Width,
+			Height:      out.Height,
+			Size:        int64(len(out.Data)),
+		})
+	}
+
+	if err := s.mediaRepo.MarkReady(&processed); err != nil {
+		s.deleteBlobs(ctx, written)
+		return err
+	}
+	s.deleteBlobs(ctx, []string{originalKey})
+	return nil
+}
+
+// deleteBlobs removes blobs on a best-effort basis
+func (s *mediaService) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("Error removing blob %s: %v", key, err)
+		}
+	}
+}
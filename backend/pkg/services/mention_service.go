@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+)
+
+// maxMentionsPerText caps the users notified by one post or comment; later mentions are ignored
+const maxMentionsPerText = 20
+
+// mentionPattern matches an '@' followed by a username. The character before
+// the '@' is captured so that email addresses (bob@example.com) aren't taken
+// for mentions.
+var mentionPattern = regexp.MustCompile(`(^|[^\p{L}\p{N}_.@/])@([\p{L}\p{N}_.\-]+)`)
+
+// extractMentions returns the distinct usernames mentioned in a text, in order
+// of appearance. Trailing dots and dashes are dropped so "thanks @bob." finds bob.
+func extractMentions(text string) []string {
+	usernames := []string{}
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[2], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+		if len(usernames) == maxMentionsPerText {
+			break
+		}
+	}
+	return usernames
+}
+
+// MentionService defines the interface for @mentions in posts and comments
+type MentionService interface {
+	// ProcessMentions resolves the @usernames in content written by authorID
+	// in a post, or in a comment on it when commentID is set. Mentioned users
+	// who can view the post are recorded and sent a mention notification;
+	// others are ignored. Returns the IDs of the notified users.
+	ProcessMentions(authorID, postID, commentID, content string) ([]string, error)
+	// RefreshPostAudience brings the mentions of a post in line with its
+	// audience after it changed. Users who can no longer see the post lose
+	// their mentions in it and its comments, and the notifications about it.
+	// Users mentioned in the post who can see it now are notified.
+	RefreshPostAudience(postID string) error
+}
+
+// mentionService implements MentionService
+type mentionService struct {
+	mentionRepo         repositories.MentionRepository
+	userRepo            repositories.UserRepository     // Resolves usernames
+	postRepo            repositories.PostRepository     // Needed for visibility checks
+	followerRepo        repositories.FollowerRepository // Needed for visibility checks
+	groupRepo           repositories.GroupRepository    // Needed for visibility checks
+	notificationService NotificationService
+}
+
+// NewMentionService creates a new MentionService
+func NewMentionService(mentionRepo repositories.MentionRepository, userRepo repositories.UserRepository, postRepo repositories.PostRepository, followerRepo repositories.FollowerRepository, groupRepo repositories.GroupRepository, notificationService NotificationService) MentionService {
+	return &mentionService{
+		mentionRepo:         mentionRepo,
+		userRepo:            userRepo,
+		postRepo:            postRepo,
+		followerRepo:        followerRepo,
+		groupRepo:           groupRepo,
+		notificationService: notificationService,
+	}
+}
+
+// ProcessMentions records and notifies the users mentioned in a post or comment.
+// Unknown usernames and self-mentions are skipped.
+func (s *mentionService) ProcessMentions(authorID, postID, commentID, content string) ([]string, error) {
+	usernames := extractMentions(content)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post %s for mentions: %w", postID, err)
+	}
+	return s.mention(authorID, post, commentID, usernames, nil)
+}
+
+// refreshPostMentions calls RefreshPostAudience for posts whose audience
+// changed. The change is already saved, so failures are only logged.
+func refreshPostMentions(mentionService MentionService, postIDs []string) {
+	for _, postID := range postIDs {
+		if err := mentionService.RefreshPostAudience(postID); err != nil {
+			log.Printf("Error refreshing mentions of post %s: %v", postID, err)
+		}
+	}
+}
+
+// RefreshPostAudience withdraws and adds mentions of a post for its new audience
+func (s *mentionService) RefreshPostAudience(postID string) error {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return fmt.Errorf("failed to get post %s for mentions: %w", postID, err)
+	}
+	if post.Status != models.PostStatusPublished {
+		return nil // Mentions are processed when it's published
+	}
+	mentions, err := s.mentionRepo.ListByPost(postID)
+	if err != nil {
+		return err
+	}
+
+	canView := make(map[string]bool)
+	mentioned := make(map[string]bool) // Still mentioned in the post itself
+	for _, m := range mentions {
+		visible, checked := canView[m.UserID]
+		if !checked {
+			visible = canViewPost(post, m.UserID, s.postRepo, s.followerRepo, s.groupRepo)
+			canView[m.UserID] = visible
+			if !visible {
+				if err := s.mentionRepo.DeleteForUser(postID, m.UserID); err != nil {
+					return err
+				}
+				if err := s.notificationService.WithdrawNotifications(context.TODO(), m.UserID, models.PostEntityType, postID); err != nil {
+					return fmt.Errorf("failed to withdraw notifications of user %s about post %s: %w", m.UserID, postID, err)
+				}
+			}
+		}
+		if visible && !m.CommentID.Valid {
+			mentioned[m.UserID] = true
+		}
+	}
+
+	_, err = s.mention(post.UserID, post, "", extractMentions(post.Content), mentioned)
+	return err
+}
+
+// mention records and notifies the users among usernames who can view the
+// post, except those in skip
+func (s *mentionService) mention(authorID string, post *models.Post, commentID string, usernames []string, skip map[string]bool) ([]string, error) {
+	postID := post.ID
+	mentions := make([]models.Mention, 0, len(usernames))
+	for _, username := range usernames {
+		user, err := s.userRepo.GetByUsername(username)
+		if err != nil {
+			if !errors.Is(err, repositories.ErrUserNotFound) {
+				log.Printf("Error resolving mention @%s in post %s: %v", username, postID, err)
+			}
+			continue
+		}
+		if user.ID == authorID || skip[user.ID] {
+			continue
+		}
+		// Never tell users about content they aren't allowed to see
+		if !canViewPost(post, user.ID, s.postRepo, s.followerRepo, s.groupRepo) {
+			continue
+		}
+		mentions = append(mentions, models.Mention{
+			UserID:    user.ID,
+			AuthorID:  authorID,
+			PostID:    postID,
+			CommentID: sql.NullString{String: commentID, Valid: commentID != ""},
+		})
+	}
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	if err := s.mentionRepo.Create(mentions); err != nil {
+		return nil, fmt.Errorf("failed to record mentions: %w", err)
+	}
+
+	authorName := "Someone"
+	if author, err := s.userRepo.GetByID(authorID); err == nil && author != nil {
+		authorName = strings.TrimSpace(author.FirstName + " " + author.LastName)
+	}
+	where := "a post"
+	if commentID != "" {
+		where = "a comment"
+	}
+	message := fmt.Sprintf("%s mentioned you in %s.", authorName, where)
+
+	notified := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		_, err := s.notificationService.CreateNotification(
+			context.TODO(),
+			m.UserID,
+			models.MentionNotification,
+			models.PostEntityType,
+			message,
+			postID,
+		)
+		if err != nil {
+			log.Printf("Error sending mention notification to user %s for post %s: %v", m.UserID, postID, err)
+			continue
+		}
+		notified = append(notified, m.UserID)
+	}
+	return notified, nil
+}
@@ -12,9 +12,9 @@ import (
 // MessageService defines the interface for message-related business logic.
 type MessageService interface {
 	// GetDirectMessagesBetweenUsers retrieves paginated messages between two specified users.
-	// It returns the list of messages, the total count of messages between them, and an error if any.
-	GetDirectMessagesBetweenUsers(user1ID, user2ID string, limit, offset int) ([]models.Message, int64, error)
-	GetGroupMessages(groupID string, limit, offset int, requestingUserID string) ([]*models.GroupMessage, error)
+	// It returns the list of messages, the cursor of the next page, the total count of messages between them, and an error if any.
+	GetDirectMessagesBetweenUsers(user1ID, user2ID string, page models.Page) ([]models.Message, models.PageInfo, int64, error)
+	GetGroupMessages(groupID string, page models.Page, requestingUserID string) ([]*models.GroupMessage, models.PageInfo, error)
 	// GetChatPartners retrieves a list of users with whom the current user has a chat history.
 	GetChatPartners(currentUserID string) ([]models.ChatPartner, error)
 	// TODO: Add methods for sending messages if needed (currently handled by websocket)
@@ -24,52 +24,71 @@ type MessageService interface {
 type messageService struct {
 	messageRepo repositories.MessageRepository // Updated to use the new MessageRepository
 	groupRepo   repositories.GroupRepository // Needed for authorization (e.g., checking group membership)
+	linkPreviews LinkPreviewService // Previews of links in messages
 	// Add other dependencies like userRepo if needed
 }
 
 // NewMessageService creates a new MessageService.
-func NewMessageService(messageRepo repositories.MessageRepository, groupRepo repositories.GroupRepository) MessageService { // Updated parameter type
+func NewMessageService(messageRepo repositories.MessageRepository, groupRepo repositories.GroupRepository, linkPreviews LinkPreviewService) MessageService { // Updated parameter type
 	return &messageService{
 		messageRepo: messageRepo,
 		groupRepo:   groupRepo,
+		linkPreviews: linkPreviews,
 	}
 }
 
 // GetDirectMessagesBetweenUsers retrieves paginated direct messages between two users.
 // The repository layer handles fetching messages where the pair are sender/receiver in either order.
 // Authorization is implicitly handled by the handler ensuring the requestor is one of the users.
-func (s *messageService) GetDirectMessagesBetweenUsers(user1ID, user2ID string, limit, offset int) ([]models.Message, int64, error) {
+func (s *messageService) GetDirectMessagesBetweenUsers(user1ID, user2ID string, page models.Page) ([]models.Message, models.PageInfo, int64, error) {
 	// Call the repository method that fetches messages and total count
-	messages, totalCount, err := s.messageRepo.GetDirectMessagesBetweenUsers(user1ID, user2ID, limit, offset)
+	messages, info, totalCount, err := s.messageRepo.GetDirectMessagesBetweenUsers(user1ID, user2ID, page)
 	if err != nil {
 		// It's often better to return the specific repository error or wrap it
-		return nil, 0, fmt.Errorf("failed to get direct messages from repository: %w", err)
+		return nil, models.PageInfo{}, 0, fmt.Errorf("failed to get direct messages from repository: %w", err)
+	}
+
+	// Previews are cached, not stored with messages
+	contents := make([]string, len(messages))
+	for i := range messages {
+		contents[i] = messages[i].Content
+	}
+	for i, previews := range s.linkPreviews.Previews(contents) {
+		messages[i].LinkPreviews = previews
 	}
 
 	// TODO: Map to response DTOs if needed. Assuming models.Message is suitable for now.
-	return messages, totalCount, nil
+	return messages, info, totalCount, nil
 }
 
 // GetGroupMessages retrieves messages for a specific group.
 // Authorization: Ensure the requesting user is a member of the group.
-func (s *messageService) GetGroupMessages(groupID string, limit, offset int, requestingUserID string) ([]*models.GroupMessage, error) {
+func (s *messageService) GetGroupMessages(groupID string, page models.Page, requestingUserID string) ([]*models.GroupMessage, models.PageInfo, error) {
 	// Authorization check: Is user a member of the group?
 	isMember, err := s.groupRepo.IsMember(groupID, requestingUserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check group membership for user %s in group %s: %w", requestingUserID, groupID, err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to check group membership for user %s in group %s: %w", requestingUserID, groupID, err)
 	}
 	if !isMember {
 		// Using the error from group service for consistency
-		return nil, ErrGroupMemberRequired // Assuming ErrGroupMemberRequired is defined elsewhere
+		return nil, models.PageInfo{}, ErrGroupMemberRequired // Assuming ErrGroupMemberRequired is defined elsewhere
 	}
 
-	messages, err := s.messageRepo.GetGroupMessages(groupID, limit, offset, requestingUserID)
+	messages, info, err := s.messageRepo.GetGroupMessages(groupID, page, requestingUserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get group messages from repository: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to get group messages from repository: %w", err)
+	}
+
+	contents := make([]string, len(messages))
+	for i, message := range messages {
+		contents[i] = message.Content
+	}
+	for i, previews := range s.linkPreviews.Previews(contents) {
+		messages[i].LinkPreviews = previews
 	}
 
 	// TODO: Map to response DTOs if needed
-	return messages, nil
+	return messages, info, nil
 }
 
 // Note: ErrGroupMemberRequired is likely defined in group_service.go or a shared errors package
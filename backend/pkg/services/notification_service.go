@@ -19,11 +19,13 @@ type RealTimeNotifier interface {
 
 type NotificationService interface {
 	CreateNotification(ctx context.Context, userID string, notificationType models.NotificationType, entityType models.EntityType, message string, entityID string) (*models.Notification, error)
-	GetUserNotifications(ctx context.Context, userID string, limit, offset int) ([]*models.Notification, error)
+	GetUserNotifications(ctx context.Context, userID string, page models.Page) ([]*models.Notification, models.PageInfo, error)
 	MarkNotificationAsRead(ctx context.Context, notificationID string, userID string) error
 	MarkAllUserNotificationsAsRead(ctx context.Context, userID string) error
 	GetUnreadNotificationCount(ctx context.Context, userID string) (int, error)
 	SendNotificationToUser(userID string, notification *models.Notification) error
+	// WithdrawNotifications deletes a user's notifications about an entity, e.g. a post they can no longer see
+	WithdrawNotifications(ctx context.Context, userID string, entityType models.EntityType, entityID string) error
 }
 
 type notificationService struct {
@@ -79,8 +81,8 @@ func (s *notificationService) CreateNotification(ctx context.Context, userID str
 	return notification, nil
 }
 
-func (s *notificationService) GetUserNotifications(ctx context.Context, userID string, limit, offset int) ([]*models.Notification, error) {
-	return s.repo.GetByUserID(ctx, userID, limit, offset)
+func (s *notificationService) GetUserNotifications(ctx context.Context, userID string, page models.Page) ([]*models.Notification, models.PageInfo, error) {
+	return s.repo.GetByUserID(ctx, userID, page)
 }
 
 func (s *notificationService) MarkNotificationAsRead(ctx context.Context, notificationID string, userID string) error {
@@ -121,4 +123,8 @@ func (s *notificationService) SendNotificationToUser(userID string, notification
 	}
 	log.Printf("Attempting to send explicit notification to user %s via Notifier: %+v", userID, payload)
 	return s.notifier.NotifyUser(userID, payload)
-}
\ No newline at end of file
+}
+
+func (s *notificationService) WithdrawNotifications(ctx context.Context, userID string, entityType models.EntityType, entityID string) error {
+	return s.repo.DeleteByEntity(ctx, userID, entityType, entityID)
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+	"github.com/HASANALI117/social-network/pkg/types"
+)
+
+// Limits of polls
+const (
+	maxPollOptions          = 10
+	maxPollOptionTextLength = 100 // In characters
+	maxEmbeddedVoters       = 10  // Voters listed with each option; ListVoters pages through the rest
+)
+
+var (
+	ErrInvalidPoll = errors.New("invalid poll")
+	ErrInvalidVote = errors.New("invalid vote")
+	ErrPollClosed  = errors.New("poll is closed")
+	// ErrPollAnonymous indicates that the poll doesn't show who voted
+	ErrPollAnonymous = errors.New("poll is anonymous")
+	// ErrPollOptionNotFound indicates that the option isn't part of the poll
+	ErrPollOptionNotFound = errors.New("poll option not found")
+)
+
+// PollCreateRequest is the DTO for a poll attached to a new post
+type PollCreateRequest struct {
+	Options        []string   `json:"options"`             // 2 to 10 answers, in order
+	MultipleChoice bool       `json:"multiple_choice"`     // Voters may pick several options
+	Anonymous      bool       `json:"anonymous"`           // Only show vote counts, not who voted
+	ClosesAt       *time.Time `json:"closes_at,omitempty"` // Optional; the poll stays open otherwise
+}
+
+// PollResponse is the DTO for a poll and its results
+type PollResponse struct {
+	MultipleChoice bool                  `json:"multiple_choice"`
+	Anonymous      bool                  `json:"anonymous"`
+	ClosesAt       *time.Time            `json:"closes_at,omitempty"`
+	Closed         bool                  `json:"closed"`
+	VoterCount     int                   `json:"voter_count"`
+	Options        []*PollOptionResponse `json:"options"`
+	Voted          []string              `json:"voted"` // Options the viewer voted for
+}
+
+// PollOptionResponse is an option of a poll with its votes. Voters holds the
+// first voters only, and is left out for anonymous polls.
+type PollOptionResponse struct {
+	ID        string                `json:"id"`
+	Text      string                `json:"text"`
+	VoteCount int                   `json:"vote_count"`
+	Voters    []types.UserBasicInfo `json:"voters,omitempty"`
+}
+
+// PollService defines the interface for voting in polls. Polls are created
+// with their post through PostService.
+type PollService interface {
+	// Vote replaces the user's votes in the poll of a post they can see
+	Vote(postID, userID string, optionIDs []string) (*PollResponse, error)
+	Unvote(postID, userID string) (*PollResponse, error)
+	// ListVoters returns a page of the voters of an option, in voting order
+	ListVoters(postID, optionID, userID string, page models.Page) ([]types.UserBasicInfo, models.PageInfo, error)
+}
+
+// pollService implements PollService
+type pollService struct {
+	pollRepo    repositories.PollRepository
+	postService PostService // Applies the visibility rules of posts
+}
+
+// NewPollService creates a new PollService
+func NewPollService(pollRepo repositories.PollRepository, postService PostService) PollService {
+	return &pollService{
+		pollRepo:    pollRepo,
+		postService: postService,
+	}
+}
+
+// Vote records the user's choice in a poll
+func (s *pollService) Vote(postID, userID string, optionIDs []string) (*PollResponse, error) {
+	poll, err := s.openPoll(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(optionIDs) == 0 {
+		return nil, fmt.Errorf("%w: pick at least one option", ErrInvalidVote)
+	}
+	if len(optionIDs) > 1 && !poll.MultipleChoice {
+		return nil, fmt.Errorf("%w: pick only one option", ErrInvalidVote)
+	}
+	picked := make(map[string]bool, len(optionIDs))
+	for _, optionID := range optionIDs {
+		if picked[optionID] {
+			return nil, fmt.Errorf("%w: option %s is picked twice", ErrInvalidVote, optionID)
+		}
+		picked[optionID] = true
+	}
+	valid := 0
+	for _, option := range poll.Options {
+		if picked[option.ID] {
+			valid++
+		}
+	}
+	if valid != len(optionIDs) {
+		return nil, fmt.Errorf("%w: unknown option", ErrInvalidVote)
+	}
+
+	if err := s.pollRepo.SetVotes(postID, userID, optionIDs); err != nil {
+		return nil, err
+	}
+	return mapPollToResponse(s.pollRepo, postID, userID)
+}
+
+// Unvote withdraws the user's votes while the poll is open
+func (s *pollService) Unvote(postID, userID string) (*PollResponse, error) {
+	if _, err := s.openPoll(postID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.pollRepo.DeleteVotes(postID, userID); err != nil {
+		return nil, err
+	}
+	return mapPollToResponse(s.pollRepo, postID, userID)
+}
+
+// ListVoters lists who voted for an option of a public poll the user can see
+func (s *pollService) ListVoters(postID, optionID, userID string, page models.Page) ([]types.UserBasicInfo, models.PageInfo, error) {
+	if _, err := s.postService.GetByID(postID, userID); err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	poll, err := s.pollRepo.GetByPostID(postID)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	if poll.Anonymous {
+		return nil, models.PageInfo{}, ErrPollAnonymous
+	}
+	for _, option := range poll.Options {
+		if option.ID == optionID {
+			return s.pollRepo.ListOptionVoters(optionID, page)
+		}
+	}
+	return nil, models.PageInfo{}, ErrPollOptionNotFound
+}
+
+// openPoll retrieves the poll of a published post the user can see, if it
+// still takes votes
+func (s *pollService) openPoll(postID, userID string) (*models.Poll, error) {
+	post, err := s.postService.GetByID(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	poll, err := s.pollRepo.GetByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != models.PostStatusPublished {
+		return nil, fmt.Errorf("%w: post is not published", ErrInvalidVote)
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, ErrPollClosed
+	}
+	return poll, nil
+}
+
+// newPoll validates the poll of a post creation request for a post published at publishAt
+func newPoll(request *PollCreateRequest, publishAt time.Time) (*models.Poll, error) {
+	if len(request.Options) < 2 || len(request.Options) > maxPollOptions {
+		return nil, fmt.Errorf("%w: a poll needs 2 to %d options", ErrInvalidPoll, maxPollOptions)
+	}
+	poll := &models.Poll{
+		MultipleChoice: request.MultipleChoice,
+		Anonymous:      request.Anonymous,
+		Options:        make([]*models.PollOption, 0, len(request.Options)),
+	}
+	seen := make(map[string]bool, len(request.Options))
+	for i, text := range request.Options {
+		text = strings.TrimSpace(text)
+		if text == "" || utf8.RuneCountInString(text) > maxPollOptionTextLength {
+			return nil, fmt.Errorf("%w: option %d must have 1 to %d characters", ErrInvalidPoll, i, maxPollOptionTextLength)
+		}
+		if seen[strings.ToLower(text)] {
+			return nil, fmt.Errorf("%w: option %q is listed twice", ErrInvalidPoll, text)
+		}
+		seen[strings.ToLower(text)] = true
+		poll.Options = append(poll.Options, &models.PollOption{Text: text})
+	}
+	if request.ClosesAt != nil {
+		if !request.ClosesAt.After(publishAt) {
+			return nil, fmt.Errorf("%w: closes_at must be after the post is published", ErrInvalidPoll)
+		}
+		poll.ClosesAt.Time = request.ClosesAt.UTC()
+		poll.ClosesAt.Valid = true
+	}
+	return poll, nil
+}
+
+// mapPollToResponse loads the results of the poll of a post as seen by the
+// viewer, who may be anonymous. It returns ErrPollNotFound if the post has no poll.
+func mapPollToResponse(pollRepo repositories.PollRepository, postID, viewerID string) (*PollResponse, error) {
+	polls, err := mapPollsToResponse(pollRepo, []string{postID}, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if polls[postID] == nil {
+		return nil, repositories.ErrPollNotFound
+	}
+	return polls[postID], nil
+}
+
+// mapPollsToResponse is mapPollToResponse for several posts, by post ID.
+// Posts without a poll are left out.
+func mapPollsToResponse(pollRepo repositories.PollRepository, postIDs []string, viewerID string) (map[string]*PollResponse, error) {
+	polls, err := pollRepo.GetByPostIDs(postIDs)
+	if err != nil || len(polls) == 0 {
+		return nil, err
+	}
+	pollIDs := make([]string, 0, len(polls))
+	var publicIDs []string // Polls that show their voters
+	for postID, poll := range polls {
+		pollIDs = append(pollIDs, postID)
+		if !poll.Anonymous {
+			publicIDs = append(publicIDs, postID)
+		}
+	}
+	voterCounts, err := pollRepo.CountVoters(pollIDs)
+	if err != nil {
+		return nil, err
+	}
+	var votes map[string][]string
+	if viewerID != "" {
+		if votes, err = pollRepo.GetUserVotes(pollIDs, viewerID); err != nil {
+			return nil, err
+		}
+	}
+	voters, err := pollRepo.ListVoters(publicIDs, maxEmbeddedVoters)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make(map[string]*PollResponse, len(polls))
+	for postID, poll := range polls {
+		response := &PollResponse{
+			MultipleChoice: poll.MultipleChoice,
+			Anonymous:      poll.Anonymous,
+			Closed:         poll.IsClosed(now),
+			VoterCount:     voterCounts[postID],
+			Options:        make([]*PollOptionResponse, len(poll.Options)),
+			Voted:          []string{},
+		}
+		if poll.ClosesAt.Valid {
+			response.ClosesAt = &poll.ClosesAt.Time
+		}
+		if votes[postID] != nil {
+			response.Voted = votes[postID]
+		}
+		for i, option := range poll.Options {
+			response.Options[i] = &PollOptionResponse{
+				ID:        option.ID,
+				Text:      option.Text,
+				VoteCount: option.VoteCount,
+				Voters:    voters[option.ID],
+			}
+		}
+		responses[postID] = response
+	}
+	return responses, nil
+}
@@ -1,11 +1,14 @@
 package services
 
 import (
+	"context"
 	"database/sql" // Needed for sql.ErrNoRows check in follower lookup
 	"errors"
 	"fmt"
 	"log" // For logging errors during auth checks
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/HASANALI117/social-network/pkg/models"
 	"github.com/HASANALI117/social-network/pkg/repositories"
@@ -13,69 +16,295 @@ import (
 
 // PostResponse is the DTO for post data sent to clients
 type PostResponse struct {
-	ID            string    `json:"id"`
-	UserID        string    `json:"user_id"`
-	GroupID       *string   `json:"group_id,omitempty"` // Use pointer for optional field
-	Title         string    `json:"title"`
-	Content       string    `json:"content"`
-	ImageURL      string    `json:"image_url,omitempty"`
-	Privacy       string    `json:"privacy"` // Note: For group posts, this might always be 'public' conceptually
-	CreatedAt     time.Time `json:"created_at"`
-	UserFirstName string    `json:"user_first_name,omitempty"`
-	UserLastName  string    `json:"user_last_name,omitempty"`
-	UserAvatarURL string    `json:"user_avatar_url,omitempty"`
+	ID            string                    `json:"id"`
+	UserID        string                    `json:"user_id"`
+	GroupID       *string                   `json:"group_id,omitempty"` // Use pointer for optional field
+	Title         string                    `json:"title"`
+	Content       string                    `json:"content"`
+	ImageURL      string                    `json:"image_url,omitempty"`
+	ImageID       string                    `json:"image_id,omitempty"`
+	Image         *MediaResponse            `json:"image,omitempty"`        // Processing state and variant URLs of the image
+	Attachments   []*PostAttachmentResponse `json:"attachments,omitempty"`  // Gallery, in order
+	Privacy       string                    `json:"privacy"`                // Note: For group posts, this might always be 'public' conceptually
+	Tags          []string                  `json:"tags,omitempty"`         // Hashtags in the content, lowercase without '#'
+	Status        string                    `json:"status"`                 // draft, scheduled or published
+	ScheduledAt   *time.Time                `json:"scheduled_at,omitempty"` // Set while scheduled
+	CreatedAt     time.Time                 `json:"created_at"`             // Publication time once published
+	UserFirstName string                    `json:"user_first_name,omitempty"`
+	UserLastName  string                    `json:"user_last_name,omitempty"`
+	UserAvatarURL string                    `json:"user_avatar_url,omitempty"`
+	ShareCount    int                       `json:"share_count"`    // Reposts and quotes of this post
+	Pinned        bool                      `json:"pinned"`         // Pinned on the author's profile, or in the group for group posts
+	Poll          *PollResponse             `json:"poll,omitempty"` // Results as seen by the viewer
+	// Audience list a private post is shared with; only shown to the author
+	AudienceListID string `json:"audience_list_id,omitempty"`
+	// Previews of the links in the content, in order. Links are fetched in the
+	// background, so previews of a new post show up on later reads.
+	LinkPreviews []*models.LinkPreview `json:"link_previews,omitempty"`
+
+	// Set on reposts and quotes. SharedPost is left out if the viewer can't see
+	// the shared post, and SharedPostDeleted marks shares of deleted posts.
+	ShareType         string        `json:"share_type,omitempty"`
+	SharedPostID      *string       `json:"shared_post_id,omitempty"`
+	SharedPost        *PostResponse `json:"shared_post,omitempty"`
+	SharedPostDeleted bool          `json:"shared_post_deleted,omitempty"`
 }
 
 // PostCreateRequest is the DTO for creating a new post
 type PostCreateRequest struct {
-	UserID         string   `json:"-"`                  // Set internally from authenticated user
-	GroupID        *string  `json:"group_id,omitempty"` // Optional: ID of the group to post in
-	Title          string   `json:"title" validate:"required,max=100"`
-	Content        string   `json:"content" validate:"required"`
-	ImageURL       string   `json:"image_url" validate:"omitempty,url"`
-	Privacy        string   `json:"privacy" validate:"required_without=GroupID,omitempty,oneof=public almost_private private"` // Required if not a group post
-	AllowedUserIDs []string `json:"allowed_user_ids,omitempty"`                                                                // For 'private' non-group posts
+	UserID         string                  `json:"-"`                  // Set internally from authenticated user
+	GroupID        *string                 `json:"group_id,omitempty"` // Optional: ID of the group to post in
+	Title          string                  `json:"title" validate:"required,max=100"`
+	Content        string                  `json:"content" validate:"required"`
+	ImageID        string                  `json:"image_id,omitempty"`                                                                        // Media uploaded through /api/media; shorthand for a single attachment
+	Attachments    []PostAttachmentRequest `json:"attachments,omitempty"`                                                                     // Gallery of uploaded images and videos, in order
+	Privacy        string                  `json:"privacy" validate:"required_without=GroupID,omitempty,oneof=public almost_private private"` // Required if not a group post
+	AllowedUserIDs []string                `json:"allowed_user_ids,omitempty"`                                                                // For 'private' non-group posts
+	AudienceListID string                  `json:"audience_list_id,omitempty"`                                                                // For 'private' non-group posts: one of the author's audience lists
+	Status         string                  `json:"status,omitempty"`                                                                          // "draft" or "scheduled" to publish later; published by default
+	ScheduledAt    *time.Time              `json:"scheduled_at,omitempty"`                                                                    // Publication time of a scheduled post
+	Poll           *PollCreateRequest      `json:"poll,omitempty"`                                                                            // Optional poll to attach
+}
+
+// PostUpdateRequest is the DTO for editing a post. Only the text can change;
+// hashtags are parsed again from the new content.
+type PostUpdateRequest struct {
+	Title   string `json:"title" validate:"required,max=100"`
+	Content string `json:"content" validate:"required"`
+}
+
+// PostShareRequest is the DTO for sharing a post. Without content the post is
+// reposted as is, otherwise it is quoted. Privacy defaults to the shared post's.
+type PostShareRequest struct {
+	UserID         string   `json:"-"` // Set internally from authenticated user
+	Title          string   `json:"title,omitempty" validate:"max=100"`
+	Content        string   `json:"content,omitempty"`
+	Privacy        string   `json:"privacy,omitempty" validate:"omitempty,oneof=public semi_private private"`
+	AllowedUserIDs []string `json:"allowed_user_ids,omitempty"` // For 'private' shares
+}
+
+// PostAudienceRequest is the DTO for changing who can see a post. It replaces
+// the privacy and, for private posts, the allowed users and audience list.
+type PostAudienceRequest struct {
+	Privacy        string   `json:"privacy" validate:"required,oneof=public semi_private private"`
+	AllowedUserIDs []string `json:"allowed_user_ids,omitempty"` // For 'private', along with or instead of an audience list
+	AudienceListID string   `json:"audience_list_id,omitempty"` // For 'private': one of the author's audience lists
+}
+
+// PostAudienceResponse is the DTO for who can see a post
+type PostAudienceResponse struct {
+	PostID         string   `json:"post_id"`
+	Privacy        string   `json:"privacy"`
+	AllowedUserIDs []string `json:"allowed_user_ids"`           // Empty unless private
+	AudienceListID string   `json:"audience_list_id,omitempty"` // Set if a private post is shared with a list
+}
+
+// PostAttachmentRequest attaches uploaded media to a post
+type PostAttachmentRequest struct {
+	MediaID string `json:"media_id"`
+	AltText string `json:"alt_text,omitempty"` // Description for screen readers
+}
+
+// PostAttachmentResponse is an item of a post's gallery
+type PostAttachmentResponse struct {
+	Position int            `json:"position"`
+	AltText  string         `json:"alt_text"`
+	Media    *MediaResponse `json:"media"`
 }
 
+// maxAltTextLength limits attachment descriptions, in characters
+const maxAltTextLength = 1000
+
 var (
-	ErrPostForbidden     = errors.New("user not authorized to perform this action on the post")
-	ErrGroupAccessDenied = errors.New("user is not a member of the group")
+	ErrPostForbidden      = errors.New("user not authorized to perform this action on the post")
+	ErrGroupAccessDenied  = errors.New("user is not a member of the group")
+	ErrTooManyAttachments = errors.New("post has too many attachments")
+	ErrInvalidAttachments = errors.New("invalid post attachments")
+	ErrInvalidShare       = errors.New("invalid share")
+	ErrShareAudience      = errors.New("share would reach beyond the audience of the shared post")
+	ErrInvalidSchedule    = errors.New("invalid post schedule")
+	ErrPostPublished      = errors.New("post is already published")
+	ErrInvalidPin         = errors.New("post can't be pinned")
+	ErrPinLimit           = errors.New("too many pinned posts")
+	ErrInvalidAudience    = errors.New("invalid post audience")
 )
 
 // PostService defines the interface for post business logic
 type PostService interface {
 	Create(request *PostCreateRequest) (*PostResponse, error)
-	GetByID(postID string, requestingUserID string) (*PostResponse, error)                              // requestingUserID for auth check
-	List(requestingUserID string, limit, offset int) ([]*PostResponse, error)                           // General feed (non-group)
-	ListPostsByUser(targetUserID, requestingUserID string, limit, offset int) ([]*PostResponse, error)  // User profile (non-group)
-	ListGroupPosts(groupID string, requestingUserID string, limit, offset int) ([]*PostResponse, error) // Group posts
-	ListExplore(limit, offset int) ([]*PostResponse, error)                                             // For "Explore" feed
-	ListFollowingFeed(requestingUserID string, limit, offset int) ([]*PostResponse, error)
-	// Update(...) // TODO: Implement Update
-	Delete(postID string, requestingUserID string) error // requestingUserID for auth check
+	GetByID(postID string, requestingUserID string) (*PostResponse, error)                                              // requestingUserID for auth check
+	GetByIDs(postIDs []string, requestingUserID string) (map[string]*PostResponse, error)                               // By ID; posts that don't exist or requestingUserID can't see are left out
+	List(requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error)                           // General feed (non-group)
+	ListPostsByUser(targetUserID, requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error)  // User profile (non-group)
+	ListGroupPosts(groupID string, requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) // Group posts
+	ListExplore(page models.Page) ([]*PostResponse, models.PageInfo, error)                                             // For "Explore" feed
+	ListFollowingFeed(requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error)
+	ListByTag(tag, requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) // Tag page (non-group)
+	Update(postID, requestingUserID string, request *PostUpdateRequest) (*PostResponse, error)          // Author only
+	Delete(postID string, requestingUserID string) error                                                // requestingUserID for auth check
+	Share(postID string, request *PostShareRequest) (*PostResponse, error)                              // Repost or quote
+	Unrepost(postID, requestingUserID string) error
+
+	// Drafts and scheduled posts, author only. Unpublished posts are edited with Update.
+	ListUnpublished(requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error)
+	Schedule(postID, requestingUserID string, at time.Time) (*PostResponse, error) // Schedules or reschedules
+	Unschedule(postID, requestingUserID string) (*PostResponse, error)             // Turns a scheduled post back into a draft
+	Publish(postID, requestingUserID string) (*PostResponse, error)                // Publishes now
+	// PublishDue publishes the scheduled posts due at now and returns how many were published
+	PublishDue(now time.Time) (int, error)
+	// StartScheduler runs PublishDue every interval until ctx is done. It does nothing if interval is zero.
+	StartScheduler(ctx context.Context, interval time.Duration)
+
+	// Pinned posts. Authors pin their posts on their profile, group admins pin posts in their group.
+	Pin(postID, requestingUserID string) (*PostResponse, error)
+	Unpin(postID, requestingUserID string) (*PostResponse, error)
+
+	// Audience of user posts, author only
+	GetAudience(postID, requestingUserID string) (*PostAudienceResponse, error)
+	UpdateAudience(postID, requestingUserID string, request *PostAudienceRequest) (*PostAudienceResponse, error)
 }
 
 // postService implements PostService interface
 type postService struct {
-	postRepo     repositories.PostRepository
-	followerRepo repositories.FollowerRepository // Needed for non-group privacy checks
-	groupRepo    repositories.GroupRepository    // Needed for group membership/admin checks
-	userRepo     repositories.UserRepository     // Needed for user details in posts
+	postRepo       repositories.PostRepository
+	tagRepo        repositories.TagRepository          // Hashtags of posts
+	followerRepo   repositories.FollowerRepository     // Needed for non-group privacy checks
+	groupRepo      repositories.GroupRepository        // Needed for group membership/admin checks
+	pollRepo       repositories.PollRepository         // Polls attached to posts
+	audienceRepo   repositories.AudienceListRepository // Audience lists private posts are shared with
+	userRepo       repositories.UserRepository         // Needed for user details in posts
+	uow            repositories.UnitOfWork             // Runs multi-step writes atomically
+	mediaService   MediaService                        // Resolves attached images
+	mentionService MentionService                      // Notifies @mentioned users
+	feedService    FeedService                         // Ranks the home and following feeds
+	linkPreviews   LinkPreviewService                  // Previews of links in the content
+	maxAttachments int                                 // Size of a post's gallery
+	maxFanout      int                                 // Authors with more followers aren't fanned out on write
+	maxPinned      int                                 // Pinned posts per profile or group
 	// authService AuthService // Potentially needed if complex auth logic arises
 }
 
+// PostServiceDeps holds the repositories, services and limits a PostService uses
+type PostServiceDeps struct {
+	PostRepo       repositories.PostRepository
+	TagRepo        repositories.TagRepository
+	FollowerRepo   repositories.FollowerRepository
+	GroupRepo      repositories.GroupRepository
+	PollRepo       repositories.PollRepository
+	AudienceRepo   repositories.AudienceListRepository
+	UserRepo       repositories.UserRepository
+	UOW            repositories.UnitOfWork
+	MediaService   MediaService
+	MentionService MentionService
+	FeedService    FeedService
+	LinkPreviews   LinkPreviewService
+	MaxAttachments int // Size of a post's gallery
+	MaxFanout      int // Authors with more followers aren't fanned out on write
+	MaxPinned      int // Pinned posts per profile or group
+}
+
 // NewPostService creates a new PostService
-func NewPostService(postRepo repositories.PostRepository, followerRepo repositories.FollowerRepository, groupRepo repositories.GroupRepository, userRepo repositories.UserRepository) PostService {
+func NewPostService(deps PostServiceDeps) PostService {
 	return &postService{
-		postRepo:     postRepo,
-		followerRepo: followerRepo,
-		groupRepo:    groupRepo,
-		userRepo:     userRepo,
+		postRepo:       deps.PostRepo,
+		tagRepo:        deps.TagRepo,
+		followerRepo:   deps.FollowerRepo,
+		groupRepo:      deps.GroupRepo,
+		pollRepo:       deps.PollRepo,
+		audienceRepo:   deps.AudienceRepo,
+		userRepo:       deps.UserRepo,
+		uow:            deps.UOW,
+		mediaService:   deps.MediaService,
+		mentionService: deps.MentionService,
+		feedService:    deps.FeedService,
+		linkPreviews:   deps.LinkPreviews,
+		maxAttachments: deps.MaxAttachments,
+		maxFanout:      deps.MaxFanout,
+		maxPinned:      deps.MaxPinned,
+	}
+}
+
+// postPageData holds what the responses of a page of posts need besides the
+// posts themselves. It is loaded with one query per kind of data rather than
+// one per post; data that fails to load is logged and left out.
+type postPageData struct {
+	authors       map[string]*models.User
+	media         map[string]*MediaResponse
+	attachments   map[string][]models.PostAttachment
+	tags          map[string][]string
+	shareCounts   map[string]int
+	polls         map[string]*PollResponse // As the viewer sees them
+	audienceLists map[string]string        // The viewer's own private posts only
+	previews      map[string][]*models.LinkPreview
+}
+
+// loadPageData loads the data of the posts' responses as viewerID sees them
+func (s *postService) loadPageData(posts []*models.Post, viewerID string) *postPageData {
+	data := &postPageData{previews: make(map[string][]*models.LinkPreview, len(posts))}
+	var postIDs, authorIDs, ownListed []string
+	seen := make(map[string]bool, len(posts))
+	for _, post := range posts {
+		if seen[post.ID] {
+			continue
+		}
+		seen[post.ID] = true
+		postIDs = append(postIDs, post.ID)
+		authorIDs = append(authorIDs, post.UserID)
+		if post.UserID == viewerID && post.Privacy == models.PrivacyPrivate && !post.GroupID.Valid {
+			ownListed = append(ownListed, post.ID)
+		}
+	}
+
+	var err error
+	if data.authors, err = s.userRepo.GetByIDs(authorIDs); err != nil {
+		log.Printf("Error loading authors of posts: %v", err)
+	}
+	if data.attachments, err = s.postRepo.GetAttachmentsByPostIDs(postIDs); err != nil {
+		log.Printf("Error loading attachments of posts: %v", err)
+	}
+	var mediaIDs []string
+	for _, post := range posts {
+		if post.ImageID != "" {
+			mediaIDs = append(mediaIDs, post.ImageID)
+		}
+		attachments := post.Attachments
+		if attachments == nil {
+			attachments = data.attachments[post.ID]
+		}
+		for _, a := range attachments {
+			mediaIDs = append(mediaIDs, a.MediaID)
+		}
+	}
+	if data.media, err = s.mediaService.GetByIDs(mediaIDs); err != nil {
+		log.Printf("Error loading media of posts: %v", err)
+	}
+	if data.tags, err = s.tagRepo.GetPostTags(postIDs); err != nil {
+		log.Printf("Error loading tags of posts: %v", err)
+	}
+	if data.shareCounts, err = s.postRepo.CountShares(postIDs); err != nil {
+		log.Printf("Error counting shares of posts: %v", err)
+	}
+	if data.polls, err = mapPollsToResponse(s.pollRepo, postIDs, viewerID); err != nil {
+		log.Printf("Error loading polls of posts: %v", err)
+	}
+	if data.audienceLists, err = s.postRepo.GetAudienceLists(ownListed); err != nil {
+		log.Printf("Error loading audience lists of posts: %v", err)
+	}
+
+	contents := make([]string, len(posts))
+	for i, post := range posts {
+		contents[i] = post.Content
+	}
+	for i, previews := range s.linkPreviews.Previews(contents) {
+		if len(previews) > 0 {
+			data.previews[posts[i].ID] = previews
+		}
 	}
+	return data
 }
 
 // mapPostToResponse converts a model.Post to a PostResponse DTO
-func (s *postService) mapPostToResponse(post *models.Post, author *models.User) *PostResponse {
+func (s *postService) mapPostToResponse(post *models.Post, data *postPageData) *PostResponse {
 	if post == nil {
 		return nil
 	}
@@ -91,37 +320,119 @@ func (s *postService) mapPostToResponse(post *models.Post, author *models.User)
 		Title:     post.Title,
 		Content:   post.Content,
 		ImageURL:  post.ImageURL,
+		ImageID:   post.ImageID,
 		Privacy:   post.Privacy,
+		Status:    post.Status,
 		CreatedAt: post.CreatedAt,
 	}
+	if post.ScheduledAt.Valid {
+		response.ScheduledAt = &post.ScheduledAt.Time
+	}
+	response.Pinned = post.PinnedAt.Valid
 
-	// Use pre-fetched author details if available, otherwise fetch from repository
-	if author != nil {
+	if author := data.authors[post.UserID]; author != nil {
 		response.UserFirstName = author.FirstName
 		response.UserLastName = author.LastName
 		response.UserAvatarURL = author.AvatarURL
-	} else if user, err := s.userRepo.GetByID(post.UserID); err == nil && user != nil {
-		response.UserFirstName = user.FirstName
-		response.UserLastName = user.LastName
-		response.UserAvatarURL = user.AvatarURL
 	}
 
+	if post.ImageID != "" {
+		response.Image = data.media[post.ImageID]
+	}
+
+	attachments := post.Attachments
+	if attachments == nil {
+		attachments = data.attachments[post.ID]
+	}
+	for _, a := range attachments {
+		media := data.media[a.MediaID]
+		if media == nil {
+			log.Printf("Attachment %s of post %s not found", a.MediaID, post.ID)
+			continue
+		}
+		response.Attachments = append(response.Attachments, &PostAttachmentResponse{
+			Position: a.Position,
+			AltText:  a.AltText,
+			Media:    media,
+		})
+	}
+
+	tags := post.Tags
+	if tags == nil && post.Status != models.PostStatusPublished {
+		tags = extractHashtags(post.Content) // Stored when published
+	}
+	if tags == nil {
+		tags = data.tags[post.ID]
+	}
+	if len(tags) > 0 {
+		response.Tags = tags
+	}
+
+	response.ShareCount = data.shareCounts[post.ID]
+	response.Poll = data.polls[post.ID]
+	response.AudienceListID = data.audienceLists[post.ID]
+	response.LinkPreviews = data.previews[post.ID]
 	return response
 }
 
+// mapPostForViewer converts a post to a PostResponse, embedding the post it
+// shares if the viewer may see it
+func (s *postService) mapPostForViewer(post *models.Post, viewerID string) *PostResponse {
+	return s.mapPostsToResponse([]*models.Post{post}, &viewerID)[0]
+}
+
 // mapPostsToResponse converts a slice of model.Post to a slice of PostResponse DTOs
-// The requestingUserID is optional and used if specific privacy checks per post are needed in the future,
-// but currently, the repository layer handles most privacy filtering.
+// The requestingUserID is optional; the repository layer handles the privacy filtering
+// of the posts themselves, and it is used to check the posts they share.
+// Visibility of shared posts is checked on every read since their audience
+// may have changed since they were shared.
 func (s *postService) mapPostsToResponse(posts []*models.Post, requestingUserID *string) []*PostResponse {
+	viewerID := ""
+	if requestingUserID != nil {
+		viewerID = *requestingUserID
+	}
+
+	// Shared posts are mapped along with the page (not nested further)
+	var sharedIDs []string
+	for _, post := range posts {
+		if post.ShareType != "" && post.SharedPostID.Valid {
+			sharedIDs = append(sharedIDs, post.SharedPostID.String)
+		}
+	}
+	shared, err := s.postRepo.GetByIDs(sharedIDs)
+	if err != nil {
+		log.Printf("Error loading shared posts: %v", err)
+	}
+	visible := make(map[string]bool, len(shared))
+	all := make([]*models.Post, 0, len(posts)+len(shared))
+	all = append(all, posts...)
+	for id, post := range shared {
+		if canViewPost(post, viewerID, s.postRepo, s.followerRepo, s.groupRepo) {
+			visible[id] = true
+			all = append(all, post)
+		}
+	}
+	data := s.loadPageData(all, viewerID)
+
 	responses := make([]*PostResponse, len(posts))
-	// Fetch user details in bulk if possible, or individually if not.
-	// For simplicity here, we fetch individually within mapPostToResponse.
-	// A more optimized approach might gather all unique UserIDs from posts
-	// and fetch them in a single query to s.userRepo.
 	for i, post := range posts {
-		// Pass nil for author, mapPostToResponse will fetch it.
-		// If mapPostToResponse were to use requestingUserID for further checks, pass it here.
-		responses[i] = s.mapPostToResponse(post, nil)
+		response := s.mapPostToResponse(post, data)
+		responses[i] = response
+		if post.ShareType == "" {
+			continue
+		}
+		response.ShareType = post.ShareType
+		switch {
+		case !post.SharedPostID.Valid:
+			response.SharedPostDeleted = true
+		case err != nil:
+			// Logged above
+		case shared[post.SharedPostID.String] == nil:
+			response.SharedPostDeleted = true
+		case visible[post.SharedPostID.String]:
+			response.SharedPostID = &post.SharedPostID.String
+			response.SharedPost = s.mapPostToResponse(shared[post.SharedPostID.String], data)
+		}
 	}
 	return responses
 }
@@ -134,12 +445,36 @@ func (s *postService) Create(request *PostCreateRequest) (*PostResponse, error)
 	}
 
 	post := &models.Post{
-		UserID:   request.UserID, // Assumes UserID is set correctly before calling
-		Title:    request.Title,
-		Content:  request.Content,
-		ImageURL: request.ImageURL,
+		UserID:  request.UserID, // Assumes UserID is set correctly before calling
+		Title:   request.Title,
+		Content: request.Content,
+		Tags:    extractHashtags(request.Content),
 		// GroupID and Privacy are set below
 	}
+	if err := setInitialStatus(post, request); err != nil {
+		return nil, err
+	}
+	if request.Poll != nil {
+		publishAt := time.Now() // Drafts are checked again when published
+		if post.ScheduledAt.Valid {
+			publishAt = post.ScheduledAt.Time
+		}
+		poll, err := newPoll(request.Poll, publishAt)
+		if err != nil {
+			return nil, err
+		}
+		post.Poll = poll
+	}
+
+	attachments, cover, err := s.resolveAttachments(request)
+	if err != nil {
+		return nil, err
+	}
+	post.Attachments = attachments
+	if cover != nil {
+		post.ImageID = cover.ID
+		post.ImageURL = cover.URL
+	}
 
 	// Handle Group Post vs User Post
 	if request.GroupID != nil && *request.GroupID != "" {
@@ -162,37 +497,185 @@ func (s *postService) Create(request *PostCreateRequest) (*PostResponse, error)
 		// Validate Privacy for non-group posts
 		switch request.Privacy {
 		case models.PrivacyPublic, models.PrivacyAlmostPrivate:
+			if request.AudienceListID != "" {
+				return nil, fmt.Errorf("%w: audience lists are only for private posts", ErrInvalidAudience)
+			}
 			post.Privacy = request.Privacy
 		case models.PrivacyPrivate:
-			if len(request.AllowedUserIDs) == 0 {
-				return nil, errors.New("allowed_user_ids are required for private non-group posts")
+			if len(request.AllowedUserIDs) == 0 && request.AudienceListID == "" {
+				return nil, errors.New("allowed_user_ids or audience_list_id are required for private non-group posts")
+			}
+			if err := s.checkAudienceList(request.UserID, request.AudienceListID); err != nil {
+				return nil, err
 			}
 			post.Privacy = request.Privacy
+			post.AudienceListID = request.AudienceListID
 		// TODO: Optionally validate if AllowedUserIDs actually exist?
 		default:
 			return nil, errors.New("invalid privacy setting for non-group post: must be public, almost_private, or private")
 		}
 	}
 
-	// Create the post in the repository
-	err := s.postRepo.Create(post)
+	if err := s.save(post, request.AllowedUserIDs); err != nil {
+		return nil, err
+	}
+	return s.mapPostForViewer(post, post.UserID), nil
+}
+
+// setInitialStatus applies the requested status of a new post. Scheduled
+// posts need a time in the future.
+func setInitialStatus(post *models.Post, request *PostCreateRequest) error {
+	status := request.Status
+	if status == "" && request.ScheduledAt != nil {
+		status = models.PostStatusScheduled
+	}
+	switch status {
+	case "", models.PostStatusPublished:
+		if request.ScheduledAt != nil {
+			return fmt.Errorf("%w: only scheduled posts have a scheduled_at", ErrInvalidSchedule)
+		}
+		post.Status = models.PostStatusPublished
+	case models.PostStatusDraft:
+		if request.ScheduledAt != nil {
+			return fmt.Errorf("%w: drafts have no scheduled_at", ErrInvalidSchedule)
+		}
+		post.Status = models.PostStatusDraft
+	case models.PostStatusScheduled:
+		if request.ScheduledAt == nil || !request.ScheduledAt.After(time.Now()) {
+			return fmt.Errorf("%w: scheduled_at must be in the future", ErrInvalidSchedule)
+		}
+		post.Status = models.PostStatusScheduled
+		post.ScheduledAt = sql.NullTime{Time: request.ScheduledAt.UTC(), Valid: true}
+	default:
+		return fmt.Errorf("%w: status must be draft, scheduled or published", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// save creates a post with its gallery and, for private *user* posts, its
+// allowed users and audience list in one transaction. Published posts are also distributed and
+// the users they mention notified; drafts and scheduled posts wait for publish.
+func (s *postService) save(post *models.Post, allowedUserIDs []string) error {
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Post.Create(post); err != nil {
+			return fmt.Errorf("failed to create post in repository: %w", err)
+		}
+		for i := range post.Attachments {
+			post.Attachments[i].PostID = post.ID
+		}
+		if err := repos.Post.AddAttachments(post.ID, post.Attachments); err != nil {
+			return fmt.Errorf("failed to add post attachments: %w", err)
+		}
+		if post.Poll != nil {
+			post.Poll.PostID = post.ID
+			if err := repos.Poll.Create(post.Poll); err != nil {
+				return fmt.Errorf("failed to create poll: %w", err)
+			}
+		}
+		if !post.GroupID.Valid && post.Privacy == models.PrivacyPrivate {
+			if err := repos.Post.AddAllowedUsers(post.ID, allowedUserIDs); err != nil {
+				log.Printf("Error adding allowed users for private post %s: %v", post.ID, err)
+				return fmt.Errorf("failed to add allowed users for private post: %w", err)
+			}
+			if post.AudienceListID != "" {
+				if err := repos.Post.SetAudienceList(post.ID, post.AudienceListID); err != nil {
+					return fmt.Errorf("failed to share private post with audience list: %w", err)
+				}
+			}
+		}
+		if post.Status != models.PostStatusPublished {
+			return nil
+		}
+		return s.distribute(repos, post)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create post in repository: %w", err)
+		return err
 	}
 
-	// If it's a private *user* post, add allowed users
-	if !post.GroupID.Valid && post.Privacy == models.PrivacyPrivate {
-		err = s.postRepo.AddAllowedUsers(post.ID, request.AllowedUserIDs)
-		if err != nil {
-			// Log the error, but should we delete the post? Or just return the error?
-			// Returning error seems reasonable. The post exists but isn't configured correctly.
-			log.Printf("Error adding allowed users for private post %s: %v", post.ID, err)
-			// Consider a cleanup mechanism or transaction if this is critical
-			return nil, fmt.Errorf("failed to add allowed users for private post: %w", err)
+	if post.Status == models.PostStatusPublished {
+		s.notifyMentions(post)
+	}
+	return nil
+}
+
+// distribute tags a newly published post and fans it out to home timelines
+func (s *postService) distribute(repos *repositories.Repositories, post *models.Post) error {
+	if err := repos.Tag.SetPostTags(post.ID, post.Tags, post.CreatedAt); err != nil {
+		return fmt.Errorf("failed to tag post: %w", err)
+	}
+	if post.GroupID.Valid {
+		return nil
+	}
+	// Celebrity posts are left to fan-out on read
+	followers, err := repos.Follower.CountFollowers(post.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to count followers for fan-out: %w", err)
+	}
+	if followers <= s.maxFanout {
+		return repos.Timeline.FanOut(post.ID)
+	}
+	return nil
+}
+
+// notifyMentions notifies the users mentioned in a published post. The post
+// exists already, so failing to notify them is only logged.
+func (s *postService) notifyMentions(post *models.Post) {
+	if _, err := s.mentionService.ProcessMentions(post.UserID, post.ID, "", post.Content); err != nil {
+		log.Printf("Error processing mentions in post %s: %v", post.ID, err)
+	}
+}
+
+// resolveAttachments validates the requested gallery and returns it along with
+// its first image, which becomes the post's cover (image_id/image_url) for
+// clients that show a single image. A bare image_id is treated as a gallery of one.
+func (s *postService) resolveAttachments(request *PostCreateRequest) ([]models.PostAttachment, *MediaResponse, error) {
+	items := request.Attachments
+	if request.ImageID != "" {
+		if len(items) > 0 {
+			return nil, nil, fmt.Errorf("%w: use either image_id or attachments", ErrInvalidAttachments)
 		}
+		items = []PostAttachmentRequest{{MediaID: request.ImageID}}
+	}
+	if len(items) > s.maxAttachments {
+		return nil, nil, fmt.Errorf("%w: at most %d are allowed", ErrTooManyAttachments, s.maxAttachments)
 	}
 
-	return s.mapPostToResponse(post, nil), nil
+	var cover *MediaResponse
+	attachments := make([]models.PostAttachment, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if item.MediaID == "" {
+			return nil, nil, fmt.Errorf("%w: attachment %d has no media_id", ErrInvalidAttachments, i)
+		}
+		if seen[item.MediaID] {
+			return nil, nil, fmt.Errorf("%w: media %s is attached twice", ErrInvalidAttachments, item.MediaID)
+		}
+		seen[item.MediaID] = true
+		altText := strings.TrimSpace(item.AltText)
+		if utf8.RuneCountInString(altText) > maxAltTextLength {
+			return nil, nil, fmt.Errorf("%w: alt text of attachment %d exceeds %d characters", ErrInvalidAttachments, i, maxAltTextLength)
+		}
+
+		var media *MediaResponse
+		var err error
+		if request.ImageID != "" {
+			media, err = s.mediaService.ResolveImage(item.MediaID, request.UserID)
+		} else {
+			media, err = s.mediaService.ResolveAttachment(item.MediaID, request.UserID)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if cover == nil && strings.HasPrefix(media.ContentType, "image/") {
+			cover = media
+		}
+		attachments = append(attachments, models.PostAttachment{
+			MediaID:  media.ID,
+			Position: i,
+			AltText:  altText,
+		})
+	}
+	return attachments, cover, nil
 }
 
 // GetByID retrieves a single post, performing authorization checks based on requestingUserID
@@ -206,16 +689,56 @@ func (s *postService) GetByID(postID string, requestingUserID string) (*PostResp
 	}
 
 	// Authorization Check
+	canView := canViewPost(post, requestingUserID, s.postRepo, s.followerRepo, s.groupRepo)
+
+	if !canView {
+		// Return NotFound to avoid revealing existence of non-public/non-group posts
+		return nil, repositories.ErrPostNotFound
+	}
+
+	return s.mapPostForViewer(post, requestingUserID), nil
+}
+
+// GetByIDs retrieves several posts at once, mapping the ones the requesting
+// user can see together
+func (s *postService) GetByIDs(postIDs []string, requestingUserID string) (map[string]*PostResponse, error) {
+	posts, err := s.postRepo.GetByIDs(postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts from repository: %w", err)
+	}
+	visible := make([]*models.Post, 0, len(posts))
+	for _, id := range postIDs {
+		if post := posts[id]; post != nil && canViewPost(post, requestingUserID, s.postRepo, s.followerRepo, s.groupRepo) {
+			visible = append(visible, post)
+		}
+	}
+
+	responses := make(map[string]*PostResponse, len(visible))
+	for i, response := range s.mapPostsToResponse(visible, &requestingUserID) {
+		responses[visible[i].ID] = response
+	}
+	return responses, nil
+}
+
+// canViewPost applies the visibility rules of a post: group posts are visible to
+// group members, other posts according to their privacy setting. It is shared by
+// PostService and the checks on media attached to posts.
+func canViewPost(post *models.Post, requestingUserID string, postRepo repositories.PostRepository, followerRepo repositories.FollowerRepository, groupRepo repositories.GroupRepository) bool {
 	canView := false
 	isOwner := post.UserID == requestingUserID
 
+	if post.Status != "" && post.Status != models.PostStatusPublished {
+		// Drafts and scheduled posts are only visible to their author
+		return isOwner && requestingUserID != ""
+	}
+
 	if post.GroupID.Valid {
 		// --- Group Post Authorization ---
 		// Check if requestingUser is a member of the group
 		if requestingUserID != "" {
-			isMember, err := s.groupRepo.IsMember(post.GroupID.String, requestingUserID)
+			isMember, err := groupRepo.IsMember(post.GroupID.String, requestingUserID)
 			if err != nil {
-				log.Printf("Error checking group membership for user %s in group %s for post %s: %v", requestingUserID, post.GroupID.String, postID, err)
+				log.Printf("Error checking group membership for user %s in group %s for post %s: %v", requestingUserID, post.GroupID.String, post.ID, err)
 				// Treat error as not being a member for safety
 			} else if isMember {
 				canView = true
@@ -232,9 +755,9 @@ func (s *postService) GetByID(postID string, requestingUserID string) (*PostResp
 			case models.PrivacyAlmostPrivate:
 				// Check if requestingUser follows post.UserID
 				if requestingUserID != "" { // Must be logged in to follow
-					follow, err := s.followerRepo.FindFollow(requestingUserID, post.UserID)
+					follow, err := followerRepo.FindFollow(requestingUserID, post.UserID)
 					if err != nil && !errors.Is(err, sql.ErrNoRows) {
-						log.Printf("Error checking follow status from %s to %s for post %s: %v", requestingUserID, post.UserID, postID, err)
+						log.Printf("Error checking follow status from %s to %s for post %s: %v", requestingUserID, post.UserID, post.ID, err)
 					} else if follow != nil && follow.Status == "accepted" {
 						canView = true
 					}
@@ -242,71 +765,281 @@ func (s *postService) GetByID(postID string, requestingUserID string) (*PostResp
 			case models.PrivacyPrivate:
 				// Check if requestingUser is in the allowed list
 				if requestingUserID != "" { // Must be logged in to be allowed
-					allowed, err := s.postRepo.IsUserAllowed(postID, requestingUserID)
+					allowed, err := postRepo.IsUserAllowed(post.ID, requestingUserID)
 					if err != nil {
-						log.Printf("Error checking if user %s is allowed for post %s: %v", requestingUserID, postID, err)
+						log.Printf("Error checking if user %s is allowed for post %s: %v", requestingUserID, post.ID, err)
 					} else if allowed {
 						canView = true
 					}
 				}
 			default:
-				log.Printf("Warning: Post %s has unknown privacy setting '%s'", postID, post.Privacy)
+				log.Printf("Warning: Post %s has unknown privacy setting '%s'", post.ID, post.Privacy)
 				// canView remains false
 			}
 		}
 	}
 
-	if !canView {
-		// Return NotFound to avoid revealing existence of non-public/non-group posts
-		return nil, repositories.ErrPostNotFound
+	return canView
+}
+
+// List retrieves a list of non-group posts for the general feed, filtered by the repository.
+func (s *postService) List(requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) {
+	list := func(page models.Page) ([]*models.Post, models.PageInfo, error) {
+		return s.postRepo.List(requestingUserID, page)
+	}
+	ranked, err := s.isFeedRanked(requestingUserID)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	if ranked {
+		return s.listRanked(requestingUserID, page, list)
 	}
 
-	return s.mapPostToResponse(post, nil), nil
+	posts, info, err := list(page)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list non-group posts from repository: %w", err)
+	}
+	return s.mapPostsToResponse(posts, &requestingUserID), info, nil
 }
 
-// List retrieves a list of non-group posts for the general feed, filtered by the repository.
-func (s *postService) List(requestingUserID string, limit, offset int) ([]*PostResponse, error) {
-	posts, err := s.postRepo.List(requestingUserID, limit, offset)
+// isFeedRanked tells whether the viewer's feeds are ranked. Anonymous viewers get chronological feeds.
+func (s *postService) isFeedRanked(requestingUserID string) (bool, error) {
+	if requestingUserID == "" {
+		return false, nil
+	}
+	mode, err := s.feedService.GetMode(requestingUserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get feed mode: %w", err)
+	}
+	return mode == models.FeedModeRanked, nil
+}
+
+// listRanked ranks the newest maxFeedCandidates posts returned by list and
+// pages through the ranking, then through the older posts in list's order.
+// The first page fixes an anchor time that later pages reuse: candidates are
+// the posts created by then and they are scored with the interactions up to
+// then, so the order stays the same from page to page.
+func (s *postService) listRanked(requestingUserID string, page models.Page, list func(models.Page) ([]*models.Post, models.PageInfo, error)) ([]*PostResponse, models.PageInfo, error) {
+	posts, info, err := s.rankedPage(requestingUserID, page, time.Now(), list)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list non-group posts from repository: %w", err)
+		return nil, models.PageInfo{}, err
 	}
-	return s.mapPostsToResponse(posts, &requestingUserID), nil
+	return s.mapPostsToResponse(posts, &requestingUserID), info, nil
+}
+
+// rankedPage returns the page of the ranked feed that page points to. now
+// becomes the anchor of a first page.
+func (s *postService) rankedPage(requestingUserID string, page models.Page, now time.Time, list func(models.Page) ([]*models.Post, models.PageInfo, error)) ([]*models.Post, models.PageInfo, error) {
+	anchor, offset, rest := now, page.Offset, ""
+	if page.After != "" {
+		var err error
+		if anchor, offset, rest, err = decodeRankedCursor(page.After); err != nil {
+			return nil, models.PageInfo{}, err
+		}
+	}
+	limit := page.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// older returns the posts after the ranked ones, keeping the anchor in
+	// the cursor
+	older := func(page models.Page) ([]*models.Post, models.PageInfo, error) {
+		posts, info, err := list(page)
+		if err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to list older feed posts from repository: %w", err)
+		}
+		if info.HasMore {
+			info.NextCursor = encodeRankedCursor(anchor, 0, info.NextCursor)
+		}
+		return posts, info, nil
+	}
+	if rest != "" {
+		return older(models.Page{Limit: limit, After: rest})
+	}
+
+	// The last batch stops at the oldest candidate, so its cursor is where
+	// the older posts start
+	candidates := make([]*models.Post, 0, maxFeedCandidates)
+	batch := models.Page{Limit: min(100, maxFeedCandidates)}
+	for len(candidates) < maxFeedCandidates {
+		posts, info, err := list(batch)
+		if err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to list feed candidates from repository: %w", err)
+		}
+		for _, post := range posts {
+			if !post.CreatedAt.After(anchor) && len(candidates) < maxFeedCandidates {
+				candidates = append(candidates, post)
+			}
+		}
+		if !info.HasMore {
+			rest = ""
+			break
+		}
+		batch.After = info.NextCursor
+		batch.Limit = min(100, maxFeedCandidates-len(candidates))
+		rest = info.NextCursor
+	}
+
+	ranked, err := s.feedService.Rank(requestingUserID, candidates, anchor)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	posts := []*models.Post{}
+	if offset < len(ranked) {
+		end := min(offset+limit, len(ranked))
+		posts = ranked[offset:end]
+		if end < len(ranked) {
+			return posts, models.PageInfo{HasMore: true, NextCursor: encodeRankedCursor(anchor, end, "")}, nil
+		}
+	}
+	if rest == "" {
+		return posts, models.PageInfo{}, nil
+	}
+	if len(posts) == limit {
+		return posts, models.PageInfo{HasMore: true, NextCursor: encodeRankedCursor(anchor, 0, rest)}, nil
+	}
+
+	// Fill the page with the posts after the ranked ones
+	fill := models.Page{Limit: limit - len(posts), After: rest}
+	if offset > len(ranked) {
+		fill = models.Page{Limit: limit, Offset: offset}
+	}
+	olderPosts, info, err := older(fill)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return append(posts, olderPosts...), info, nil
 }
 
 // ListPostsByUser retrieves non-group posts for a specific user's profile, filtered by the repository.
-func (s *postService) ListPostsByUser(targetUserID, requestingUserID string, limit, offset int) ([]*PostResponse, error) {
-	posts, err := s.postRepo.ListByUser(targetUserID, requestingUserID, limit, offset)
+func (s *postService) ListPostsByUser(targetUserID, requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) {
+	posts, info, err := s.postRepo.ListByUser(targetUserID, requestingUserID, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list non-group posts by user from repository: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list non-group posts by user from repository: %w", err)
 	}
+	var pinned []*models.Post
+	if isFirstPage(page) {
+		if pinned, err = s.postRepo.ListPinnedByUser(targetUserID, requestingUserID); err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to list pinned posts by user from repository: %w", err)
+		}
+	}
+	posts = withPinnedFirst(pinned, posts)
 	// The mapPostsToResponse function will handle fetching author details for each post.
 	// The requestingUserID is passed for context, in case mapPostsToResponse evolves to use it.
-	return s.mapPostsToResponse(posts, &requestingUserID), nil
+	return s.mapPostsToResponse(posts, &requestingUserID), info, nil
 }
 
 // ListGroupPosts retrieves posts belonging to a specific group, checking membership first.
-func (s *postService) ListGroupPosts(groupID string, requestingUserID string, limit, offset int) ([]*PostResponse, error) {
+func (s *postService) ListGroupPosts(groupID string, requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) {
 	// 1. Check if requesting user is a member of the group
 	isMember, err := s.groupRepo.IsMember(groupID, requestingUserID)
 	if err != nil {
 		log.Printf("Error checking group membership for user %s in group %s: %v", requestingUserID, groupID, err)
-		return nil, fmt.Errorf("failed to verify group membership: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to verify group membership: %w", err)
 	}
 	if !isMember {
 		// Return empty list or specific error? Empty list might be better to avoid revealing group existence.
 		// Or return ErrGroupAccessDenied if revealing group existence is okay.
-		return []*PostResponse{}, nil // Return empty list if not a member
-		// return nil, ErrGroupAccessDenied
+		return []*PostResponse{}, models.PageInfo{}, nil // Return empty list if not a member
+		// return nil, models.PageInfo{}, ErrGroupAccessDenied
 	}
 
 	// 2. Fetch posts from the repository
-	posts, err := s.postRepo.ListByGroupID(groupID, limit, offset)
+	posts, info, err := s.postRepo.ListByGroupID(groupID, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list posts by group ID %s from repository: %w", groupID, err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list posts by group ID %s from repository: %w", groupID, err)
+	}
+	var pinned []*models.Post
+	if isFirstPage(page) {
+		if pinned, err = s.postRepo.ListPinnedByGroupID(groupID); err != nil {
+			return nil, models.PageInfo{}, fmt.Errorf("failed to list pinned posts by group ID %s from repository: %w", groupID, err)
+		}
 	}
+	posts = withPinnedFirst(pinned, posts)
 
 	// 3. Map to response DTOs
-	return s.mapPostsToResponse(posts, &requestingUserID), nil
+	return s.mapPostsToResponse(posts, &requestingUserID), info, nil
+}
+
+// isFirstPage reports whether page starts at the top of a list
+func isFirstPage(page models.Page) bool {
+	return page.After == "" && page.Offset == 0
+}
+
+// withPinnedFirst puts the pinned posts of a profile or group before a page
+// of its posts. Pinned posts are only listed there, so they are dropped from
+// the pages themselves, which may come out a little short.
+func withPinnedFirst(pinned, posts []*models.Post) []*models.Post {
+	result := make([]*models.Post, 0, len(pinned)+len(posts))
+	result = append(result, pinned...)
+	for _, post := range posts {
+		if !post.PinnedAt.Valid {
+			result = append(result, post)
+		}
+	}
+	return result
+}
+
+// Update edits the title and content of a post and re-syncs its hashtags.
+// Only the author may edit a post, including in groups.
+func (s *postService) Update(postID, requestingUserID string, request *PostUpdateRequest) (*PostResponse, error) {
+	if request.Title == "" || request.Content == "" {
+		return nil, errors.New("title and content are required")
+	}
+
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get post for update: %w", err)
+	}
+	if post.UserID != requestingUserID {
+		// Don't reveal posts the user can't see
+		if !canViewPost(post, requestingUserID, s.postRepo, s.followerRepo, s.groupRepo) {
+			return nil, repositories.ErrPostNotFound
+		}
+		return nil, ErrPostForbidden
+	}
+	if post.ShareType == models.ShareTypeRepost {
+		return nil, fmt.Errorf("%w: reposts have no text to edit", ErrPostForbidden)
+	}
+
+	post.Title = request.Title
+	post.Content = request.Content
+	post.Tags = extractHashtags(request.Content)
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Post.Update(post); err != nil {
+			return fmt.Errorf("failed to update post in repository: %w", err)
+		}
+		if post.Status != models.PostStatusPublished {
+			return nil // Tagged when published
+		}
+		if err := repos.Tag.SetPostTags(post.ID, post.Tags, post.CreatedAt); err != nil {
+			return fmt.Errorf("failed to re-tag post: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapPostForViewer(post, requestingUserID), nil
+}
+
+// ListByTag retrieves the non-group posts with a hashtag, filtered by the repository
+// with the same privacy rules as List
+func (s *postService) ListByTag(tag, requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) {
+	name, err := normalizeTag(tag)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	posts, info, err := s.postRepo.ListByTag(name, requestingUserID, page)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list posts by tag from repository: %w", err)
+	}
+	return s.mapPostsToResponse(posts, &requestingUserID), info, nil
 }
 
 // Delete handles the deletion of a post, performing authorization checks
@@ -344,56 +1077,707 @@ func (s *postService) Delete(postID string, requestingUserID string) error {
 		if isOwner {
 			isAuthorized = true
 		}
-		// Manually remove allowed users first if it's a private non-group post
-		// (CASCADE DELETE might not be set up for post_allowed_users)
-		if isAuthorized && post.Privacy == models.PrivacyPrivate {
-			allowedUserIDs, err := s.postRepo.GetAllowedUsers(postID)
+	}
+
+	if !isAuthorized {
+		return ErrPostForbidden
+	}
+
+	// Media used by the post, deleted afterwards unless something else still uses it
+	mediaIDs := []string{}
+	if post.ImageID != "" {
+		mediaIDs = append(mediaIDs, post.ImageID)
+	}
+	attachments, err := s.postRepo.GetAttachments(postID)
+	if err != nil {
+		return fmt.Errorf("failed to get attachments for post before deletion: %w", err)
+	}
+	for _, a := range attachments {
+		if a.MediaID != post.ImageID {
+			mediaIDs = append(mediaIDs, a.MediaID)
+		}
+	}
+
+	// 3. Proceed with post deletion. Allowed users are removed manually first for private
+	// non-group posts (CASCADE DELETE might not be set up for post_allowed_users), so both
+	// steps share a transaction. Attachments and timeline entries are removed by ON DELETE CASCADE.
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if !post.GroupID.Valid && post.Privacy == models.PrivacyPrivate {
+			allowedUserIDs, err := repos.Post.GetAllowedUsers(postID)
 			if err != nil {
-				log.Printf("Warning: Failed to get allowed users for private post %s before deletion: %v", postID, err)
-			} else if len(allowedUserIDs) > 0 {
-				err = s.postRepo.RemoveAllowedUsers(postID, allowedUserIDs)
-				if err != nil {
-					log.Printf("Warning: Failed to remove allowed users for private post %s before deletion: %v", postID, err)
+				return fmt.Errorf("failed to get allowed users for private post before deletion: %w", err)
+			}
+			if err := repos.Post.RemoveAllowedUsers(postID, allowedUserIDs); err != nil {
+				return fmt.Errorf("failed to remove allowed users for private post before deletion: %w", err)
+			}
+		}
+		if err := repos.Post.Delete(postID); err != nil {
+			// Repository already returns ErrPostNotFound if deletion failed due to not found
+			return fmt.Errorf("failed to delete post in repository: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 4. Clean up the media. The post is already gone, so failures are only logged
+	for _, mediaID := range mediaIDs {
+		if err := s.mediaService.DeleteIfUnused(context.Background(), mediaID); err != nil {
+			log.Printf("Error cleaning up media %s of deleted post %s: %v", mediaID, postID, err)
+		}
+	}
+	return nil
+}
+
+// Share reposts or quotes a post the user can see. Reposts of reposts share
+// the original post. The share may not reach anyone the shared post isn't
+// visible to, see checkShareAudience.
+func (s *postService) Share(postID string, request *PostShareRequest) (*PostResponse, error) {
+	shared, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get post to share: %w", err)
+	}
+	if !canViewPost(shared, request.UserID, s.postRepo, s.followerRepo, s.groupRepo) || shared.Status != models.PostStatusPublished {
+		return nil, repositories.ErrPostNotFound
+	}
+	if shared.ShareType == models.ShareTypeRepost {
+		if !shared.SharedPostID.Valid {
+			return nil, repositories.ErrPostNotFound // Reposted post was deleted
+		}
+		if shared, err = s.postRepo.GetByID(shared.SharedPostID.String); err != nil {
+			if errors.Is(err, repositories.ErrPostNotFound) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to get reposted post to share: %w", err)
+		}
+		if !canViewPost(shared, request.UserID, s.postRepo, s.followerRepo, s.groupRepo) {
+			return nil, repositories.ErrPostNotFound
+		}
+	}
+
+	post := &models.Post{
+		UserID:       request.UserID,
+		Title:        request.Title,
+		Content:      request.Content,
+		Tags:         extractHashtags(request.Content),
+		Privacy:      request.Privacy,
+		SharedPostID: sql.NullString{String: shared.ID, Valid: true},
+		ShareType:    models.ShareTypeQuote,
+	}
+	if strings.TrimSpace(request.Content) == "" {
+		if request.Title != "" {
+			return nil, fmt.Errorf("%w: reposts have no title; add content to quote the post", ErrInvalidShare)
+		}
+		post.Content = ""
+		post.ShareType = models.ShareTypeRepost
+	}
+
+	allowedUserIDs := request.AllowedUserIDs
+	if post.Privacy == "" {
+		post.Privacy = shared.Privacy
+		if shared.Privacy == models.PrivacyPrivate && len(allowedUserIDs) == 0 {
+			// Share with the same audience, which includes the shared post's author
+			if allowedUserIDs, err = s.postRepo.GetAllowedUsers(shared.ID); err != nil {
+				return nil, fmt.Errorf("failed to get allowed users of shared post: %w", err)
+			}
+			allowedUserIDs = append(allowedUserIDs, shared.UserID)
+			if post.AudienceListID, err = s.postRepo.GetAudienceList(shared.ID); err != nil {
+				return nil, fmt.Errorf("failed to get audience list of shared post: %w", err)
+			}
+		}
+	}
+	allowedUserIDs = withoutUser(allowedUserIDs, post.UserID)
+	switch post.Privacy {
+	case models.PrivacyPublic, models.PrivacyAlmostPrivate:
+		allowedUserIDs = nil
+	case models.PrivacyPrivate:
+		if len(allowedUserIDs) == 0 {
+			return nil, fmt.Errorf("%w: allowed_user_ids are required for private shares", ErrInvalidShare)
+		}
+	default:
+		return nil, fmt.Errorf("%w: privacy must be public, semi_private, or private", ErrInvalidShare)
+	}
+	if err := s.checkShareAudience(shared, post, allowedUserIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.save(post, allowedUserIDs); err != nil {
+		return nil, err
+	}
+	return s.mapPostForViewer(post, post.UserID), nil
+}
+
+// checkShareAudience makes sure a share is only visible to users who can see
+// the shared post: semi_private posts can be shared with the author's
+// followers, private posts with their audience. Group posts stay in the group.
+// A share can only use the audience list of the shared post, or one of the
+// author's lists when sharing their own followers-only post.
+func (s *postService) checkShareAudience(shared, share *models.Post, allowedUserIDs []string) error {
+	if shared.GroupID.Valid {
+		return fmt.Errorf("%w: group posts can't be shared", ErrShareAudience)
+	}
+	switch shared.Privacy {
+	case models.PrivacyPublic:
+		return nil
+	case models.PrivacyAlmostPrivate:
+		switch share.Privacy {
+		case models.PrivacyAlmostPrivate:
+			// The sharer's followers are the author's only if the sharer is the author
+			if share.UserID == shared.UserID {
+				return nil
+			}
+		case models.PrivacyPrivate:
+			if share.AudienceListID != "" && share.UserID != shared.UserID {
+				return fmt.Errorf("%w: followers-only posts can't be shared with your audience lists", ErrShareAudience)
+			}
+			for _, userID := range allowedUserIDs {
+				if userID == shared.UserID {
+					continue
+				}
+				follow, err := s.followerRepo.FindFollow(userID, shared.UserID)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("failed to check follow status for share: %w", err)
+				}
+				if follow == nil || follow.Status != "accepted" {
+					return fmt.Errorf("%w: user %s doesn't follow the author", ErrShareAudience, userID)
 				}
 			}
+			return nil
+		}
+		return fmt.Errorf("%w: followers-only posts can only be shared privately with the author's followers", ErrShareAudience)
+	case models.PrivacyPrivate:
+		if share.Privacy != models.PrivacyPrivate {
+			return fmt.Errorf("%w: private posts can only be shared privately", ErrShareAudience)
 		}
+		if share.AudienceListID != "" {
+			listID, err := s.postRepo.GetAudienceList(shared.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get audience list of shared post: %w", err)
+			}
+			if share.AudienceListID != listID {
+				return fmt.Errorf("%w: the audience list isn't the shared post's", ErrShareAudience)
+			}
+		}
+		for _, userID := range allowedUserIDs {
+			if userID == shared.UserID {
+				continue
+			}
+			allowed, err := s.postRepo.IsUserAllowed(shared.ID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check audience of shared post: %w", err)
+			}
+			if !allowed {
+				return fmt.Errorf("%w: user %s can't see the shared post", ErrShareAudience, userID)
+			}
+		}
+		return nil
 	}
+	return fmt.Errorf("%w: unknown privacy %q", ErrShareAudience, shared.Privacy)
+}
 
-	if !isAuthorized {
-		return ErrPostForbidden
+// withoutUser returns the user IDs other than userID
+func withoutUser(userIDs []string, userID string) []string {
+	result := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != userID {
+			result = append(result, id)
+		}
 	}
+	return result
+}
 
-	// 3. Proceed with post deletion
-	err = s.postRepo.Delete(postID)
+// Unrepost deletes the user's repost of a post
+func (s *postService) Unrepost(postID, requestingUserID string) error {
+	repost, err := s.postRepo.FindRepost(requestingUserID, postID)
 	if err != nil {
-		// Repository already returns ErrPostNotFound if deletion failed due to not found
-		return fmt.Errorf("failed to delete post in repository: %w", err)
+		return err
 	}
+	return s.Delete(repost.ID, requestingUserID)
+}
+
+// maxDuePosts is how many due scheduled posts one PublishDue run publishes at most
+const maxDuePosts = 100
+
+// ListUnpublished lists the requesting user's drafts and scheduled posts
+func (s *postService) ListUnpublished(requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) {
+	posts, info, err := s.postRepo.ListUnpublished(requestingUserID, page)
+	if err != nil {
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list unpublished posts from repository: %w", err)
+	}
+	return s.mapPostsToResponse(posts, &requestingUserID), info, nil
+}
+
+// getUnpublished retrieves one of the requesting user's unpublished posts
+func (s *postService) getUnpublished(postID, requestingUserID string) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if post.UserID != requestingUserID {
+		if !canViewPost(post, requestingUserID, s.postRepo, s.followerRepo, s.groupRepo) {
+			return nil, repositories.ErrPostNotFound
+		}
+		return nil, ErrPostForbidden
+	}
+	if post.Status == models.PostStatusPublished {
+		return nil, ErrPostPublished
+	}
+	return post, nil
+}
 
+// Schedule sets the publication time of a draft or scheduled post
+func (s *postService) Schedule(postID, requestingUserID string, at time.Time) (*PostResponse, error) {
+	if !at.After(time.Now()) {
+		return nil, fmt.Errorf("%w: scheduled_at must be in the future", ErrInvalidSchedule)
+	}
+	post, err := s.getUnpublished(postID, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPollOpen(post.ID, at); err != nil {
+		return nil, err
+	}
+	post.Status = models.PostStatusScheduled
+	post.ScheduledAt = sql.NullTime{Time: at.UTC(), Valid: true}
+	if err := s.postRepo.SetSchedule(post.ID, post.Status, post.ScheduledAt); err != nil {
+		return nil, err
+	}
+	return s.mapPostForViewer(post, requestingUserID), nil
+}
+
+// Unschedule cancels the publication of a scheduled post, keeping it as a draft
+func (s *postService) Unschedule(postID, requestingUserID string) (*PostResponse, error) {
+	post, err := s.getUnpublished(postID, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+	post.Status = models.PostStatusDraft
+	post.ScheduledAt = sql.NullTime{}
+	if err := s.postRepo.SetSchedule(post.ID, post.Status, post.ScheduledAt); err != nil {
+		return nil, err
+	}
+	return s.mapPostForViewer(post, requestingUserID), nil
+}
+
+// Publish publishes one of the requesting user's drafts or scheduled posts now
+func (s *postService) Publish(postID, requestingUserID string) (*PostResponse, error) {
+	post, err := s.getUnpublished(postID, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publish(post, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.mapPostForViewer(post, requestingUserID), nil
+}
+
+// PublishDue publishes the scheduled posts whose time has come. A failure is
+// logged and the post is retried on the next run.
+func (s *postService) PublishDue(now time.Time) (int, error) {
+	posts, err := s.postRepo.ListDueScheduled(now.UTC(), maxDuePosts)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, post := range posts {
+		if err := s.publish(post, now); err != nil {
+			if !errors.Is(err, ErrPostPublished) {
+				log.Printf("Error publishing scheduled post %s: %v", post.ID, err)
+			}
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// checkPollOpen returns ErrInvalidSchedule if the post has a poll that is
+// closed at publishAt
+func (s *postService) checkPollOpen(postID string, publishAt time.Time) error {
+	poll, err := s.pollRepo.GetByPostID(postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPollNotFound) {
+			return nil
+		}
+		return err
+	}
+	if poll.IsClosed(publishAt) {
+		return fmt.Errorf("%w: the poll closes before the post is published", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// publish makes an unpublished post visible as of now, then distributes it
+// like a new post. Group posts of authors who left the group, and posts whose
+// poll closed in the meantime, go back to drafts.
+func (s *postService) publish(post *models.Post, now time.Time) error {
+	if post.GroupID.Valid {
+		isMember, err := s.groupRepo.IsMember(post.GroupID.String, post.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to verify group membership: %w", err)
+		}
+		if !isMember {
+			if err := s.postRepo.SetSchedule(post.ID, models.PostStatusDraft, sql.NullTime{}); err != nil {
+				return err
+			}
+			return ErrGroupAccessDenied
+		}
+	}
+	if err := s.checkPollOpen(post.ID, now); err != nil {
+		if errors.Is(err, ErrInvalidSchedule) && post.Status == models.PostStatusScheduled {
+			if err := s.postRepo.SetSchedule(post.ID, models.PostStatusDraft, sql.NullTime{}); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	post.Tags = extractHashtags(post.Content)
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		published, err := repos.Post.Publish(post.ID, now)
+		if err != nil {
+			return err
+		}
+		if !published {
+			return ErrPostPublished
+		}
+		post.Status = models.PostStatusPublished
+		post.ScheduledAt = sql.NullTime{}
+		post.CreatedAt = now
+		return s.distribute(repos, post)
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyMentions(post)
 	return nil
 }
 
+// StartScheduler publishes due scheduled posts on a schedule
+func (s *postService) StartScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Post scheduler disabled; scheduled posts will not be published")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				published, err := s.PublishDue(now)
+				if err != nil {
+					log.Printf("Error publishing scheduled posts: %v", err)
+					continue
+				}
+				if published > 0 {
+					log.Printf("Published %d scheduled posts", published)
+				}
+			}
+		}
+	}()
+}
+
 // ListExplore retrieves public, non-group posts for the "Explore" feed.
 // No specific requestingUserID is needed here as it's for public content.
-func (s *postService) ListExplore(limit, offset int) ([]*PostResponse, error) {
-	posts, err := s.postRepo.ListPublic(limit, offset)
+func (s *postService) ListExplore(page models.Page) ([]*PostResponse, models.PageInfo, error) {
+	posts, info, err := s.postRepo.ListPublic(page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list public posts from repository for explore: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list public posts from repository for explore: %w", err)
 	}
 	// The existing mapPostsToResponse should fetch user details for each post.
-	return s.mapPostsToResponse(posts, nil), nil
+	return s.mapPostsToResponse(posts, nil), info, nil
 }
 
 // ListFollowingFeed retrieves posts from users that the requestingUserID follows.
-func (s *postService) ListFollowingFeed(requestingUserID string, limit, offset int) ([]*PostResponse, error) {
+func (s *postService) ListFollowingFeed(requestingUserID string, page models.Page) ([]*PostResponse, models.PageInfo, error) {
 	if requestingUserID == "" {
-		return nil, errors.New("requestingUserID is required for following feed")
+		return nil, models.PageInfo{}, errors.New("requestingUserID is required for following feed")
+	}
+	list := func(page models.Page) ([]*models.Post, models.PageInfo, error) {
+		return s.postRepo.ListFollowedByUser(requestingUserID, page)
 	}
-	posts, err := s.postRepo.ListFollowedByUser(requestingUserID, limit, offset)
+	ranked, err := s.isFeedRanked(requestingUserID)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	if ranked {
+		return s.listRanked(requestingUserID, page, list)
+	}
+
+	posts, info, err := list(page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list posts from followed users: %w", err)
+		return nil, models.PageInfo{}, fmt.Errorf("failed to list posts from followed users: %w", err)
 	}
 	// Pass requestingUserID to mapPostsToResponse; it might be used for additional checks
 	// or to enrich responses based on the viewer, though current mapPostsToResponse primarily uses it for author details.
-	return s.mapPostsToResponse(posts, &requestingUserID), nil
+	return s.mapPostsToResponse(posts, &requestingUserID), info, nil
+}
+
+// Pin pins a post on its author's profile or, for group posts, in its group
+func (s *postService) Pin(postID, requestingUserID string) (*PostResponse, error) {
+	post, err := s.getPinnable(postID, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !post.PinnedAt.Valid {
+		err := s.uow.Do(func(repos *repositories.Repositories) error {
+			count, err := repos.Post.CountPinned(post.UserID, post.GroupID.String)
+			if err != nil {
+				return err
+			}
+			if count >= s.maxPinned {
+				return fmt.Errorf("%w: at most %d posts can be pinned", ErrPinLimit, s.maxPinned)
+			}
+			post.PinnedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+			return repos.Post.SetPinned(post.ID, post.PinnedAt)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.mapPostForViewer(post, requestingUserID), nil
+}
+
+// Unpin unpins a post
+func (s *postService) Unpin(postID, requestingUserID string) (*PostResponse, error) {
+	post, err := s.getPinnable(postID, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+	if post.PinnedAt.Valid {
+		post.PinnedAt = sql.NullTime{}
+		if err := s.postRepo.SetPinned(post.ID, post.PinnedAt); err != nil {
+			return nil, err
+		}
+	}
+	return s.mapPostForViewer(post, requestingUserID), nil
+}
+
+// getPinnable retrieves a published post the requesting user may pin: their
+// own user posts, and any post in a group they are an admin of
+func (s *postService) getPinnable(postID, requestingUserID string) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if !canViewPost(post, requestingUserID, s.postRepo, s.followerRepo, s.groupRepo) {
+		return nil, repositories.ErrPostNotFound
+	}
+
+	allowed := post.UserID == requestingUserID
+	if post.GroupID.Valid {
+		if allowed, err = s.groupRepo.IsAdmin(post.GroupID.String, requestingUserID); err != nil {
+			return nil, fmt.Errorf("failed to check group admin status: %w", err)
+		}
+	}
+	if !allowed {
+		return nil, ErrPostForbidden
+	}
+	if post.Status != models.PostStatusPublished {
+		return nil, fmt.Errorf("%w: only published posts can be pinned", ErrInvalidPin)
+	}
+	return post, nil
+}
+
+// GetAudience returns the privacy and allowed users of the requesting user's post
+func (s *postService) GetAudience(postID, requestingUserID string) (*PostAudienceResponse, error) {
+	post, err := s.getAudienceOwner(postID, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.audienceResponse(post)
+}
+
+// UpdateAudience changes who can see a post. Timelines are rebuilt by the
+// repository; mentions and their notifications follow the new audience.
+func (s *postService) UpdateAudience(postID, requestingUserID string, request *PostAudienceRequest) (*PostAudienceResponse, error) {
+	post, err := s.getAudienceOwner(postID, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedUserIDs, err := s.validateAudience(request)
+	if err != nil {
+		return nil, err
+	}
+	currentList, err := s.postRepo.GetAudienceList(post.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audience list: %w", err)
+	}
+	if request.AudienceListID != currentList {
+		// Shares may keep the list of the post they share
+		if err := s.checkAudienceList(post.UserID, request.AudienceListID); err != nil {
+			return nil, err
+		}
+	}
+	target := &models.Post{UserID: post.UserID, Privacy: request.Privacy, AudienceListID: request.AudienceListID}
+	if post.ShareType != "" && post.SharedPostID.Valid {
+		shared, err := s.postRepo.GetByID(post.SharedPostID.String)
+		if err != nil && !errors.Is(err, repositories.ErrPostNotFound) {
+			return nil, fmt.Errorf("failed to get shared post: %w", err)
+		}
+		if shared != nil {
+			if err := s.checkShareAudience(shared, target, allowedUserIDs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	current, err := s.postRepo.GetAllowedUsers(post.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowed users: %w", err)
+	}
+	keep := make(map[string]bool, len(allowedUserIDs))
+	for _, userID := range allowedUserIDs {
+		keep[userID] = true
+	}
+	removed := make([]string, 0)
+	for _, userID := range current {
+		if keep[userID] {
+			delete(keep, userID) // Already allowed
+		} else {
+			removed = append(removed, userID)
+		}
+	}
+	added := make([]string, 0, len(keep))
+	for _, userID := range allowedUserIDs {
+		if keep[userID] {
+			added = append(added, userID)
+		}
+	}
+
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if request.Privacy != post.Privacy {
+			if err := repos.Post.UpdatePrivacy(post.ID, request.Privacy); err != nil {
+				return err
+			}
+		}
+		if err := repos.Post.RemoveAllowedUsers(post.ID, removed); err != nil {
+			return fmt.Errorf("failed to remove allowed users: %w", err)
+		}
+		if err := repos.Post.AddAllowedUsers(post.ID, added); err != nil {
+			return fmt.Errorf("failed to add allowed users: %w", err)
+		}
+		if request.AudienceListID != currentList {
+			if err := repos.Post.SetAudienceList(post.ID, request.AudienceListID); err != nil {
+				return fmt.Errorf("failed to set audience list: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	post.Privacy = request.Privacy
+
+	if post.Status == models.PostStatusPublished {
+		if err := s.mentionService.RefreshPostAudience(post.ID); err != nil {
+			log.Printf("Error updating mentions of post %s for its new audience: %v", post.ID, err)
+		}
+	}
+	return s.audienceResponse(post)
+}
+
+// getAudienceOwner retrieves a user post whose audience the requesting user may manage
+func (s *postService) getAudienceOwner(postID, requestingUserID string) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPostNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if post.UserID != requestingUserID {
+		if !canViewPost(post, requestingUserID, s.postRepo, s.followerRepo, s.groupRepo) {
+			return nil, repositories.ErrPostNotFound
+		}
+		return nil, ErrPostForbidden
+	}
+	if post.GroupID.Valid {
+		return nil, fmt.Errorf("%w: group posts are visible to the group's members", ErrInvalidAudience)
+	}
+	return post, nil
+}
+
+// validateAudience checks a requested audience and returns its allowed
+// users without duplicates. The audience list is checked by the caller.
+func (s *postService) validateAudience(request *PostAudienceRequest) ([]string, error) {
+	switch request.Privacy {
+	case models.PrivacyPublic, models.PrivacyAlmostPrivate:
+		if len(request.AllowedUserIDs) > 0 || request.AudienceListID != "" {
+			return nil, fmt.Errorf("%w: allowed_user_ids and audience_list_id are only for private posts", ErrInvalidAudience)
+		}
+		return nil, nil
+	case models.PrivacyPrivate:
+		if len(request.AllowedUserIDs) == 0 && request.AudienceListID == "" {
+			return nil, fmt.Errorf("%w: allowed_user_ids or audience_list_id are required for private posts", ErrInvalidAudience)
+		}
+	default:
+		return nil, fmt.Errorf("%w: privacy must be public, semi_private or private", ErrInvalidAudience)
+	}
+
+	seen := make(map[string]bool, len(request.AllowedUserIDs))
+	allowedUserIDs := make([]string, 0, len(request.AllowedUserIDs))
+	for _, userID := range request.AllowedUserIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		if _, err := s.userRepo.GetByID(userID); err != nil {
+			if errors.Is(err, repositories.ErrUserNotFound) {
+				return nil, fmt.Errorf("%w: user %s not found", ErrInvalidAudience, userID)
+			}
+			return nil, fmt.Errorf("failed to check allowed user %s: %w", userID, err)
+		}
+		allowedUserIDs = append(allowedUserIDs, userID)
+	}
+	return allowedUserIDs, nil
+}
+
+// audienceResponse reads the current audience of a post
+func (s *postService) audienceResponse(post *models.Post) (*PostAudienceResponse, error) {
+	response := &PostAudienceResponse{PostID: post.ID, Privacy: post.Privacy, AllowedUserIDs: []string{}}
+	if post.Privacy != models.PrivacyPrivate {
+		return response, nil
+	}
+	allowed, err := s.postRepo.GetAllowedUsers(post.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowed users: %w", err)
+	}
+	if allowed != nil {
+		response.AllowedUserIDs = allowed
+	}
+	if response.AudienceListID, err = s.postRepo.GetAudienceList(post.ID); err != nil {
+		return nil, fmt.Errorf("failed to get audience list: %w", err)
+	}
+	return response, nil
+}
+
+// checkAudienceList makes sure a private post is shared with one of its
+// author's audience lists, if any
+func (s *postService) checkAudienceList(authorID, listID string) error {
+	if listID == "" {
+		return nil
+	}
+	if _, err := s.audienceRepo.GetByID(authorID, listID); err != nil {
+		if errors.Is(err, repositories.ErrAudienceListNotFound) {
+			return fmt.Errorf("%w: audience list %s not found", ErrInvalidAudience, listID)
+		}
+		return fmt.Errorf("failed to check audience list: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+)
+
+const (
+	// SearchTypeAll searches every kind of entity
+	SearchTypeAll = "all"
+	// maxSearchTerms caps the number of words of a query that are matched
+	maxSearchTerms = 8
+	// maxSearchLimit caps the page size
+	maxSearchLimit = 50
+)
+
+var (
+	ErrEmptySearchQuery  = errors.New("search query has no words to search for")
+	ErrInvalidSearchType = errors.New("invalid search type")
+)
+
+// SearchResponse is a page of search results
+type SearchResponse struct {
+	Query   string                `json:"query"`
+	Type    string                `json:"type"`
+	Results []models.SearchResult `json:"results"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+	HasMore bool                  `json:"has_more"`
+}
+
+// SearchService defines the interface for full-text search
+type SearchService interface {
+	// Search finds the posts, comments, users and groups matching query that
+	// requestingUserID may see. searchType is one of the models.SearchType
+	// constants, or SearchTypeAll (or empty) for every type. Titles and
+	// snippets are HTML-escaped, with the matched words wrapped in <mark>.
+	Search(query, searchType, requestingUserID string, limit, offset int) (*SearchResponse, error)
+}
+
+// searchService implements SearchService
+type searchService struct {
+	searchRepo repositories.SearchRepository
+}
+
+// NewSearchService creates a new SearchService
+func NewSearchService(searchRepo repositories.SearchRepository) SearchService {
+	return &searchService{searchRepo: searchRepo}
+}
+
+// Search matches every word of the query, allowing the end of each word to be
+// missing so results show up while the user is still typing
+func (s *searchService) Search(query, searchType, requestingUserID string, limit, offset int) (*SearchResponse, error) {
+	types := models.SearchTypes
+	if searchType == "" {
+		searchType = SearchTypeAll
+	}
+	if searchType != SearchTypeAll {
+		if !slices.Contains(models.SearchTypes, searchType) {
+			return nil, ErrInvalidSearchType
+		}
+		types = []string{searchType}
+	}
+
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearchQuery
+	}
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Fetch one extra result to tell whether there is another page
+	results, err := s.searchRepo.Search(terms, types, requestingUserID, limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(results) > limit
+	if hasMore {
+		results = results[:limit]
+	}
+	for i := range results {
+		results[i].Title = highlightHTML(results[i].Title)
+		results[i].Snippet = highlightHTML(results[i].Snippet)
+	}
+
+	return &SearchResponse{
+		Query:   query,
+		Type:    searchType,
+		Results: results,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: hasMore,
+	}, nil
+}
+
+// searchTerms splits a query into lowercase words. Punctuation and search
+// syntax are dropped, so queries can't be used to build expressions.
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// highlightHTML escapes text and turns the match markers set by the repository into <mark> tags
+func highlightHTML(text string) string {
+	return strings.NewReplacer(
+		models.SearchMatchStart, "<mark>",
+		models.SearchMatchEnd, "</mark>",
+	).Replace(html.EscapeString(text))
+}
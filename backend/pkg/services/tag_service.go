@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/HASANALI117/social-network/pkg/models"
+	"github.com/HASANALI117/social-network/pkg/repositories"
+)
+
+const (
+	// maxTagLength is the longest hashtag recognized, in characters
+	maxTagLength = 50
+	// maxTagsPerPost caps the hashtags stored for one post; later ones are ignored
+	maxTagsPerPost = 20
+	// DefaultTrendingWindow is how far back trending tags are computed by default
+	DefaultTrendingWindow = 24 * time.Hour
+	// MaxTrendingWindow caps the window of trending tags
+	MaxTrendingWindow = 7 * 24 * time.Hour
+	// maxTrendingLimit caps the number of trending tags returned
+	maxTrendingLimit = 50
+)
+
+var ErrInvalidTag = errors.New("invalid tag")
+
+// hashtagPattern matches a '#' followed by letters, digits and underscores.
+// The character before the '#' is captured so that URL fragments and HTML
+// entities (page#section, &#39;) aren't taken for hashtags.
+var hashtagPattern = regexp.MustCompile(`(^|[^\p{L}\p{N}_&/#])#([\p{L}\p{N}_]+)`)
+
+// extractHashtags returns the distinct hashtags of a text, lowercased and in
+// order of appearance. Tags made only of digits or underscores (#1) and tags
+// longer than maxTagLength are skipped.
+func extractHashtags(text string) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag, err := normalizeTag(match[2])
+		if err != nil || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxTagsPerPost {
+			break
+		}
+	}
+	return tags
+}
+
+// normalizeTag validates a tag name given by a client or found in a post and
+// returns it lowercase without a leading '#'
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if name == "" || len([]rune(name)) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+	hasLetter := false
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r), r == '_':
+		default:
+			return "", ErrInvalidTag
+		}
+	}
+	if !hasLetter {
+		return "", ErrInvalidTag
+	}
+	return name, nil
+}
+
+// TagService defines the interface for hashtag business logic. Posts by tag
+// are listed through PostService.ListByTag.
+type TagService interface {
+	Trending(window time.Duration, limit int) ([]models.TrendingTag, error)
+	Follow(userID, tag string) error
+	Unfollow(userID, tag string) error
+	IsFollowing(userID, tag string) (bool, error)
+	ListFollowed(userID string) ([]models.FollowedTag, error)
+}
+
+// tagService implements TagService
+type tagService struct {
+	tagRepo repositories.TagRepository
+}
+
+// NewTagService creates a new TagService
+func NewTagService(tagRepo repositories.TagRepository) TagService {
+	return &tagService{tagRepo: tagRepo}
+}
+
+// Trending returns the tags most used in public posts during the last window,
+// ranked by number of distinct authors
+func (s *tagService) Trending(window time.Duration, limit int) ([]models.TrendingTag, error) {
+	if window <= 0 {
+		window = DefaultTrendingWindow
+	}
+	if window > MaxTrendingWindow {
+		window = MaxTrendingWindow
+	}
+	if limit <= 0 || limit > maxTrendingLimit {
+		limit = maxTrendingLimit
+	}
+	tags, err := s.tagRepo.Trending(time.Now().Add(-window), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending tags: %w", err)
+	}
+	return tags, nil
+}
+
+// Follow makes public posts with the tag show up in the user's following feed
+func (s *tagService) Follow(userID, tag string) error {
+	name, err := normalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	return s.tagRepo.Follow(userID, name)
+}
+
+// Unfollow stops following a tag
+func (s *tagService) Unfollow(userID, tag string) error {
+	name, err := normalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	return s.tagRepo.Unfollow(userID, name)
+}
+
+// IsFollowing checks whether the user follows a tag
+func (s *tagService) IsFollowing(userID, tag string) (bool, error) {
+	name, err := normalizeTag(tag)
+	if err != nil {
+		return false, err
+	}
+	return s.tagRepo.IsFollowing(userID, name)
+}
+
+// ListFollowed returns the tags the user follows, most recent first
+func (s *tagService) ListFollowed(userID string) ([]models.FollowedTag, error) {
+	return s.tagRepo.ListFollowed(userID)
+}
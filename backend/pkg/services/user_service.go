@@ -63,18 +63,20 @@ type userService struct {
 	postService         PostService     // Added PostService dependency
 	followerService     FollowerService // Added FollowerService dependency
 	groupRepo           repositories.GroupRepository // New dependency
+	mediaService        MediaService                 // Resolves avatar uploads
 	// NotificationService is not directly used by UserService for creating follow request notifications.
 	// That logic will be in FollowerService. UserService might use it for other user-specific notifications in the future.
 }
 
 // NewUserService creates a new UserService
-func NewUserService(userRepo repositories.UserRepository, postService PostService, followerService FollowerService, groupRepo repositories.GroupRepository) UserService {
+func NewUserService(userRepo repositories.UserRepository, postService PostService, followerService FollowerService, groupRepo repositories.GroupRepository, mediaService MediaService) UserService {
 	// No NotificationService needed here for now, as follow request notifications are handled by FollowerService.
 	return &userService{
 		userRepo:        userRepo,
 		postService:     postService,
 		followerService: followerService,
 		groupRepo:       groupRepo, // Initialize new dependency
+		mediaService:    mediaService,
 	}
 }
 
@@ -142,6 +144,11 @@ func (s *userService) Register(user *models.User) (*UserResponse, error) {
 		user.Username = generatedUsername
 	}
 	
+	// Avatars reference uploaded media, which requires a session; users set
+	// them with Update after signing in, from their profile
+	user.AvatarURL = ""
+	user.AvatarID = ""
+
 	// Generate UUID for new user
 	user.ID = uuid.New().String()
 
@@ -257,8 +264,16 @@ func (s *userService) Update(id string, updateData map[string]interface{}) (*Use
 	if lastName, ok := updateData["last_name"].(string); ok {
 		user.LastName = lastName
 	}
-	if avatarURL, ok := updateData["avatar_url"].(string); ok {
-		user.AvatarURL = avatarURL
+	if avatarID, ok := updateData["avatar_id"].(string); ok {
+		if avatarID == "" {
+			user.AvatarID, user.AvatarURL = "", ""
+		} else {
+			media, err := s.mediaService.ResolveImage(avatarID, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			user.AvatarID, user.AvatarURL = media.ID, media.URL
+		}
 	}
 	if aboutMe, ok := updateData["about_me"].(string); ok {
 		user.AboutMe = aboutMe
@@ -443,7 +458,7 @@ func (s *userService) GetUserProfile(viewerID, profileUserID string) (*UserProfi
 		followingCount = 0
 	}
 
-	postsResponse, fetchErr = s.postService.ListPostsByUser(profileUserID, viewerID, profileDataLimit, 0)
+	postsResponse, _, fetchErr = s.postService.ListPostsByUser(profileUserID, viewerID, models.Page{Limit: profileDataLimit})
 	if fetchErr != nil {
 		log.Printf("Error fetching posts for user %s (viewer %s): %v", profileUserID, viewerID, fetchErr)
 		postsResponse = []*PostResponse{}
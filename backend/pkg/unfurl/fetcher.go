@@ -0,0 +1,208 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrUnsupportedURL is returned for URLs that aren't absolute http(s) URLs
+	ErrUnsupportedURL = errors.New("unsupported URL")
+	// ErrBlockedAddress is returned when a host resolves to a loopback,
+	// private or otherwise internal address
+	ErrBlockedAddress = errors.New("address is not publicly routable")
+)
+
+// Page is a fetched document
+type Page struct {
+	URL         string // Final URL, after redirects
+	ContentType string // Media type without parameters, e.g. "text/html"
+	Body        []byte // At most the fetcher's size limit; longer documents are cut off
+}
+
+// Fetcher retrieves the documents link previews are built from. Implementations
+// must refuse URLs that would reach the server's own network.
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*Page, error)
+}
+
+// HTTPFetcherOptions configures an HTTPFetcher
+type HTTPFetcherOptions struct {
+	Timeout      time.Duration // Whole request, including redirects and reading the body
+	MaxBytes     int64         // Bytes of the body read at most
+	MaxRedirects int
+	UserAgent    string
+	// AllowedHosts are exempt from the address check, e.g. an intranet wiki
+	// or a local stand-in during development
+	AllowedHosts []string
+}
+
+// HTTPFetcher fetches documents over HTTP(S). Every connection, including
+// those of redirects, is checked against the address the host resolved to,
+// so DNS tricks can't point it at internal services.
+type HTTPFetcher struct {
+	client    *http.Client
+	maxBytes  int64
+	userAgent string
+}
+
+// NewHTTPFetcher creates an HTTPFetcher
+func NewHTTPFetcher(opts HTTPFetcherOptions) *HTTPFetcher {
+	allowed := make(map[string]bool, len(opts.AllowedHosts))
+	for _, host := range opts.AllowedHosts {
+		allowed[strings.ToLower(host)] = true
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	guarded := &net.Dialer{Timeout: opts.Timeout, Control: checkAddress}
+	transport := &http.Transport{
+		Proxy: nil, // A proxy would make the connection, bypassing the address check
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+			if allowed[strings.ToLower(host)] {
+				return dialer.DialContext(ctx, network, address)
+			}
+			return guarded.DialContext(ctx, network, address)
+		},
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	maxRedirects := opts.MaxRedirects
+	return &HTTPFetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return checkURL(req.URL)
+			},
+		},
+		maxBytes:  opts.MaxBytes,
+		userAgent: opts.UserAgent,
+	}
+}
+
+// Fetch retrieves rawURL. Responses other than 200 OK are errors.
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedURL, err)
+	}
+	if err := checkURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/json;q=0.9,*/*;q=0.8")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	contentType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	return &Page{
+		URL:         resp.Request.URL.String(),
+		ContentType: strings.ToLower(strings.TrimSpace(contentType)),
+		Body:        body,
+	}, nil
+}
+
+// checkURL accepts absolute http(s) URLs without credentials
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrUnsupportedURL)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: missing host", ErrUnsupportedURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL", ErrUnsupportedURL)
+	}
+	return nil
+}
+
+// checkAddress is a net.Dialer Control function that refuses to connect to
+// internal addresses. It runs after DNS resolution, for every address tried.
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// Ranges that aren't publicly routable beyond what the net.IP methods cover
+var reservedNets = mustParseCIDRs(
+	"0.0.0.0/8",       // "This" network
+	"100.64.0.0/10",   // Carrier-grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // TEST-NET-1
+	"198.18.0.0/15",   // Benchmarking
+	"198.51.100.0/24", // TEST-NET-2
+	"203.0.113.0/24",  // TEST-NET-3
+	"240.0.0.0/4",     // Reserved, including broadcast
+	"64:ff9b::/96",    // NAT64, which can embed internal IPv4 addresses
+	"2001:db8::/32",   // Documentation
+)
+
+// IsPublicIP reports whether ip is a publicly routable unicast address
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4 // IPv4-mapped IPv6 addresses are checked as IPv4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
@@ -0,0 +1,273 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestFetcher returns a fetcher that may reach stand-in servers on 127.0.0.1
+func newTestFetcher(opts HTTPFetcherOptions) *HTTPFetcher {
+	if opts.Timeout == 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = 1 << 20
+	}
+	if opts.AllowedHosts == nil {
+		opts.AllowedHosts = []string{"127.0.0.1"}
+	}
+	return NewHTTPFetcher(opts)
+}
+
+// newStandIn starts a server for the test, closed when it ends
+func newStandIn(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	userAgents := make(chan string, 1)
+	server := newStandIn(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			userAgents <- r.UserAgent()
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html></html>")
+		case "/big":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, strings.Repeat("x", 10000))
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+			fmt.Fprint(w, "late")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	t.Run("ok", func(t *testing.T) {
+		f := newTestFetcher(HTTPFetcherOptions{UserAgent: "TestBot/1.0"})
+		page, err := f.Fetch(context.Background(), server.URL+"/page")
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if page.ContentType != "text/html" || string(page.Body) != "<html></html>" || page.URL != server.URL+"/page" {
+			t.Errorf("page = %+v", page)
+		}
+		if got := <-userAgents; got != "TestBot/1.0" {
+			t.Errorf("User-Agent = %q", got)
+		}
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		f := newTestFetcher(HTTPFetcherOptions{MaxBytes: 100})
+		page, err := f.Fetch(context.Background(), server.URL+"/big")
+		if err != nil {
+			t.Fatalf("Fetch: %v", err)
+		}
+		if len(page.Body) != 100 {
+			t.Errorf("read %d bytes, want 100", len(page.Body))
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		f := newTestFetcher(HTTPFetcherOptions{Timeout: 100 * time.Millisecond})
+		start := time.Now()
+		if _, err := f.Fetch(context.Background(), server.URL+"/slow"); err == nil {
+			t.Fatal("Fetch of a slow page succeeded")
+		}
+		if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+			t.Errorf("Fetch took %v, want about the 100ms timeout", elapsed)
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		f := newTestFetcher(HTTPFetcherOptions{})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := f.Fetch(ctx, server.URL+"/slow"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Fetch = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		f := newTestFetcher(HTTPFetcherOptions{})
+		if _, err := f.Fetch(context.Background(), server.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+			t.Errorf("Fetch = %v, want a 404 error", err)
+		}
+	})
+
+	t.Run("unsupported URLs", func(t *testing.T) {
+		f := newTestFetcher(HTTPFetcherOptions{})
+		for _, rawURL := range []string{
+			"ftp://example.com/file",
+			"file:///etc/passwd",
+			"http://user:secret@" + strings.TrimPrefix(server.URL, "http://") + "/page",
+			"http:///page",
+			"/relative",
+		} {
+			if _, err := f.Fetch(context.Background(), rawURL); !errors.Is(err, ErrUnsupportedURL) {
+				t.Errorf("Fetch(%q) = %v, want ErrUnsupportedURL", rawURL, err)
+			}
+		}
+	})
+}
+
+func TestFetchRedirects(t *testing.T) {
+	// /r/N redirects N more times, then serves a page
+	var server *httptest.Server
+	server = newStandIn(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/r/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if n == 0 {
+			fmt.Fprint(w, "<html></html>")
+			return
+		}
+		http.Redirect(w, r, server.URL+"/r/"+strconv.Itoa(n-1), http.StatusFound)
+	}))
+	f := newTestFetcher(HTTPFetcherOptions{MaxRedirects: 2})
+
+	page, err := f.Fetch(context.Background(), server.URL+"/r/2")
+	if err != nil {
+		t.Fatalf("Fetch with 2 redirects: %v", err)
+	}
+	if page.URL != server.URL+"/r/0" {
+		t.Errorf("final URL = %q, want %q", page.URL, server.URL+"/r/0")
+	}
+	if _, err := f.Fetch(context.Background(), server.URL+"/r/3"); err == nil || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+		t.Errorf("Fetch with 3 redirects = %v, want the redirect limit", err)
+	}
+}
+
+func TestFetchBlocksInternalAddresses(t *testing.T) {
+	internal := newStandIn(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secret")
+	}))
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(internal.URL, "http://"))
+
+	t.Run("loopback", func(t *testing.T) {
+		f := NewHTTPFetcher(HTTPFetcherOptions{Timeout: time.Second, MaxBytes: 1024})
+		for _, host := range []string{"127.0.0.1", "localhost", "[::ffff:127.0.0.1]"} {
+			rawURL := "http://" + host + ":" + port + "/"
+			if _, err := f.Fetch(context.Background(), rawURL); !errors.Is(err, ErrBlockedAddress) {
+				t.Errorf("Fetch(%s) = %v, want ErrBlockedAddress", rawURL, err)
+			}
+		}
+	})
+
+	t.Run("after a redirect", func(t *testing.T) {
+		// The redirecting stand-in is allowed, but "localhost" isn't and resolves to loopback
+		redirector := newStandIn(t, http.RedirectHandler("http://localhost:"+port+"/", http.StatusFound))
+		f := newTestFetcher(HTTPFetcherOptions{MaxRedirects: 5})
+		if _, err := f.Fetch(context.Background(), redirector.URL); !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("Fetch = %v, want ErrBlockedAddress", err)
+		}
+	})
+
+	t.Run("redirect to another scheme", func(t *testing.T) {
+		redirector := newStandIn(t, http.RedirectHandler("file:///etc/passwd", http.StatusFound))
+		f := newTestFetcher(HTTPFetcherOptions{MaxRedirects: 5})
+		if _, err := f.Fetch(context.Background(), redirector.URL); !errors.Is(err, ErrUnsupportedURL) {
+			t.Errorf("Fetch = %v, want ErrUnsupportedURL", err)
+		}
+	})
+}
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		blocked bool
+	}{
+		{"127.0.0.1:80", true},
+		{"10.1.2.3:443", true},
+		{"192.168.0.10:80", true},
+		{"169.254.169.254:80", true}, // Cloud metadata
+		{"[::1]:80", true},
+		{"[fd00::1]:80", true},
+		{"93.184.216.34:443", false},
+		{"[2606:4700::1111]:443", false},
+	}
+	for _, tt := range tests {
+		err := checkAddress("tcp", tt.address, nil)
+		if blocked := errors.Is(err, ErrBlockedAddress); blocked != tt.blocked {
+			t.Errorf("checkAddress(%s) = %v, want blocked %v", tt.address, err, tt.blocked)
+		}
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700::1111", true},
+		{"::ffff:8.8.8.8", true},    // IPv4-mapped, public
+		{"::ffff:127.0.0.1", false}, // IPv4-mapped loopback
+		{"::ffff:10.0.0.1", false},  // IPv4-mapped private
+		{"64:ff9b::a00:1", false},   // NAT64 of 10.0.0.1
+		{"64:ff9b::808:808", false}, // NAT64 is refused altogether
+		{"127.0.0.1", false},
+		{"0.0.0.0", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false}, // Carrier-grade NAT
+		{"169.254.169.254", false},
+		{"192.0.2.1", false},
+		{"198.18.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"ff02::1", false},
+		{"2001:db8::1", false},
+	}
+	for _, tt := range tests {
+		ip := net.ParseIP(tt.ip)
+		if ip == nil {
+			t.Fatalf("bad test IP %q", tt.ip)
+		}
+		if got := IsPublicIP(ip); got != tt.public {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", tt.ip, got, tt.public)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		rawURL string
+		ok     bool
+	}{
+		{"https://example.com/a?b=c", true},
+		{"HTTP://EXAMPLE.COM", true},
+		{"javascript:alert(1)", false},
+		{"mailto:a@example.com", false},
+		{"https://user@example.com", false},
+		{"https://", false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.rawURL)
+		if err != nil {
+			t.Fatalf("bad test URL %q: %v", tt.rawURL, err)
+		}
+		if err := checkURL(u); (err == nil) != tt.ok {
+			t.Errorf("checkURL(%q) = %v, want ok %v", tt.rawURL, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package unfurl
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parseHTML reads the metadata in the head of a page and the URL of its JSON
+// oEmbed endpoint, if it advertises one. OpenGraph tags take precedence over
+// Twitter cards, which take precedence over the plain title and description.
+func parseHTML(body []byte, base *url.URL) (*Metadata, string) {
+	tags := make(map[string]string) // First value of each meta property or name
+	var title, oembedURL string
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	inTitle := false
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break // End of the document, or of what was fetched of it
+		}
+		token := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch token.DataAtom {
+			case atom.Body:
+				return finishMetadata(tags, title, base), oembedURL
+			case atom.Title:
+				inTitle = title == ""
+			case atom.Meta:
+				key := strings.ToLower(attr(token, "property"))
+				if key == "" {
+					key = strings.ToLower(attr(token, "name"))
+				}
+				if _, ok := tags[key]; key != "" && !ok {
+					tags[key] = attr(token, "content")
+				}
+			case atom.Link:
+				if oembedURL == "" && strings.EqualFold(attr(token, "type"), "application/json+oembed") &&
+					hasToken(attr(token, "rel"), "alternate") {
+					oembedURL = resolveURL(base, attr(token, "href"))
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				title += token.Data
+			}
+		case html.EndTagToken:
+			switch token.DataAtom {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return finishMetadata(tags, title, base), oembedURL
+			}
+		}
+	}
+	return finishMetadata(tags, title, base), oembedURL
+}
+
+// finishMetadata picks the best of the collected tags
+func finishMetadata(tags map[string]string, title string, base *url.URL) *Metadata {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := strings.TrimSpace(tags[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	meta := &Metadata{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		SiteName:    first("og:site_name", "application-name"),
+		Type:        first("og:type"),
+		ImageURL: resolveURL(base, first(
+			"og:image:secure_url", "og:image:url", "og:image", "twitter:image", "twitter:image:src")),
+	}
+	if meta.Title == "" {
+		meta.Title = title
+	}
+	return meta
+}
+
+// attr returns the value of an attribute of token, or "" if it has none
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasToken reports whether a space-separated attribute value contains token
+func hasToken(value, token string) bool {
+	for _, field := range strings.Fields(value) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
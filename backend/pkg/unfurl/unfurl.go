@@ -0,0 +1,198 @@
+// Package unfurl builds link previews: it finds URLs in text and reads the
+// OpenGraph, Twitter card and oEmbed metadata of the pages they point to.
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the text kept from a page, in characters
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxSiteNameLength    = 100
+)
+
+// Metadata describes a linked page
+type Metadata struct {
+	Title       string
+	Description string
+	ImageURL    string // Absolute http(s) URL
+	SiteName    string
+	Type        string // og:type or oEmbed type, e.g. "article" or "video"; "image" for links to images
+}
+
+// IsEmpty reports whether there is nothing to show for the page
+func (m *Metadata) IsEmpty() bool {
+	return m.Title == "" && m.ImageURL == ""
+}
+
+// urlPattern matches http(s) URLs up to the next whitespace or quote
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"'` + "`" + `]+`)
+
+// ExtractURLs returns the distinct http(s) URLs in text, in order, at most max
+func ExtractURLs(text string, max int) []string {
+	seen := make(map[string]bool)
+	urls := make([]string, 0)
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		if len(urls) >= max {
+			break
+		}
+		match = trimTrailingPunctuation(match)
+		u, err := url.Parse(match)
+		if err != nil || checkURL(u) != nil || seen[match] {
+			continue
+		}
+		seen[match] = true
+		urls = append(urls, match)
+	}
+	return urls
+}
+
+// trimTrailingPunctuation drops punctuation that ends the sentence rather
+// than the URL, keeping closing parentheses that have an opening one
+func trimTrailingPunctuation(s string) string {
+	for len(s) > 0 {
+		last := s[len(s)-1]
+		switch {
+		case strings.IndexByte(".,;:!?]}*", last) >= 0:
+			s = s[:len(s)-1]
+		case last == ')' && strings.Count(s, "(") < strings.Count(s, ")"):
+			s = s[:len(s)-1]
+		default:
+			return s
+		}
+	}
+	return s
+}
+
+// Unfurl fetches rawURL and reads its metadata. Links to images become
+// previews of the image itself. If the page advertises an oEmbed endpoint,
+// it fills in what the page's own tags leave out.
+func Unfurl(ctx context.Context, fetcher Fetcher, rawURL string) (*Metadata, error) {
+	page, err := fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(page.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page URL %q: %w", page.URL, err)
+	}
+
+	switch {
+	case strings.HasPrefix(page.ContentType, "image/"):
+		return &Metadata{ImageURL: page.URL, Type: "image"}, nil
+	case page.ContentType == "text/html" || page.ContentType == "application/xhtml+xml" || page.ContentType == "":
+	default:
+		return &Metadata{}, nil // Nothing to preview
+	}
+
+	meta, oembedURL := parseHTML(page.Body, base)
+	if oembedURL != "" && (meta.Title == "" || meta.ImageURL == "") {
+		if embed, err := fetchOEmbed(ctx, fetcher, oembedURL); err == nil {
+			meta.fillFrom(embed)
+		}
+	}
+	meta.clean()
+	return meta, nil
+}
+
+// oEmbed is the subset of an oEmbed response used for previews. The embed
+// HTML is ignored; previews never run third-party markup.
+type oEmbed struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	URL          string `json:"url"` // The image of "photo" embeds
+}
+
+// fetchOEmbed retrieves a JSON oEmbed response
+func fetchOEmbed(ctx context.Context, fetcher Fetcher, endpoint string) (*Metadata, error) {
+	page, err := fetcher.Fetch(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var embed oEmbed
+	if err := json.Unmarshal(page.Body, &embed); err != nil {
+		return nil, fmt.Errorf("invalid oEmbed response from %s: %w", endpoint, err)
+	}
+	base, _ := url.Parse(page.URL)
+	meta := &Metadata{
+		Title:       embed.Title,
+		Description: embed.AuthorName,
+		SiteName:    embed.ProviderName,
+		ImageURL:    resolveURL(base, embed.ThumbnailURL),
+		Type:        embed.Type,
+	}
+	if embed.Type == "photo" && embed.URL != "" {
+		meta.ImageURL = resolveURL(base, embed.URL)
+	}
+	return meta, nil
+}
+
+// fillFrom copies the fields of other that m lacks
+func (m *Metadata) fillFrom(other *Metadata) {
+	if m.Title == "" {
+		m.Title = other.Title
+	}
+	if m.Description == "" {
+		m.Description = other.Description
+	}
+	if m.ImageURL == "" {
+		m.ImageURL = other.ImageURL
+	}
+	if m.SiteName == "" {
+		m.SiteName = other.SiteName
+	}
+	if m.Type == "" {
+		m.Type = other.Type
+	}
+}
+
+// clean collapses whitespace and cuts overlong text
+func (m *Metadata) clean() {
+	m.Title = truncate(collapseSpace(m.Title), maxTitleLength)
+	m.Description = truncate(collapseSpace(m.Description), maxDescriptionLength)
+	m.SiteName = truncate(collapseSpace(m.SiteName), maxSiteNameLength)
+	m.Type = truncate(strings.ToLower(strings.TrimSpace(m.Type)), 50)
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate cuts s to at most max characters, ending with an ellipsis if cut
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// resolveURL resolves ref against base, keeping only http(s) results
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if checkURL(u) != nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,262 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExtractURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want []string
+	}{
+		{"none", "no links here, just www.example.com", 3, []string{}},
+		{"sentence end", "Read https://example.com/a. Then https://example.org/b!", 3,
+			[]string{"https://example.com/a", "https://example.org/b"}},
+		{"trailing punctuation", "(https://example.com/x), https://example.com/y?; https://example.com/z:", 3,
+			[]string{"https://example.com/x", "https://example.com/y", "https://example.com/z"}},
+		{"balanced parentheses", "see https://en.wikipedia.org/wiki/Go_(programming_language).", 3,
+			[]string{"https://en.wikipedia.org/wiki/Go_(programming_language)"}},
+		{"wrapped in parentheses", "(see https://en.wikipedia.org/wiki/Go_(programming_language))", 3,
+			[]string{"https://en.wikipedia.org/wiki/Go_(programming_language)"}},
+		{"quotes and angle brackets", `"https://example.com/q" <https://example.com/a>`, 3,
+			[]string{"https://example.com/q", "https://example.com/a"}},
+		{"duplicates", "https://example.com https://example.com https://example.org", 3,
+			[]string{"https://example.com", "https://example.org"}},
+		{"max", "https://a.example https://b.example https://c.example", 2,
+			[]string{"https://a.example", "https://b.example"}},
+		{"case-insensitive scheme", "HTTPS://Example.com/Path", 3, []string{"HTTPS://Example.com/Path"}},
+		{"query and fragment", "https://example.com/s?q=go&page=2#top", 3, []string{"https://example.com/s?q=go&page=2#top"}},
+		{"other schemes", "ftp://example.com javascript:alert(1) mailto:a@example.com", 3, []string{}},
+		{"credentials", "https://user:pw@example.com/", 3, []string{}},
+		{"no host", "https://... and http://", 3, []string{}},
+		{"glued to text", "link:https://example.com/x", 3, []string{"https://example.com/x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractURLs(tt.text, tt.max); !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractURLs(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimTrailingPunctuation(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/a.":       "https://example.com/a",
+		"https://example.com/a...":     "https://example.com/a",
+		"https://example.com/a?!":      "https://example.com/a",
+		"https://example.com/a)":       "https://example.com/a",
+		"https://example.com/(a)":      "https://example.com/(a)",
+		"https://example.com/(a))":     "https://example.com/(a)",
+		"https://example.com/a]*":      "https://example.com/a",
+		"https://example.com/a.html":   "https://example.com/a.html",
+		"https://example.com/?q=1&r=2": "https://example.com/?q=1&r=2",
+		".,;":                          "",
+	}
+	for in, want := range tests {
+		if got := trimTrailingPunctuation(in); got != want {
+			t.Errorf("trimTrailingPunctuation(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	base, _ := url.Parse("https://example.com/articles/1")
+	tests := []struct {
+		name       string
+		html       string
+		want       Metadata
+		wantOEmbed string
+	}{
+		{
+			name: "opengraph",
+			html: `<html><head><title>Plain title</title>
+				<meta property="og:title" content="OG title">
+				<meta property="og:description" content="OG description">
+				<meta name="description" content="Plain description">
+				<meta property="og:image" content="/img/cover.png">
+				<meta property="og:site_name" content="Example">
+				<meta property="og:type" content="article">
+				<meta name="twitter:title" content="Twitter title">
+				</head><body></body></html>`,
+			want: Metadata{Title: "OG title", Description: "OG description", ImageURL: "https://example.com/img/cover.png", SiteName: "Example", Type: "article"},
+		},
+		{
+			name: "twitter card",
+			html: `<head><title>Plain title</title>
+				<meta name="twitter:title" content="Twitter title">
+				<meta name="twitter:description" content="Twitter description">
+				<meta name="twitter:image" content="https://cdn.example.com/t.jpg">`,
+			want: Metadata{Title: "Twitter title", Description: "Twitter description", ImageURL: "https://cdn.example.com/t.jpg"},
+		},
+		{
+			name: "plain title and description",
+			html: `<HEAD><TITLE> Plain  title </TITLE><META NAME="Description" CONTENT="Plain description"></HEAD>`,
+			want: Metadata{Title: " Plain  title ", Description: "Plain description"},
+		},
+		{
+			name: "first value wins",
+			html: `<meta property="og:title" content="First"><meta property="og:title" content="Second">`,
+			want: Metadata{Title: "First"},
+		},
+		{
+			name: "secure image preferred, unsafe image dropped",
+			html: `<meta property="og:image" content="javascript:alert(1)"><meta property="og:image:secure_url" content="https://example.com/s.png">`,
+			want: Metadata{ImageURL: "https://example.com/s.png"},
+		},
+		{
+			name: "unsafe image dropped",
+			html: `<meta property="og:image" content="javascript:alert(1)">`,
+			want: Metadata{},
+		},
+		{
+			name:       "oembed link",
+			html:       `<title>T</title><link rel="alternate nofollow" type="application/json+oembed" href="/oembed?url=1">`,
+			want:       Metadata{Title: "T"},
+			wantOEmbed: "https://example.com/oembed?url=1",
+		},
+		{
+			name: "body ignored",
+			html: `<head><title>Head</title></head><body><meta property="og:title" content="Body"></body>`,
+			want: Metadata{Title: "Head"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, oembedURL := parseHTML([]byte(tt.html), base)
+			if *got != tt.want {
+				t.Errorf("metadata = %+v, want %+v", *got, tt.want)
+			}
+			if oembedURL != tt.wantOEmbed {
+				t.Errorf("oEmbed URL = %q, want %q", oembedURL, tt.wantOEmbed)
+			}
+		})
+	}
+}
+
+func TestUnfurl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/og", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head>
+			<meta property="og:title" content="  Stand-in   page ">
+			<meta property="og:description" content="`+strings.Repeat("long ", 300)+`">
+			<meta property="og:image" content="/cover.png">
+			<meta property="og:type" content="Article">
+			<link rel="alternate" type="application/json+oembed" href="/oembed.json">
+		</head></html>`)
+	})
+	mux.HandleFunc("/video", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Video page</title>
+			<link rel="alternate" type="application/json+oembed" href="/oembed.json"></head></html>`)
+	})
+	mux.HandleFunc("/oembed.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"type": "video", "title": "Embedded video", "author_name": "Someone",
+			"provider_name": "VideoSite", "thumbnail_url": "/thumb.jpg", "html": "<iframe src=x></iframe>"}`)
+	})
+	mux.HandleFunc("/broken-oembed", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<title>Still here</title><link rel="alternate" type="application/json+oembed" href="/missing.json">`)
+	})
+	mux.HandleFunc("/photo.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	server := newStandIn(t, mux)
+	fetcher := newTestFetcher(HTTPFetcherOptions{MaxRedirects: 3})
+
+	t.Run("opengraph", func(t *testing.T) {
+		meta, err := Unfurl(context.Background(), fetcher, server.URL+"/og")
+		if err != nil {
+			t.Fatalf("Unfurl: %v", err)
+		}
+		if meta.Title != "Stand-in page" || meta.ImageURL != server.URL+"/cover.png" || meta.Type != "article" {
+			t.Errorf("metadata = %+v", meta)
+		}
+		// The page's own tags are complete, so oEmbed isn't consulted
+		if meta.SiteName != "" {
+			t.Errorf("site name = %q, want none", meta.SiteName)
+		}
+		if n := utf8.RuneCountInString(meta.Description); n != maxDescriptionLength || !strings.HasSuffix(meta.Description, "…") {
+			t.Errorf("description has %d characters, want %d ending in an ellipsis", n, maxDescriptionLength)
+		}
+	})
+
+	t.Run("oembed fills in", func(t *testing.T) {
+		meta, err := Unfurl(context.Background(), fetcher, server.URL+"/video")
+		if err != nil {
+			t.Fatalf("Unfurl: %v", err)
+		}
+		want := Metadata{Title: "Video page", Description: "Someone", ImageURL: server.URL + "/thumb.jpg", SiteName: "VideoSite", Type: "video"}
+		if *meta != want {
+			t.Errorf("metadata = %+v, want %+v", *meta, want)
+		}
+	})
+
+	t.Run("broken oembed", func(t *testing.T) {
+		meta, err := Unfurl(context.Background(), fetcher, server.URL+"/broken-oembed")
+		if err != nil {
+			t.Fatalf("Unfurl: %v", err)
+		}
+		if meta.Title != "Still here" {
+			t.Errorf("metadata = %+v", meta)
+		}
+	})
+
+	t.Run("image", func(t *testing.T) {
+		meta, err := Unfurl(context.Background(), fetcher, server.URL+"/photo.png")
+		if err != nil {
+			t.Fatalf("Unfurl: %v", err)
+		}
+		if *meta != (Metadata{ImageURL: server.URL + "/photo.png", Type: "image"}) {
+			t.Errorf("metadata = %+v", meta)
+		}
+	})
+
+	t.Run("other content", func(t *testing.T) {
+		meta, err := Unfurl(context.Background(), fetcher, server.URL+"/data.json")
+		if err != nil {
+			t.Fatalf("Unfurl: %v", err)
+		}
+		if !meta.IsEmpty() {
+			t.Errorf("metadata = %+v, want empty", meta)
+		}
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		if _, err := Unfurl(context.Background(), fetcher, server.URL+"/missing"); err == nil {
+			t.Error("Unfurl of a missing page succeeded")
+		}
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"hello world", 6, "hello…"},
+		{"héllo wörld", 8, "héllo w…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
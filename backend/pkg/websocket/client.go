@@ -1,6 +1,7 @@
 package websocket // Changed package name
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -14,9 +15,16 @@ type Client struct {
 	UserID   string
 	Username string
 	Image    string
+
+	// Messages read from the connection, waiting for their link previews.
+	// ctx is cancelled when the connection closes.
+	pending chan *Message
+	ctx     context.Context
+	cancel  context.CancelFunc
 }
 
 func NewClient(hub *Hub, conn *websocket.Conn, userID, username, image string) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Client{
 		Hub:      hub,
 		Conn:     conn,
@@ -24,11 +32,17 @@ func NewClient(hub *Hub, conn *websocket.Conn, userID, username, image string) *
 		UserID:   userID,
 		Username: username,
 		Image:    image,
+		pending:  make(chan *Message, 16),
+		ctx:      ctx,
+		cancel:   cancel,
 	}
 }
 
 func (c *Client) ReadPump() {
+	go c.forwardMessages()
 	defer func() {
+		c.cancel()
+		close(c.pending)
 		c.Hub.Unregister <- c
 		c.Conn.Close()
 	}()
@@ -61,10 +75,10 @@ func (c *Client) ReadPump() {
 
 		switch message.Type {
 		case "direct":
-			c.Hub.Broadcast <- &message
+			c.pending <- &message
 
 		case "group":
-			c.Hub.Broadcast <- &message
+			c.pending <- &message
 
 		default:
 			log.Printf("unknown message type: %s", message.Type)
@@ -72,6 +86,17 @@ func (c *Client) ReadPump() {
 	}
 }
 
+// forwardMessages attaches link previews to the messages ReadPump has read and
+// hands them to the hub in order. Fetching previews here keeps slow links from
+// holding up reading; fetches still running when the client disconnects are
+// cancelled and its messages go out without them.
+func (c *Client) forwardMessages() {
+	for message := range c.pending {
+		c.Hub.attachLinkPreviews(c.ctx, message)
+		c.Hub.Broadcast <- message
+	}
+}
+
 func (c *Client) WritePump() {
 	defer func() {
 		c.Conn.Close()
@@ -1,8 +1,10 @@
 package websocket // Changed package name
 
 import (
+	"context"
 	"fmt"
 	"log" // Added for logging
+	"strings"
 	"time"
 
 	// "github.com/HASANALI117/social-network/pkg/helpers" // No longer needed
@@ -20,6 +22,8 @@ type Hub struct {
 	Unregister      chan *Client
 	chatMessageRepo repositories.ChatMessageRepository // Correct field
 	groupRepo       repositories.GroupRepository       // Changed from groupService
+	mediaRepo       repositories.MediaRepository       // Validates image attachments
+	linkPreviewer   LinkPreviewer                      // Optional; set with SetLinkPreviewer
 }
 
 type Message struct {
@@ -27,11 +31,26 @@ type Message struct {
 	SenderID   string `json:"sender_id"`
 	ReceiverID string `json:"receiver_id"`
 	Content    string `json:"content"`
+	ImageID    string `json:"image_id,omitempty"` // Optional image uploaded through /api/media
 	CreatedAt  string `json:"created_at"`
+
+	// Previews of the links in the content, set by the server
+	LinkPreviews []*models.LinkPreview `json:"link_previews,omitempty"`
+}
+
+// LinkPreviewer builds the previews of the links in a message, e.g. services.LinkPreviewService
+type LinkPreviewer interface {
+	Unfurl(ctx context.Context, content string) []*models.LinkPreview
 }
 
-// Update NewHub signature to accept ChatMessageRepository and GroupRepository
-func NewHub(chatMessageRepo repositories.ChatMessageRepository, groupRepo repositories.GroupRepository) *Hub {
+// SetLinkPreviewer makes the hub attach link previews to messages. It must be
+// called before clients connect.
+func (h *Hub) SetLinkPreviewer(previewer LinkPreviewer) {
+	h.linkPreviewer = previewer
+}
+
+// Update NewHub signature to accept ChatMessageRepository, GroupRepository and MediaRepository
+func NewHub(chatMessageRepo repositories.ChatMessageRepository, groupRepo repositories.GroupRepository, mediaRepo repositories.MediaRepository) *Hub {
 	return &Hub{
 		Clients:         make(map[string]*Client),
 		Broadcast:       make(chan *Message),
@@ -39,6 +58,7 @@ func NewHub(chatMessageRepo repositories.ChatMessageRepository, groupRepo reposi
 		Unregister:      make(chan *Client),
 		chatMessageRepo: chatMessageRepo, // Correct initialization
 		groupRepo:       groupRepo,       // Changed from groupService
+		mediaRepo:       mediaRepo,
 	}
 }
 
@@ -70,6 +90,8 @@ func (h *Hub) Run() {
 			}
 
 		case message := <-h.Broadcast:
+			h.checkAttachment(message)
+
 			switch message.Type {
 			case "direct":
 				fmt.Printf("\n📨 New direct message received:\n")
@@ -86,6 +108,7 @@ func (h *Hub) Run() {
 					SenderID:   message.SenderID,
 					ReceiverID: message.ReceiverID,
 					Content:    message.Content,
+					ImageID:    message.ImageID,
 					CreatedAt:  message.CreatedAt,
 				}
 
@@ -145,6 +168,7 @@ func (h *Hub) Run() {
 					GroupID:   message.ReceiverID, // GroupID is in the ReceiverID field
 					SenderID:  message.SenderID,
 					Content:   message.Content,
+					ImageID:   message.ImageID,
 					CreatedAt: createdAt, // Use the parsed time.Time value
 				}
 
@@ -190,6 +214,38 @@ func (h *Hub) Run() {
 	}
 }
 
+// checkAttachment drops an image attachment unless the sender uploaded it and it is
+// a usable image. Who may then view it follows from the conversation it is sent in.
+func (h *Hub) checkAttachment(message *Message) {
+	if message.ImageID == "" {
+		return
+	}
+	media, err := h.mediaRepo.GetByID(message.ImageID)
+	switch {
+	case err != nil:
+		log.Printf("Dropping attachment %s from user %s: %v", message.ImageID, message.SenderID, err)
+	case media.OwnerID != message.SenderID:
+		log.Printf("Dropping attachment %s from user %s: uploaded by another user", message.ImageID, message.SenderID)
+	case !strings.HasPrefix(media.ContentType, "image/") || media.Status == models.MediaStatusFailed:
+		log.Printf("Dropping attachment %s from user %s: not a usable image", message.ImageID, message.SenderID)
+	default:
+		return
+	}
+	message.ImageID = ""
+}
+
+// attachLinkPreviews replaces the previews a client may have sent with those
+// of the links in the message. It fetches previews that aren't cached yet, so
+// it runs on the sender's goroutine rather than the hub's, and gives up on
+// them when ctx is cancelled.
+func (h *Hub) attachLinkPreviews(ctx context.Context, message *Message) {
+	message.LinkPreviews = nil
+	if h.linkPreviewer == nil {
+		return
+	}
+	message.LinkPreviews = h.linkPreviewer.Unfurl(ctx, message.Content)
+}
+
 // deliverMessage is removed as client.Send is now chan interface{} and handles *Message specifically.
 // Direct message sending logic will be handled in the broadcast loops.
 
@@ -1,6 +1,9 @@
 /** @type {import('next').NextConfig} */
 const nextConfig = {
   images: {
+    // Media is authorized per viewer (/api/media checks the session cookie), so
+    // images must be fetched by the browser rather than the optimizer
+    unoptimized: true,
     remotePatterns: [
       {
         protocol: "http",
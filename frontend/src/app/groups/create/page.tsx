@@ -18,7 +18,7 @@ import Link from 'next/link';
 import Image from 'next/image';
 import { FiUploadCloud } from 'react-icons/fi';
 import ImageCropperModal from '../../../components/common/ImageCropperModal';
-import { uploadFileToMinio } from '../../../lib/minioUploader';
+import { uploadMedia } from '../../../lib/mediaUploader';
 import 'react-image-crop/dist/ReactCrop.css';
 import toast from 'react-hot-toast';
 import GroupInviteManager from '../../../components/groups/GroupInviteManager'; // New Import
@@ -127,12 +127,12 @@ export default function CreateGroupPage() {
     setSubmissionError(null);
     setIsUploadingAvatar(false); // Reset upload status
 
-    let uploadedAvatarUrl: string | undefined = undefined;
+    let uploadedAvatarId: string | undefined = undefined;
 
     if (newAvatarFile) {
       setIsUploadingAvatar(true);
       try {
-        uploadedAvatarUrl = await uploadFileToMinio(newAvatarFile);
+        uploadedAvatarId = (await uploadMedia(newAvatarFile)).id;
       } catch (uploadError) {
         console.error('Failed to upload group avatar:', uploadError);
         setSubmissionError('Failed to upload avatar. Please try again.');
@@ -143,16 +143,12 @@ export default function CreateGroupPage() {
     }
 
     try {
-      const payload: { name: string; description: string; avatar_url?: string } = {
+      const payload: { name: string; description: string; avatar_id?: string } = {
         name: formData.name,
         description: formData.description,
       };
-      // Use the uploaded Minio URL if available, otherwise use the URL from the form (if any, though this field will be removed)
-      // Or, if we strictly use file upload, then only uploadedAvatarUrl matters.
-      if (uploadedAvatarUrl) {
-        payload.avatar_url = uploadedAvatarUrl;
-      } else if (formData.avatar_url && !newAvatarFile) { // Keep existing URL if no new file and URL was somehow pre-filled
-        payload.avatar_url = formData.avatar_url;
+      if (uploadedAvatarId) {
+        payload.avatar_id = uploadedAvatarId;
       }
 
 
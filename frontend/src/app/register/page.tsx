@@ -1,9 +1,7 @@
 'use client';
 
 import { useForm } from 'react-hook-form';
-import { useState, useEffect, ChangeEvent } from 'react';
-import ImageCropperModal from '@/components/common/ImageCropperModal';
-import 'react-image-crop/dist/ReactCrop.css';
+import { useEffect } from 'react';
 import { zodResolver } from '@hookform/resolvers/zod';
 import { z } from 'zod';
 import { Button } from '@/components/ui/button';
@@ -14,7 +12,6 @@ import { useRequest } from '@/hooks/useRequest';
 import { UserSignupData } from '@/types/User';
 import { useRouter } from 'next/navigation';
 import toast from 'react-hot-toast';
-import { uploadFileToMinio } from '../../lib/minioUploader';
 
 const formSchema = z.object({
   first_name: z.string().min(2, 'First name must be at least 2 characters'),
@@ -55,15 +52,6 @@ const formSchema = z.object({
 type FormValues = z.infer<typeof formSchema>;
 
 export default function RegisterPage() {
-  const [selectedAvatarFile, setSelectedAvatarFile] = useState<File | null>(
-    null
-  );
-  const [avatarPreviewUrl, setAvatarPreviewUrl] = useState<string | null>(null);
-  const [isCropperOpen, setIsCropperOpen] = useState(false);
-  const [cropperImageSrc, setCropperImageSrc] = useState<string | null>(null);
-  const [originalFileName, setOriginalFileName] = useState<string | null>(null);
-  const [originalFileType, setOriginalFileType] = useState<string | null>(null);
-
   const {
     register,
     handleSubmit,
@@ -110,94 +98,6 @@ export default function RegisterPage() {
     }
   }, [error]);
 
-  useEffect(() => {
-    // Clean up the object URL when the component unmounts or the file changes
-    return () => {
-      if (avatarPreviewUrl) {
-        URL.revokeObjectURL(avatarPreviewUrl);
-      }
-    };
-  }, [avatarPreviewUrl]);
-
-  const handleAvatarChange = (event: ChangeEvent<HTMLInputElement>) => {
-    if (avatarPreviewUrl) {
-      URL.revokeObjectURL(avatarPreviewUrl);
-      setAvatarPreviewUrl(null);
-    }
-    const file = event.target.files?.[0];
-    if (file) {
-      if (file.size > 5 * 1024 * 1024) {
-        // 5MB
-        toast.error('File must be less than 5MB');
-        setSelectedAvatarFile(null);
-        if (event.target) event.target.value = ''; // Reset file input
-        return;
-      }
-      if (
-        !['image/jpeg', 'image/png', 'image/webp', 'image/gif'].includes(
-          file.type
-        )
-      ) {
-        toast.error('Only JPEG, PNG, WEBP, and GIF formats are allowed');
-        setSelectedAvatarFile(null);
-        if (event.target) event.target.value = ''; // Reset file input
-        return;
-      }
-
-      setOriginalFileName(file.name);
-      setOriginalFileType(file.type);
-
-      const reader = new FileReader();
-      reader.onload = () => {
-        setCropperImageSrc(reader.result as string);
-        setIsCropperOpen(true);
-      };
-      reader.readAsDataURL(file);
-      // Clear the file input so the same file can be selected again if needed after closing cropper without saving
-      if (event.target) event.target.value = '';
-    } else {
-      setSelectedAvatarFile(null);
-      setCropperImageSrc(null);
-      setOriginalFileName(null);
-      setOriginalFileType(null);
-    }
-  };
-
-  const handleCropComplete = (croppedImageBlob: Blob | null) => {
-    if (croppedImageBlob) {
-      const fileName = originalFileName || 'avatar.png';
-      const fileType = originalFileType || croppedImageBlob.type || 'image/png';
-
-      // Ensure the filename has an appropriate extension
-      let finalFileName = fileName;
-      const currentExtension = fileName.split('.').pop()?.toLowerCase();
-      const blobExtension = fileType.split('/')[1];
-
-      if (blobExtension && currentExtension !== blobExtension) {
-        finalFileName = `${
-          fileName.substring(0, fileName.lastIndexOf('.')) || fileName
-        }.${blobExtension}`;
-      }
-
-      const croppedFile = new File([croppedImageBlob], finalFileName, {
-        type: fileType,
-      });
-      setSelectedAvatarFile(croppedFile);
-
-      if (avatarPreviewUrl) {
-        URL.revokeObjectURL(avatarPreviewUrl);
-      }
-      setAvatarPreviewUrl(URL.createObjectURL(croppedFile));
-    }
-    setIsCropperOpen(false);
-    setCropperImageSrc(null);
-    // Reset original file info if not used or if cropper is simply closed
-    if (!croppedImageBlob) {
-      setOriginalFileName(null);
-      setOriginalFileType(null);
-    }
-  };
-
   return (
     <>
       <div className="max-w-md mx-auto my-12 p-6 bg-white rounded-lg shadow-md dark:bg-zinc-900">
@@ -206,35 +106,17 @@ export default function RegisterPage() {
           onSubmit={(e) => {
             e.preventDefault();
             handleSubmit(async (formData: FormValues) => {
-              let avatarUrl = '';
-              if (selectedAvatarFile) {
-                try {
-                  // uploadFileToMinio returns the URL string directly or throws an error
-                  avatarUrl = await uploadFileToMinio(selectedAvatarFile);
-                  if (!avatarUrl) {
-                    // Should not happen if uploadFileToMinio resolves
-                    toast.error(
-                      'Avatar upload failed to return a URL. Please try again.'
-                    );
-                    return;
-                  }
-                } catch (uploadError) {
-                  console.error('Avatar upload error:', uploadError);
-                  toast.error('Avatar upload failed. Please try again.');
-                  return;
-                }
-              }
-
-              const registrationData = {
-                ...formData,
-                avatar_url: avatarUrl || null, // Send null or empty string if no avatar
-              };
+              const registrationData = { ...formData };
 
               post<UserSignupData>(
                 '/api/users',
                 registrationData,
                 (userData: UserSignupData) => {
-                  toast.success('Account created successfully! Please log in.');
+                  // Uploads need a session, so the avatar is added from the
+                  // profile after logging in
+                  toast.success(
+                    'Account created successfully! Please log in. You can add a profile picture from your profile.'
+                  );
                   console.log('User created:', userData);
                   router.push('/login');
                 }
@@ -331,32 +213,6 @@ export default function RegisterPage() {
               )}
             </Field>
 
-            <Field>
-              <label
-                className="block text-sm font-medium mb-1"
-                htmlFor="avatar"
-              >
-                Profile Picture (Optional, max 5MB, JPG/PNG/WEBP/GIF)
-              </label>
-              {avatarPreviewUrl && (
-                <div className="mt-2 mb-2">
-                  <img
-                    src={avatarPreviewUrl}
-                    alt="Avatar Preview"
-                    className="w-24 h-24 rounded-full object-cover mx-auto"
-                  />
-                </div>
-              )}
-              <Input
-                id="avatar"
-                type="file"
-                accept="image/jpeg,image/png,image/webp,image/gif"
-                onChange={handleAvatarChange}
-                className="block w-full text-sm text-gray-900 border border-gray-300 rounded-lg cursor-pointer bg-gray-50 dark:text-gray-400 focus:outline-none dark:bg-zinc-700 dark:border-zinc-600 dark:placeholder-zinc-400"
-              />
-              {/* No direct form error for avatar via react-hook-form, handled by toast */}
-            </Field>
-
             <Field>
               <label
                 className="block text-sm font-medium mb-1"
@@ -459,21 +315,6 @@ export default function RegisterPage() {
           </Link>
         </p>
       </div>
-      {cropperImageSrc && (
-        <ImageCropperModal
-          isOpen={isCropperOpen}
-          onClose={() => {
-            setIsCropperOpen(false);
-            setCropperImageSrc(null);
-            setOriginalFileName(null);
-            setOriginalFileType(null);
-          }}
-          imageSrc={cropperImageSrc}
-          onCropComplete={handleCropComplete}
-          aspect={1}
-          circularCrop={true}
-        />
-      )}
     </>
   );
 }
@@ -51,7 +51,30 @@ export default function PostCard({ post }: PostCardProps) {
       <Link href={`/posts/${post.id}`} className="block space-y-4">
         <h2 className="text-xl font-semibold text-gray-100">{post.title}</h2>
         <p className="text-gray-200">{post.content}</p>
-        {post.image_url && (
+        {post.attachments && post.attachments.length > 0 ? (
+          <div className={`my-3 grid gap-2 ${post.attachments.length > 1 ? "grid-cols-2" : "grid-cols-1"}`}>
+            {post.attachments.map((attachment) =>
+              attachment.media.content_type.startsWith("video/") ? (
+                <video
+                  key={attachment.media.id}
+                  src={attachment.media.url}
+                  aria-label={attachment.alt_text || undefined}
+                  controls
+                  preload="metadata"
+                  className="rounded-lg w-full h-auto max-h-[500px]"
+                  onClick={(e) => e.preventDefault()}
+                />
+              ) : (
+                <img
+                  key={attachment.media.id}
+                  src={post.attachments!.length > 1 ? attachment.media.variants?.thumb ?? attachment.media.url : attachment.media.url}
+                  alt={attachment.alt_text}
+                  className="rounded-lg w-full h-auto object-cover max-h-[500px]"
+                />
+              )
+            )}
+          </div>
+        ) : post.image_url && (
           <div className="my-3 relative">
             <img
               src={post.image_url}
@@ -9,7 +9,7 @@ import { Textarea } from '@/components/ui/textarea';
 import { Input } from '@/components/ui/input';
 import { Alert } from '@/components/ui/alert';
 import { useUserStore } from '@/store/useUserStore';
-import { uploadFileToMinio } from '@/lib/minioUploader';
+import { uploadMedia } from '@/lib/mediaUploader';
 
 interface CommentFormValues {
   content: string;
@@ -53,21 +53,16 @@ const CreateCommentForm: React.FC<CreateCommentFormProps> = ({ postId, onComment
     }
 
     setSubmissionError(null);
-    let imageUrl: string | undefined = undefined;
+    let imageId: string | undefined = undefined;
 
     try {
       if (selectedFile) { // Use selectedFile instead of croppedImageFile
-        const uploadedUrl = await uploadFileToMinio(selectedFile); // Removed second argument
-        if (uploadedUrl) { // Assuming uploadFileToMinio returns string URL directly or null/undefined on failure
-          imageUrl = uploadedUrl;
-        } else {
-          throw new Error('Failed to get image URL from upload.');
-        }
+        imageId = (await uploadMedia(selectedFile)).id;
       }
 
       const payload = {
         content: data.content,
-        ...(imageUrl && { image_url: imageUrl }),
+        ...(imageId && { image_id: imageId }),
       };
 
       await submitComment(`/api/posts/${postId}/comments`, payload, (newComment: Comment) => {
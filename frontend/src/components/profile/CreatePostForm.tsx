@@ -8,7 +8,7 @@ import { Post } from '@/types/Post';
 import { User } from '@/types/User'; // Added User import
 import { useState, useRef, useEffect, ChangeEvent } from 'react';
 import toast from 'react-hot-toast';
-import { uploadFileToMinio } from '../../lib/minioUploader';
+import { uploadMedia } from '../../lib/mediaUploader';
 import Image from 'next/image';
 
 // Define CreatePostFormValues locally
@@ -28,7 +28,15 @@ interface CreatePostFormProps {
 
 const MAX_FILE_SIZE_MB = 5;
 const MAX_FILE_SIZE_BYTES = MAX_FILE_SIZE_MB * 1024 * 1024;
-const ALLOWED_FILE_TYPES = ['image/jpeg', 'image/png', 'image/gif', 'image/webp'];
+const ALLOWED_FILE_TYPES = ['image/jpeg', 'image/png', 'image/gif', 'image/webp', 'video/mp4', 'video/webm'];
+const MAX_ATTACHMENTS = 4; // Matches MEDIA_MAX_POST_ATTACHMENTS on the backend
+
+// A file picked for the post's gallery, uploaded on submit
+interface SelectedAttachment {
+  file: File;
+  previewUrl: string;
+  altText: string;
+}
 
 export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProps) {
   const { user } = useUserStore();
@@ -41,8 +49,9 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
     get: getFollowers, // Renamed from getFollowersRequest
   } = useRequest<User[]>();
   const [isUploadingImage, setIsUploadingImage] = useState(false);
-  const [selectedPostImageFile, setSelectedPostImageFile] = useState<File | null>(null);
-  const [postImagePreviewUrl, setPostImagePreviewUrl] = useState<string | null>(null);
+  const [attachments, setAttachments] = useState<SelectedAttachment[]>([]);
+  const attachmentsRef = useRef<SelectedAttachment[]>([]);
+  attachmentsRef.current = attachments;
   const fileInputRef = useRef<HTMLInputElement>(null);
   const [followers, setFollowers] = useState<User[]>([]);
   const [selectedFollowerIds, setSelectedFollowerIds] = useState<string[]>([]);
@@ -68,13 +77,11 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
   const privacyValue = watch('privacy'); // Watch privacy field
 
   useEffect(() => {
-    // Clean up the object URL when the component unmounts or the preview URL changes
+    // Clean up the preview URLs when the component unmounts
     return () => {
-      if (postImagePreviewUrl) {
-        URL.revokeObjectURL(postImagePreviewUrl);
-      }
+      attachmentsRef.current.forEach(a => URL.revokeObjectURL(a.previewUrl));
     };
-  }, [postImagePreviewUrl]);
+  }, []);
 
   // Effect to trigger follower fetch
   useEffect(() => {
@@ -120,36 +127,45 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
   }, [followersDataFromHook, followersErrorFromHook, isLoadingFollowersFromHook]);
 
   const handleFileSelect = (event: ChangeEvent<HTMLInputElement>) => {
-    const file = event.target.files?.[0];
-    if (file) {
+    const files = Array.from(event.target.files ?? []);
+    if (fileInputRef.current) fileInputRef.current.value = ''; // Allow picking the same file again
+
+    const added: SelectedAttachment[] = [];
+    for (const file of files) {
+      if (attachments.length + added.length >= MAX_ATTACHMENTS) {
+        toast.error(`A post can have at most ${MAX_ATTACHMENTS} attachments.`);
+        break;
+      }
       if (!ALLOWED_FILE_TYPES.includes(file.type)) {
-        toast.error(`Invalid file type. Please select a JPG, PNG, GIF, or WEBP image.`);
-        if (fileInputRef.current) fileInputRef.current.value = ''; // Reset file input
-        return;
+        toast.error(`Invalid file type. Please select a JPG, PNG, GIF or WEBP image, or an MP4 or WEBM video.`);
+        continue;
       }
       if (file.size > MAX_FILE_SIZE_BYTES) {
         toast.error(`File is too large. Maximum size is ${MAX_FILE_SIZE_MB}MB.`);
-        if (fileInputRef.current) fileInputRef.current.value = ''; // Reset file input
-        return;
-      }
-      setSelectedPostImageFile(file);
-      if (postImagePreviewUrl) {
-        URL.revokeObjectURL(postImagePreviewUrl); // Revoke old URL
+        continue;
       }
-      setPostImagePreviewUrl(URL.createObjectURL(file));
+      added.push({ file, previewUrl: URL.createObjectURL(file), altText: '' });
+    }
+    if (added.length > 0) {
+      setAttachments(prev => [...prev, ...added]);
     }
   };
 
-  const handleRemoveImage = () => {
-    setSelectedPostImageFile(null);
-    if (postImagePreviewUrl) {
-      URL.revokeObjectURL(postImagePreviewUrl);
-    }
-    setPostImagePreviewUrl(null);
+  const handleRemoveAttachment = (index: number) => {
+    setAttachments(prev => {
+      URL.revokeObjectURL(prev[index].previewUrl);
+      return prev.filter((_, i) => i !== index);
+    });
+  };
+
+  const handleAltTextChange = (index: number, altText: string) => {
+    setAttachments(prev => prev.map((a, i) => (i === index ? { ...a, altText } : a)));
+  };
+
+  const clearAttachments = () => {
+    attachments.forEach(a => URL.revokeObjectURL(a.previewUrl));
+    setAttachments([]);
     setValue('image_url', null);
-    if (fileInputRef.current) {
-      fileInputRef.current.value = ''; // Reset the file input
-    }
   };
 
   const handleFollowerSelectionChange = (followerId: string) => {
@@ -168,17 +184,20 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
       return;
     }
 
-    let imageUrl: string | null = null;
+    const uploadedAttachments: { media_id: string; alt_text: string }[] = [];
 
-    if (selectedPostImageFile) {
+    if (attachments.length > 0) {
       setIsUploadingImage(true);
-      const uploadToastId = toast.loading('Uploading image...');
+      const uploadToastId = toast.loading('Uploading attachments...');
       try {
-        imageUrl = await uploadFileToMinio(selectedPostImageFile);
-        toast.success('Image uploaded successfully!', { id: uploadToastId });
+        for (const attachment of attachments) {
+          const media = await uploadMedia(attachment.file);
+          uploadedAttachments.push({ media_id: media.id, alt_text: attachment.altText.trim() });
+        }
+        toast.success('Attachments uploaded successfully!', { id: uploadToastId });
       } catch (err) {
-        console.error('Failed to upload image:', err);
-        toast.error('Failed to upload image. Please try again.', { id: uploadToastId });
+        console.error('Failed to upload attachment:', err);
+        toast.error('Failed to upload attachments. Please try again.', { id: uploadToastId });
         setIsUploadingImage(false);
         return;
       } finally {
@@ -190,7 +209,7 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
       user_id: user.id,
       title: data.title,
       content: data.content,
-      image_url: imageUrl,
+      attachments: uploadedAttachments,
       privacy: groupId ? 'public' : data.privacy // If in group, force public (within group context)
     };
 
@@ -212,7 +231,7 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
       if (result) {
         toast.success('Post created successfully!', { id: createPostToastId });
         reset(); // Resets form to defaultValues
-        handleRemoveImage(); // Clear image selection and preview
+        clearAttachments(); // Clear attachment selection and previews
         setSelectedFollowerIds([]); // Clear selected followers
         setFollowers([]); // Clear fetched followers list
         // setValue('allowed_user_ids', []); // Already handled by reset if in defaultValues
@@ -245,23 +264,39 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
         rows={3}
       />
 
-      {postImagePreviewUrl && (
-        <div className="mb-4 relative">
-          <Image
-            src={postImagePreviewUrl}
-            alt="Selected image preview"
-            width={200}
-            height={200}
-            className="rounded-lg object-cover max-h-48 w-auto"
-          />
-          <button
-            type="button"
-            onClick={handleRemoveImage}
-            className="absolute top-1 right-1 bg-red-600 hover:bg-red-700 text-white rounded-full p-1.5"
-            aria-label="Remove image"
-          >
-            <FiX size={16} />
-          </button>
+      {attachments.length > 0 && (
+        <div className="mb-4 grid grid-cols-2 gap-3">
+          {attachments.map((attachment, index) => (
+            <div key={attachment.previewUrl} className="relative">
+              {attachment.file.type.startsWith('video/') ? (
+                <video src={attachment.previewUrl} className="rounded-lg object-cover h-32 w-full" muted />
+              ) : (
+                <Image
+                  src={attachment.previewUrl}
+                  alt={attachment.altText || 'Selected image preview'}
+                  width={200}
+                  height={200}
+                  className="rounded-lg object-cover h-32 w-full"
+                />
+              )}
+              <button
+                type="button"
+                onClick={() => handleRemoveAttachment(index)}
+                className="absolute top-1 right-1 bg-red-600 hover:bg-red-700 text-white rounded-full p-1.5"
+                aria-label="Remove attachment"
+              >
+                <FiX size={16} />
+              </button>
+              <input
+                type="text"
+                value={attachment.altText}
+                onChange={(e) => handleAltTextChange(index, e.target.value)}
+                placeholder="Describe this for people using screen readers"
+                maxLength={1000}
+                className="mt-2 w-full p-2 text-sm border border-gray-700 bg-gray-900 text-gray-100 rounded-lg focus:ring-2 focus:ring-purple-500 focus:border-transparent"
+              />
+            </div>
+          ))}
         </div>
       )}
 
@@ -270,6 +305,7 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
         ref={fileInputRef}
         onChange={handleFileSelect}
         accept={ALLOWED_FILE_TYPES.join(',')}
+        multiple
         className="hidden"
         id="post-image-upload"
       />
@@ -310,10 +346,10 @@ export default function CreatePostForm({ onSubmit, groupId }: CreatePostFormProp
             type="button"
             onClick={() => fileInputRef.current?.click()}
             className="text-purple-400 hover:text-purple-300 flex items-center gap-2 px-3 py-2 rounded-md border border-gray-700 hover:border-purple-500"
-            disabled={isLoading}
+            disabled={isLoading || attachments.length >= MAX_ATTACHMENTS}
           >
             <FiImage />
-            {selectedPostImageFile ? 'Change Image' : 'Add Image'}
+            {attachments.length > 0 ? `Add Media (${attachments.length}/${MAX_ATTACHMENTS})` : 'Add Media'}
           </button>
           {!groupId && ( // Only show privacy dropdown if not in group context
             <select
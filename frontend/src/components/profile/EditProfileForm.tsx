@@ -7,7 +7,7 @@ import { z } from 'zod';
 import { zodResolver } from '@hookform/resolvers/zod';
 import { useState, useEffect, ChangeEvent } from 'react';
 import Image from 'next/image';
-import { uploadFileToMinio } from '@/lib/minioUploader'; // Adjusted path
+import { uploadMedia } from '@/lib/mediaUploader';
 import ImageCropperModal from '@/components/common/ImageCropperModal'; // Added import
 import 'react-image-crop/dist/ReactCrop.css'; // Ensure CSS is imported
 // import { toast } from 'react-hot-toast'; // Assuming you have a toast library
@@ -107,11 +107,11 @@ export default function EditProfileForm({ user, onSubmit, onCancel }: EditProfil
 
   const handleFormSubmit = async (data: ProfileFormData) => {
     setIsUploading(true);
-    let newAvatarMinioUrl: string | undefined = undefined;
+    let newAvatarId: string | undefined = undefined;
 
     if (newAvatarFile) {
       try {
-        newAvatarMinioUrl = await uploadFileToMinio(newAvatarFile);
+        newAvatarId = (await uploadMedia(newAvatarFile)).id;
       } catch (error) {
         console.error('Failed to upload avatar:', error);
         // toast.error('Failed to upload avatar. Please try again.');
@@ -121,14 +121,10 @@ export default function EditProfileForm({ user, onSubmit, onCancel }: EditProfil
     }
 
     const payload: UpdateUserProfileData = { ...data };
-    if (newAvatarMinioUrl) {
-      payload.avatar_url = newAvatarMinioUrl;
+    if (newAvatarId) {
+      payload.avatar_id = newAvatarId;
     }
-    // If no new avatar, avatar_url will not be in payload, assuming backend handles partial update.
-    // If backend requires it, send user.avatar_url:
-    // else if (user.avatar_url && !newAvatarFile) {
-    //  payload.avatar_url = user.avatar_url;
-    // }
+    // If no new avatar, avatar_id is left out and the backend keeps the current one.
 
 
     onSubmit(payload);
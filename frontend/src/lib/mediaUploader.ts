@@ -0,0 +1,45 @@
+// Uploads go through the backend (POST /api/media), which validates the file
+// and stores it. Posts, comments and avatars then reference the returned ID.
+
+export interface UploadedMedia {
+  id: string;
+  url: string;
+  content_type: string;
+  size: number;
+  status: 'pending' | 'processing' | 'ready' | 'failed';
+  width?: number;
+  height?: number;
+  blurhash?: string;
+  variants?: Record<string, string>; // e.g. full, thumb, avatar; set once processed
+  created_at: string;
+}
+
+export const uploadMedia = async (file: File): Promise<UploadedMedia> => {
+  if (!file) {
+    throw new Error("No file provided for upload.");
+  }
+
+  const formData = new FormData();
+  formData.append("file", file);
+
+  // No Content-Type header: the browser sets the multipart boundary itself
+  const response = await fetch("/api/media", {
+    method: "POST",
+    body: formData,
+  });
+
+  if (!response.ok) {
+    let message = `${response.status} ${response.statusText}`;
+    try {
+      const body = await response.json();
+      if (body?.error) {
+        message = body.error;
+      }
+    } catch {
+      // Not a JSON error body
+    }
+    throw new Error(`Failed to upload file: ${message}`);
+  }
+
+  return response.json();
+};
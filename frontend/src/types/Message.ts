@@ -5,6 +5,7 @@ export interface Message {
   sender_id: string;
   receiver_id: string; // Target user ID for direct messages
   content: string;
+  image_id?: string; // Optional image from uploadMedia; served at /api/media/{image_id}
   created_at: string; // ISO 8601 format (e.g., from new Date().toISOString())
   sender_username?: string; // Optional: For display purposes
   sender_avatar_url?: string; // Optional: For display purposes
@@ -1,10 +1,20 @@
+import { UploadedMedia } from '@/lib/mediaUploader';
+
+// An item of a post's gallery
+export interface PostAttachment {
+  position: number;
+  alt_text: string;
+  media: UploadedMedia;
+}
+
 export interface Post {
   id: string;
   user_id: string;
   group_id?: string | null; // Match backend (sql.NullString becomes string | null)
   title: string;
   content: string;
-  image_url?: string; // Optional
+  image_url?: string; // Optional; the first image of the gallery
+  attachments?: PostAttachment[]; // Gallery of images and videos, in order
   privacy: 'public' | 'semi_private' | 'private'; // Match backend model constants
   created_at: string; // ISO date string from backend
   user_first_name?: string; // Optional, from PostResponse
@@ -68,7 +68,6 @@ export interface UserSignupData {
   birth_date: string;
   username?: string;
   about_me?: string;
-  avatar_url?: string | null;
 }
 
 // Type for updating user profile data
@@ -77,6 +76,6 @@ export interface UpdateUserProfileData {
   last_name?: string;
   username?: string;
   about_me?: string;
-  avatar_url?: string;
+  avatar_id?: string; // Media ID returned by POST /api/media
   // Add other updatable fields as needed, making them optional
 }